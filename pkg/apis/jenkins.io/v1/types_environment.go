@@ -36,6 +36,15 @@ type EnvironmentSpec struct {
 	TeamSettings      TeamSettings          `json:"teamSettings,omitempty" protobuf:"bytes,9,opt,name=teamSettings"`
 	PreviewGitSpec    PreviewGitSpec        `json:"previewGitInfo,omitempty" protobuf:"bytes,10,opt,name=previewGitInfo"`
 	WebHookEngine     WebHookEngineType     `json:"webHookEngine,omitempty" protobuf:"bytes,11,opt,name=webHookEngine"`
+	// ProgressiveDelivery if specified then promotions are rolled out gradually using a canary or blue/green strategy.
+	// It is not supported for Environments promoted via the Pull Requests on their git repository
+	ProgressiveDelivery *ProgressiveDeliverySpec `json:"progressiveDelivery,omitempty" protobuf:"bytes,12,opt,name=progressiveDelivery"`
+	// DeploymentWindows if specified then promotions into this environment are queued until one of the windows is open
+	DeploymentWindows []DeploymentWindow `json:"deploymentWindows,omitempty" protobuf:"bytes,13,opt,name=deploymentWindows"`
+	// Freezes are periods when promotions into this environment are queued even if a deployment window is open
+	Freezes []DeploymentWindow `json:"freezes,omitempty" protobuf:"bytes,14,opt,name=freezes"`
+	// CVEPolicy if specified then the images promoted into this environment are checked for vulnerabilities
+	CVEPolicy *CVEPolicy `json:"cvePolicy,omitempty" protobuf:"bytes,15,opt,name=cvePolicy"`
 }
 
 // EnvironmentStatus is the status for an Environment resource
@@ -115,6 +124,81 @@ var PromotionStrategyTypeValues = []string{
 	string(PromotionStrategyTypeNever),
 }
 
+// ProgressiveDeliveryStrategyType is the type of a progressive delivery strategy
+type ProgressiveDeliveryStrategyType string
+
+const (
+	// ProgressiveDeliveryStrategyTypeCanary shifts traffic to the new version gradually using an Istio VirtualService
+	ProgressiveDeliveryStrategyTypeCanary ProgressiveDeliveryStrategyType = "Canary"
+	// ProgressiveDeliveryStrategyTypeBlueGreen deploys the new version alongside the current one then switches the Service selector over
+	ProgressiveDeliveryStrategyTypeBlueGreen ProgressiveDeliveryStrategyType = "BlueGreen"
+)
+
+// ProgressiveDeliveryStrategyTypeValues is the list of all values
+var ProgressiveDeliveryStrategyTypeValues = []string{
+	string(ProgressiveDeliveryStrategyTypeCanary),
+	string(ProgressiveDeliveryStrategyTypeBlueGreen),
+}
+
+// ProgressiveDeliverySpec defines how a new version is rolled out into an Environment
+type ProgressiveDeliverySpec struct {
+	Strategy ProgressiveDeliveryStrategyType `json:"strategy,omitempty" protobuf:"bytes,1,opt,name=strategy"`
+	// Service is the name of the Istio VirtualService for canaries or the Service for blue/green. Defaults to the application name.
+	// The canary and blue/green releases set the service.name value of the chart to their release name so they get their own Service
+	Service string `json:"service,omitempty" protobuf:"bytes,2,opt,name=service"`
+	// CanarySteps are the percentages of traffic sent to the canary before it is promoted. Defaults to 10, 50
+	CanarySteps []int32 `json:"canarySteps,omitempty" protobuf:"bytes,3,opt,name=canarySteps"`
+	// StepInterval is how long to wait after each step before analysing the metrics such as 1m or 30s
+	StepInterval string `json:"stepInterval,omitempty" protobuf:"bytes,4,opt,name=stepInterval"`
+	// Analysis the metrics checked before moving on to the next step
+	Analysis *PromotionAnalysis `json:"analysis,omitempty" protobuf:"bytes,5,opt,name=analysis"`
+}
+
+// PromotionAnalysis is a Prometheus query which must stay below a threshold for a promotion to continue
+type PromotionAnalysis struct {
+	// PrometheusURL the URL of Prometheus. Defaults to the server installed by the prometheus addon
+	PrometheusURL string `json:"prometheusURL,omitempty" protobuf:"bytes,1,opt,name=prometheusURL"`
+	// Query the PromQL query to evaluate
+	Query string `json:"query,omitempty" protobuf:"bytes,2,opt,name=query"`
+	// MaxValue the analysis fails if any value returned by the query is greater than this value
+	MaxValue string `json:"maxValue,omitempty" protobuf:"bytes,3,opt,name=maxValue"`
+}
+
+// DeploymentWindow is a recurring period of time which starts on a cron schedule and lasts for a duration
+type DeploymentWindow struct {
+	Name string `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
+	// Schedule the cron expression of when the window starts such as "0 9 * * 1-4" for 9am Monday to Thursday
+	Schedule string `json:"schedule,omitempty" protobuf:"bytes,2,opt,name=schedule"`
+	// Duration how long the window lasts such as 8h
+	Duration string `json:"duration,omitempty" protobuf:"bytes,3,opt,name=duration"`
+	// TimeZone the IANA time zone of the schedule such as Europe/London. Defaults to UTC
+	TimeZone string `json:"timeZone,omitempty" protobuf:"bytes,4,opt,name=timeZone"`
+}
+
+// CVEPolicyActionType is what happens when an image violates a CVEPolicy
+type CVEPolicyActionType string
+
+const (
+	// CVEPolicyActionTypeBlock fails the promotion if the policy is violated
+	CVEPolicyActionTypeBlock CVEPolicyActionType = "Block"
+	// CVEPolicyActionTypeWarn only warns if the policy is violated
+	CVEPolicyActionTypeWarn CVEPolicyActionType = "Warn"
+)
+
+// CVEPolicy defines which vulnerabilities are allowed in the images promoted into an Environment
+type CVEPolicy struct {
+	// MaxSeverity the highest severity of vulnerability allowed such as Low or Medium
+	MaxSeverity string `json:"maxSeverity,omitempty" protobuf:"bytes,1,opt,name=maxSeverity"`
+	// AllowedCVEs the IDs of vulnerabilities which are allowed whatever their severity
+	AllowedCVEs []string `json:"allowedCVEs,omitempty" protobuf:"bytes,2,opt,name=allowedCVEs"`
+	// GracePeriod how long a vulnerability is allowed after it was first found in the application such as 168h
+	GracePeriod string `json:"gracePeriod,omitempty" protobuf:"bytes,3,opt,name=gracePeriod"`
+	// Action what happens when the policy is violated. Defaults to Block
+	Action CVEPolicyActionType `json:"action,omitempty" protobuf:"bytes,4,opt,name=action"`
+	// MaxChecks how many times a blocked promotion is checked again before it fails. Defaults to 30
+	MaxChecks int32 `json:"maxChecks,omitempty" protobuf:"varint,5,opt,name=maxChecks"`
+}
+
 // EnvironmentRepositoryType is the repository type
 type EnvironmentRepositoryType string
 
@@ -150,6 +234,20 @@ type TeamSettings struct {
 	DockerRegistryOrg   string               `json:"dockerRegistryOrg,omitempty" protobuf:"bytes,16,opt,name=dockerRegistryOrg" command:"dockerregistryorg" commandUsage:"Docker registry organisation used for new projects in Jenkins X."`
 	GitPrivate          bool                 `json:"gitPrivate,omitempty" protobuf:"bytes,17,opt,name=gitPrivate" command:"gitprivate" commandUsage:"Are new repositories private by default"`
 	KubeProvider        string               `json:"kubeProvider,omitempty" protobuf:"bytes,18,opt,name=kubeProvider"`
+	// BranchProtection is applied to the repositories created or imported by the team
+	BranchProtection *BranchProtectionPolicy `json:"branchProtection,omitempty" protobuf:"bytes,19,opt,name=branchProtection"`
+}
+
+// BranchProtectionPolicy describes the checks required before merging into the protected branch of a repository
+type BranchProtectionPolicy struct {
+	// Branch is the branch to protect which defaults to master
+	Branch string `json:"branch,omitempty" protobuf:"bytes,1,opt,name=branch"`
+	// RequiredStatusContexts are the commit status contexts which must succeed before merging
+	RequiredStatusContexts []string `json:"requiredStatusContexts,omitempty" protobuf:"bytes,2,opt,name=requiredStatusContexts"`
+	// RequiredApprovingReviewCount is the number of approving reviews required before merging
+	RequiredApprovingReviewCount int `json:"requiredApprovingReviewCount,omitempty" protobuf:"bytes,3,opt,name=requiredApprovingReviewCount"`
+	// EnforceAdmins applies the protection to repository administrators too
+	EnforceAdmins bool `json:"enforceAdmins,omitempty" protobuf:"bytes,4,opt,name=enforceAdmins"`
 }
 
 // QuickStartLocation
@@ -172,6 +270,8 @@ type PreviewGitSpec struct {
 	BuildStatusURL  string   `json:"buildStatusUrl,omitempty" protobuf:"bytes,7,opt,name=buildStatusUrl"`
 	ApplicationName string   `json:"appName,omitempty" protobuf:"bytes,8,opt,name=appName"`
 	ApplicationURL  string   `json:"applicationURL,omitempty" protobuf:"bytes,9,opt,name=applicationURL"`
+	// Hibernated is true if the deployments of the preview have been scaled to zero as it was idle
+	Hibernated bool `json:"hibernated,omitempty" protobuf:"varint,10,opt,name=hibernated"`
 }
 
 // UserSpec is the user details
@@ -51,10 +51,14 @@ type PipelineActivitySpec struct {
 
 // PipelineActivityStep represents a step in a pipeline activity
 type PipelineActivityStep struct {
-	Kind    ActivityStepKindType `json:"kind,omitempty" protobuf:"bytes,1,opt,name=kind"`
-	Stage   *StageActivityStep   `json:"stage,omitempty" protobuf:"bytes,2,opt,name=stage"`
-	Promote *PromoteActivityStep `json:"promote,omitempty" protobuf:"bytes,3,opt,name=promote"`
-	Preview *PreviewActivityStep `json:"preview,omitempty" protobuf:"bytes,4,opt,name=preview"`
+	Kind     ActivityStepKindType  `json:"kind,omitempty" protobuf:"bytes,1,opt,name=kind"`
+	Stage    *StageActivityStep    `json:"stage,omitempty" protobuf:"bytes,2,opt,name=stage"`
+	Promote  *PromoteActivityStep  `json:"promote,omitempty" protobuf:"bytes,3,opt,name=promote"`
+	Preview  *PreviewActivityStep  `json:"preview,omitempty" protobuf:"bytes,4,opt,name=preview"`
+	Approval *ApprovalActivityStep `json:"approval,omitempty" protobuf:"bytes,5,opt,name=approval"`
+	Wait     *WaitActivityStep     `json:"wait,omitempty" protobuf:"bytes,6,opt,name=wait"`
+	Job      *JobActivityStep      `json:"job,omitempty" protobuf:"bytes,7,opt,name=job"`
+	Rollback *RollbackActivityStep `json:"rollback,omitempty" protobuf:"bytes,8,opt,name=rollback"`
 }
 
 // CoreActivityStep is a base step included in Stages of a pipeline or other kinds of step
@@ -90,6 +94,49 @@ type PromoteActivityStep struct {
 	PullRequest    *PromotePullRequestStep `json:"pullRequest,omitempty" protobuf:"bytes,2,opt,name=pullRequest"`
 	Update         *PromoteUpdateStep      `json:"update,omitempty" protobuf:"bytes,3,opt,name=update"`
 	ApplicationURL string                  `json:"applicationURL,omitempty" protobuf:"bytes,4,opt,name=environment"`
+	// the reason the CVE policy of the Environment is blocking the promotion
+	PolicyViolation string `json:"policyViolation,omitempty" protobuf:"bytes,5,opt,name=policyViolation"`
+	// the number of times the CVE policy has been checked while it blocks the promotion
+	PolicyChecks int32 `json:"policyChecks,omitempty" protobuf:"varint,6,opt,name=policyChecks"`
+}
+
+// ApprovalActivityStep is the step of waiting for a manual approval before the workflow continues
+type ApprovalActivityStep struct {
+	CoreActivityStep
+
+	Environment string   `json:"environment,omitempty" protobuf:"bytes,1,opt,name=environment"`
+	Approvers   []string `json:"approvers,omitempty" protobuf:"bytes,2,opt,name=approvers"`
+	Message     string   `json:"message,omitempty" protobuf:"bytes,3,opt,name=message"`
+	// the user who approved or rejected the step
+	Approver *UserDetails `json:"approver,omitempty" protobuf:"bytes,4,opt,name=approver"`
+	Comment  string       `json:"comment,omitempty" protobuf:"bytes,5,opt,name=comment"`
+}
+
+// WaitActivityStep is the step of waiting for a soak period before the workflow continues
+type WaitActivityStep struct {
+	CoreActivityStep
+
+	Duration string `json:"duration,omitempty" protobuf:"bytes,1,opt,name=duration"`
+}
+
+// JobActivityStep is the step of running a verification Job before the workflow continues
+type JobActivityStep struct {
+	CoreActivityStep
+
+	Environment string `json:"environment,omitempty" protobuf:"bytes,1,opt,name=environment"`
+	Namespace   string `json:"namespace,omitempty" protobuf:"bytes,2,opt,name=namespace"`
+	Job         string `json:"job,omitempty" protobuf:"bytes,3,opt,name=job"`
+}
+
+// RollbackActivityStep is the step of reverting an environment to the previous version of an application after
+// the verification of a promotion failed
+type RollbackActivityStep struct {
+	CoreActivityStep
+
+	Environment     string `json:"environment,omitempty" protobuf:"bytes,1,opt,name=environment"`
+	Version         string `json:"version,omitempty" protobuf:"bytes,2,opt,name=version"`
+	PreviousVersion string `json:"previousVersion,omitempty" protobuf:"bytes,3,opt,name=previousVersion"`
+	PullRequestURL  string `json:"pullRequestURL,omitempty" protobuf:"bytes,4,opt,name=pullRequestURL"`
 }
 
 // GitStatus the status of a git commit in terms of CI/CD
@@ -141,6 +188,14 @@ const (
 	ActivityStepKindTypePreview ActivityStepKindType = "Preview"
 	// ActivityStepKindTypePromote a promote activity
 	ActivityStepKindTypePromote ActivityStepKindType = "Promote"
+	// ActivityStepKindTypeApproval a manual approval gate
+	ActivityStepKindTypeApproval ActivityStepKindType = "Approval"
+	// ActivityStepKindTypeWait a soak period
+	ActivityStepKindTypeWait ActivityStepKindType = "Wait"
+	// ActivityStepKindTypeJob a verification Job
+	ActivityStepKindTypeJob ActivityStepKindType = "Job"
+	// ActivityStepKindTypeRollback a rollback of a failed promotion
+	ActivityStepKindTypeRollback ActivityStepKindType = "Rollback"
 )
 
 // ActivityStatusType is the status of an activity; usually succeeded or failed/error on completion
@@ -235,6 +290,7 @@ const (
 const (
 	FactTypeCoverage              = "jx.coverage"
 	FactTypeStaticProgramAnalysis = "jx.staticProgramAnalysis"
+	FactTypeCVE                   = "jx.cve"
 )
 
 // IsTerminated returns true if this activity has stopped executing
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -34,6 +35,10 @@ type WorkflowStep struct {
 	Description   string                `json:"description,omitempty" protobuf:"bytes,2,opt,name=description"`
 	Preconditions WorkflowPreconditions `json:"trigger,omitempty" protobuf:"bytes,3,opt,name=trigger"`
 	Promote       *PromoteWorkflowStep  `json:"promote,omitempty" protobuf:"bytes,4,opt,name=promote"`
+	Approval      *ApprovalWorkflowStep `json:"approval,omitempty" protobuf:"bytes,5,opt,name=approval"`
+	Wait          *WaitWorkflowStep     `json:"wait,omitempty" protobuf:"bytes,6,opt,name=wait"`
+	Job           *JobWorkflowStep      `json:"job,omitempty" protobuf:"bytes,7,opt,name=job"`
+	Parallel      *ParallelWorkflowStep `json:"parallel,omitempty" protobuf:"bytes,8,opt,name=parallel"`
 }
 
 // PromoteWorkflowStep is the step of promoting a version of an application to an environment
@@ -41,10 +46,39 @@ type PromoteWorkflowStep struct {
 	Environment string `json:"environment,omitempty" protobuf:"bytes,1,opt,name=environment"`
 }
 
+// ApprovalWorkflowStep is a manual gate which waits for someone to approve the workflow before continuing
+type ApprovalWorkflowStep struct {
+	// the environment the approval gates, if any
+	Environment string `json:"environment,omitempty" protobuf:"bytes,1,opt,name=environment"`
+	// the users who are allowed to approve; if empty anyone can approve
+	Approvers []string `json:"approvers,omitempty" protobuf:"bytes,2,opt,name=approvers"`
+	Message   string   `json:"message,omitempty" protobuf:"bytes,3,opt,name=message"`
+}
+
+// WaitWorkflowStep is a soak period where the workflow waits for a duration before continuing
+type WaitWorkflowStep struct {
+	// the duration to wait such as 30m or 2h
+	Duration string `json:"duration,omitempty" protobuf:"bytes,1,opt,name=duration"`
+}
+
+// JobWorkflowStep runs a kubernetes Job to verify a promotion; the workflow continues if the Job succeeds
+type JobWorkflowStep struct {
+	// the environment whose namespace the Job runs in; defaults to the namespace of the workflow
+	Environment string          `json:"environment,omitempty" protobuf:"bytes,1,opt,name=environment"`
+	Spec        batchv1.JobSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
+// ParallelWorkflowStep is the step of promoting a version of an application to several environments at once
+type ParallelWorkflowStep struct {
+	Environments []string `json:"environments,omitempty" protobuf:"bytes,1,opt,name=environments"`
+}
+
 // WorkflowPreconditions is the trigger to start a step
 type WorkflowPreconditions struct {
 	// the names of the environments which need to have promoted before this step can be triggered
 	Environments []string `json:"environments,omitempty" protobuf:"bytes,1,opt,name=environments"`
+	// the names of the approval, wait, job or parallel steps which need to have succeeded before this step can be triggered
+	Steps []string `json:"steps,omitempty" protobuf:"bytes,2,opt,name=steps"`
 }
 
 // WorkflowStatus is the status for an Environment resource
@@ -70,6 +104,14 @@ const (
 	WorkflowStepKindTypeNone WorkflowStepKindType = ""
 	// WorkflowStepKindTypePromote a promote activity
 	WorkflowStepKindTypePromote WorkflowStepKindType = "Promote"
+	// WorkflowStepKindTypeApproval a manual approval gate
+	WorkflowStepKindTypeApproval WorkflowStepKindType = "Approval"
+	// WorkflowStepKindTypeWait a soak period of a fixed duration
+	WorkflowStepKindTypeWait WorkflowStepKindType = "Wait"
+	// WorkflowStepKindTypeJob a verification Job
+	WorkflowStepKindTypeJob WorkflowStepKindType = "Job"
+	// WorkflowStepKindTypeParallel a promote activity to several environments at once
+	WorkflowStepKindTypeParallel WorkflowStepKindType = "Parallel"
 )
 
 // WorkflowStatusType is the status of an activity; usually succeeded or failed/error on completion
@@ -10,6 +10,54 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovalActivityStep) DeepCopyInto(out *ApprovalActivityStep) {
+	*out = *in
+	in.CoreActivityStep.DeepCopyInto(&out.CoreActivityStep)
+	if in.Approvers != nil {
+		in, out := &in.Approvers, &out.Approvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Approver != nil {
+		in, out := &in.Approver, &out.Approver
+		*out = new(UserDetails)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovalActivityStep.
+func (in *ApprovalActivityStep) DeepCopy() *ApprovalActivityStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ApprovalActivityStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovalWorkflowStep) DeepCopyInto(out *ApprovalWorkflowStep) {
+	*out = *in
+	if in.Approvers != nil {
+		in, out := &in.Approvers, &out.Approvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovalWorkflowStep.
+func (in *ApprovalWorkflowStep) DeepCopy() *ApprovalWorkflowStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ApprovalWorkflowStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Attachment) DeepCopyInto(out *Attachment) {
 	*out = *in
@@ -31,6 +79,48 @@ func (in *Attachment) DeepCopy() *Attachment {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BranchProtectionPolicy) DeepCopyInto(out *BranchProtectionPolicy) {
+	*out = *in
+	if in.RequiredStatusContexts != nil {
+		in, out := &in.RequiredStatusContexts, &out.RequiredStatusContexts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BranchProtectionPolicy.
+func (in *BranchProtectionPolicy) DeepCopy() *BranchProtectionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BranchProtectionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CVEPolicy) DeepCopyInto(out *CVEPolicy) {
+	*out = *in
+	if in.AllowedCVEs != nil {
+		in, out := &in.AllowedCVEs, &out.AllowedCVEs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CVEPolicy.
+func (in *CVEPolicy) DeepCopy() *CVEPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CVEPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CommitSummary) DeepCopyInto(out *CommitSummary) {
 	*out = *in
@@ -201,6 +291,22 @@ func (in *CoreActivityStep) DeepCopy() *CoreActivityStep {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentWindow) DeepCopyInto(out *DeploymentWindow) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentWindow.
+func (in *DeploymentWindow) DeepCopy() *DeploymentWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Environment) DeepCopyInto(out *Environment) {
 	*out = *in
@@ -416,6 +522,26 @@ func (in *EnvironmentSpec) DeepCopyInto(out *EnvironmentSpec) {
 	out.Source = in.Source
 	in.TeamSettings.DeepCopyInto(&out.TeamSettings)
 	out.PreviewGitSpec = in.PreviewGitSpec
+	if in.ProgressiveDelivery != nil {
+		in, out := &in.ProgressiveDelivery, &out.ProgressiveDelivery
+		*out = new(ProgressiveDeliverySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeploymentWindows != nil {
+		in, out := &in.DeploymentWindows, &out.DeploymentWindows
+		*out = make([]DeploymentWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Freezes != nil {
+		in, out := &in.Freezes, &out.Freezes
+		*out = make([]DeploymentWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.CVEPolicy != nil {
+		in, out := &in.CVEPolicy, &out.CVEPolicy
+		*out = new(CVEPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -965,6 +1091,40 @@ func (in *IssueSummary) DeepCopy() *IssueSummary {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobActivityStep) DeepCopyInto(out *JobActivityStep) {
+	*out = *in
+	in.CoreActivityStep.DeepCopyInto(&out.CoreActivityStep)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobActivityStep.
+func (in *JobActivityStep) DeepCopy() *JobActivityStep {
+	if in == nil {
+		return nil
+	}
+	out := new(JobActivityStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobWorkflowStep) DeepCopyInto(out *JobWorkflowStep) {
+	*out = *in
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobWorkflowStep.
+func (in *JobWorkflowStep) DeepCopy() *JobWorkflowStep {
+	if in == nil {
+		return nil
+	}
+	out := new(JobWorkflowStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Measurement) DeepCopyInto(out *Measurement) {
 	*out = *in
@@ -1007,6 +1167,27 @@ func (in *Original) DeepCopy() *Original {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParallelWorkflowStep) DeepCopyInto(out *ParallelWorkflowStep) {
+	*out = *in
+	if in.Environments != nil {
+		in, out := &in.Environments, &out.Environments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParallelWorkflowStep.
+func (in *ParallelWorkflowStep) DeepCopy() *ParallelWorkflowStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ParallelWorkflowStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineActivity) DeepCopyInto(out *PipelineActivity) {
 	*out = *in
@@ -1154,6 +1335,26 @@ func (in *PipelineActivityStep) DeepCopyInto(out *PipelineActivityStep) {
 		*out = new(PreviewActivityStep)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(ApprovalActivityStep)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Wait != nil {
+		in, out := &in.Wait, &out.Wait
+		*out = new(WaitActivityStep)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(JobActivityStep)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(RollbackActivityStep)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1201,6 +1402,32 @@ func (in *PreviewGitSpec) DeepCopy() *PreviewGitSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProgressiveDeliverySpec) DeepCopyInto(out *ProgressiveDeliverySpec) {
+	*out = *in
+	if in.CanarySteps != nil {
+		in, out := &in.CanarySteps, &out.CanarySteps
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Analysis != nil {
+		in, out := &in.Analysis, &out.Analysis
+		*out = new(PromotionAnalysis)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProgressiveDeliverySpec.
+func (in *ProgressiveDeliverySpec) DeepCopy() *ProgressiveDeliverySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProgressiveDeliverySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PromoteActivityStep) DeepCopyInto(out *PromoteActivityStep) {
 	*out = *in
@@ -1283,6 +1510,22 @@ func (in *PromoteWorkflowStep) DeepCopy() *PromoteWorkflowStep {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionAnalysis) DeepCopyInto(out *PromotionAnalysis) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionAnalysis.
+func (in *PromotionAnalysis) DeepCopy() *PromotionAnalysis {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionAnalysis)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *QuickStartLocation) DeepCopyInto(out *QuickStartLocation) {
 	*out = *in
@@ -1439,6 +1682,23 @@ func (in *ResourceReference) DeepCopy() *ResourceReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollbackActivityStep) DeepCopyInto(out *RollbackActivityStep) {
+	*out = *in
+	in.CoreActivityStep.DeepCopyInto(&out.CoreActivityStep)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackActivityStep.
+func (in *RollbackActivityStep) DeepCopy() *RollbackActivityStep {
+	if in == nil {
+		return nil
+	}
+	out := new(RollbackActivityStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StageActivityStep) DeepCopyInto(out *StageActivityStep) {
 	*out = *in
@@ -1562,6 +1822,11 @@ func (in *TeamSettings) DeepCopyInto(out *TeamSettings) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.BranchProtection != nil {
+		in, out := &in.BranchProtection, &out.BranchProtection
+		*out = new(BranchProtectionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1709,6 +1974,39 @@ func (in *UserSpec) DeepCopy() *UserSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaitActivityStep) DeepCopyInto(out *WaitActivityStep) {
+	*out = *in
+	in.CoreActivityStep.DeepCopyInto(&out.CoreActivityStep)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaitActivityStep.
+func (in *WaitActivityStep) DeepCopy() *WaitActivityStep {
+	if in == nil {
+		return nil
+	}
+	out := new(WaitActivityStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaitWorkflowStep) DeepCopyInto(out *WaitWorkflowStep) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaitWorkflowStep.
+func (in *WaitWorkflowStep) DeepCopy() *WaitWorkflowStep {
+	if in == nil {
+		return nil
+	}
+	out := new(WaitWorkflowStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Workflow) DeepCopyInto(out *Workflow) {
 	*out = *in
@@ -1778,6 +2076,11 @@ func (in *WorkflowPreconditions) DeepCopyInto(out *WorkflowPreconditions) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1839,6 +2142,26 @@ func (in *WorkflowStep) DeepCopyInto(out *WorkflowStep) {
 		*out = new(PromoteWorkflowStep)
 		**out = **in
 	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(ApprovalWorkflowStep)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Wait != nil {
+		in, out := &in.Wait, &out.Wait
+		*out = new(WaitWorkflowStep)
+		**out = **in
+	}
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(JobWorkflowStep)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Parallel != nil {
+		in, out := &in.Parallel, &out.Parallel
+		*out = new(ParallelWorkflowStep)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1,10 +1,12 @@
 package chats
 
 const (
-	Slack = "slack"
-	Irc   = "irc"
+	Slack      = "slack"
+	Irc        = "irc"
+	MSTeams    = "msteams"
+	Mattermost = "mattermost"
 )
 
 var (
-	ChatKinds = []string{Slack, Irc}
+	ChatKinds = []string{Slack, Irc, MSTeams, Mattermost}
 )
@@ -0,0 +1,204 @@
+package chats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// MattermostChatProvider uses the Mattermost REST API v4 with the access token of a bot or user
+type MattermostChatProvider struct {
+	Client   *http.Client
+	Server   *auth.AuthServer
+	UserAuth *auth.UserAuth
+}
+
+// MattermostTeam a Mattermost team
+type MattermostTeam struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// MattermostChannel a Mattermost channel
+type MattermostChannel struct {
+	ID          string `json:"id"`
+	TeamID      string `json:"team_id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// MattermostChannelStats the statistics of a channel
+type MattermostChannelStats struct {
+	ChannelID   string `json:"channel_id"`
+	MemberCount int    `json:"member_count"`
+}
+
+// MattermostPost a message posted to a channel
+type MattermostPost struct {
+	ID        string                 `json:"id,omitempty"`
+	ChannelID string                 `json:"channel_id"`
+	RootID    string                 `json:"root_id,omitempty"`
+	Message   string                 `json:"message"`
+	Props     map[string]interface{} `json:"props,omitempty"`
+}
+
+// MattermostAttachment a Slack compatible message attachment
+type MattermostAttachment struct {
+	Fallback  string `json:"fallback"`
+	Color     string `json:"color,omitempty"`
+	Title     string `json:"title,omitempty"`
+	TitleLink string `json:"title_link,omitempty"`
+	Text      string `json:"text,omitempty"`
+}
+
+// CreateMattermostChatProvider creates a provider for the Mattermost server using the access token of the user
+func CreateMattermostChatProvider(server *auth.AuthServer, userAuth *auth.UserAuth, batchMode bool) (ChatProvider, error) {
+	u := server.URL
+	if u == "" {
+		return nil, fmt.Errorf("No base URL for server!")
+	}
+	if userAuth == nil || userAuth.IsInvalid() || userAuth.ApiToken == "" {
+		return nil, fmt.Errorf("No authentication found for Mattermost server %s", u)
+	}
+	return &MattermostChatProvider{
+		Client:   http.DefaultClient,
+		Server:   server,
+		UserAuth: userAuth,
+	}, nil
+}
+
+// GetChannelMetrics returns the member count of the channel which is either a channel name or team/channel
+func (c *MattermostChatProvider) GetChannelMetrics(name string) (*ChannelMetrics, error) {
+	metrics := &ChannelMetrics{
+		Name: name,
+	}
+	team, channel, err := c.findChannel(name)
+	if err != nil {
+		return metrics, err
+	}
+	stats := &MattermostChannelStats{}
+	err = c.do("GET", "/api/v4/channels/"+channel.ID+"/stats", nil, stats)
+	if err != nil {
+		return metrics, err
+	}
+	metrics.ID = channel.ID
+	metrics.Name = channel.Name
+	metrics.MemberCount = stats.MemberCount
+	metrics.URL = util.UrlJoin(c.Server.URL, team.Name, "channels", channel.Name)
+	return metrics, nil
+}
+
+// PostMessage posts the message with an attachment highlighting its status, replying in the thread of the
+// message if it has a ThreadID
+func (c *MattermostChatProvider) PostMessage(channel string, message *Message) (string, error) {
+	_, ch, err := c.findChannel(channel)
+	if err != nil {
+		return "", err
+	}
+	title := message.Title
+	if message.Status != "" {
+		title = title + " " + message.Status
+	}
+	post := &MattermostPost{
+		ChannelID: ch.ID,
+		RootID:    message.ThreadID,
+		Props: map[string]interface{}{
+			"attachments": []MattermostAttachment{
+				{
+					Fallback:  title,
+					Color:     message.Color(),
+					Title:     title,
+					TitleLink: message.URL,
+					Text:      message.Text,
+				},
+			},
+		},
+	}
+	answer := &MattermostPost{}
+	err = c.do("POST", "/api/v4/posts", post, answer)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to post message to Mattermost channel %s", channel)
+	}
+	if answer.RootID != "" {
+		return answer.RootID, nil
+	}
+	return answer.ID, nil
+}
+
+// findChannel finds the channel for a name of the form team/channel or just the channel name in which case
+// the teams of the user are searched
+func (c *MattermostChatProvider) findChannel(name string) (*MattermostTeam, *MattermostChannel, error) {
+	name = strings.TrimPrefix(name, "#")
+	teamName := ""
+	if i := strings.Index(name, "/"); i >= 0 {
+		teamName, name = name[:i], name[i+1:]
+	}
+	teams := []MattermostTeam{}
+	err := c.do("GET", "/api/v4/users/me/teams", nil, &teams)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range teams {
+		team := &teams[i]
+		if teamName != "" && team.Name != teamName {
+			continue
+		}
+		channel := &MattermostChannel{}
+		err = c.do("GET", "/api/v4/teams/"+team.ID+"/channels/name/"+name, nil, channel)
+		if err == nil {
+			return team, channel, nil
+		}
+		if errors.Cause(err) != errMattermostNotFound {
+			return nil, nil, err
+		}
+	}
+	if teamName != "" {
+		return nil, nil, fmt.Errorf("could not find Mattermost channel %s in team %s", name, teamName)
+	}
+	return nil, nil, fmt.Errorf("could not find Mattermost channel %s in any team", name)
+}
+
+var errMattermostNotFound = errors.New("not found")
+
+func (c *MattermostChatProvider) do(method string, subPath string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.Server.URL, "/")+subPath, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Authorization", "Bearer "+c.UserAuth.ApiToken)
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errMattermostNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("error response from Mattermost %s %s: %s %s", method, subPath, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, result)
+}
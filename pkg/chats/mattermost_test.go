@@ -0,0 +1,107 @@
+package chats_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/chats"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mattermostRouter = util.Router{
+	"/api/v4/users/me/teams": util.MethodMap{
+		"GET": "teams.json",
+	},
+	"/api/v4/teams/team2/channels/name/developers": util.MethodMap{
+		"GET": "channel.json",
+	},
+	"/api/v4/channels/channel1/stats": util.MethodMap{
+		"GET": "stats.json",
+	},
+}
+
+func createMattermostTestServer(t *testing.T, posts *[]chats.MattermostPost) *httptest.Server {
+	mux := http.NewServeMux()
+	for path, methodMap := range mattermostRouter {
+		mux.HandleFunc(path, util.GetMockAPIResponseFromFile("test_data/mattermost", methodMap))
+	}
+	mux.HandleFunc("/api/v4/posts", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer mytoken", r.Header.Get("Authorization"))
+		post := chats.MattermostPost{}
+		err := json.NewDecoder(r.Body).Decode(&post)
+		assert.NoError(t, err)
+		*posts = append(*posts, post)
+		post.ID = "post" + strconv.Itoa(len(*posts))
+		json.NewEncoder(w).Encode(&post)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestMattermostPostMessage(t *testing.T) {
+	t.Parallel()
+	posts := []chats.MattermostPost{}
+	server := createMattermostTestServer(t, &posts)
+	defer server.Close()
+
+	provider, err := chats.CreateChatProvider(chats.Mattermost, &auth.AuthServer{
+		URL:  server.URL,
+		Kind: chats.Mattermost,
+	}, &auth.UserAuth{
+		Username: "jenkins-x-bot",
+		ApiToken: "mytoken",
+	}, true)
+	require.NoError(t, err)
+
+	threadID, err := provider.PostMessage("#developers", &chats.Message{
+		Title:  "Pipeline jenkins-x/myapp/master #3",
+		URL:    "http://jenkins/job/myapp/3",
+		Status: "Succeeded",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "post1", threadID)
+
+	reply, err := provider.PostMessage("jenkins-x/developers", &chats.Message{
+		Title:    "Promoted myapp to Staging",
+		ThreadID: threadID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "post1", reply)
+
+	require.Len(t, posts, 2)
+	assert.Equal(t, "channel1", posts[0].ChannelID)
+	assert.Equal(t, "", posts[0].RootID)
+	assert.Equal(t, "post1", posts[1].RootID)
+
+	_, err = provider.PostMessage("other/developers", &chats.Message{Title: "missing"})
+	assert.Error(t, err)
+}
+
+func TestMattermostGetChannelMetrics(t *testing.T) {
+	t.Parallel()
+	posts := []chats.MattermostPost{}
+	server := createMattermostTestServer(t, &posts)
+	defer server.Close()
+
+	provider, err := chats.CreateMattermostChatProvider(&auth.AuthServer{
+		URL: server.URL,
+	}, &auth.UserAuth{
+		Username: "jenkins-x-bot",
+		ApiToken: "mytoken",
+	}, true)
+	require.NoError(t, err)
+
+	metrics, err := provider.GetChannelMetrics("developers")
+	require.NoError(t, err)
+	assert.Equal(t, "channel1", metrics.ID)
+	assert.Equal(t, 42, metrics.MemberCount)
+	assert.Equal(t, server.URL+"/jenkins-x/channels/developers", metrics.URL)
+}
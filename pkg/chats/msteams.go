@@ -0,0 +1,138 @@
+package chats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/pkg/errors"
+)
+
+// MSTeamsChatProvider posts messages to Microsoft Teams channels using incoming webhooks. As each incoming webhook
+// belongs to a single channel the channel can either be the URL of its webhook or the name of a user of the server
+// whose token is the webhook URL of the channel
+type MSTeamsChatProvider struct {
+	Client *http.Client
+	Server *auth.AuthServer
+}
+
+// MSTeamsMessageCard is the legacy actionable message card accepted by Microsoft Teams incoming webhooks
+type MSTeamsMessageCard struct {
+	Type            string                 `json:"@type"`
+	Context         string                 `json:"@context"`
+	Summary         string                 `json:"summary,omitempty"`
+	ThemeColor      string                 `json:"themeColor,omitempty"`
+	Title           string                 `json:"title,omitempty"`
+	Text            string                 `json:"text,omitempty"`
+	PotentialAction []MSTeamsOpenURIAction `json:"potentialAction,omitempty"`
+}
+
+// MSTeamsOpenURIAction an action which opens a link from a message card
+type MSTeamsOpenURIAction struct {
+	Type    string          `json:"@type"`
+	Name    string          `json:"name"`
+	Targets []MSTeamsTarget `json:"targets"`
+}
+
+// MSTeamsTarget the URI of an action for an operating system
+type MSTeamsTarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+// CreateMSTeamsChatProvider creates a provider for the incoming webhook URL of the server. No user is required
+// as the webhook URL is the secret
+func CreateMSTeamsChatProvider(server *auth.AuthServer, userAuth *auth.UserAuth, batchMode bool) (ChatProvider, error) {
+	if server.URL == "" {
+		return nil, fmt.Errorf("No incoming webhook URL for Microsoft Teams server!")
+	}
+	return &MSTeamsChatProvider{
+		Client: http.DefaultClient,
+		Server: server,
+	}, nil
+}
+
+// GetChannelMetrics is not supported as incoming webhooks cannot query channels
+func (c *MSTeamsChatProvider) GetChannelMetrics(name string) (*ChannelMetrics, error) {
+	return &ChannelMetrics{Name: name}, fmt.Errorf("channel metrics are not supported by Microsoft Teams incoming webhooks")
+}
+
+// PostMessage posts the message as a message card. Incoming webhooks cannot reply in threads so the ThreadID is ignored
+// and no thread ID is returned
+func (c *MSTeamsChatProvider) PostMessage(channel string, message *Message) (string, error) {
+	webhookURL, err := c.webhookURL(channel)
+	if err != nil {
+		return "", err
+	}
+	title := message.Title
+	if message.Status != "" {
+		title = title + " " + message.Status
+	}
+	card := &MSTeamsMessageCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		Summary:    title,
+		ThemeColor: msTeamsThemeColor(message.Color()),
+		Title:      title,
+		Text:       message.Text,
+	}
+	if message.URL != "" {
+		card.PotentialAction = []MSTeamsOpenURIAction{
+			{
+				Type: "OpenUri",
+				Name: "View",
+				Targets: []MSTeamsTarget{
+					{
+						OS:  "default",
+						URI: message.URL,
+					},
+				},
+			},
+		}
+	}
+	data, err := json.Marshal(card)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.Client.Post(webhookURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to post message to Microsoft Teams channel %s", channel)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("error response posting message to Microsoft Teams channel %s: %s %s", channel, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return "", nil
+}
+
+// webhookURL returns the incoming webhook URL of the channel
+func (c *MSTeamsChatProvider) webhookURL(channel string) (string, error) {
+	if strings.HasPrefix(channel, "https://") || strings.HasPrefix(channel, "http://") {
+		return channel, nil
+	}
+	name := strings.TrimPrefix(channel, "#")
+	for _, user := range c.Server.Users {
+		if user != nil && user.Username == name && user.ApiToken != "" {
+			return user.ApiToken, nil
+		}
+	}
+	return "", fmt.Errorf("no incoming webhook URL for Microsoft Teams channel %s. Use the webhook URL as the channel or add a user called %s to the chat server %s with the webhook URL as its token", channel, name, c.Server.URL)
+}
+
+func msTeamsThemeColor(color string) string {
+	switch color {
+	case "good":
+		return "2DC72D"
+	case "danger":
+		return "D70000"
+	case "warning":
+		return "FFA500"
+	default:
+		return ""
+	}
+}
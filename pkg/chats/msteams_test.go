@@ -0,0 +1,78 @@
+package chats_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/chats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMSTeamsPostMessage(t *testing.T) {
+	t.Parallel()
+	cards := map[string]chats.MSTeamsMessageCard{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		card := chats.MSTeamsMessageCard{}
+		err := json.NewDecoder(r.Body).Decode(&card)
+		assert.NoError(t, err)
+		cards[r.URL.Path] = card
+		w.Write([]byte("1"))
+	}))
+	defer server.Close()
+
+	provider, err := chats.CreateChatProvider(chats.MSTeams, &auth.AuthServer{
+		URL:  server.URL,
+		Kind: chats.MSTeams,
+		Users: []*auth.UserAuth{
+			{
+				Username: "developers",
+				ApiToken: server.URL + "/webhook/developers",
+			},
+		},
+	}, nil, true)
+	require.NoError(t, err)
+
+	threadID, err := provider.PostMessage("developers", &chats.Message{
+		Title:  "Pipeline jenkins-x/myapp/master #3",
+		Text:   "Release 1.0.3",
+		URL:    "http://jenkins/job/myapp/3",
+		Status: "Failed",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "", threadID)
+
+	card := cards["/webhook/developers"]
+	assert.Equal(t, "MessageCard", card.Type)
+	assert.Equal(t, "Pipeline jenkins-x/myapp/master #3 Failed", card.Title)
+	assert.Equal(t, "Release 1.0.3", card.Text)
+	assert.Equal(t, "D70000", card.ThemeColor)
+	require.Len(t, card.PotentialAction, 1)
+	assert.Equal(t, "http://jenkins/job/myapp/3", card.PotentialAction[0].Targets[0].URI)
+
+	_, err = provider.PostMessage(server.URL+"/webhook/staging", &chats.Message{
+		Title: "Promoted myapp",
+	})
+	require.NoError(t, err)
+	card = cards["/webhook/staging"]
+	assert.Equal(t, "Promoted myapp", card.Title)
+	assert.Empty(t, card.PotentialAction)
+
+	_, err = provider.PostMessage("#developers", &chats.Message{
+		Title: "Preview environment myapp-pr-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Preview environment myapp-pr-1", cards["/webhook/developers"].Title)
+
+	_, err = provider.PostMessage("random", &chats.Message{
+		Title: "Promoted myapp",
+	})
+	assert.Error(t, err, "channels without a webhook URL should not be posted to the server URL")
+	assert.Len(t, cards, 2)
+
+	_, err = provider.GetChannelMetrics("developers")
+	assert.Error(t, err)
+}
@@ -10,6 +10,39 @@ import (
 // CreateChatProvider represents an integration interface to chat
 type ChatProvider interface {
 	GetChannelMetrics(name string) (*ChannelMetrics, error)
+
+	// PostMessage posts the message to the channel returning the ID of the thread which can be used to reply to it.
+	// If the message has a ThreadID then it is posted as a reply in that thread
+	PostMessage(channel string, message *Message) (string, error)
+}
+
+// DirectMessageProvider is implemented by chat providers which can send direct messages to users
+type DirectMessageProvider interface {
+	// PostDirectMessage posts the message directly to the user returning the ID of its thread
+	PostDirectMessage(user string, message *Message) (string, error)
+}
+
+// Message a notification to post to a chat channel
+type Message struct {
+	Title    string
+	Text     string
+	URL      string
+	Status   string
+	ThreadID string
+}
+
+// Color returns the color used to highlight the status of the message
+func (m *Message) Color() string {
+	switch m.Status {
+	case "Succeeded":
+		return "good"
+	case "Failed", "Error", "Aborted":
+		return "danger"
+	case "":
+		return ""
+	default:
+		return "warning"
+	}
 }
 
 // ChannelMetrics metrics for a channel
@@ -30,6 +63,10 @@ func CreateChatProvider(kind string, server *auth.AuthServer, userAuth *auth.Use
 	switch kind {
 	case Slack:
 		return CreateSlackChatProvider(server, userAuth, batchMode)
+	case MSTeams:
+		return CreateMSTeamsChatProvider(server, userAuth, batchMode)
+	case Mattermost:
+		return CreateMattermostChatProvider(server, userAuth, batchMode)
 	default:
 		return nil, fmt.Errorf("Unsupported chat provider kind: %s", kind)
 	}
@@ -39,6 +76,8 @@ func ProviderAccessTokenURL(kind string, url string) string {
 	switch kind {
 	case Slack:
 		return "https://my.slack.com/services/new/bot"
+	case Mattermost:
+		return util.UrlJoin(url, "integrations/bots")
 	default:
 		return ""
 	}
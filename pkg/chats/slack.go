@@ -2,14 +2,18 @@ package chats
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/jenkins-x/jx/pkg/auth"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/nlopes/slack"
+	"github.com/pkg/errors"
 )
 
+var slackUserIDRegex = regexp.MustCompile(`^[UW][A-Z0-9]{6,}$`)
+
 type SlackChatProvider struct {
 	SlackClient *slack.Client
 	Server      *auth.AuthServer
@@ -62,3 +66,65 @@ func (c *SlackChatProvider) GetChannelMetrics(name string) (*ChannelMetrics, err
 	metrics.URL = util.UrlJoin(c.Server.URL, "messages", info.ID)
 	return metrics, nil
 }
+
+// PostMessage posts the message as an attachment so that its status is highlighted, replying in the thread of the
+// message if it has a ThreadID
+func (c *SlackChatProvider) PostMessage(channel string, message *Message) (string, error) {
+	params := slack.NewPostMessageParameters()
+	params.AsUser = true
+	if message.ThreadID != "" {
+		params.ThreadTimestamp = message.ThreadID
+	}
+	title := message.Title
+	if message.Status != "" {
+		title = title + " " + message.Status
+	}
+	params.Attachments = []slack.Attachment{
+		{
+			Color:     message.Color(),
+			Fallback:  title,
+			Title:     title,
+			TitleLink: message.URL,
+			Text:      message.Text,
+		},
+	}
+	_, ts, err := c.SlackClient.PostMessage(channel, "", params)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to post message to Slack channel %s", channel)
+	}
+	if message.ThreadID != "" {
+		return message.ThreadID, nil
+	}
+	return ts, nil
+}
+
+// PostDirectMessage posts the message to the direct message channel of the user which can either be a Slack user ID
+// or a user name
+func (c *SlackChatProvider) PostDirectMessage(user string, message *Message) (string, error) {
+	id, err := c.findUserID(user)
+	if err != nil {
+		return "", err
+	}
+	_, _, channel, err := c.SlackClient.OpenIMChannel(id)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to open direct message channel with Slack user %s", user)
+	}
+	return c.PostMessage(channel, message)
+}
+
+func (c *SlackChatProvider) findUserID(user string) (string, error) {
+	user = strings.TrimPrefix(user, "@")
+	if slackUserIDRegex.MatchString(user) {
+		return user, nil
+	}
+	users, err := c.SlackClient.GetUsers()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to find Slack users")
+	}
+	for _, u := range users {
+		if !u.Deleted && u.Name == user {
+			return u.ID, nil
+		}
+	}
+	return "", fmt.Errorf("could not find Slack user %s", user)
+}
@@ -0,0 +1,116 @@
+package chats_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/chats"
+	"github.com/nlopes/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackPostMessage(t *testing.T) {
+	forms := []map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat.postMessage", r.URL.Path)
+		r.ParseForm()
+		forms = append(forms, map[string]string{
+			"channel":     r.Form.Get("channel"),
+			"thread_ts":   r.Form.Get("thread_ts"),
+			"attachments": r.Form.Get("attachments"),
+		})
+		w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "1540000000.000100"}`))
+	}))
+	defer server.Close()
+
+	oldURL := slack.SLACK_API
+	slack.SLACK_API = server.URL + "/"
+	defer func() {
+		slack.SLACK_API = oldURL
+	}()
+
+	provider, err := chats.CreateChatProvider(chats.Slack, &auth.AuthServer{
+		URL: "https://jenkins-x.slack.com",
+	}, &auth.UserAuth{
+		Username: "jenkins-x-bot",
+		ApiToken: "mytoken",
+	}, true)
+	require.NoError(t, err)
+
+	threadID, err := provider.PostMessage("#developers", &chats.Message{
+		Title:  "Pipeline jenkins-x/myapp/master #3",
+		URL:    "http://jenkins/job/myapp/3",
+		Status: "Failed",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1540000000.000100", threadID)
+
+	reply, err := provider.PostMessage("#developers", &chats.Message{
+		Title:    "Build log",
+		ThreadID: threadID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, threadID, reply)
+
+	require.Len(t, forms, 2)
+	assert.Equal(t, "#developers", forms[0]["channel"])
+	assert.Equal(t, "", forms[0]["thread_ts"])
+	assert.Equal(t, threadID, forms[1]["thread_ts"])
+
+	attachments := []slack.Attachment{}
+	err = json.Unmarshal([]byte(forms[0]["attachments"]), &attachments)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "danger", attachments[0].Color)
+	assert.Equal(t, "Pipeline jenkins-x/myapp/master #3 Failed", attachments[0].Title)
+	assert.Equal(t, "http://jenkins/job/myapp/3", attachments[0].TitleLink)
+}
+
+func TestSlackPostDirectMessage(t *testing.T) {
+	channels := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/users.list":
+			w.Write([]byte(`{"ok": true, "members": [{"id": "U0OLD0001", "name": "jstrachan", "deleted": true}, {"id": "U0123ABCD", "name": "jstrachan"}]}`))
+		case "/im.open":
+			assert.Equal(t, "U0123ABCD", r.Form.Get("user"))
+			w.Write([]byte(`{"ok": true, "channel": {"id": "D0123ABCD"}}`))
+		case "/chat.postMessage":
+			channels = append(channels, r.Form.Get("channel"))
+			w.Write([]byte(`{"ok": true, "channel": "D0123ABCD", "ts": "1540000000.000200"}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	oldURL := slack.SLACK_API
+	slack.SLACK_API = server.URL + "/"
+	defer func() {
+		slack.SLACK_API = oldURL
+	}()
+
+	provider, err := chats.CreateSlackChatProvider(&auth.AuthServer{
+		URL: "https://jenkins-x.slack.com",
+	}, &auth.UserAuth{
+		Username: "jenkins-x-bot",
+		ApiToken: "mytoken",
+	}, true)
+	require.NoError(t, err)
+	dm, ok := provider.(chats.DirectMessageProvider)
+	require.True(t, ok, "the Slack provider should support direct messages")
+
+	for _, user := range []string{"U0123ABCD", "@jstrachan"} {
+		_, err = dm.PostDirectMessage(user, &chats.Message{
+			Title:  "Your commits broke pipeline jenkins-x/myapp/master #3",
+			Status: "Failed",
+		})
+		require.NoError(t, err, "posting to %s", user)
+	}
+	assert.Equal(t, []string{"D0123ABCD", "D0123ABCD"}, channels)
+}
@@ -1,11 +1,15 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
 	"reflect"
+	"strings"
+	"time"
 
+	ghodssyaml "github.com/ghodss/yaml"
 	"github.com/jenkins-x/jx/pkg/util"
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
@@ -14,6 +18,15 @@ import (
 const (
 	// ProjectConfigFileName is the name of the project configuration file
 	ProjectConfigFileName = "jenkins-x.yml"
+
+	// DefaultJUnitResultsDir the default directory into which post preview Jobs write JUnit XML results
+	DefaultJUnitResultsDir = "/results/junit"
+
+	// DefaultJUnitCollectorImage the default image of the Pod which reads the JUnit XML results of the post preview Jobs
+	DefaultJUnitCollectorImage = "busybox"
+
+	// DefaultLogTailLines the default number of log lines of each post preview Job included in the pull request comment
+	DefaultLogTailLines = 20
 )
 
 type ProjectConfig struct {
@@ -30,17 +43,315 @@ type ProjectConfig struct {
 	BuildPackGitURL     string                    `yaml:"buildPackGitURL,omitempty"`
 	BuildPackGitURef    string                    `yaml:"buildPackGitRef,omitempty"`
 	Workflow            string                    `yaml:"workflow,omitempty"`
+
+	// Apps the applications in sub directories of a monorepo which are built and versioned separately
+	Apps []AppConfig `yaml:"apps,omitempty"`
+}
+
+// AppConfig is an application in a sub directory of a monorepo
+type AppConfig struct {
+	Name string `yaml:"name,omitempty"`
+	// Dir the directory of the app relative to the root of the repository
+	Dir string `yaml:"dir,omitempty"`
+	// Paths any other files or directories outside of the app directory which cause the app to be rebuilt when
+	// they change such as shared libraries. Glob patterns such as 'libs/*.go' are supported
+	Paths []string `yaml:"paths,omitempty"`
+}
+
+// Contains returns true if the path relative to the root of the repository is part of the app
+func (a *AppConfig) Contains(path string) bool {
+	path = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
+	for _, p := range append([]string{a.Dir}, a.Paths...) {
+		p = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(filepath.Clean(p)), "./"), "/")
+		if p == "" || p == "." {
+			continue
+		}
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+		if matched, err := filepath.Match(p, path); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// GetApp returns the app with the given name or nil if there is none
+func (c *ProjectConfig) GetApp(name string) *AppConfig {
+	for i := range c.Apps {
+		if c.Apps[i].Name == name {
+			return &c.Apps[i]
+		}
+	}
+	return nil
+}
+
+// AddApp adds the app or replaces the app of the same name
+func (c *ProjectConfig) AddApp(app AppConfig) {
+	existing := c.GetApp(app.Name)
+	if existing != nil {
+		*existing = app
+		return
+	}
+	c.Apps = append(c.Apps, app)
+}
+
+// FindAppForPath returns the app which contains the path relative to the root of the repository or nil if no app does
+func (c *ProjectConfig) FindAppForPath(path string) *AppConfig {
+	for i := range c.Apps {
+		app := &c.Apps[i]
+		dir := strings.TrimPrefix(filepath.ToSlash(filepath.Clean(app.Dir)), "./")
+		cleanPath := strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
+		if dir != "" && dir != "." && (cleanPath == dir || strings.HasPrefix(cleanPath, dir+"/")) {
+			return app
+		}
+	}
+	return nil
+}
+
+// ChangedApps returns the apps which contain any of the changed paths relative to the root of the repository
+func (c *ProjectConfig) ChangedApps(changedPaths []string) []AppConfig {
+	answer := []AppConfig{}
+	for _, app := range c.Apps {
+		for _, path := range changedPaths {
+			if app.Contains(path) {
+				answer = append(answer, app)
+				break
+			}
+		}
+	}
+	return answer
 }
 
 type PreviewEnvironmentConfig struct {
 	Disabled         bool `yaml:"disabled,omitempty"`
 	MaximumInstances int  `yaml:"maximumInstances,omitempty"`
+
+	// TimeToLive the duration such as '72h' after its last deployment when a preview environment is deleted
+	TimeToLive string `yaml:"timeToLive,omitempty"`
+	// IdleTimeout the duration such as '24h' without deployments, ingress requests or wake ups after which a preview environment is deleted
+	IdleTimeout string `yaml:"idleTimeout,omitempty"`
+	// HibernateAfter the duration such as '2h' without deployments, ingress requests or wake ups after which the deployments of a
+	// preview environment are scaled to zero until the next push or `jx preview wake`
+	HibernateAfter string `yaml:"hibernateAfter,omitempty"`
+
+	// Dependencies the charts such as databases and queues installed into the preview namespace before the application
+	Dependencies []PreviewDependencyConfig `yaml:"dependencies,omitempty"`
+	// SeedJobs the Jobs run once in the preview namespace after the dependencies are installed such as to seed a database
+	SeedJobs []PreviewSeedJobConfig `yaml:"seedJobs,omitempty"`
+	// PostPreviewJobs overrides the post preview Jobs of the team for this repository
+	PostPreviewJobs *PostPreviewJobsConfig `yaml:"postPreviewJobs,omitempty"`
+}
+
+// PreviewDependencyConfig is a chart installed alongside a preview environment
+type PreviewDependencyConfig struct {
+	// Name the name of the dependency which is appended to the preview namespace to create the release name
+	Name string `yaml:"name"`
+	// Chart the chart name such as 'stable/postgresql'
+	Chart string `yaml:"chart"`
+	// Version the optional version of the chart
+	Version string `yaml:"version,omitempty"`
+	// Repository the optional URL of the chart repository which is added using the prefix of the chart name
+	Repository string `yaml:"repository,omitempty"`
+	// Values the values to set such as 'postgresqlDatabase=cheese'
+	Values []string `yaml:"values,omitempty"`
+	// ValueFiles the values files relative to the root of the repository
+	ValueFiles []string `yaml:"valueFiles,omitempty"`
+}
+
+// PreviewSeedJobConfig is a Job run in a preview environment after its dependencies are installed
+type PreviewSeedJobConfig struct {
+	Name    string   `yaml:"name"`
+	Image   string   `yaml:"image"`
+	Command []string `yaml:"command,omitempty"`
+	Args    []string `yaml:"args,omitempty"`
+	Env     JobEnv   `yaml:"env,omitempty"`
+}
+
+// PostPreviewJobsConfig overrides the post preview Jobs configured in the team settings for a repository
+type PostPreviewJobsConfig struct {
+	// Disabled disables all the post preview Jobs for the repository
+	Disabled bool `yaml:"disabled,omitempty"`
+	// Replace only runs the Jobs of the repository rather than adding them to the Jobs of the team
+	Replace bool `yaml:"replace,omitempty"`
+	// Exclude the names of the Jobs of the team which are not run for the repository
+	Exclude []string `yaml:"exclude,omitempty"`
+	// Jobs the Jobs of the repository. A Job with the same name as a Job of the team replaces it
+	Jobs []PostPreviewJobConfig `yaml:"jobs,omitempty"`
+
+	// JUnitResults enables the collection of JUnit XML results. A ReadWriteOnce volume is shared by the Jobs so they
+	// must be able to run on the same node
+	JUnitResults bool `yaml:"junitResults,omitempty"`
+	// JUnitResultsDir the directory of the shared volume mounted in each Job into which JUnit XML results are written.
+	// Defaults to DefaultJUnitResultsDir
+	JUnitResultsDir string `yaml:"junitResultsDir,omitempty"`
+	// JUnitCollectorImage the image with a shell used to read the JUnit XML results from the shared volume.
+	// Defaults to DefaultJUnitCollectorImage
+	JUnitCollectorImage string `yaml:"junitCollectorImage,omitempty"`
+	// LogTailLines the number of lines at the end of the log of each Job included in the pull request comment
+	LogTailLines int `yaml:"logTailLines,omitempty"`
+}
+
+// PostPreviewJobConfig is a Job run against a preview environment once the application has been deployed
+type PostPreviewJobConfig struct {
+	Name    string   `yaml:"name"`
+	Image   string   `yaml:"image"`
+	Command []string `yaml:"command,omitempty"`
+	Args    []string `yaml:"args,omitempty"`
+	Env     JobEnv   `yaml:"env,omitempty"`
+}
+
+// JobEnv the environment variables of a Job in the jenkins-x.yml. corev1.EnvVar only has json tags so the YAML is
+// converted via JSON so that valueFrom and the other camel case fields are loaded and saved
+type JobEnv []corev1.EnvVar
+
+// UnmarshalYAML loads the environment variables using the json tags of corev1.EnvVar
+func (e *JobEnv) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw interface{}
+	err := unmarshal(&raw)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	env := []corev1.EnvVar{}
+	err = ghodssyaml.Unmarshal(data, &env)
+	if err != nil {
+		return err
+	}
+	*e = env
+	return nil
+}
+
+// MarshalYAML saves the environment variables using the json tags of corev1.EnvVar
+func (e JobEnv) MarshalYAML() (interface{}, error) {
+	data, err := json.Marshal([]corev1.EnvVar(e))
+	if err != nil {
+		return nil, err
+	}
+	var answer interface{}
+	err = json.Unmarshal(data, &answer)
+	return answer, err
+}
+
+// GetJUnitResultsDir returns the directory into which the post preview Jobs write JUnit results or an empty string
+// if JUnit results are not collected
+func (c *PostPreviewJobsConfig) GetJUnitResultsDir() string {
+	if c == nil || !c.JUnitResults {
+		return ""
+	}
+	if c.JUnitResultsDir == "" {
+		return DefaultJUnitResultsDir
+	}
+	return c.JUnitResultsDir
+}
+
+// GetJUnitCollectorImage returns the image of the Pod which reads the JUnit XML results of the post preview Jobs
+func (c *PostPreviewJobsConfig) GetJUnitCollectorImage() string {
+	if c == nil || c.JUnitCollectorImage == "" {
+		return DefaultJUnitCollectorImage
+	}
+	return c.JUnitCollectorImage
+}
+
+// GetLogTailLines returns the number of log lines of each post preview Job to include in the pull request comment
+func (c *PostPreviewJobsConfig) GetLogTailLines() int {
+	if c == nil || c.LogTailLines == 0 {
+		return DefaultLogTailLines
+	}
+	return c.LogTailLines
+}
+
+// Validate returns an error if the preview environment configuration is invalid
+func (c *PreviewEnvironmentConfig) Validate() error {
+	if c.MaximumInstances < 0 {
+		return fmt.Errorf("invalid previewEnvironments.maximumInstances %d", c.MaximumInstances)
+	}
+	for name, value := range map[string]string{"timeToLive": c.TimeToLive, "idleTimeout": c.IdleTimeout, "hibernateAfter": c.HibernateAfter} {
+		if value != "" {
+			_, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid previewEnvironments.%s %s: %s", name, value, err)
+			}
+		}
+	}
+	names := map[string]bool{}
+	for i, d := range c.Dependencies {
+		if d.Name == "" || d.Chart == "" {
+			return fmt.Errorf("previewEnvironments.dependencies[%d] must have a name and a chart", i)
+		}
+		if names[d.Name] {
+			return fmt.Errorf("duplicate previewEnvironments.dependencies name %s", d.Name)
+		}
+		names[d.Name] = true
+	}
+	names = map[string]bool{}
+	for i, j := range c.SeedJobs {
+		if j.Name == "" || j.Image == "" {
+			return fmt.Errorf("previewEnvironments.seedJobs[%d] must have a name and an image", i)
+		}
+		if names[j.Name] {
+			return fmt.Errorf("duplicate previewEnvironments.seedJobs name %s", j.Name)
+		}
+		names[j.Name] = true
+	}
+	if c.PostPreviewJobs != nil {
+		return c.PostPreviewJobs.Validate()
+	}
+	return nil
+}
+
+// Validate returns an error if the post preview Jobs configuration is invalid
+func (c *PostPreviewJobsConfig) Validate() error {
+	if c.LogTailLines < 0 {
+		return fmt.Errorf("invalid previewEnvironments.postPreviewJobs.logTailLines %d", c.LogTailLines)
+	}
+	if c.JUnitResultsDir != "" && !strings.HasPrefix(c.JUnitResultsDir, "/") {
+		return fmt.Errorf("previewEnvironments.postPreviewJobs.junitResultsDir %s must be an absolute path", c.JUnitResultsDir)
+	}
+	names := map[string]bool{}
+	for i, j := range c.Jobs {
+		if j.Name == "" || j.Image == "" {
+			return fmt.Errorf("previewEnvironments.postPreviewJobs.jobs[%d] must have a name and an image", i)
+		}
+		if names[j.Name] {
+			return fmt.Errorf("duplicate previewEnvironments.postPreviewJobs.jobs name %s", j.Name)
+		}
+		names[j.Name] = true
+	}
+	return nil
 }
 
 type IssueTrackerConfig struct {
 	Kind    string `yaml:"kind,omitempty"`
 	URL     string `yaml:"url,omitempty"`
 	Project string `yaml:"project,omitempty"`
+
+	// Transitions the status of the issues of a release when it is promoted to an environment
+	Transitions []IssueTransitionConfig `yaml:"transitions,omitempty"`
+}
+
+// IssueTransitionConfig is the status to move the issues of a release to when it is promoted to an environment
+type IssueTransitionConfig struct {
+	// Environment the name or label of the environment
+	Environment string `yaml:"environment,omitempty"`
+	// Status the status of the issue tracker to move the issues to such as "In Staging" or "Released"
+	Status string `yaml:"status,omitempty"`
+	// FixVersion sets the version of the release as the fix version of the issues
+	FixVersion bool `yaml:"fixVersion,omitempty"`
+}
+
+// GetTransition returns the transition of the environment with the given name or label or nil if there is none
+func (c *IssueTrackerConfig) GetTransition(name string, label string) *IssueTransitionConfig {
+	for i := range c.Transitions {
+		t := &c.Transitions[i]
+		if strings.EqualFold(t.Environment, name) || (label != "" && strings.EqualFold(t.Environment, label)) {
+			return t
+		}
+	}
+	return nil
 }
 
 type WikiConfig struct {
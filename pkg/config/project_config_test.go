@@ -47,3 +47,214 @@ func TestProjectConfigMarshal(t *testing.T) {
 	assert.True(t, projectConfig.Builds[0].ExcludePodTemplateEnv)
 	assert.True(t, projectConfig.Builds[0].ExcludePodTemplateVolumes)
 }
+
+func TestIssueTrackerTransitions(t *testing.T) {
+	t.Parallel()
+	text := `issueTracker:
+  kind: jira
+  url: https://jenkins-x.atlassian.net
+  project: JX
+  transitions:
+  - environment: staging
+    status: In Staging
+  - environment: Production
+    status: Released
+    fixVersion: true
+`
+	projectConfig := &config.ProjectConfig{}
+	err := yaml.Unmarshal([]byte(text), projectConfig)
+	assert.NoError(t, err)
+
+	it := projectConfig.IssueTracker
+	assert.NotNil(t, it)
+	staging := it.GetTransition("staging", "Staging")
+	if assert.NotNil(t, staging) {
+		assert.Equal(t, "In Staging", staging.Status)
+		assert.False(t, staging.FixVersion)
+	}
+	production := it.GetTransition("production", "")
+	if assert.NotNil(t, production) {
+		assert.Equal(t, "Released", production.Status)
+		assert.True(t, production.FixVersion)
+	}
+	assert.Nil(t, it.GetTransition("test", "Test"))
+}
+
+func TestMonorepoApps(t *testing.T) {
+	t.Parallel()
+	text := `apps:
+- name: frontend
+  dir: apps/frontend
+- name: backend
+  dir: apps/backend
+  paths:
+  - libs/common
+  - go.*
+`
+	projectConfig := &config.ProjectConfig{}
+	err := yaml.Unmarshal([]byte(text), projectConfig)
+	assert.NoError(t, err)
+	assert.Len(t, projectConfig.Apps, 2)
+
+	assertChangedApps(t, projectConfig, []string{"README.md"})
+	assertChangedApps(t, projectConfig, []string{"apps/frontend/package.json"}, "frontend")
+	assertChangedApps(t, projectConfig, []string{"apps/frontend-old/package.json"})
+	assertChangedApps(t, projectConfig, []string{"libs/common/util.go", "apps/frontend/src/index.js"}, "frontend", "backend")
+	assertChangedApps(t, projectConfig, []string{"go.sum"}, "backend")
+
+	app := projectConfig.FindAppForPath("apps/backend/charts/backend")
+	if assert.NotNil(t, app) {
+		assert.Equal(t, "backend", app.Name)
+	}
+	assert.Nil(t, projectConfig.FindAppForPath("libs/common"))
+
+	projectConfig.AddApp(config.AppConfig{Name: "frontend", Dir: "web"})
+	projectConfig.AddApp(config.AppConfig{Name: "docs", Dir: "docs"})
+	assert.Len(t, projectConfig.Apps, 3)
+	assert.Equal(t, "web", projectConfig.GetApp("frontend").Dir)
+	assert.Nil(t, projectConfig.GetApp("cheese"))
+}
+
+func TestPreviewEnvironmentConfigValidate(t *testing.T) {
+	t.Parallel()
+	text := `previewEnvironments:
+  maximumInstances: 3
+  timeToLive: 72h
+  idleTimeout: 24h
+  hibernateAfter: 2h
+`
+	projectConfig := &config.ProjectConfig{}
+	err := yaml.Unmarshal([]byte(text), projectConfig)
+	assert.NoError(t, err)
+	previews := projectConfig.PreviewEnvironments
+	if assert.NotNil(t, previews) {
+		assert.Equal(t, 3, previews.MaximumInstances)
+		assert.Equal(t, "72h", previews.TimeToLive)
+		assert.Equal(t, "24h", previews.IdleTimeout)
+		assert.Equal(t, "2h", previews.HibernateAfter)
+		assert.NoError(t, previews.Validate())
+	}
+
+	assert.Error(t, (&config.PreviewEnvironmentConfig{IdleTimeout: "1 day"}).Validate())
+	assert.Error(t, (&config.PreviewEnvironmentConfig{HibernateAfter: "soon"}).Validate())
+	assert.Error(t, (&config.PreviewEnvironmentConfig{MaximumInstances: -1}).Validate())
+}
+
+func TestPreviewEnvironmentDependencies(t *testing.T) {
+	t.Parallel()
+	text := `previewEnvironments:
+  dependencies:
+  - name: postgres
+    chart: stable/postgresql
+    version: 2.1.0
+    values:
+    - postgresqlDatabase=cheese
+  seedJobs:
+  - name: data
+    image: postgres:10
+    command:
+    - psql
+    env:
+    - name: PGHOST
+      value: postgres
+    - name: PGPASSWORD
+      valueFrom:
+        secretKeyRef:
+          name: postgres
+          key: postgres-password
+`
+	projectConfig := &config.ProjectConfig{}
+	err := yaml.Unmarshal([]byte(text), projectConfig)
+	assert.NoError(t, err)
+	previews := projectConfig.PreviewEnvironments
+	if assert.NotNil(t, previews) && assert.Len(t, previews.Dependencies, 1) && assert.Len(t, previews.SeedJobs, 1) {
+		assert.Equal(t, "stable/postgresql", previews.Dependencies[0].Chart)
+		assert.Equal(t, []string{"postgresqlDatabase=cheese"}, previews.Dependencies[0].Values)
+		env := previews.SeedJobs[0].Env
+		if assert.Len(t, env, 2) {
+			assert.Equal(t, "postgres", env[0].Value)
+			if assert.NotNil(t, env[1].ValueFrom) && assert.NotNil(t, env[1].ValueFrom.SecretKeyRef) {
+				assert.Equal(t, "postgres", env[1].ValueFrom.SecretKeyRef.Name)
+				assert.Equal(t, "postgres-password", env[1].ValueFrom.SecretKeyRef.Key)
+			}
+		}
+		assert.NoError(t, previews.Validate())
+
+		// the environment variables are saved with the same camel case fields
+		data, err := yaml.Marshal(projectConfig)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "secretKeyRef:")
+		loaded := &config.ProjectConfig{}
+		assert.NoError(t, yaml.Unmarshal(data, loaded))
+		assert.Equal(t, previews.SeedJobs, loaded.PreviewEnvironments.SeedJobs)
+	}
+
+	assert.Error(t, (&config.PreviewEnvironmentConfig{
+		Dependencies: []config.PreviewDependencyConfig{{Name: "postgres"}},
+	}).Validate())
+	assert.Error(t, (&config.PreviewEnvironmentConfig{
+		Dependencies: []config.PreviewDependencyConfig{{Name: "db", Chart: "stable/mysql"}, {Name: "db", Chart: "stable/postgresql"}},
+	}).Validate())
+	assert.Error(t, (&config.PreviewEnvironmentConfig{
+		SeedJobs: []config.PreviewSeedJobConfig{{Name: "data"}},
+	}).Validate())
+}
+
+func TestPreviewEnvironmentPostPreviewJobs(t *testing.T) {
+	t.Parallel()
+	text := `previewEnvironments:
+  postPreviewJobs:
+    exclude:
+    - owasp
+    logTailLines: 50
+    jobs:
+    - name: smoke
+      image: maven:3
+      command:
+      - mvn
+      args:
+      - verify
+`
+	projectConfig := &config.ProjectConfig{}
+	err := yaml.Unmarshal([]byte(text), projectConfig)
+	assert.NoError(t, err)
+	previews := projectConfig.PreviewEnvironments
+	if assert.NotNil(t, previews) && assert.NotNil(t, previews.PostPreviewJobs) {
+		jobs := previews.PostPreviewJobs
+		assert.Equal(t, []string{"owasp"}, jobs.Exclude)
+		assert.Equal(t, []string{"verify"}, jobs.Jobs[0].Args)
+		assert.Equal(t, 50, jobs.GetLogTailLines())
+		assert.Equal(t, "", jobs.GetJUnitResultsDir(), "JUnit results should only be collected when enabled")
+		assert.Equal(t, config.DefaultJUnitCollectorImage, jobs.GetJUnitCollectorImage())
+		assert.NoError(t, previews.Validate())
+	}
+
+	var jobs *config.PostPreviewJobsConfig
+	assert.Equal(t, "", jobs.GetJUnitResultsDir())
+	assert.Equal(t, config.DefaultJUnitCollectorImage, jobs.GetJUnitCollectorImage())
+	assert.Equal(t, config.DefaultLogTailLines, jobs.GetLogTailLines())
+	assert.Equal(t, config.DefaultJUnitResultsDir, (&config.PostPreviewJobsConfig{JUnitResults: true}).GetJUnitResultsDir())
+	assert.Equal(t, "/junit", (&config.PostPreviewJobsConfig{JUnitResults: true, JUnitResultsDir: "/junit"}).GetJUnitResultsDir())
+	assert.Equal(t, "alpine:3.8", (&config.PostPreviewJobsConfig{JUnitCollectorImage: "alpine:3.8"}).GetJUnitCollectorImage())
+
+	assert.Error(t, (&config.PreviewEnvironmentConfig{
+		PostPreviewJobs: &config.PostPreviewJobsConfig{Jobs: []config.PostPreviewJobConfig{{Name: "smoke"}}},
+	}).Validate())
+	assert.Error(t, (&config.PreviewEnvironmentConfig{
+		PostPreviewJobs: &config.PostPreviewJobsConfig{JUnitResultsDir: "results"},
+	}).Validate())
+	assert.Error(t, (&config.PreviewEnvironmentConfig{
+		PostPreviewJobs: &config.PostPreviewJobsConfig{LogTailLines: -1},
+	}).Validate())
+}
+
+func assertChangedApps(t *testing.T, projectConfig *config.ProjectConfig, paths []string, expected ...string) {
+	names := []string{}
+	for _, app := range projectConfig.ChangedApps(paths) {
+		names = append(names, app.Name)
+	}
+	if expected == nil {
+		expected = []string{}
+	}
+	assert.Equal(t, expected, names, "changed apps for paths %v", paths)
+}
@@ -8,11 +8,8 @@ import (
 	"fmt"
 
 	"github.com/jenkins-x/jx/pkg/auth"
-	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
-	"github.com/jenkins-x/jx/pkg/table"
 	"github.com/jenkins-x/jx/pkg/util"
-	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -31,11 +28,11 @@ type VulnerabilityList struct {
 }
 
 type Vulnerability struct {
-	Fix      string
-	Package  string
-	Severity string
-	URL      string
-	Vuln     string
+	Fix      string `json:"fix,omitempty"`
+	Package  string `json:"package,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Vuln     string `json:"vuln"`
 }
 
 type Image struct {
@@ -71,84 +68,55 @@ func NewAnchoreProvider(server *auth.AuthServer, user *auth.UserAuth) (CVEProvid
 	return &provider, nil
 }
 
-func (a AnchoreProvider) GetImageVulnerabilityTable(jxClient versioned.Interface, client kubernetes.Interface, table *table.Table, query CVEQuery) error {
-
-	var err error
-	var vList VulnerabilityList
-	var imageIDs []string
-
+// GetImageVulnerabilities returns the vulnerabilities of the images matching the image ID or the image name and version of the query
+func (a AnchoreProvider) GetImageVulnerabilities(query CVEQuery) ([]ImageVulnerabilities, error) {
+	answer := []ImageVulnerabilities{}
+	imageIDs := []string{}
 	if query.ImageID != "" {
-		var vList VulnerabilityList
-		subPath := fmt.Sprintf(getVulnerabilitiesByImageID, query.ImageID, vulnerabilityType)
-
-		err = a.AnchoreGet(subPath, &vList)
+		imageIDs = append(imageIDs, query.ImageID)
+	} else if query.ImageName != "" {
+		var images []Image
+		err := a.AnchoreGet(GetImages, &images)
 		if err != nil {
-			return fmt.Errorf("error getting vulnerabilities for image %s: %v", query.ImageID, err)
+			return answer, fmt.Errorf("error getting images %v", err)
 		}
-
-		return a.addVulnerabilitiesTableRows(table, &vList)
+		for _, image := range images {
+			for _, d := range image.ImageDetails {
+				if (d.Repo == query.ImageName || d.Registry+"/"+d.Repo == query.ImageName) && (query.Version == "" || query.Version == d.Tag) {
+					imageIDs = append(imageIDs, d.ImageId)
+				}
+			}
+		}
+		if len(imageIDs) == 0 {
+			return answer, errors.Wrapf(ErrImageNotFound, "no matching images found for ImageName %s and Version %s", query.ImageName, query.Version)
+		}
+	} else {
+		return answer, fmt.Errorf("choose an image name, an optinal version or anchore image id to find vulnerabilities")
 	}
 
-	if query.Environment != "" {
-
+	for _, imageID := range imageIDs {
 		var vList VulnerabilityList
-		// list pods in the namespace
-		podList, err := client.CoreV1().Pods(query.TargetNamespace).List(meta_v1.ListOptions{})
+		subPath := fmt.Sprintf(getVulnerabilitiesByImageID, imageID, vulnerabilityType)
+		err := a.AnchoreGet(subPath, &vList)
 		if err != nil {
-			return err
+			return answer, fmt.Errorf("error getting vulnerabilities for image %s: %v", imageID, err)
 		}
-		// if they have the annotation add the value to a list
-		for _, p := range podList.Items {
-			if p.Annotations[AnnotationCVEImageId] != "" {
-				imageIDs = append(imageIDs, p.Annotations[AnnotationCVEImageId])
-			}
-		}
-		// loop over the list and get the CVEs for each, adding the rows
-		err = a.getCVEsFromImageList(table, &vList, imageIDs)
+		var image []Image
+		err = a.AnchoreGet(fmt.Sprintf(getVulnerabilitiesByImageDigest, vList.ImageDigest), &image)
 		if err != nil {
-			return err
+			return answer, fmt.Errorf("error getting image for image digest %s: %v", vList.ImageDigest, err)
 		}
-	}
-
-	// see if we can match images using an image name and optional version
-	if query.ImageID == "" {
-		// if we have an image name then lets try and match image id(s)
-		if query.ImageName != "" {
-
-			var images []Image
-			subPath := fmt.Sprintf(GetImages)
-
-			err = a.AnchoreGet(subPath, &images)
-			if err != nil {
-				return fmt.Errorf("error getting images %v", err)
-			}
-
-			for _, image := range images {
-				for _, d := range image.ImageDetails {
-					if d.Repo == query.ImageName {
-						// if user has provided a version and it doesn't match lets skip this image
-						if query.Vesion != "" && query.Vesion != d.Tag {
-							continue
-						}
-						imageIDs = append(imageIDs, d.ImageId)
-					}
-				}
-			}
-			if len(imageIDs) > 0 {
-				err = a.getCVEsFromImageList(table, &vList, imageIDs)
-				if err != nil {
-					return err
-				}
-			} else {
-				return fmt.Errorf("no matching images found for ImageName %s and Vesion %s", query.ImageName, query.Vesion)
-			}
+		name := imageID
+		if len(image) > 0 && len(image[0].ImageDetails) > 0 {
+			name = image[0].ImageDetails[0].Fulltag
 		}
-	} else {
-		return fmt.Errorf("choose an image name, an optinal version or anchore image id to find vulnerabilities")
+		answer = append(answer, ImageVulnerabilities{
+			Image:           name,
+			ImageID:         imageID,
+			Vulnerabilities: vList.Vulnerabilities,
+		})
 	}
-
-	return nil
-
+	return answer, nil
 }
 
 // AnchoreGet get command
@@ -174,46 +142,3 @@ func (a AnchoreProvider) AnchoreGet(subPath string, rs result) error {
 	}
 	return nil
 }
-
-func (a AnchoreProvider) addVulnerabilitiesTableRows(table *table.Table, vList *VulnerabilityList) error {
-
-	var image []Image
-	subPath := fmt.Sprintf(getVulnerabilitiesByImageDigest, vList.ImageDigest)
-
-	err := a.AnchoreGet(subPath, &image)
-	if err != nil {
-		return fmt.Errorf("error getting image for image digest %s: %v", vList.ImageDigest, err)
-	}
-	// TODO sort vList on severity and version?
-
-	for _, v := range vList.Vulnerabilities {
-		var sev string
-		switch v.Severity {
-		case "High":
-			sev = util.ColorError(v.Severity)
-		case "Medium":
-			sev = util.ColorWarning(v.Severity)
-		case "Low":
-			sev = util.ColorStatus(v.Severity)
-		}
-		table.AddRow(image[0].ImageDetails[0].Fulltag, sev, v.Vuln, v.URL, v.Package, v.Fix)
-	}
-	return nil
-}
-
-func (a AnchoreProvider) getCVEsFromImageList(table *table.Table, vList *VulnerabilityList, ids []string) error {
-	for _, imageID := range ids {
-		subPath := fmt.Sprintf(getVulnerabilitiesByImageID, imageID, vulnerabilityType)
-
-		err := a.AnchoreGet(subPath, &vList)
-		if err != nil {
-			return fmt.Errorf("error getting vulnerabilities for image %s: %v", imageID, err)
-		}
-
-		err = a.addVulnerabilitiesTableRows(table, vList)
-		if err != nil {
-			return fmt.Errorf("error building vulnerabilities table for image digest %s: %v", vList.ImageDigest, err)
-		}
-	}
-	return nil
-}
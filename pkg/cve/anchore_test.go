@@ -109,9 +109,44 @@ func (suite *AnchoreProviderTestSuite) TestGetImageVulnerabilityTable() {
 		ImageID: "07b67913cd8c1ffc961c402b58c4e539ee6aaeae0b08969fc653267f4b975503",
 	}
 
-	err := suite.provider.GetImageVulnerabilityTable(nil, nil, &vTable, query)
+	images, err := suite.provider.GetImageVulnerabilities(query)
 	suite.Require().NoError(err)
 
+	cve.AddVulnerabilityTableRows(&vTable, images)
 	vTable.Render()
 
 }
+
+func (suite *AnchoreProviderTestSuite) TestGetImageVulnerabilities() {
+
+	query := cve.CVEQuery{
+		ImageID: "07b67913cd8c1ffc961c402b58c4e539ee6aaeae0b08969fc653267f4b975503",
+	}
+
+	images, err := suite.provider.GetImageVulnerabilities(query)
+	suite.Require().NoError(err)
+	suite.Require().Len(images, 1)
+
+	suite.EqualValues(query.ImageID, images[0].ImageID)
+	suite.NotEmpty(images[0].Image)
+	suite.Require().NotEmpty(images[0].Vulnerabilities)
+	suite.EqualValues("RHSA-2018:0102", images[0].Vulnerabilities[0].Vuln)
+	suite.EqualValues("High", images[0].Vulnerabilities[0].Severity)
+}
+
+func (suite *AnchoreProviderTestSuite) TestGetImageVulnerabilitiesByName() {
+
+	query := cve.CVEQuery{
+		ImageName: "docker.io/jenkinsxio/nexus",
+		Version:   "0.0.6",
+	}
+
+	images, err := suite.provider.GetImageVulnerabilities(query)
+	suite.Require().NoError(err)
+	suite.Require().Len(images, 1)
+	suite.EqualValues("07b67913cd8c1ffc961c402b58c4e539ee6aaeae0b08969fc653267f4b975503", images[0].ImageID)
+
+	query.Version = "0.0.7"
+	_, err = suite.provider.GetImageVulnerabilities(query)
+	suite.True(cve.IsImageNotFound(err))
+}
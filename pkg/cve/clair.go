@@ -0,0 +1,176 @@
+package cve
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/pkg/errors"
+)
+
+const (
+	getClairVulnerabilityReport = "/matcher/api/v1/vulnerability_report/%s"
+)
+
+// ClairVulnerabilityReport is the vulnerability report of a manifest returned by the Clair v4 matcher API
+type ClairVulnerabilityReport struct {
+	ManifestHash           string                        `json:"manifest_hash"`
+	Packages               map[string]ClairPackage       `json:"packages"`
+	Vulnerabilities        map[string]ClairVulnerability `json:"vulnerabilities"`
+	PackageVulnerabilities map[string][]string           `json:"package_vulnerabilities"`
+}
+
+// ClairPackage is a package found in a manifest
+type ClairPackage struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ClairVulnerability is a vulnerability affecting a package
+type ClairVulnerability struct {
+	ID                 string        `json:"id"`
+	Name               string        `json:"name"`
+	Description        string        `json:"description"`
+	Links              string        `json:"links"`
+	Severity           string        `json:"severity"`
+	NormalizedSeverity string        `json:"normalized_severity"`
+	FixedInVersion     string        `json:"fixed_in_version"`
+	Package            *ClairPackage `json:"package"`
+}
+
+// ClairProvider implements CVEProvider interface for Clair v4
+type ClairProvider struct {
+	Client      *http.Client
+	BearerToken string
+	BaseURL     string
+}
+
+// NewClairProvider creates a CVEProvider for the Clair v4 server
+func NewClairProvider(server *auth.AuthServer, user *auth.UserAuth) (CVEProvider, error) {
+	token := ""
+	if user != nil {
+		token = user.BearerToken
+		if token == "" {
+			token = user.ApiToken
+		}
+	}
+	provider := ClairProvider{
+		BaseURL:     strings.TrimSuffix(server.URL, "/"),
+		BearerToken: token,
+		Client:      http.DefaultClient,
+	}
+	return &provider, nil
+}
+
+// GetImageVulnerabilities returns the vulnerabilities of the manifest digest of the query. Clair indexes manifests
+// so the image ID is only used if it is a digest
+func (c ClairProvider) GetImageVulnerabilities(query CVEQuery) ([]ImageVulnerabilities, error) {
+	answer := []ImageVulnerabilities{}
+	digest := query.ImageDigest
+	if digest == "" && strings.HasPrefix(query.ImageID, "sha256:") {
+		digest = query.ImageID
+	}
+	if digest == "" {
+		return answer, fmt.Errorf("the Clair provider needs the manifest digest of image %s to find vulnerabilities", query.ImageName)
+	}
+
+	var report ClairVulnerabilityReport
+	err := c.ClairGet(fmt.Sprintf(getClairVulnerabilityReport, digest), &report)
+	if err != nil {
+		return answer, errors.Wrapf(err, "error getting vulnerabilities for manifest %s", digest)
+	}
+
+	name := digest
+	if query.ImageName != "" {
+		name = query.ImageName + "@" + digest
+		if query.Version != "" {
+			name = query.ImageName + ":" + query.Version
+		}
+	}
+	answer = append(answer, ImageVulnerabilities{
+		Image:           name,
+		ImageID:         digest,
+		Vulnerabilities: report.ToVulnerabilities(),
+	})
+	return answer, nil
+}
+
+// ToVulnerabilities returns a Vulnerability for each vulnerable package in the report
+func (r *ClairVulnerabilityReport) ToVulnerabilities() []Vulnerability {
+	answer := []Vulnerability{}
+	packageIDs := []string{}
+	for id := range r.PackageVulnerabilities {
+		packageIDs = append(packageIDs, id)
+	}
+	sort.Strings(packageIDs)
+	for _, id := range packageIDs {
+		pkg := r.Packages[id]
+		for _, vulnID := range r.PackageVulnerabilities[id] {
+			v, ok := r.Vulnerabilities[vulnID]
+			if !ok {
+				continue
+			}
+			if pkg.Name == "" && v.Package != nil {
+				pkg = *v.Package
+			}
+			severity := v.NormalizedSeverity
+			if severity == "" {
+				severity = v.Severity
+			}
+			url := ""
+			links := strings.Fields(v.Links)
+			if len(links) > 0 {
+				url = links[0]
+			}
+			answer = append(answer, Vulnerability{
+				Vuln:     v.Name,
+				Severity: NormalizeSeverity(severity),
+				Package:  strings.TrimSpace(pkg.Name + "-" + pkg.Version),
+				Fix:      v.FixedInVersion,
+				URL:      url,
+			})
+		}
+	}
+	SortVulnerabilities(answer)
+	return answer
+}
+
+// ClairGet gets the JSON resource at the path of the Clair server
+func (c ClairProvider) ClairGet(subPath string, rs result) error {
+	url := c.BaseURL + subPath
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	if c.BearerToken != "" {
+		req.Header.Add("Authorization", "Bearer "+c.BearerToken)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error getting vulnerabilities from clair %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrImageNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error response getting vulnerabilities from clair: %s", resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(data, rs)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling %v", err)
+	}
+	return nil
+}
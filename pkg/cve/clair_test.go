@@ -0,0 +1,69 @@
+package cve_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const clairManifest = "sha256:b9f03c3c4b196d46639bee0ec9cd0f6dbea8cc39d32767c8312f04317c3b18f4"
+
+func TestClairGetImageVulnerabilities(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/matcher/api/v1/vulnerability_report/"+clairManifest, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer mytoken", r.Header.Get("Authorization"))
+		util.GetMockAPIResponseFromFile("test_data/clair", util.MethodMap{
+			"GET": "vulnerability_report.json",
+		})(w, r)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider, err := cve.NewCVEProvider(&auth.AuthServer{
+		URL:  server.URL,
+		Kind: cve.ProviderKindClair,
+	}, &auth.UserAuth{
+		BearerToken: "mytoken",
+	})
+	require.NoError(t, err)
+	require.IsType(t, &cve.ClairProvider{}, provider)
+
+	images, err := provider.GetImageVulnerabilities(cve.CVEQuery{
+		ImageName:   "jenkinsxio/nexus",
+		Version:     "0.0.6",
+		ImageDigest: clairManifest,
+	})
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, "jenkinsxio/nexus:0.0.6", images[0].Image)
+	assert.Equal(t, clairManifest, images[0].ImageID)
+
+	vulnerabilities := images[0].Vulnerabilities
+	require.Len(t, vulnerabilities, 3)
+	assert.Equal(t, cve.Vulnerability{
+		Vuln:     "CVE-2018-1000120",
+		Severity: "High",
+		Package:  "curl-7.58.0-2",
+		Fix:      "7.58.0-2+deb10u1",
+		URL:      "https://security-tracker.debian.org/tracker/CVE-2018-1000120",
+	}, vulnerabilities[0])
+	assert.Equal(t, "CVE-2018-0739", vulnerabilities[1].Vuln)
+	assert.Equal(t, "Negligible", vulnerabilities[2].Severity)
+
+	_, err = provider.GetImageVulnerabilities(cve.CVEQuery{
+		ImageDigest: "sha256:unknown",
+	})
+	assert.True(t, cve.IsImageNotFound(err))
+
+	_, err = provider.GetImageVulnerabilities(cve.CVEQuery{
+		ImageName: "jenkinsxio/nexus",
+	})
+	assert.Error(t, err)
+}
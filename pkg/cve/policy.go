@@ -0,0 +1,179 @@
+package cve
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// DefaultPolicyMaxChecks is how many times a promotion blocked by a CVE policy is checked again before it fails
+const DefaultPolicyMaxChecks = 30
+
+// Severities the severities of vulnerabilities in increasing order
+var Severities = []string{"Unknown", "Negligible", "Low", "Medium", "High", "Critical"}
+
+// PolicyViolation is a vulnerability in an image which is not allowed by a CVEPolicy
+type PolicyViolation struct {
+	Image         string
+	Vulnerability Vulnerability
+}
+
+// SeverityIndex returns the position of the severity in Severities or 0 if it is not known
+func SeverityIndex(severity string) int {
+	for i, s := range Severities {
+		if strings.EqualFold(s, severity) {
+			return i
+		}
+	}
+	return 0
+}
+
+// CheckPolicy returns the vulnerabilities of the images which are more severe than the policy allows, are not
+// in the allowed list and were first seen longer ago than the grace period
+func CheckPolicy(policy *v1.CVEPolicy, images []ImageVulnerabilities, firstSeen map[string]time.Time, now time.Time) ([]PolicyViolation, error) {
+	answer := []PolicyViolation{}
+	if policy == nil {
+		return answer, nil
+	}
+	maxSeverity := len(Severities) - 1
+	if policy.MaxSeverity != "" {
+		maxSeverity = SeverityIndex(policy.MaxSeverity)
+	}
+	gracePeriod := time.Duration(0)
+	if policy.GracePeriod != "" {
+		var err error
+		gracePeriod, err = time.ParseDuration(policy.GracePeriod)
+		if err != nil {
+			return answer, errors.Wrapf(err, "invalid gracePeriod %s on CVE policy", policy.GracePeriod)
+		}
+	}
+	for _, image := range images {
+		for _, v := range image.Vulnerabilities {
+			if SeverityIndex(v.Severity) <= maxSeverity || util.StringArrayIndex(policy.AllowedCVEs, v.Vuln) >= 0 {
+				continue
+			}
+			seen, ok := firstSeen[v.Vuln]
+			if !ok {
+				seen = now
+			}
+			if gracePeriod > 0 && now.Sub(seen) < gracePeriod {
+				continue
+			}
+			answer = append(answer, PolicyViolation{
+				Image:         image.Image,
+				Vulnerability: v,
+			})
+		}
+	}
+	return answer, nil
+}
+
+// FirstSeen returns when each vulnerability was first recorded in the CVE facts of the activities of the pipeline
+func FirstSeen(activities []v1.PipelineActivity, pipeline string) map[string]time.Time {
+	answer := map[string]time.Time{}
+	for _, activity := range activities {
+		if activity.Spec.Pipeline != pipeline || activity.Spec.StartedTimestamp == nil {
+			continue
+		}
+		started := activity.Spec.StartedTimestamp.Time
+		for _, fact := range activity.Spec.Facts {
+			if fact.FactType != v1.FactTypeCVE {
+				continue
+			}
+			for _, statement := range fact.Statements {
+				seen, ok := answer[statement.Name]
+				if !ok || started.Before(seen) {
+					answer[statement.Name] = started
+				}
+			}
+		}
+	}
+	return answer
+}
+
+// CreatePolicyFact returns a Fact recording the vulnerabilities found when checking the images promoted into the environment.
+// Every vulnerability more severe than the policy allows is recorded as a statement which is true if it violates the policy
+func CreatePolicyFact(envName string, policy *v1.CVEPolicy, images []ImageVulnerabilities, violations []PolicyViolation) v1.Fact {
+	counts := map[string]int{}
+	violated := map[string]bool{}
+	for _, violation := range violations {
+		violated[violation.Vulnerability.Vuln] = true
+	}
+	maxSeverity := len(Severities) - 1
+	if policy != nil && policy.MaxSeverity != "" {
+		maxSeverity = SeverityIndex(policy.MaxSeverity)
+	}
+	statements := []v1.Statement{}
+	recorded := map[string]bool{}
+	for _, image := range images {
+		for _, v := range image.Vulnerabilities {
+			counts[Severities[SeverityIndex(v.Severity)]]++
+			if SeverityIndex(v.Severity) <= maxSeverity || recorded[v.Vuln] {
+				continue
+			}
+			recorded[v.Vuln] = true
+			statements = append(statements, v1.Statement{
+				Name:             v.Vuln,
+				StatementType:    v.Severity,
+				MeasurementValue: violated[v.Vuln],
+				Tags:             []string{image.Image, v.Package},
+			})
+		}
+	}
+	sort.Slice(statements, func(i, j int) bool {
+		return statements[i].Name < statements[j].Name
+	})
+	measurements := []v1.Measurement{}
+	for _, severity := range Severities {
+		if counts[severity] > 0 {
+			measurements = append(measurements, v1.Measurement{
+				Name:             severity,
+				MeasurementType:  v1.MeasurementCount,
+				MeasurementValue: counts[severity],
+			})
+		}
+	}
+	return v1.Fact{
+		Name:         PolicyFactName(envName),
+		FactType:     v1.FactTypeCVE,
+		Measurements: measurements,
+		Statements:   statements,
+	}
+}
+
+// PolicyFactName returns the name of the Fact recording the CVE policy check of the environment
+func PolicyFactName(envName string) string {
+	return "cve-policy-" + envName
+}
+
+// SetFact adds the fact to the activity replacing any existing fact with the same name
+func SetFact(activity *v1.PipelineActivity, fact v1.Fact) {
+	facts := activity.Spec.Facts
+	for i := range facts {
+		if facts[i].Name == fact.Name {
+			fact.ID = facts[i].ID
+			facts[i] = fact
+			return
+		}
+	}
+	fact.ID = len(facts) + 1
+	activity.Spec.Facts = append(facts, fact)
+}
+
+// DescribeViolations returns a summary of the policy violations
+func DescribeViolations(violations []PolicyViolation) string {
+	ids := []string{}
+	for _, violation := range violations {
+		v := violation.Vulnerability
+		text := fmt.Sprintf("%s (%s)", v.Vuln, v.Severity)
+		if util.StringArrayIndex(ids, text) < 0 {
+			ids = append(ids, text)
+		}
+	}
+	return strings.Join(ids, ", ")
+}
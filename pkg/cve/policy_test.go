@@ -0,0 +1,118 @@
+package cve_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var testImages = []cve.ImageVulnerabilities{
+	{
+		Image: "docker.io/myorg/myapp:1.0.1",
+		Vulnerabilities: []cve.Vulnerability{
+			{Vuln: "CVE-2018-0001", Severity: "High", Package: "openssl"},
+			{Vuln: "CVE-2018-0002", Severity: "Medium", Package: "glibc"},
+			{Vuln: "CVE-2018-0003", Severity: "Critical", Package: "bash"},
+			{Vuln: "CVE-2018-0004", Severity: "Low", Package: "curl"},
+		},
+	},
+}
+
+func TestCheckPolicy(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	policy := &v1.CVEPolicy{
+		MaxSeverity: "Medium",
+		AllowedCVEs: []string{"CVE-2018-0003"},
+	}
+	violations, err := cve.CheckPolicy(policy, testImages, nil, now)
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "CVE-2018-0001", violations[0].Vulnerability.Vuln)
+	assert.Equal(t, "docker.io/myorg/myapp:1.0.1", violations[0].Image)
+
+	policy.MaxSeverity = "critical"
+	violations, err = cve.CheckPolicy(policy, testImages, nil, now)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	violations, err = cve.CheckPolicy(nil, testImages, nil, now)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestCheckPolicyGracePeriod(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	policy := &v1.CVEPolicy{
+		MaxSeverity: "Low",
+		GracePeriod: "168h",
+	}
+	firstSeen := map[string]time.Time{
+		"CVE-2018-0001": now.Add(-200 * time.Hour),
+		"CVE-2018-0002": now.Add(-24 * time.Hour),
+	}
+	violations, err := cve.CheckPolicy(policy, testImages, firstSeen, now)
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "CVE-2018-0001", violations[0].Vulnerability.Vuln)
+
+	policy.GracePeriod = "a week"
+	_, err = cve.CheckPolicy(policy, testImages, firstSeen, now)
+	assert.Error(t, err)
+}
+
+func TestCreatePolicyFactAndFirstSeen(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	policy := &v1.CVEPolicy{
+		MaxSeverity: "Medium",
+	}
+	violations, err := cve.CheckPolicy(policy, testImages, nil, now)
+	require.NoError(t, err)
+
+	fact := cve.CreatePolicyFact("production", policy, testImages, violations)
+	assert.Equal(t, "cve-policy-production", fact.Name)
+	assert.Equal(t, v1.FactTypeCVE, fact.FactType)
+	require.Len(t, fact.Statements, 2)
+	assert.Equal(t, "CVE-2018-0001", fact.Statements[0].Name)
+	assert.True(t, fact.Statements[0].MeasurementValue)
+	assert.Equal(t, "CVE-2018-0003", fact.Statements[1].Name)
+	assert.Len(t, fact.Measurements, 4)
+
+	older := now.Add(-48 * time.Hour)
+	activities := []v1.PipelineActivity{
+		{
+			Spec: v1.PipelineActivitySpec{
+				Pipeline:         "myorg/myapp/master",
+				StartedTimestamp: &metav1.Time{Time: now},
+			},
+		},
+		{
+			Spec: v1.PipelineActivitySpec{
+				Pipeline:         "myorg/myapp/master",
+				StartedTimestamp: &metav1.Time{Time: older},
+			},
+		},
+		{
+			Spec: v1.PipelineActivitySpec{
+				Pipeline:         "myorg/another/master",
+				StartedTimestamp: &metav1.Time{Time: older.Add(-time.Hour)},
+			},
+		},
+	}
+	cve.SetFact(&activities[0], fact)
+	cve.SetFact(&activities[1], fact)
+	cve.SetFact(&activities[2], fact)
+	cve.SetFact(&activities[0], fact)
+	assert.Len(t, activities[0].Spec.Facts, 1)
+
+	firstSeen := cve.FirstSeen(activities, "myorg/myapp/master")
+	assert.Len(t, firstSeen, 2)
+	assert.True(t, older.Equal(firstSeen["CVE-2018-0001"]))
+}
@@ -1,22 +1,204 @@
 package cve
 
 import (
-	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/table"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
 const (
 	AnnotationCVEImageId = "jenkins-x.io/cve-image-id"
+
+	// ProviderKindAnchore the auth server kind of an Anchore engine
+	ProviderKindAnchore = "anchore"
+	// ProviderKindClair the auth server kind of a Clair v4 server
+	ProviderKindClair = "clair"
+	// ProviderKindTrivy the auth server kind of a location serving Trivy JSON reports
+	ProviderKindTrivy = "trivy"
 )
 
+// ProviderKinds the auth server kinds which have a CVE provider
+var ProviderKinds = []string{ProviderKindAnchore, ProviderKindClair, ProviderKindTrivy}
+
+// ErrImageNotFound is returned when a provider has no vulnerability report for an image
+var ErrImageNotFound = errors.New("no vulnerability report found")
+
 type CVEQuery struct {
 	ImageName       string
 	ImageID         string
-	Vesion          string
+	ImageDigest     string
+	Version         string
 	Environment     string
 	TargetNamespace string
 }
+
+// ImageVulnerabilities are the vulnerabilities found in an image
+type ImageVulnerabilities struct {
+	Image           string          `json:"image"`
+	ImageID         string          `json:"imageID,omitempty"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+}
+
 type CVEProvider interface {
-	GetImageVulnerabilityTable(jxClient versioned.Interface, client kubernetes.Interface, table *table.Table, query CVEQuery) error
+	// GetImageVulnerabilities returns the vulnerabilities of the images matching the image ID, digest or the image name and version of the query
+	GetImageVulnerabilities(query CVEQuery) ([]ImageVulnerabilities, error)
+}
+
+// NewCVEProvider creates the CVE provider for the kind of the auth server defaulting to Anchore
+func NewCVEProvider(server *auth.AuthServer, user *auth.UserAuth) (CVEProvider, error) {
+	switch server.Kind {
+	case ProviderKindClair:
+		return NewClairProvider(server, user)
+	case ProviderKindTrivy:
+		return NewTrivyProvider(server, user)
+	default:
+		return NewAnchoreProvider(server, user)
+	}
+}
+
+// IsImageNotFound returns true if the error is caused by the provider having no vulnerability report for an image
+func IsImageNotFound(err error) bool {
+	return errors.Cause(err) == ErrImageNotFound
+}
+
+// GetVulnerabilities returns the vulnerabilities of the images matching the query. If the query is for an environment
+// then the images of the pods running in the target namespace are queried, skipping any the provider has not scanned
+func GetVulnerabilities(provider CVEProvider, client kubernetes.Interface, query CVEQuery) ([]ImageVulnerabilities, error) {
+	if query.Environment == "" {
+		return provider.GetImageVulnerabilities(query)
+	}
+	queries, err := EnvironmentQueries(client, query.TargetNamespace)
+	if err != nil {
+		return nil, err
+	}
+	answer := []ImageVulnerabilities{}
+	found := map[string]bool{}
+	for _, q := range queries {
+		images, err := provider.GetImageVulnerabilities(q)
+		if err != nil {
+			if IsImageNotFound(err) {
+				log.Warnf("Skipping image %s: %s\n", q.ImageName, err)
+				continue
+			}
+			return answer, err
+		}
+		for _, image := range images {
+			key := image.Image + "/" + image.ImageID
+			if !found[key] {
+				found[key] = true
+				answer = append(answer, image)
+			}
+		}
+	}
+	return answer, nil
+}
+
+// EnvironmentQueries returns a query for each of the images of the pods running in the namespace
+func EnvironmentQueries(client kubernetes.Interface, ns string) ([]CVEQuery, error) {
+	podList, err := client.CoreV1().Pods(ns).List(meta_v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	answer := []CVEQuery{}
+	queried := map[string]bool{}
+	add := func(q CVEQuery) {
+		key := q.ImageID + "/" + q.ImageName + ":" + q.Version + "@" + q.ImageDigest
+		if !queried[key] {
+			queried[key] = true
+			answer = append(answer, q)
+		}
+	}
+	for _, p := range podList.Items {
+		imageID := p.Annotations[AnnotationCVEImageId]
+		if imageID != "" {
+			add(CVEQuery{ImageID: imageID})
+			continue
+		}
+		digests := map[string]string{}
+		for _, status := range p.Status.ContainerStatuses {
+			_, _, digests[status.Name] = ParseImage(status.ImageID)
+		}
+		for _, c := range p.Spec.Containers {
+			name, tag, digest := ParseImage(c.Image)
+			if digest == "" {
+				digest = digests[c.Name]
+			}
+			add(CVEQuery{
+				ImageName:   name,
+				Version:     tag,
+				ImageDigest: digest,
+			})
+		}
+	}
+	return answer, nil
+}
+
+// ParseImage splits an image reference such as docker.io/jenkinsxio/nexus:0.0.5 into its name, tag and digest.
+// Container status image IDs such as docker-pullable://jenkinsxio/nexus@sha256:abc are also supported
+func ParseImage(image string) (string, string, string) {
+	name := image
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+	digest := ""
+	if i := strings.Index(name, "@"); i >= 0 {
+		digest = name[i+1:]
+		name = name[:i]
+	}
+	tag := ""
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		tag = name[i+1:]
+		name = name[:i]
+	}
+	return name, tag, digest
+}
+
+// NormalizeSeverity returns the severity using the case of Severities
+func NormalizeSeverity(severity string) string {
+	for _, s := range Severities {
+		if strings.EqualFold(s, severity) {
+			return s
+		}
+	}
+	return severity
+}
+
+// SortVulnerabilities sorts the vulnerabilities with the most severe first
+func SortVulnerabilities(vulnerabilities []Vulnerability) {
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		si := SeverityIndex(vulnerabilities[i].Severity)
+		sj := SeverityIndex(vulnerabilities[j].Severity)
+		if si != sj {
+			return si > sj
+		}
+		if vulnerabilities[i].Vuln != vulnerabilities[j].Vuln {
+			return vulnerabilities[i].Vuln < vulnerabilities[j].Vuln
+		}
+		return vulnerabilities[i].Package < vulnerabilities[j].Package
+	})
+}
+
+// AddVulnerabilityTableRows adds a row to the table for each vulnerability of the images
+func AddVulnerabilityTableRows(table *table.Table, images []ImageVulnerabilities) {
+	for _, image := range images {
+		for _, v := range image.Vulnerabilities {
+			sev := v.Severity
+			switch NormalizeSeverity(v.Severity) {
+			case "Critical", "High":
+				sev = util.ColorError(v.Severity)
+			case "Medium":
+				sev = util.ColorWarning(v.Severity)
+			case "Low":
+				sev = util.ColorStatus(v.Severity)
+			}
+			table.AddRow(image.Image, sev, v.Vuln, v.URL, v.Package, v.Fix)
+		}
+	}
 }
@@ -0,0 +1,79 @@
+package cve_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseImage(t *testing.T) {
+	t.Parallel()
+	testData := map[string][]string{
+		"jenkinsxio/nexus":                                 {"jenkinsxio/nexus", "", ""},
+		"docker.io/jenkinsxio/nexus:0.0.5":                 {"docker.io/jenkinsxio/nexus", "0.0.5", ""},
+		"localhost:5000/myapp:1.0.0":                       {"localhost:5000/myapp", "1.0.0", ""},
+		"localhost:5000/myapp":                             {"localhost:5000/myapp", "", ""},
+		"docker-pullable://jenkinsxio/nexus@sha256:abc123": {"jenkinsxio/nexus", "", "sha256:abc123"},
+	}
+	for image, expected := range testData {
+		name, tag, digest := cve.ParseImage(image)
+		assert.Equal(t, expected, []string{name, tag, digest}, "parsing image %s", image)
+	}
+}
+
+func TestNewCVEProviderDefaultsToAnchore(t *testing.T) {
+	t.Parallel()
+	provider, err := cve.NewCVEProvider(&auth.AuthServer{
+		URL:  "http://anchore",
+		Kind: "cve",
+	}, &auth.UserAuth{})
+	require.NoError(t, err)
+	assert.IsType(t, &cve.AnchoreProvider{}, provider)
+}
+
+func TestGetEnvironmentVulnerabilities(t *testing.T) {
+	t.Parallel()
+	client := fake.NewSimpleClientset(
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "nexus-1",
+				Namespace: "jx-staging",
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{Name: "nexus", Image: "jenkinsxio/nexus:0.0.6"},
+					{Name: "sidecar", Image: "jenkinsxio/sidecar:1.0.0"},
+				},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "nexus-2",
+				Namespace: "jx-staging",
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{Name: "nexus", Image: "jenkinsxio/nexus:0.0.6"},
+				},
+			},
+		},
+	)
+	provider, err := cve.NewTrivyProvider(&auth.AuthServer{
+		URL: "test_data/trivy",
+	}, nil)
+	require.NoError(t, err)
+
+	images, err := cve.GetVulnerabilities(provider, client, cve.CVEQuery{
+		Environment:     "staging",
+		TargetNamespace: "jx-staging",
+	})
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, "jenkinsxio/nexus:0.0.6", images[0].Image)
+}
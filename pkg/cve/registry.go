@@ -0,0 +1,139 @@
+package cve
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	dockerHubRegistry = "registry-1.docker.io"
+
+	manifestMediaTypes = "application/vnd.docker.distribution.manifest.v2+json, " +
+		"application/vnd.docker.distribution.manifest.list.v2+json, " +
+		"application/vnd.oci.image.manifest.v1+json, " +
+		"application/vnd.oci.image.index.v1+json"
+)
+
+// ResolveImageDigest returns the manifest digest of the tag of the image by asking its docker registry.
+// Images without a registry host such as jenkinsxio/nexus are looked up on Docker Hub and insecure registries are
+// tried over http. Registries which require a bearer token are supported as long as they issue anonymous tokens
+func ResolveImageDigest(client *http.Client, image string, tag string) (string, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	registry, repository := SplitImageRegistry(image)
+	if registry == "docker.io" || registry == "index.docker.io" {
+		registry = dockerHubRegistry
+	}
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", registry, repository, tag)
+
+	resp, err := headManifest(client, manifestURL, "")
+	if err != nil {
+		// the registry inside the cluster is often insecure
+		manifestURL = "http" + strings.TrimPrefix(manifestURL, "https")
+		resp, err = headManifest(client, manifestURL, "")
+		if err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := anonymousToken(client, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to authenticate with registry %s", registry)
+		}
+		resp, err = headManifest(client, manifestURL, token)
+		if err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry %s returned status %s for image %s:%s", registry, resp.Status, repository, tag)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry %s did not return the digest of image %s:%s", registry, repository, tag)
+	}
+	return digest, nil
+}
+
+// SplitImageRegistry splits an image name such as docker.io/jenkinsxio/nexus into its registry host and repository.
+// The registry defaults to docker.io and official Docker Hub images are in the library repository
+func SplitImageRegistry(image string) (string, string) {
+	registry := "docker.io"
+	repository := image
+	paths := strings.SplitN(image, "/", 2)
+	if len(paths) == 2 && (strings.ContainsAny(paths[0], ".:") || paths[0] == "localhost") {
+		registry = paths[0]
+		repository = paths[1]
+	}
+	if registry == "docker.io" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return registry, repository
+}
+
+func headManifest(client *http.Client, manifestURL string, token string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", manifestMediaTypes)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get manifest %s", manifestURL)
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// anonymousToken requests a token from the realm of a Bearer WWW-Authenticate challenge
+func anonymousToken(client *http.Client, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("unsupported authentication challenge %s", challenge)
+	}
+	params := map[string]string{}
+	for _, param := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		values := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(values) == 2 {
+			params[values[0]] = strings.Trim(values[1], `"`)
+		}
+	}
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("no realm in authentication challenge %s", challenge)
+	}
+	query := url.Values{}
+	for _, name := range []string{"service", "scope"} {
+		if params[name] != "" {
+			query.Set(name, params[name])
+		}
+	}
+	resp, err := client.Get(realm + "?" + query.Encode())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request to %s returned status %s", realm, resp.Status)
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse the token from %s", realm)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
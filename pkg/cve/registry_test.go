@@ -0,0 +1,58 @@
+package cve_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveImageDigest(t *testing.T) {
+	t.Parallel()
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "repository:myorg/myapp:pull", r.URL.Query().Get("scope"))
+		fmt.Fprint(w, `{"token": "anonymous"}`)
+	})
+	mux.HandleFunc("/v2/myorg/myapp/manifests/1.2.3", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+		assert.Contains(t, r.Header.Get("Accept"), "application/vnd.docker.distribution.manifest.v2+json")
+		if r.Header.Get("Authorization") != "Bearer anonymous" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:myorg/myapp:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", clairManifest)
+	})
+	server = httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	image := strings.TrimPrefix(server.URL, "https://") + "/myorg/myapp"
+	digest, err := cve.ResolveImageDigest(server.Client(), image, "1.2.3")
+	require.NoError(t, err)
+	assert.Equal(t, clairManifest, digest)
+
+	_, err = cve.ResolveImageDigest(server.Client(), image, "0.0.1")
+	assert.Error(t, err)
+}
+
+func TestSplitImageRegistry(t *testing.T) {
+	t.Parallel()
+	for image, expected := range map[string][]string{
+		"jenkinsxio/nexus":                       {"docker.io", "jenkinsxio/nexus"},
+		"nginx":                                  {"docker.io", "library/nginx"},
+		"docker.io/jenkinsxio/nexus":             {"docker.io", "jenkinsxio/nexus"},
+		"10.0.0.1:5000/myorg/myapp":              {"10.0.0.1:5000", "myorg/myapp"},
+		"localhost/myapp":                        {"localhost", "myapp"},
+		"gcr.io/jenkinsxio/builder-maven/latest": {"gcr.io", "jenkinsxio/builder-maven/latest"},
+	} {
+		registry, repository := cve.SplitImageRegistry(image)
+		assert.Equal(t, expected, []string{registry, repository}, "image %s", image)
+	}
+}
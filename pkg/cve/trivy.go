@@ -0,0 +1,168 @@
+package cve
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// TrivyReport is a Trivy JSON report. Older versions of Trivy only output the results
+type TrivyReport struct {
+	SchemaVersion int
+	ArtifactName  string
+	Results       []TrivyResult
+}
+
+// TrivyResult are the vulnerabilities found in a target of the image such as the OS packages or a lock file
+type TrivyResult struct {
+	Target          string
+	Vulnerabilities []TrivyVulnerability
+}
+
+// TrivyVulnerability is a vulnerability found by Trivy
+type TrivyVulnerability struct {
+	VulnerabilityID  string
+	PkgName          string
+	InstalledVersion string
+	FixedVersion     string
+	Severity         string
+	PrimaryURL       string
+	References       []string
+}
+
+// TrivyProvider implements CVEProvider interface by consuming the Trivy JSON reports stored at
+// <base URL>/<image name>/<version>.json which can either be a http(s) URL or a directory
+type TrivyProvider struct {
+	Client    *http.Client
+	BasicAuth string
+	BaseURL   string
+}
+
+// NewTrivyProvider creates a CVEProvider for the Trivy reports at the URL of the server
+func NewTrivyProvider(server *auth.AuthServer, user *auth.UserAuth) (CVEProvider, error) {
+	basicAuth := ""
+	if user != nil && user.Username != "" {
+		basicAuth = util.BasicAuth(user.Username, user.Password)
+	}
+	provider := TrivyProvider{
+		BaseURL:   strings.TrimSuffix(server.URL, "/"),
+		BasicAuth: basicAuth,
+		Client:    http.DefaultClient,
+	}
+	return &provider, nil
+}
+
+// GetImageVulnerabilities returns the vulnerabilities in the Trivy report of the image name and version of the query
+func (t TrivyProvider) GetImageVulnerabilities(query CVEQuery) ([]ImageVulnerabilities, error) {
+	answer := []ImageVulnerabilities{}
+	if query.ImageName == "" {
+		return answer, fmt.Errorf("the Trivy provider needs an image name to find vulnerabilities")
+	}
+	version := query.Version
+	if version == "" {
+		version = "latest"
+	}
+	data, err := t.loadReport(query.ImageName + "/" + version + ".json")
+	if err != nil {
+		return answer, errors.Wrapf(err, "error loading the Trivy report of image %s:%s", query.ImageName, version)
+	}
+	report, err := ParseTrivyReport(data)
+	if err != nil {
+		return answer, errors.Wrapf(err, "error parsing the Trivy report of image %s:%s", query.ImageName, version)
+	}
+	name := report.ArtifactName
+	if name == "" {
+		name = query.ImageName + ":" + version
+	}
+	answer = append(answer, ImageVulnerabilities{
+		Image:           name,
+		ImageID:         query.ImageDigest,
+		Vulnerabilities: report.ToVulnerabilities(),
+	})
+	return answer, nil
+}
+
+// ParseTrivyReport parses a Trivy JSON report supporting both the current and the older results only format
+func ParseTrivyReport(data []byte) (*TrivyReport, error) {
+	report := &TrivyReport{}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err := json.Unmarshal(data, &report.Results)
+		return report, err
+	}
+	err := json.Unmarshal(data, report)
+	return report, err
+}
+
+// ToVulnerabilities returns the vulnerabilities of all the results of the report
+func (r *TrivyReport) ToVulnerabilities() []Vulnerability {
+	answer := []Vulnerability{}
+	found := map[string]bool{}
+	for _, result := range r.Results {
+		for _, v := range result.Vulnerabilities {
+			pkg := v.PkgName
+			if v.InstalledVersion != "" {
+				pkg = pkg + "-" + v.InstalledVersion
+			}
+			key := v.VulnerabilityID + "/" + pkg
+			if found[key] {
+				continue
+			}
+			found[key] = true
+			url := v.PrimaryURL
+			if url == "" && len(v.References) > 0 {
+				url = v.References[0]
+			}
+			answer = append(answer, Vulnerability{
+				Vuln:     v.VulnerabilityID,
+				Severity: NormalizeSeverity(v.Severity),
+				Package:  pkg,
+				Fix:      v.FixedVersion,
+				URL:      url,
+			})
+		}
+	}
+	SortVulnerabilities(answer)
+	return answer
+}
+
+func (t TrivyProvider) loadReport(subPath string) ([]byte, error) {
+	if !strings.HasPrefix(t.BaseURL, "http://") && !strings.HasPrefix(t.BaseURL, "https://") {
+		fileName := filepath.Join(strings.TrimPrefix(t.BaseURL, "file://"), filepath.FromSlash(subPath))
+		exists, err := util.FileExists(fileName)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrImageNotFound
+		}
+		return ioutil.ReadFile(fileName)
+	}
+
+	req, err := http.NewRequest("GET", t.BaseURL+"/"+subPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	if t.BasicAuth != "" {
+		req.Header.Add("Authorization", "Basic "+t.BasicAuth)
+	}
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrImageNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error response getting Trivy report: %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
@@ -0,0 +1,85 @@
+package cve_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrivyGetImageVulnerabilitiesFromDirectory(t *testing.T) {
+	t.Parallel()
+	provider, err := cve.NewCVEProvider(&auth.AuthServer{
+		URL:  "test_data/trivy",
+		Kind: cve.ProviderKindTrivy,
+	}, &auth.UserAuth{})
+	require.NoError(t, err)
+	require.IsType(t, &cve.TrivyProvider{}, provider)
+
+	images, err := provider.GetImageVulnerabilities(cve.CVEQuery{
+		ImageName: "jenkinsxio/nexus",
+		Version:   "0.0.6",
+	})
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, "jenkinsxio/nexus:0.0.6", images[0].Image)
+
+	vulnerabilities := images[0].Vulnerabilities
+	require.Len(t, vulnerabilities, 3)
+	assert.Equal(t, cve.Vulnerability{
+		Vuln:     "CVE-2018-7489",
+		Severity: "Critical",
+		Package:  "com.fasterxml.jackson.core:jackson-databind-2.8.9",
+		Fix:      "2.8.11.1",
+		URL:      "https://avd.aquasec.com/nvd/cve-2018-7489",
+	}, vulnerabilities[0])
+	assert.Equal(t, "High", vulnerabilities[1].Severity)
+	assert.Equal(t, "https://access.redhat.com/errata/RHSA-2018:0102", vulnerabilities[1].URL)
+	assert.Equal(t, "Medium", vulnerabilities[2].Severity)
+
+	// older versions of Trivy only output the results
+	images, err = provider.GetImageVulnerabilities(cve.CVEQuery{
+		ImageName: "jenkinsxio/nexus",
+		Version:   "0.0.5",
+	})
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, "jenkinsxio/nexus:0.0.5", images[0].Image)
+	require.Len(t, images[0].Vulnerabilities, 1)
+	assert.Equal(t, "CVE-2018-0805", images[0].Vulnerabilities[0].Vuln)
+
+	_, err = provider.GetImageVulnerabilities(cve.CVEQuery{
+		ImageName: "jenkinsxio/nexus",
+		Version:   "0.0.4",
+	})
+	assert.True(t, cve.IsImageNotFound(err))
+}
+
+func TestTrivyGetImageVulnerabilitiesFromURL(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.FileServer(http.Dir("test_data/trivy")))
+	defer server.Close()
+
+	provider, err := cve.NewTrivyProvider(&auth.AuthServer{
+		URL:  server.URL + "/",
+		Kind: cve.ProviderKindTrivy,
+	}, nil)
+	require.NoError(t, err)
+
+	images, err := provider.GetImageVulnerabilities(cve.CVEQuery{
+		ImageName: "jenkinsxio/nexus",
+		Version:   "0.0.6",
+	})
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Len(t, images[0].Vulnerabilities, 3)
+
+	_, err = provider.GetImageVulnerabilities(cve.CVEQuery{
+		ImageName: "jenkinsxio/nexus",
+	})
+	assert.True(t, cve.IsImageNotFound(err))
+}
@@ -0,0 +1,1058 @@
+package gits
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+)
+
+const (
+	azureAPIVersion   = "5.0"
+	azureStatusGenre  = "jenkins-x"
+	azureWorkItemType = "Issue"
+	azureMaxWorkItems = 200
+)
+
+// AzureDevOpsProvider implements GitProvider interface for Azure DevOps Repos.
+//
+// The server URL is the URL of the Azure DevOps organisation (e.g. https://dev.azure.com/myorg)
+// and the Azure DevOps projects are mapped onto git organisations. Work items are mapped onto issues.
+type AzureDevOpsProvider struct {
+	Client    *http.Client
+	BasicAuth string
+	Username  string
+
+	Server auth.AuthServer
+	User   auth.UserAuth
+	Git    Gitter
+}
+
+func NewAzureDevOpsProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
+	provider := AzureDevOpsProvider{
+		Client: http.DefaultClient,
+		// Azure DevOps accepts a personal access token as the password of basic authentication
+		BasicAuth: util.BasicAuth(user.Username, user.ApiToken),
+		Username:  user.Username,
+		Server:    *server,
+		User:      *user,
+		Git:       git,
+	}
+	return &provider, nil
+}
+
+type azureProject struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+type azureProjectList struct {
+	Count int            `json:"count"`
+	Value []azureProject `json:"value"`
+}
+
+type azureRepository struct {
+	ID        string        `json:"id,omitempty"`
+	Name      string        `json:"name,omitempty"`
+	URL       string        `json:"url,omitempty"`
+	RemoteURL string        `json:"remoteUrl,omitempty"`
+	SSHURL    string        `json:"sshUrl,omitempty"`
+	WebURL    string        `json:"webUrl,omitempty"`
+	Project   *azureProject `json:"project,omitempty"`
+}
+
+type azureRepositoryList struct {
+	Count int               `json:"count"`
+	Value []azureRepository `json:"value"`
+}
+
+type azureIdentity struct {
+	ID          string `json:"id,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	UniqueName  string `json:"uniqueName,omitempty"`
+	URL         string `json:"url,omitempty"`
+	ImageURL    string `json:"imageUrl,omitempty"`
+}
+
+type azureCommitRef struct {
+	CommitID string `json:"commitId,omitempty"`
+}
+
+type azureCompletionOptions struct {
+	MergeCommitMessage string `json:"mergeCommitMessage,omitempty"`
+	DeleteSourceBranch bool   `json:"deleteSourceBranch"`
+}
+
+type azurePullRequest struct {
+	PullRequestID         int                     `json:"pullRequestId,omitempty"`
+	Repository            *azureRepository        `json:"repository,omitempty"`
+	Status                string                  `json:"status,omitempty"`
+	CreatedBy             *azureIdentity          `json:"createdBy,omitempty"`
+	CreationDate          string                  `json:"creationDate,omitempty"`
+	ClosedDate            string                  `json:"closedDate,omitempty"`
+	Title                 string                  `json:"title,omitempty"`
+	Description           string                  `json:"description,omitempty"`
+	SourceRefName         string                  `json:"sourceRefName,omitempty"`
+	TargetRefName         string                  `json:"targetRefName,omitempty"`
+	MergeStatus           string                  `json:"mergeStatus,omitempty"`
+	LastMergeSourceCommit *azureCommitRef         `json:"lastMergeSourceCommit,omitempty"`
+	LastMergeCommit       *azureCommitRef         `json:"lastMergeCommit,omitempty"`
+	CompletionOptions     *azureCompletionOptions `json:"completionOptions,omitempty"`
+	URL                   string                  `json:"url,omitempty"`
+}
+
+type azureGitUserDate struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	Date  string `json:"date,omitempty"`
+}
+
+type azureCommit struct {
+	CommitID  string           `json:"commitId,omitempty"`
+	Comment   string           `json:"comment,omitempty"`
+	Author    azureGitUserDate `json:"author,omitempty"`
+	Committer azureGitUserDate `json:"committer,omitempty"`
+	URL       string           `json:"url,omitempty"`
+	RemoteURL string           `json:"remoteUrl,omitempty"`
+}
+
+type azureCommitList struct {
+	Count int           `json:"count"`
+	Value []azureCommit `json:"value"`
+}
+
+type azureStatusContext struct {
+	Name  string `json:"name,omitempty"`
+	Genre string `json:"genre,omitempty"`
+}
+
+type azureStatus struct {
+	ID           int                `json:"id,omitempty"`
+	State        string             `json:"state,omitempty"`
+	Description  string             `json:"description,omitempty"`
+	Context      azureStatusContext `json:"context,omitempty"`
+	TargetURL    string             `json:"targetUrl,omitempty"`
+	CreationDate string             `json:"creationDate,omitempty"`
+	URL          string             `json:"url,omitempty"`
+}
+
+type azureStatusList struct {
+	Count int           `json:"count"`
+	Value []azureStatus `json:"value"`
+}
+
+type azureComment struct {
+	ParentCommentID int    `json:"parentCommentId"`
+	Content         string `json:"content"`
+	CommentType     int    `json:"commentType"`
+}
+
+type azureCommentThread struct {
+	Comments []azureComment `json:"comments"`
+	Status   int            `json:"status"`
+}
+
+type azureSubscription struct {
+	ID               string            `json:"id,omitempty"`
+	PublisherID      string            `json:"publisherId"`
+	EventType        string            `json:"eventType"`
+	ResourceVersion  string            `json:"resourceVersion"`
+	ConsumerID       string            `json:"consumerId"`
+	ConsumerActionID string            `json:"consumerActionId"`
+	PublisherInputs  map[string]string `json:"publisherInputs"`
+	ConsumerInputs   map[string]string `json:"consumerInputs"`
+}
+
+type azureWorkItem struct {
+	ID     int                    `json:"id"`
+	Rev    int                    `json:"rev,omitempty"`
+	Fields map[string]interface{} `json:"fields,omitempty"`
+	URL    string                 `json:"url,omitempty"`
+}
+
+type azureWorkItemList struct {
+	Count int             `json:"count"`
+	Value []azureWorkItem `json:"value"`
+}
+
+type azureWorkItemRef struct {
+	ID  int    `json:"id"`
+	URL string `json:"url,omitempty"`
+}
+
+type azureWiqlResult struct {
+	WorkItems []azureWorkItemRef `json:"workItems"`
+}
+
+type azurePatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+type azureIdentityList struct {
+	Count int `json:"count"`
+	Value []struct {
+		ID                  string `json:"id"`
+		ProviderDisplayName string `json:"providerDisplayName"`
+		Properties          map[string]struct {
+			Value string `json:"$value"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+// azureError is the error body returned by the Azure DevOps REST API
+type azureError struct {
+	Message string `json:"message"`
+}
+
+// do invokes the Azure DevOps REST API for the path relative to the organisation URL
+// and unmarshals the JSON response into the given result if it is not nil
+func (p *AzureDevOpsProvider) do(method string, path string, params url.Values, body interface{}, result interface{}) (*http.Response, error) {
+	return p.doWithContentType(method, path, params, "application/json", body, result)
+}
+
+func (p *AzureDevOpsProvider) doWithContentType(method string, path string, params url.Values, contentType string, body interface{}, result interface{}) (*http.Response, error) {
+	u := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		u = util.UrlJoin(p.Server.URL, path)
+	}
+	if params == nil {
+		params = url.Values{}
+	}
+	if params.Get("api-version") == "" {
+		params.Set("api-version", azureAPIVersion)
+	}
+	u += "?" + params.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Basic "+p.BasicAuth)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		azErr := azureError{}
+		if json.Unmarshal(data, &azErr) == nil && azErr.Message != "" {
+			return resp, fmt.Errorf("%s %s failed with status %s: %s", method, u, resp.Status, azErr.Message)
+		}
+		return resp, fmt.Errorf("%s %s failed with status %s", method, u, resp.Status)
+	}
+	if result != nil && len(data) > 0 {
+		err = json.Unmarshal(data, result)
+		if err != nil {
+			return resp, fmt.Errorf("failed to unmarshal the response of %s %s: %s", method, u, err)
+		}
+	}
+	return resp, nil
+}
+
+func azurePath(segments ...string) string {
+	escaped := []string{}
+	for _, s := range segments {
+		escaped = append(escaped, url.PathEscape(s))
+	}
+	return strings.Join(escaped, "/")
+}
+
+func azureRepositoryPath(org string, name string, paths ...string) string {
+	return azurePath(org, "_apis", "git", "repositories", name) + "/" + strings.Join(paths, "/")
+}
+
+// azureProjectName returns the project of the repository which Azure DevOps uses in its REST paths
+func azureProjectName(info *GitRepositoryInfo) string {
+	if info.Project != "" {
+		return info.Project
+	}
+	return info.Organisation
+}
+
+func azureBranchRef(branch string) string {
+	if strings.HasPrefix(branch, "refs/") {
+		return branch
+	}
+	return "refs/heads/" + branch
+}
+
+func parseAzureTime(text string) *time.Time {
+	if text == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil || t.IsZero() || t.Year() <= 1 {
+		return nil
+	}
+	return &t
+}
+
+func (p *AzureDevOpsProvider) toGitRepository(repo *azureRepository) *GitRepository {
+	return &GitRepository{
+		Name:     repo.Name,
+		HTMLURL:  repo.WebURL,
+		CloneURL: repo.RemoteURL,
+		SSHURL:   repo.SSHURL,
+	}
+}
+
+func (p *AzureDevOpsProvider) getProject(org string) (*azureProject, error) {
+	project := &azureProject{}
+	_, err := p.do("GET", azurePath("_apis", "projects", org), nil, nil, project)
+	return project, err
+}
+
+func (p *AzureDevOpsProvider) getRepository(org string, name string) (*azureRepository, *http.Response, error) {
+	repo := &azureRepository{}
+	resp, err := p.do("GET", azurePath(org, "_apis", "git", "repositories", name), nil, nil, repo)
+	return repo, resp, err
+}
+
+// ListOrganisations returns the projects of the Azure DevOps organisation
+func (p *AzureDevOpsProvider) ListOrganisations() ([]GitOrganisation, error) {
+	answer := []GitOrganisation{}
+	projects := &azureProjectList{}
+	_, err := p.do("GET", "_apis/projects", nil, nil, projects)
+	if err != nil {
+		return answer, err
+	}
+	for _, project := range projects.Value {
+		answer = append(answer, GitOrganisation{Login: project.Name})
+	}
+	return answer, nil
+}
+
+func (p *AzureDevOpsProvider) ListRepositories(org string) ([]*GitRepository, error) {
+	answer := []*GitRepository{}
+	repos := &azureRepositoryList{}
+	path := "_apis/git/repositories"
+	if org != "" {
+		path = azurePath(org, "_apis", "git", "repositories")
+	}
+	_, err := p.do("GET", path, nil, nil, repos)
+	if err != nil {
+		return answer, err
+	}
+	for i := range repos.Value {
+		answer = append(answer, p.toGitRepository(&repos.Value[i]))
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+	return answer, nil
+}
+
+func (p *AzureDevOpsProvider) CreateRepository(org string, name string, private bool) (*GitRepository, error) {
+	project, err := p.getProject(org)
+	if err != nil {
+		return nil, err
+	}
+	if !private {
+		log.Warnf("Azure DevOps repositories inherit the visibility of the project %s\n", org)
+	}
+	input := &azureRepository{
+		Name: name,
+		Project: &azureProject{
+			ID: project.ID,
+		},
+	}
+	repo := &azureRepository{}
+	_, err = p.do("POST", azurePath(org, "_apis", "git", "repositories"), nil, input, repo)
+	if err != nil {
+		return nil, err
+	}
+	return p.toGitRepository(repo), nil
+}
+
+func (p *AzureDevOpsProvider) GetRepository(org string, name string) (*GitRepository, error) {
+	repo, _, err := p.getRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	return p.toGitRepository(repo), nil
+}
+
+func (p *AzureDevOpsProvider) DeleteRepository(org string, name string) error {
+	repo, _, err := p.getRepository(org, name)
+	if err != nil {
+		return err
+	}
+	_, err = p.do("DELETE", azurePath(org, "_apis", "git", "repositories", repo.ID), nil, nil, nil)
+	return err
+}
+
+func (p *AzureDevOpsProvider) ForkRepository(originalOrg string, name string, destinationOrg string) (*GitRepository, error) {
+	return nil, fmt.Errorf("Forking of repositories is not supported for Azure DevOps")
+}
+
+func (p *AzureDevOpsProvider) RenameRepository(org string, name string, newName string) (*GitRepository, error) {
+	repo, _, err := p.getRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	input := &azureRepository{
+		Name: newName,
+	}
+	renamed := &azureRepository{}
+	_, err = p.do("PATCH", azurePath(org, "_apis", "git", "repositories", repo.ID), nil, input, renamed)
+	if err != nil {
+		return nil, err
+	}
+	return p.toGitRepository(renamed), nil
+}
+
+func (p *AzureDevOpsProvider) ValidateRepositoryName(org string, name string) error {
+	_, resp, err := p.getRepository(org, name)
+	if resp != nil && resp.StatusCode == 404 {
+		return nil
+	}
+	if err == nil {
+		return fmt.Errorf("Repository %s already exists", p.Git.RepoName(org, name))
+	}
+	return err
+}
+
+func (p *AzureDevOpsProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitPullRequest, error) {
+	owner := azureProjectName(data.GitRepositoryInfo)
+	repo := data.GitRepositoryInfo.Name
+	input := &azurePullRequest{
+		Title:         data.Title,
+		Description:   data.Body,
+		SourceRefName: azureBranchRef(data.Head),
+		TargetRefName: azureBranchRef(data.Base),
+	}
+	pr := &azurePullRequest{}
+	_, err := p.do("POST", azureRepositoryPath(owner, repo, "pullrequests"), nil, input, pr)
+	if err != nil {
+		return nil, err
+	}
+	return p.toPullRequest(owner, repo, pr), nil
+}
+
+func (p *AzureDevOpsProvider) getPullRequest(owner string, repo string, number int) (*azurePullRequest, error) {
+	pr := &azurePullRequest{}
+	_, err := p.do("GET", azureRepositoryPath(owner, repo, "pullrequests", strconv.Itoa(number)), nil, nil, pr)
+	return pr, err
+}
+
+func (p *AzureDevOpsProvider) toPullRequest(owner string, repo string, pr *azurePullRequest) *GitPullRequest {
+	number := pr.PullRequestID
+	merged := pr.Status == "completed"
+	mergeable := pr.MergeStatus != "conflicts" && pr.MergeStatus != "rejectedByPolicy" && pr.MergeStatus != "failure"
+	state := "open"
+	if pr.Status != "active" {
+		state = "closed"
+	}
+	headRef := strings.TrimPrefix(pr.SourceRefName, "refs/heads/")
+
+	answer := &GitPullRequest{
+		URL:       util.UrlJoin(p.Server.URL, azurePath(owner, "_git", repo, "pullrequest", strconv.Itoa(number))),
+		Owner:     owner,
+		Repo:      repo,
+		Number:    &number,
+		Mergeable: &mergeable,
+		Merged:    &merged,
+		HeadRef:   &headRef,
+		State:     &state,
+		Title:     pr.Title,
+		Body:      pr.Description,
+		ClosedAt:  parseAzureTime(pr.ClosedDate),
+	}
+	if pr.CreatedBy != nil {
+		answer.Author = &GitUser{
+			Login:     pr.CreatedBy.UniqueName,
+			Name:      pr.CreatedBy.DisplayName,
+			URL:       pr.CreatedBy.URL,
+			AvatarURL: pr.CreatedBy.ImageURL,
+		}
+	}
+	if pr.LastMergeSourceCommit != nil {
+		answer.LastCommitSha = pr.LastMergeSourceCommit.CommitID
+	}
+	if merged {
+		answer.MergedAt = answer.ClosedAt
+		if pr.LastMergeCommit != nil {
+			sha := pr.LastMergeCommit.CommitID
+			answer.MergeCommitSHA = &sha
+		}
+	}
+	return answer
+}
+
+func (p *AzureDevOpsProvider) UpdatePullRequestStatus(pr *GitPullRequest) error {
+	if pr.Number == nil {
+		return fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	result, err := p.getPullRequest(pr.Owner, pr.Repo, *pr.Number)
+	if err != nil {
+		return err
+	}
+	updated := p.toPullRequest(pr.Owner, pr.Repo, result)
+	pr.URL = updated.URL
+	pr.Author = updated.Author
+	pr.Mergeable = updated.Mergeable
+	pr.Merged = updated.Merged
+	pr.HeadRef = updated.HeadRef
+	pr.State = updated.State
+	pr.Title = updated.Title
+	pr.Body = updated.Body
+	pr.LastCommitSha = updated.LastCommitSha
+	pr.MergeCommitSHA = updated.MergeCommitSHA
+	pr.MergedAt = updated.MergedAt
+	pr.ClosedAt = updated.ClosedAt
+	return nil
+}
+
+func (p *AzureDevOpsProvider) GetPullRequest(owner string, repo *GitRepositoryInfo, number int) (*GitPullRequest, error) {
+	if repo.Project != "" {
+		owner = repo.Project
+	}
+	pr, err := p.getPullRequest(owner, repo.Name, number)
+	if err != nil {
+		return nil, err
+	}
+	return p.toPullRequest(owner, repo.Name, pr), nil
+}
+
+func (p *AzureDevOpsProvider) GetPullRequestCommits(owner string, repo *GitRepositoryInfo, number int) ([]*GitCommit, error) {
+	answer := []*GitCommit{}
+	commits := &azureCommitList{}
+	if repo.Project != "" {
+		owner = repo.Project
+	}
+	_, err := p.do("GET", azureRepositoryPath(owner, repo.Name, "pullrequests", strconv.Itoa(number), "commits"), nil, nil, commits)
+	if err != nil {
+		return answer, err
+	}
+	for _, commit := range commits.Value {
+		answer = append(answer, &GitCommit{
+			SHA:     commit.CommitID,
+			Message: commit.Comment,
+			URL:     commit.RemoteURL,
+			Author: &GitUser{
+				Name:  commit.Author.Name,
+				Email: commit.Author.Email,
+			},
+			Committer: &GitUser{
+				Name:  commit.Committer.Name,
+				Email: commit.Committer.Email,
+			},
+		})
+	}
+	return answer, nil
+}
+
+// fromAzureState converts an Azure DevOps status state into a GitRepoStatus state
+func fromAzureState(state string) string {
+	switch state {
+	case "succeeded":
+		return "success"
+	case "failed":
+		return "failure"
+	case "error":
+		return "error"
+	default:
+		return "pending"
+	}
+}
+
+// toAzureState converts a GitRepoStatus state into an Azure DevOps status state
+func toAzureState(state string) (string, error) {
+	switch state {
+	case "pending":
+		return "pending", nil
+	case "success":
+		return "succeeded", nil
+	case "failure":
+		return "failed", nil
+	case "error":
+		return "error", nil
+	default:
+		return "", fmt.Errorf("unsupported commit status state %s", state)
+	}
+}
+
+func (p *AzureDevOpsProvider) toGitRepoStatus(status *azureStatus) *GitRepoStatus {
+	return &GitRepoStatus{
+		ID:          strconv.Itoa(status.ID),
+		Context:     status.Context.Name,
+		URL:         status.URL,
+		State:       fromAzureState(status.State),
+		TargetURL:   status.TargetURL,
+		Description: status.Description,
+	}
+}
+
+// PullRequestLastCommitStatus returns the combined state of the latest status of each context on the last commit
+func (p *AzureDevOpsProvider) PullRequestLastCommitStatus(pr *GitPullRequest) (string, error) {
+	statuses, err := p.ListCommitStatus(pr.Owner, pr.Repo, pr.LastCommitSha)
+	if err != nil {
+		return "", err
+	}
+	if len(statuses) == 0 {
+		return "", fmt.Errorf("no commit statuses found for commit %s", pr.LastCommitSha)
+	}
+	states := map[string]bool{}
+	for _, status := range statuses {
+		states[status.State] = true
+	}
+	for _, state := range []string{"failure", "error", "pending"} {
+		if states[state] {
+			return state, nil
+		}
+	}
+	return "success", nil
+}
+
+// ListCommitStatus returns the latest status for each context of the commit
+func (p *AzureDevOpsProvider) ListCommitStatus(org string, repo string, sha string) ([]*GitRepoStatus, error) {
+	answer := []*GitRepoStatus{}
+	statuses := &azureStatusList{}
+	params := url.Values{}
+	params.Set("latestOnly", "true")
+	_, err := p.do("GET", azureRepositoryPath(org, repo, "commits", sha, "statuses"), params, nil, statuses)
+	if err != nil {
+		return answer, err
+	}
+	for i := range statuses.Value {
+		answer = append(answer, p.toGitRepoStatus(&statuses.Value[i]))
+	}
+	return answer, nil
+}
+
+func (p *AzureDevOpsProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
+	state, err := toAzureState(status.State)
+	if err != nil {
+		return &GitRepoStatus{}, err
+	}
+	input := &azureStatus{
+		State:       state,
+		Description: status.Description,
+		TargetURL:   status.TargetURL,
+		Context: azureStatusContext{
+			Name:  status.Context,
+			Genre: azureStatusGenre,
+		},
+	}
+	result := &azureStatus{}
+	_, err = p.do("POST", azureRepositoryPath(org, repo, "commits", sha, "statuses"), nil, input, result)
+	if err != nil {
+		return &GitRepoStatus{}, err
+	}
+	return p.toGitRepoStatus(result), nil
+}
+
+// MergePullRequest completes the pull request at its current source commit
+func (p *AzureDevOpsProvider) MergePullRequest(pr *GitPullRequest, message string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	current, err := p.getPullRequest(pr.Owner, pr.Repo, *pr.Number)
+	if err != nil {
+		return err
+	}
+	input := &azurePullRequest{
+		Status:                "completed",
+		LastMergeSourceCommit: current.LastMergeSourceCommit,
+		CompletionOptions: &azureCompletionOptions{
+			MergeCommitMessage: message,
+		},
+	}
+	_, err = p.do("PATCH", azureRepositoryPath(pr.Owner, pr.Repo, "pullrequests", strconv.Itoa(*pr.Number)), nil, input, nil)
+	return err
+}
+
+// CreateWebHook creates service hook subscriptions which post push and pull request events to the webhook URL
+func (p *AzureDevOpsProvider) CreateWebHook(data *GitWebHookArguments) error {
+	owner := data.Owner
+	if data.Repo != nil && data.Repo.Project != "" {
+		owner = data.Repo.Project
+	}
+	repo, _, err := p.getRepository(owner, data.Repo.Name)
+	if err != nil {
+		return err
+	}
+	projectID := ""
+	if repo.Project != nil {
+		projectID = repo.Project.ID
+	}
+	for _, eventType := range []string{"git.push", "git.pullrequest.created", "git.pullrequest.updated"} {
+		input := &azureSubscription{
+			PublisherID:      "tfs",
+			EventType:        eventType,
+			ResourceVersion:  "1.0",
+			ConsumerID:       "webHooks",
+			ConsumerActionID: "httpRequest",
+			PublisherInputs: map[string]string{
+				"projectId":  projectID,
+				"repository": repo.ID,
+			},
+			ConsumerInputs: map[string]string{
+				"url": data.URL,
+			},
+		}
+		log.Infof("Creating service hook for %s events in %s to %s\n", util.ColorInfo(eventType), util.ColorInfo(owner+"/"+repo.Name), util.ColorInfo(data.URL))
+		_, err = p.do("POST", "_apis/hooks/subscriptions", nil, input, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *AzureDevOpsProvider) IsGitHub() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) IsGitea() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) IsBitbucketCloud() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) IsBitbucketServer() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) IsGerrit() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) Kind() string {
+	return KindAzureDevOps
+}
+
+func azureFieldString(fields map[string]interface{}, name string) string {
+	if value, ok := fields[name].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// azureFieldUser converts an identity field of a work item which is either an identity object or a "Name <email>" string
+func azureFieldUser(fields map[string]interface{}, name string) *GitUser {
+	switch value := fields[name].(type) {
+	case map[string]interface{}:
+		user := &GitUser{}
+		user.Login, _ = value["uniqueName"].(string)
+		user.Name, _ = value["displayName"].(string)
+		user.AvatarURL, _ = value["imageUrl"].(string)
+		return user
+	case string:
+		user := &GitUser{Name: value}
+		idx := strings.Index(value, "<")
+		if idx > 0 && strings.HasSuffix(value, ">") {
+			user.Name = strings.TrimSpace(value[0:idx])
+			user.Email = value[idx+1 : len(value)-1]
+			user.Login = user.Email
+		}
+		return user
+	default:
+		return nil
+	}
+}
+
+func (p *AzureDevOpsProvider) fromWorkItem(org string, name string, item *azureWorkItem) *GitIssue {
+	number := item.ID
+	closedAt := parseAzureTime(azureFieldString(item.Fields, "Microsoft.VSTS.Common.ClosedDate"))
+	// work item states depend on the process of the project so use the closed date to find closed work items
+	state := "open"
+	if closedAt != nil {
+		state = "closed"
+	}
+	issue := &GitIssue{
+		URL:       p.IssueURL(org, name, number, false),
+		Owner:     org,
+		Repo:      name,
+		Number:    &number,
+		Key:       strconv.Itoa(number),
+		Title:     azureFieldString(item.Fields, "System.Title"),
+		Body:      azureFieldString(item.Fields, "System.Description"),
+		State:     &state,
+		CreatedAt: parseAzureTime(azureFieldString(item.Fields, "System.CreatedDate")),
+		UpdatedAt: parseAzureTime(azureFieldString(item.Fields, "System.ChangedDate")),
+		ClosedAt:  closedAt,
+		User:      azureFieldUser(item.Fields, "System.CreatedBy"),
+		ClosedBy:  azureFieldUser(item.Fields, "Microsoft.VSTS.Common.ClosedBy"),
+	}
+	for _, tag := range strings.Split(azureFieldString(item.Fields, "System.Tags"), ";") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			issue.Labels = append(issue.Labels, GitLabel{Name: tag})
+		}
+	}
+	if assignee := azureFieldUser(item.Fields, "System.AssignedTo"); assignee != nil {
+		issue.Assignees = append(issue.Assignees, *assignee)
+	}
+	return issue
+}
+
+func (p *AzureDevOpsProvider) GetIssue(org string, name string, number int) (*GitIssue, error) {
+	item := &azureWorkItem{}
+	_, err := p.do("GET", azurePath(org, "_apis", "wit", "workitems", strconv.Itoa(number)), nil, nil, item)
+	if err != nil {
+		return nil, err
+	}
+	return p.fromWorkItem(org, name, item), nil
+}
+
+func (p *AzureDevOpsProvider) IssueURL(org string, name string, number int, isPull bool) string {
+	if isPull {
+		return util.UrlJoin(p.Server.URL, azurePath(org, "_git", name, "pullrequest", strconv.Itoa(number)))
+	}
+	return util.UrlJoin(p.Server.URL, azurePath(org, "_workitems", "edit", strconv.Itoa(number)))
+}
+
+// queryWorkItems runs the WIQL query against the project and returns the matching work items
+func (p *AzureDevOpsProvider) queryWorkItems(org string, name string, query string) ([]*GitIssue, error) {
+	answer := []*GitIssue{}
+	result := &azureWiqlResult{}
+	input := map[string]string{
+		"query": query,
+	}
+	_, err := p.do("POST", azurePath(org, "_apis", "wit", "wiql"), nil, input, result)
+	if err != nil {
+		return answer, err
+	}
+	if len(result.WorkItems) == 0 {
+		return answer, nil
+	}
+	ids := []string{}
+	for i, ref := range result.WorkItems {
+		if i >= azureMaxWorkItems {
+			break
+		}
+		ids = append(ids, strconv.Itoa(ref.ID))
+	}
+	params := url.Values{}
+	params.Set("ids", strings.Join(ids, ","))
+	items := &azureWorkItemList{}
+	_, err = p.do("GET", azurePath(org, "_apis", "wit", "workitems"), params, nil, items)
+	if err != nil {
+		return answer, err
+	}
+	for i := range items.Value {
+		answer = append(answer, p.fromWorkItem(org, name, &items.Value[i]))
+	}
+	return answer, nil
+}
+
+func azureWiqlString(text string) string {
+	return "'" + strings.Replace(text, "'", "''", -1) + "'"
+}
+
+// SearchIssues returns the work items of the project whose title contains the query
+func (p *AzureDevOpsProvider) SearchIssues(org string, name string, query string) ([]*GitIssue, error) {
+	wiql := "SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = @project"
+	if query != "" {
+		wiql += " AND [System.Title] CONTAINS " + azureWiqlString(query)
+	}
+	wiql += " ORDER BY [System.ChangedDate] DESC"
+	return p.queryWorkItems(org, name, wiql)
+}
+
+func (p *AzureDevOpsProvider) SearchIssuesClosedSince(org string, name string, t time.Time) ([]*GitIssue, error) {
+	wiql := "SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = @project" +
+		" AND [Microsoft.VSTS.Common.ClosedDate] >= " + azureWiqlString(t.UTC().Format(time.RFC3339)) +
+		" ORDER BY [System.ChangedDate] DESC"
+	issues, err := p.queryWorkItems(org, name, wiql)
+	if err != nil {
+		return issues, err
+	}
+	return FilterIssuesClosedSince(issues, t), nil
+}
+
+// CreateIssue creates a work item of type Issue in the project
+func (p *AzureDevOpsProvider) CreateIssue(owner string, repo string, issue *GitIssue) (*GitIssue, error) {
+	operations := []azurePatchOperation{
+		{Op: "add", Path: "/fields/System.Title", Value: issue.Title},
+	}
+	if issue.Body != "" {
+		operations = append(operations, azurePatchOperation{Op: "add", Path: "/fields/System.Description", Value: issue.Body})
+	}
+	if len(issue.Labels) > 0 {
+		tags := []string{}
+		for _, label := range issue.Labels {
+			tags = append(tags, label.Name)
+		}
+		operations = append(operations, azurePatchOperation{Op: "add", Path: "/fields/System.Tags", Value: strings.Join(tags, "; ")})
+	}
+	item := &azureWorkItem{}
+	path := azurePath(owner, "_apis", "wit", "workitems") + "/$" + azureWorkItemType
+	_, err := p.doWithContentType("POST", path, nil, "application/json-patch+json", operations, item)
+	if err != nil {
+		return nil, err
+	}
+	return p.fromWorkItem(owner, repo, item), nil
+}
+
+func (p *AzureDevOpsProvider) HasIssues() bool {
+	return true
+}
+
+// AddPRComment adds the comment as a new thread on the pull request
+func (p *AzureDevOpsProvider) AddPRComment(pr *GitPullRequest, comment string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	input := &azureCommentThread{
+		Comments: []azureComment{
+			{
+				Content:     comment,
+				CommentType: 1,
+			},
+		},
+		Status: 1,
+	}
+	_, err := p.do("POST", azureRepositoryPath(pr.Owner, pr.Repo, "pullrequests", strconv.Itoa(*pr.Number), "threads"), nil, input, nil)
+	return err
+}
+
+func (p *AzureDevOpsProvider) CreateIssueComment(owner string, repo string, number int, comment string) error {
+	params := url.Values{}
+	params.Set("api-version", "5.0-preview.2")
+	input := map[string]string{
+		"text": comment,
+	}
+	_, err := p.do("POST", azurePath(owner, "_apis", "wit", "workitems", strconv.Itoa(number), "comments"), params, input, nil)
+	return err
+}
+
+func (p *AzureDevOpsProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *GitRelease) error {
+	log.Warn("Azure DevOps doesn't support releases")
+	return nil
+}
+
+func (p *AzureDevOpsProvider) ListReleases(org string, name string) ([]*GitRelease, error) {
+	answer := []*GitRelease{}
+	log.Warn("Azure DevOps doesn't support releases")
+	return answer, nil
+}
+
+// JenkinsWebHookPath uses the Generic Webhook Trigger plugin as service hooks post the Azure DevOps event payloads
+func (p *AzureDevOpsProvider) JenkinsWebHookPath(gitURL string, secret string) string {
+	return "/generic-webhook-trigger/invoke"
+}
+
+func AzureDevOpsAccessTokenURL(url string) string {
+	return util.UrlJoin(url, "/_usersSettings/tokens")
+}
+
+func (p *AzureDevOpsProvider) Label() string {
+	return p.Server.Label()
+}
+
+func (p *AzureDevOpsProvider) ServerURL() string {
+	return p.Server.URL
+}
+
+func (p *AzureDevOpsProvider) BranchArchiveURL(org string, name string, branch string) string {
+	params := url.Values{}
+	params.Set("path", "/")
+	params.Set("versionDescriptor.version", branch)
+	params.Set("$format", "zip")
+	params.Set("download", "true")
+	params.Set("api-version", azureAPIVersion)
+	return util.UrlJoin(p.Server.URL, azureRepositoryPath(org, name, "items")) + "?" + params.Encode()
+}
+
+func (p *AzureDevOpsProvider) CurrentUsername() string {
+	return p.Username
+}
+
+func (p *AzureDevOpsProvider) UserAuth() auth.UserAuth {
+	return p.User
+}
+
+// identityURL returns the URL of the identities API which is hosted on vssps.dev.azure.com for Azure DevOps Services
+func (p *AzureDevOpsProvider) identityURL() string {
+	u, err := url.Parse(p.Server.URL)
+	if err == nil && u.Host == "dev.azure.com" {
+		u.Host = "vssps.dev.azure.com"
+		return util.UrlJoin(u.String(), "_apis/identities")
+	}
+	return util.UrlJoin(p.Server.URL, "_apis/identities")
+}
+
+func (p *AzureDevOpsProvider) UserInfo(username string) *GitUser {
+	params := url.Values{}
+	params.Set("searchFilter", "General")
+	params.Set("filterValue", username)
+	identities := &azureIdentityList{}
+	_, err := p.do("GET", p.identityURL(), params, nil, identities)
+	if err != nil {
+		log.Error("Unable to fetch user info for " + username + " due to " + err.Error() + "\n")
+		return nil
+	}
+	if len(identities.Value) == 0 {
+		return nil
+	}
+	identity := identities.Value[0]
+	return &GitUser{
+		Login: username,
+		Name:  identity.ProviderDisplayName,
+		Email: identity.Properties["Mail"].Value,
+	}
+}
+
+func (p *AzureDevOpsProvider) AddCollaborator(user string, organisation string, repo string) error {
+	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Azure DevOps. Please add user: %v as a member of the project.\n", user)
+	return nil
+}
+
+func (p *AzureDevOpsProvider) ListInvitations() ([]*GitInvitation, error) {
+	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Azure DevOps.\n")
+	return []*GitInvitation{}, nil
+}
+
+func (p *AzureDevOpsProvider) AcceptInvitation(ID int64) error {
+	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Azure DevOps.\n")
+	return nil
+}
+
+// GetBranchProtection is not supported for Azure DevOps so no protection is returned
+func (p *AzureDevOpsProvider) GetBranchProtection(org string, repo string, branch string) (*BranchProtection, error) {
+	log.Warnf("Branch protection is not supported for Azure DevOps\n")
+	return nil, nil
+}
+
+// SetBranchProtection is not supported for Azure DevOps so the branch is left unprotected
+func (p *AzureDevOpsProvider) SetBranchProtection(org string, repo string, branch string, protection *BranchProtection) error {
+	log.Warnf("Branch protection is not supported for Azure DevOps\n")
+	return nil
+}
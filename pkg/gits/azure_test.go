@@ -0,0 +1,357 @@
+package gits_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/suite"
+)
+
+const azureCommitSHA = "b60280bc6e62e2f880f1b63c1e24987664d3bda3"
+
+type AzureDevOpsProviderTestSuite struct {
+	suite.Suite
+	mux      *http.ServeMux
+	server   *httptest.Server
+	provider *gits.AzureDevOpsProvider
+}
+
+var azureRouter = util.Router{
+	"/_apis/projects": util.MethodMap{
+		"GET": "projects.json",
+	},
+	"/_apis/projects/test-project": util.MethodMap{
+		"GET": "project.json",
+	},
+	"/test-project/_apis/git/repositories": util.MethodMap{
+		"GET":  "repos.json",
+		"POST": "repo.json",
+	},
+	"/test-project/_apis/git/repositories/test-repo": util.MethodMap{
+		"GET": "repo.json",
+	},
+	"/test-project/_apis/git/repositories/test-repo/pullrequests": util.MethodMap{
+		"POST": "pr.json",
+	},
+	"/test-project/_apis/git/repositories/test-repo/pullrequests/1": util.MethodMap{
+		"GET":   "pr.json",
+		"PATCH": "pr.completed.json",
+	},
+	"/test-project/_apis/git/repositories/test-repo/pullrequests/1/commits": util.MethodMap{
+		"GET": "commits.json",
+	},
+	"/test-project/_apis/git/repositories/test-repo/pullrequests/1/threads": util.MethodMap{
+		"POST": "thread.json",
+	},
+	"/test-project/_apis/git/repositories/test-repo/commits/" + azureCommitSHA + "/statuses": util.MethodMap{
+		"GET":  "statuses.json",
+		"POST": "status.json",
+	},
+	"/_apis/hooks/subscriptions": util.MethodMap{
+		"POST": "subscription.json",
+	},
+	"/test-project/_apis/wit/wiql": util.MethodMap{
+		"POST": "wiql.json",
+	},
+	"/test-project/_apis/wit/workitems": util.MethodMap{
+		"GET": "workitems.json",
+	},
+	"/test-project/_apis/wit/workitems/1": util.MethodMap{
+		"GET": "workitem.json",
+	},
+	"/test-project/_apis/wit/workitems/$Issue": util.MethodMap{
+		"POST": "workitem.json",
+	},
+	"/test-project/_apis/wit/workitems/1/comments": util.MethodMap{
+		"POST": "comment.json",
+	},
+	"/_apis/identities": util.MethodMap{
+		"GET": "identities.json",
+	},
+}
+
+func (suite *AzureDevOpsProviderTestSuite) SetupSuite() {
+	suite.mux = http.NewServeMux()
+
+	for path, methodMap := range azureRouter {
+		suite.mux.HandleFunc(path, util.GetMockAPIResponseFromFile("test_data/azure", methodMap))
+	}
+
+	suite.server = httptest.NewServer(suite.mux)
+	suite.Require().NotNil(suite.server)
+
+	as := auth.AuthServer{
+		URL:         suite.server.URL,
+		Name:        "Test Auth Server",
+		Kind:        gits.KindAzureDevOps,
+		CurrentUser: "test-user",
+	}
+	ua := auth.UserAuth{
+		Username: "test-user",
+		ApiToken: "0123456789abdef",
+	}
+
+	git := gits.NewGitCLI()
+	gp, err := gits.CreateProvider(&as, &ua, git)
+
+	suite.Require().NotNil(gp)
+	suite.Require().Nil(err)
+
+	var ok bool
+	suite.provider, ok = gp.(*gits.AzureDevOpsProvider)
+	suite.Require().True(ok)
+	suite.Require().NotNil(suite.provider)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestListOrganisations() {
+	orgs, err := suite.provider.ListOrganisations()
+
+	suite.Require().Nil(err)
+	suite.Require().Equal([]gits.GitOrganisation{{Login: "test-project"}, {Login: "other-project"}}, orgs)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestListRepositories() {
+	repos, err := suite.provider.ListRepositories("test-project")
+
+	suite.Require().Nil(err)
+	suite.Require().Len(repos, 2)
+	suite.Require().Equal("another-repo", repos[0].Name)
+	suite.Require().Equal("test-repo", repos[1].Name)
+	suite.Require().Equal("https://test-org@dev.azure.com/test-org/test-project/_git/test-repo", repos[1].CloneURL)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestGetRepository() {
+	repo, err := suite.provider.GetRepository("test-project", "test-repo")
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(repo)
+	suite.Require().Equal("test-repo", repo.Name)
+	suite.Require().Equal("https://dev.azure.com/test-org/test-project/_git/test-repo", repo.HTMLURL)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestCreateRepository() {
+	repo, err := suite.provider.CreateRepository("test-project", "test-repo", true)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(repo)
+	suite.Require().Equal("test-repo", repo.Name)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestValidateRepositoryName() {
+	err := suite.provider.ValidateRepositoryName("test-project", "test-repo")
+	suite.Require().NotNil(err)
+
+	err = suite.provider.ValidateRepositoryName("test-project", "foo-repo")
+	suite.Require().Nil(err)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestCreatePullRequest() {
+	args := &gits.GitPullRequestArguments{
+		GitRepositoryInfo: &gits.GitRepositoryInfo{Organisation: "test-project", Name: "test-repo"},
+		Head:              "feature-branch",
+		Base:              "master",
+		Title:             "Add a feature",
+		Body:              "Some more details about the feature",
+	}
+
+	pr, err := suite.provider.CreatePullRequest(args)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(pr)
+	suite.Require().Equal(1, *pr.Number)
+	suite.Require().Equal("open", *pr.State)
+	suite.Require().Equal("feature-branch", *pr.HeadRef)
+	suite.Require().Equal(azureCommitSHA, pr.LastCommitSha)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestGetPullRequest() {
+	pr, err := suite.provider.GetPullRequest("test-project", &gits.GitRepositoryInfo{Name: "test-repo"}, 1)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(pr)
+	suite.Require().Equal(1, *pr.Number)
+	suite.Require().Equal("Add a feature", pr.Title)
+	suite.Require().Equal("Some more details about the feature", pr.Body)
+	suite.Require().Equal("test-user@example.com", pr.Author.Login)
+	suite.Require().True(*pr.Mergeable)
+	suite.Require().False(*pr.Merged)
+	suite.Require().False(pr.IsClosed())
+	suite.Require().Equal(suite.server.URL+"/test-project/_git/test-repo/pullrequest/1", pr.URL)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestUpdatePullRequestStatus() {
+	number := 1
+	pr := &gits.GitPullRequest{
+		Owner:  "test-project",
+		Repo:   "test-repo",
+		Number: &number,
+	}
+	err := suite.provider.UpdatePullRequestStatus(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("open", *pr.State)
+	suite.Require().Equal(azureCommitSHA, pr.LastCommitSha)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestGetPullRequestCommits() {
+	commits, err := suite.provider.GetPullRequestCommits("test-project", &gits.GitRepositoryInfo{Name: "test-repo"}, 1)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(commits, 1)
+	suite.Require().Equal(azureCommitSHA, commits[0].SHA)
+	suite.Require().Equal("test-user@example.com", commits[0].Author.Email)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestPullRequestLastCommitStatus() {
+	pr := &gits.GitPullRequest{
+		Owner:         "test-project",
+		Repo:          "test-repo",
+		LastCommitSha: azureCommitSHA,
+	}
+	status, err := suite.provider.PullRequestLastCommitStatus(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("failure", status)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestListCommitStatus() {
+	statuses, err := suite.provider.ListCommitStatus("test-project", "test-repo", azureCommitSHA)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(statuses, 2)
+	suite.Require().Equal("continuous-integration/jenkins/pr-head", statuses[0].Context)
+	suite.Require().Equal("failure", statuses[0].State)
+	suite.Require().Equal("compliance-check", statuses[1].Context)
+	suite.Require().Equal("success", statuses[1].State)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestUpdateCommitStatus() {
+	status := &gits.GitRepoStatus{
+		Context:     "compliance-check",
+		State:       "success",
+		TargetURL:   "https://jenkins.example.com/job/test-repo/1",
+		Description: "Compliance checks passed",
+	}
+	result, err := suite.provider.UpdateCommitStatus("test-project", "test-repo", azureCommitSHA, status)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("3", result.ID)
+	suite.Require().Equal("compliance-check", result.Context)
+	suite.Require().Equal("success", result.State)
+
+	status.State = "unknown"
+	_, err = suite.provider.UpdateCommitStatus("test-project", "test-repo", azureCommitSHA, status)
+	suite.Require().NotNil(err)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestMergePullRequest() {
+	number := 1
+	pr := &gits.GitPullRequest{
+		Owner:  "test-project",
+		Repo:   "test-repo",
+		Number: &number,
+	}
+	err := suite.provider.MergePullRequest(pr, "Merging from unit tests")
+
+	suite.Require().Nil(err)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestAddPRComment() {
+	number := 1
+	pr := &gits.GitPullRequest{
+		Owner:  "test-project",
+		Repo:   "test-repo",
+		Number: &number,
+	}
+	err := suite.provider.AddPRComment(pr, "a comment")
+
+	suite.Require().Nil(err)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestCreateWebHook() {
+	data := &gits.GitWebHookArguments{
+		Owner: "test-project",
+		Repo:  &gits.GitRepositoryInfo{Name: "test-repo"},
+		URL:   "https://my-jenkins.example.com/generic-webhook-trigger/invoke",
+	}
+	err := suite.provider.CreateWebHook(data)
+
+	suite.Require().Nil(err)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestGetIssue() {
+	issue, err := suite.provider.GetIssue("test-project", "test-repo", 1)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(issue)
+	suite.Require().Equal(1, *issue.Number)
+	suite.Require().Equal("Something is broken", issue.Title)
+	suite.Require().Equal("It does not work", issue.Body)
+	suite.Require().Equal("closed", *issue.State)
+	suite.Require().Equal("test-user@example.com", issue.User.Login)
+	suite.Require().Equal([]gits.GitLabel{{Name: "bug"}, {Name: "help wanted"}}, issue.Labels)
+	suite.Require().NotNil(issue.ClosedAt)
+	suite.Require().Equal(suite.server.URL+"/test-project/_workitems/edit/1", issue.URL)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestSearchIssues() {
+	issues, err := suite.provider.SearchIssues("test-project", "test-repo", "")
+
+	suite.Require().Nil(err)
+	suite.Require().Len(issues, 2)
+	suite.Require().Equal("Add a feature", issues[1].Title)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestSearchIssuesClosedSince() {
+	t := time.Date(2018, time.November, 20, 0, 0, 0, 0, time.UTC)
+	issues, err := suite.provider.SearchIssuesClosedSince("test-project", "test-repo", t)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(issues, 1)
+	suite.Require().Equal(1, *issues[0].Number)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestCreateIssue() {
+	issue := &gits.GitIssue{
+		Title:  "Something is broken",
+		Body:   "It does not work",
+		Labels: []gits.GitLabel{{Name: "bug"}},
+	}
+	created, err := suite.provider.CreateIssue("test-project", "test-repo", issue)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(created)
+	suite.Require().Equal(1, *created.Number)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestCreateIssueComment() {
+	err := suite.provider.CreateIssueComment("test-project", "test-repo", 1, "a comment")
+
+	suite.Require().Nil(err)
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TestUserInfo() {
+	user := suite.provider.UserInfo("test-user")
+
+	suite.Require().NotNil(user)
+	suite.Require().Equal("test-user", user.Login)
+	suite.Require().Equal("Test User", user.Name)
+	suite.Require().Equal("test-user@example.com", user.Email)
+}
+
+func TestAzureDevOpsProviderTestSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping AzureDevOpsProviderTestSuite in short mode")
+	} else {
+		suite.Run(t, new(AzureDevOpsProviderTestSuite))
+	}
+}
+
+func (suite *AzureDevOpsProviderTestSuite) TearDownSuite() {
+	suite.server.Close()
+}
@@ -8,9 +8,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/pkg/errors"
-
-	"github.com/google/go-github/github"
 	"github.com/jenkins-x/jx/pkg/util"
 
 	"github.com/jenkins-x/jx/pkg/auth"
@@ -36,6 +33,14 @@ var stateMap = map[string]string{
 	"STOPPED":    "stopped",
 }
 
+// reverseStateMap maps GitRepoStatus states onto the build states used by both Bitbucket Cloud and Server
+var reverseStateMap = map[string]string{
+	"pending": "INPROGRESS",
+	"success": "SUCCESSFUL",
+	"error":   "FAILED",
+	"failure": "FAILED",
+}
+
 func NewBitbucketCloudProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
 	ctx := context.Background()
 
@@ -642,7 +647,46 @@ func (b *BitbucketCloudProvider) ListCommitStatus(org string, repo string, sha s
 }
 
 func (b *BitbucketCloudProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
-	return &GitRepoStatus{}, errors.New("TODO")
+	state, ok := reverseStateMap[status.State]
+	if !ok {
+		return &GitRepoStatus{}, fmt.Errorf("unsupported commit status state %s", status.State)
+	}
+
+	// the key identifies the status so that updates for the same context overwrite the previous one
+	options := map[string]interface{}{
+		"body": bitbucket.Commitstatus{
+			Type_:       "build",
+			Key:         status.Context,
+			Name:        status.Context,
+			State:       state,
+			Url:         status.TargetURL,
+			Description: status.Description,
+		},
+	}
+
+	result, _, err := b.Client.CommitstatusesApi.RepositoriesUsernameRepoSlugCommitNodeStatusesBuildPost(
+		b.Context,
+		org,
+		repo,
+		sha,
+		options,
+	)
+
+	if err != nil {
+		return &GitRepoStatus{}, err
+	}
+
+	newStatus := &GitRepoStatus{
+		ID:          result.Key,
+		Context:     result.Key,
+		State:       stateMap[result.State],
+		TargetURL:   result.Url,
+		Description: result.Description,
+	}
+	if result.Links != nil && result.Links.Commit != nil {
+		newStatus.URL = result.Links.Commit.Href
+	}
+	return newStatus, nil
 }
 
 func (b *BitbucketCloudProvider) MergePullRequest(pr *GitPullRequest, message string) error {
@@ -915,14 +959,26 @@ func (b *BitbucketCloudProvider) AddCollaborator(user string, organisation strin
 	return nil
 }
 
-func (b *BitbucketCloudProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
+func (b *BitbucketCloudProvider) ListInvitations() ([]*GitInvitation, error) {
 	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for bitbucket.\n")
-	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+	return []*GitInvitation{}, nil
 }
 
-func (b *BitbucketCloudProvider) AcceptInvitation(ID int64) (*github.Response, error) {
+func (b *BitbucketCloudProvider) AcceptInvitation(ID int64) error {
 	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for bitbucket.\n")
-	return &github.Response{}, nil
+	return nil
+}
+
+// GetBranchProtection is not supported for Bitbucket Cloud so no protection is returned
+func (b *BitbucketCloudProvider) GetBranchProtection(org string, repo string, branch string) (*BranchProtection, error) {
+	log.Warnf("Branch protection is not supported for Bitbucket Cloud\n")
+	return nil, nil
+}
+
+// SetBranchProtection is not supported for Bitbucket Cloud so the branch is left unprotected
+func (b *BitbucketCloudProvider) SetBranchProtection(org string, repo string, branch string, protection *BranchProtection) error {
+	log.Warnf("Branch protection is not supported for Bitbucket Cloud\n")
+	return nil
 }
 
 func BitBucketCloudAccessTokenURL(url string, username string) string {
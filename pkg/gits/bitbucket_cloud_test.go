@@ -60,6 +60,9 @@ var bitbucketRouter = util.Router{
 	"/repositories/test-user/test-repo/commit/5c8afc5/statuses": util.MethodMap{
 		"GET": "repos.test-repo.statuses.json",
 	},
+	"/repositories/test-user/test-repo/commit/5c8afc5/statuses/build": util.MethodMap{
+		"POST": "repos.test-repo.statuses.build.json",
+	},
 	"/repositories/test-user/test-repo/commit/7793466f879b83f1bdd8f3fc3f761bc3cb61bc41": util.MethodMap{
 		"GET": "repos.test-user.test-repo.commits.7793466f879b83f1bdd8f3fc3f761bc3cb61bc41.json",
 	},
@@ -334,6 +337,28 @@ func (suite *BitbucketCloudProviderTestSuite) TestListCommitStatus() {
 	suite.testStatuses(statuses, err)
 }
 
+func (suite *BitbucketCloudProviderTestSuite) TestUpdateCommitStatus() {
+	status := &gits.GitRepoStatus{
+		Context:     "compliance-check",
+		State:       "success",
+		TargetURL:   "https://jenkins.example.com/job/test-repo/1",
+		Description: "Compliance checks passed",
+	}
+	result, err := suite.provider.UpdateCommitStatus("test-user", "test-repo", "5c8afc5", status)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(result)
+	suite.Require().Equal("compliance-check", result.ID)
+	suite.Require().Equal("compliance-check", result.Context)
+	suite.Require().Equal("success", result.State)
+	suite.Require().Equal("https://jenkins.example.com/job/test-repo/1", result.TargetURL)
+	suite.Require().NotEmpty(result.URL)
+
+	status.State = "unknown"
+	_, err = suite.provider.UpdateCommitStatus("test-user", "test-repo", "5c8afc5", status)
+	suite.Require().NotNil(err)
+}
+
 func (suite *BitbucketCloudProviderTestSuite) TestMergePullRequest() {
 
 	id := 1
@@ -416,15 +441,13 @@ func (suite *BitbucketCloudProviderTestSuite) TestAddCollaborator() {
 }
 
 func (suite *BitbucketCloudProviderTestSuite) TestListInvitations() {
-	invites, res, err := suite.provider.ListInvitations()
+	invites, err := suite.provider.ListInvitations()
 	suite.Require().NotNil(invites)
-	suite.Require().NotNil(res)
 	suite.Require().Nil(err)
 }
 
 func (suite *BitbucketCloudProviderTestSuite) TestAcceptInvitations() {
-	res, err := suite.provider.AcceptInvitation(1)
-	suite.Require().NotNil(res)
+	err := suite.provider.AcceptInvitation(1)
 	suite.Require().Nil(err)
 }
 
@@ -1,15 +1,18 @@
 package gits
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/google/go-github/github"
 	"github.com/mitchellh/mapstructure"
 
 	bitbucket "github.com/gfleury/go-bitbucket-v1"
@@ -624,7 +627,36 @@ func (b *BitbucketServerProvider) ListCommitStatus(org, repo, sha string) ([]*Gi
 }
 
 func (b *BitbucketServerProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
-	return &GitRepoStatus{}, errors.New("TODO")
+	state, ok := reverseStateMap[status.State]
+	if !ok {
+		return &GitRepoStatus{}, fmt.Errorf("unsupported commit status state %s", status.State)
+	}
+
+	// the key identifies the status so that updates for the same context overwrite the previous one
+	buildStatus := bitbucket.BuildStatus{
+		State:       state,
+		Key:         status.Context,
+		Name:        status.Context,
+		Url:         status.TargetURL,
+		Description: status.Description,
+	}
+
+	requestBody, err := json.Marshal(buildStatus)
+	if err != nil {
+		return &GitRepoStatus{}, err
+	}
+
+	// the vendored client only supports reading build statuses so we POST to the build-status API directly
+	u := util.UrlJoin(b.Server.URL, "/rest/build-status/1.0/commits", sha)
+	err = b.doRequest(http.MethodPost, u, requestBody, nil)
+	if err != nil {
+		return &GitRepoStatus{}, fmt.Errorf("failed to update build status for commit %s: %s", sha, err)
+	}
+
+	answer := convertBitBucketBuildStatusToGitStatus(&buildStatus)
+	answer.Context = status.Context
+	answer.State = stateMap[state]
+	return answer, nil
 }
 
 func convertBitBucketBuildStatusToGitStatus(buildStatus *bitbucket.BuildStatus) *GitRepoStatus {
@@ -826,19 +858,237 @@ func (b *BitbucketServerProvider) ListReleases(org string, name string) ([]*GitR
 	return answer, nil
 }
 
+// AddCollaborator grants the user write permission on the repository
 func (b *BitbucketServerProvider) AddCollaborator(user string, organisation string, repo string) error {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for bitbucket. Please add user: %v as a collaborator to this project.\n", user)
+	log.Infof("Automatically adding the pipeline user: %v as a collaborator.\n", user)
+	params := url.Values{}
+	params.Set("name", user)
+	params.Set("permission", "REPO_WRITE")
+
+	// the vendored client does not support repository permissions so we PUT to the permissions API directly
+	u := util.UrlJoin(b.Server.URL, "/rest/api/1.0/projects", organisation, "repos", repo, "permissions/users") + "?" + params.Encode()
+	err := b.doRequest(http.MethodPut, u, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to grant %s write permission on %s/%s: %s", user, organisation, repo, err)
+	}
 	return nil
 }
 
-func (b *BitbucketServerProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for bitbucket.\n")
-	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+// ListInvitations returns no invitations as Bitbucket Server grants repository permissions directly
+func (b *BitbucketServerProvider) ListInvitations() ([]*GitInvitation, error) {
+	return []*GitInvitation{}, nil
 }
 
-func (b *BitbucketServerProvider) AcceptInvitation(ID int64) (*github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for bitbucket.\n")
-	return &github.Response{}, nil
+func (b *BitbucketServerProvider) AcceptInvitation(ID int64) error {
+	return nil
+}
+
+type bitbucketServerPullRequestSettings struct {
+	RequiredApprovers        int `json:"requiredApprovers"`
+	RequiredSuccessfulBuilds int `json:"requiredSuccessfulBuilds"`
+}
+
+type bitbucketServerRestrictionMatcherType struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type bitbucketServerRestrictionMatcher struct {
+	ID        string                                `json:"id"`
+	DisplayID string                                `json:"displayId,omitempty"`
+	Type      bitbucketServerRestrictionMatcherType `json:"type"`
+	Active    bool                                  `json:"active"`
+}
+
+type bitbucketServerRestriction struct {
+	ID      int                               `json:"id,omitempty"`
+	Type    string                            `json:"type"`
+	Matcher bitbucketServerRestrictionMatcher `json:"matcher"`
+	// Groups are exempt from the restriction
+	Groups []string `json:"groups,omitempty"`
+}
+
+type bitbucketServerRestrictions struct {
+	Values []bitbucketServerRestriction `json:"values"`
+}
+
+type bitbucketServerGroupPermission struct {
+	Group struct {
+		Name string `json:"name"`
+	} `json:"group"`
+	Permission string `json:"permission"`
+}
+
+type bitbucketServerGroupPermissions struct {
+	Values []bitbucketServerGroupPermission `json:"values"`
+}
+
+// bitbucketServerPullRequestOnly is the branch restriction which prevents changes without a pull request
+const bitbucketServerPullRequestOnly = "pull-request-only"
+
+// GetBranchProtection returns the protection of the branch or nil if the branch is not protected.
+// Bitbucket Server requires a number of successful builds rather than named status contexts so none are returned.
+func (b *BitbucketServerProvider) GetBranchProtection(org string, repo string, branch string) (*BranchProtection, error) {
+	settings := bitbucketServerPullRequestSettings{}
+	u := util.UrlJoin(b.Server.URL, "/rest/api/1.0/projects", org, "repos", repo, "settings/pull-requests")
+	err := b.doRequest(http.MethodGet, u, nil, &settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the pull request settings of %s/%s: %s", org, repo, err)
+	}
+	restriction, err := b.getPullRequestOnlyRestriction(org, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	if restriction == nil && settings.RequiredApprovers == 0 && settings.RequiredSuccessfulBuilds == 0 {
+		return nil, nil
+	}
+	return &BranchProtection{
+		RequiredApprovingReviewCount: settings.RequiredApprovers,
+		// branch restrictions apply to everyone unless groups such as the administrators are exempted
+		EnforceAdmins: restriction != nil && len(restriction.Groups) == 0,
+	}, nil
+}
+
+// SetBranchProtection protects the branch so that changes can only be merged once the given checks pass.
+// Each required status context requires one more successful build as Bitbucket Server cannot require named builds.
+// Unless admins are enforced the groups administering the project or repository are exempt from the restriction.
+func (b *BitbucketServerProvider) SetBranchProtection(org string, repo string, branch string, protection *BranchProtection) error {
+	settings := bitbucketServerPullRequestSettings{
+		RequiredApprovers:        protection.RequiredApprovingReviewCount,
+		RequiredSuccessfulBuilds: len(protection.RequiredStatusContexts),
+	}
+	body, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	u := util.UrlJoin(b.Server.URL, "/rest/api/1.0/projects", org, "repos", repo, "settings/pull-requests")
+	err = b.doRequest(http.MethodPost, u, body, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update the pull request settings of %s/%s: %s", org, repo, err)
+	}
+
+	groups := []string{}
+	if !protection.EnforceAdmins {
+		groups, err = b.adminGroups(org, repo)
+		if err != nil {
+			return err
+		}
+	}
+	existing, err := b.getPullRequestOnlyRestriction(org, repo, branch)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		removed, added := util.DiffSlices(existing.Groups, groups)
+		if len(removed) == 0 && len(added) == 0 {
+			return nil
+		}
+	}
+	restriction := bitbucketServerRestriction{
+		Type: bitbucketServerPullRequestOnly,
+		Matcher: bitbucketServerRestrictionMatcher{
+			ID:        "refs/heads/" + branch,
+			DisplayID: branch,
+			Type: bitbucketServerRestrictionMatcherType{
+				ID:   "BRANCH",
+				Name: "Branch",
+			},
+			Active: true,
+		},
+		Groups: groups,
+	}
+	body, err = json.Marshal(restriction)
+	if err != nil {
+		return err
+	}
+	err = b.doRequest(http.MethodPost, b.restrictionsURL(org, repo), body, nil)
+	if err != nil {
+		return fmt.Errorf("failed to restrict branch %s of %s/%s: %s", branch, org, repo, err)
+	}
+	if existing != nil {
+		// the new restriction is in place before the old one is removed so the branch is never left unprotected
+		err = b.doRequest(http.MethodDelete, util.UrlJoin(b.restrictionsURL(org, repo), strconv.Itoa(existing.ID)), nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to remove the previous restriction of branch %s of %s/%s: %s", branch, org, repo, err)
+		}
+	}
+	return nil
+}
+
+func (b *BitbucketServerProvider) getPullRequestOnlyRestriction(org string, repo string, branch string) (*bitbucketServerRestriction, error) {
+	params := url.Values{}
+	params.Set("matcherType", "BRANCH")
+	params.Set("matcherId", "refs/heads/"+branch)
+
+	restrictions := bitbucketServerRestrictions{}
+	err := b.doRequest(http.MethodGet, b.restrictionsURL(org, repo)+"?"+params.Encode(), nil, &restrictions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the restrictions of branch %s of %s/%s: %s", branch, org, repo, err)
+	}
+	for i := range restrictions.Values {
+		if restrictions.Values[i].Type == bitbucketServerPullRequestOnly {
+			return &restrictions.Values[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// adminGroups returns the names of the groups which administer the project or the repository
+func (b *BitbucketServerProvider) adminGroups(org string, repo string) ([]string, error) {
+	urls := map[string]string{
+		util.UrlJoin(b.Server.URL, "/rest/api/1.0/projects", org, "permissions/groups"):                "PROJECT_ADMIN",
+		util.UrlJoin(b.Server.URL, "/rest/api/1.0/projects", org, "repos", repo, "permissions/groups"): "REPO_ADMIN",
+	}
+	groups := []string{}
+	for u, admin := range urls {
+		permissions := bitbucketServerGroupPermissions{}
+		err := b.doRequest(http.MethodGet, u, nil, &permissions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the group permissions of %s/%s: %s", org, repo, err)
+		}
+		for _, p := range permissions.Values {
+			if p.Permission == admin && util.StringArrayIndex(groups, p.Group.Name) < 0 {
+				groups = append(groups, p.Group.Name)
+			}
+		}
+	}
+	return groups, nil
+}
+
+func (b *BitbucketServerProvider) restrictionsURL(org string, repo string) string {
+	return util.UrlJoin(b.Server.URL, "/rest/branch-permissions/2.0/projects", org, "repos", repo, "restrictions")
+}
+
+// doRequest invokes the REST API of the server directly for the resources the vendored client does not support.
+// The JSON response is unmarshalled into result when it is not nil.
+func (b *BitbucketServerProvider) doRequest(method string, u string, body []byte, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+b.User.ApiToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s", resp.Status, string(data))
+	}
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	return nil
 }
 
 func BitBucketServerAccessTokenURL(url string) string {
@@ -1,7 +1,10 @@
 package gits_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -61,8 +64,29 @@ var bitbucketServerRouter = util.Router{
 	"/rest/api/1.0/users/test-user": util.MethodMap{
 		"GET": "user.json",
 	},
+	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/permissions/users": util.MethodMap{
+		"PUT": "permissions.nil.json",
+	},
+	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/settings/pull-requests": util.MethodMap{
+		"GET":  "pull-request-settings.json",
+		"POST": "pull-request-settings.json",
+	},
+	"/rest/branch-permissions/2.0/projects/TEST-ORG/repos/test-repo/restrictions": util.MethodMap{
+		"GET":  "restrictions.json",
+		"POST": "restriction.json",
+	},
+	"/rest/branch-permissions/2.0/projects/TEST-ORG/repos/test-repo/restrictions/1": util.MethodMap{
+		"DELETE": "permissions.nil.json",
+	},
+	"/rest/api/1.0/projects/TEST-ORG/permissions/groups": util.MethodMap{
+		"GET": "project-group-permissions.json",
+	},
+	"/rest/api/1.0/projects/TEST-ORG/repos/test-repo/permissions/groups": util.MethodMap{
+		"GET": "repo-group-permissions.json",
+	},
 	"/rest/build-status/1.0/commits/d6f24ee03d76a2caf0a4e1975fb43e8f61759b9c": util.MethodMap{
-		"GET": "build-statuses.json",
+		"GET":  "build-statuses.json",
+		"POST": "build-status.nil.json",
 	},
 }
 
@@ -266,6 +290,36 @@ func (suite *BitbucketServerProviderTestSuite) TestListCommitStatuses() {
 	}
 }
 
+func (suite *BitbucketServerProviderTestSuite) TestUpdateCommitStatus() {
+	// build statuses are posted directly to the server rather than through the API client
+	provider := *suite.provider
+	provider.Server.URL = suite.server.URL
+	requests := recordRequests(suite.server, suite.mux)
+	defer func() {
+		suite.server.Config.Handler = suite.mux
+	}()
+
+	status := &gits.GitRepoStatus{
+		Context:     "compliance-check",
+		State:       "failure",
+		TargetURL:   "https://jenkins.example.com/job/test-repo/1",
+		Description: "Compliance checks failed",
+	}
+	result, err := provider.UpdateCommitStatus("TEST-ORG", "test-repo", "d6f24ee03d76a2caf0a4e1975fb43e8f61759b9c", status)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(result)
+	suite.Require().Equal("compliance-check", result.ID)
+	suite.Require().Equal("compliance-check", result.Context)
+	suite.Require().Equal("failure", result.State)
+	suite.Require().Equal("https://jenkins.example.com/job/test-repo/1", result.TargetURL)
+
+	posted := findRecordedRequests(*requests, http.MethodPost, "/rest/build-status/1.0/commits/d6f24ee03d76a2caf0a4e1975fb43e8f61759b9c")
+	suite.Require().Len(posted, 1)
+	suite.Contains(posted[0].Body, `"state":"FAILED"`)
+	suite.Contains(posted[0].Body, `"key":"compliance-check"`)
+}
+
 func (suite *BitbucketServerProviderTestSuite) TestMergePullRequest() {
 
 	id := 1
@@ -304,20 +358,125 @@ func (suite *BitbucketServerProviderTestSuite) TestUserInfo() {
 }
 
 func (suite *BitbucketServerProviderTestSuite) TestAddCollaborator() {
-	err := suite.provider.AddCollaborator("derek", orgname, "repo")
+	// repository permissions are granted directly on the server rather than through the API client
+	provider := *suite.provider
+	provider.Server.URL = suite.server.URL
+
+	err := provider.AddCollaborator("derek", "TEST-ORG", "test-repo")
 	suite.Require().Nil(err)
 }
 
+func (suite *BitbucketServerProviderTestSuite) TestGetBranchProtection() {
+	// pull request settings and branch restrictions are read directly from the server
+	provider := *suite.provider
+	provider.Server.URL = suite.server.URL
+
+	protection, err := provider.GetBranchProtection("TEST-ORG", "test-repo", "master")
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(protection)
+	suite.Require().Equal(2, protection.RequiredApprovingReviewCount)
+	suite.Require().True(protection.EnforceAdmins)
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestSetBranchProtection() {
+	// pull request settings and branch restrictions are written directly to the server
+	provider := *suite.provider
+	provider.Server.URL = suite.server.URL
+
+	protection := &gits.BranchProtection{
+		RequiredStatusContexts:       []string{"continuous-integration/jenkins/pr-merge"},
+		RequiredApprovingReviewCount: 2,
+		EnforceAdmins:                true,
+	}
+	err := provider.SetBranchProtection("TEST-ORG", "test-repo", "master", protection)
+	suite.Require().Nil(err)
+}
+
+func (suite *BitbucketServerProviderTestSuite) TestSetBranchProtectionExemptsAdmins() {
+	// the administrator groups are read directly from the server
+	provider := *suite.provider
+	provider.Server.URL = suite.server.URL
+	requests := recordRequests(suite.server, suite.mux)
+	defer func() {
+		suite.server.Config.Handler = suite.mux
+	}()
+
+	protection := &gits.BranchProtection{
+		RequiredApprovingReviewCount: 1,
+		EnforceAdmins:                false,
+	}
+	err := provider.SetBranchProtection("TEST-ORG", "test-repo", "master", protection)
+	suite.Require().Nil(err)
+
+	restrictionsPath := "/rest/branch-permissions/2.0/projects/TEST-ORG/repos/test-repo/restrictions"
+	posted := findRecordedRequests(*requests, http.MethodPost, restrictionsPath)
+	suite.Require().Len(posted, 1, "the restriction should be replaced")
+	restriction := struct {
+		Type    string `json:"type"`
+		Matcher struct {
+			ID string `json:"id"`
+		} `json:"matcher"`
+		Groups []string `json:"groups"`
+	}{}
+	suite.Require().Nil(json.Unmarshal([]byte(posted[0].Body), &restriction))
+	suite.Equal("pull-request-only", restriction.Type)
+	suite.Equal("refs/heads/master", restriction.Matcher.ID)
+	suite.ElementsMatch([]string{"project-admins", "repo-admins"}, restriction.Groups)
+
+	settings := findRecordedRequests(*requests, http.MethodPost, "/rest/api/1.0/projects/TEST-ORG/repos/test-repo/settings/pull-requests")
+	suite.Require().Len(settings, 1)
+	suite.Contains(settings[0].Body, `"requiredApprovers":1`)
+
+	deleted := findRecordedRequests(*requests, http.MethodDelete, restrictionsPath+"/1")
+	suite.Require().Len(deleted, 1, "the previous restriction should be removed")
+	suite.True(deleted[0].Index > posted[0].Index, "the previous restriction should be removed after the new one is added")
+}
+
+// recordedRequest is a request received by a mock git server
+type recordedRequest struct {
+	Index  int
+	Method string
+	Path   string
+	Body   string
+}
+
+// recordRequests records the requests received by the server before they are handled by the handler
+func recordRequests(server *httptest.Server, handler http.Handler) *[]recordedRequest {
+	requests := &[]recordedRequest{}
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		*requests = append(*requests, recordedRequest{
+			Index:  len(*requests),
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Body:   string(body),
+		})
+		handler.ServeHTTP(w, r)
+	})
+	return requests
+}
+
+// findRecordedRequests returns the recorded requests with the method and path
+func findRecordedRequests(requests []recordedRequest, method string, path string) []recordedRequest {
+	answer := []recordedRequest{}
+	for _, r := range requests {
+		if r.Method == method && r.Path == path {
+			answer = append(answer, r)
+		}
+	}
+	return answer
+}
+
 func (suite *BitbucketServerProviderTestSuite) TestListInvitations() {
-	invites, res, err := suite.provider.ListInvitations()
+	invites, err := suite.provider.ListInvitations()
 	suite.Require().NotNil(invites)
-	suite.Require().NotNil(res)
 	suite.Require().Nil(err)
 }
 
 func (suite *BitbucketServerProviderTestSuite) TestAcceptInvitations() {
-	res, err := suite.provider.AcceptInvitation(1)
-	suite.Require().NotNil(res)
+	err := suite.provider.AcceptInvitation(1)
 	suite.Require().Nil(err)
 }
 
@@ -0,0 +1,405 @@
+package gits
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/russross/blackfriday"
+)
+
+const (
+	// ChangelogGroupByKind groups the commits by their Conventional Commit type
+	ChangelogGroupByKind = "kind"
+	// ChangelogGroupByLabel groups the commits by the labels of the issues and pull requests they reference
+	ChangelogGroupByLabel = "label"
+	// ChangelogGroupByAuthor groups the commits by their author
+	ChangelogGroupByAuthor = "author"
+
+	otherChangesTitle = "Other Changes"
+
+	keepAChangelogHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.0.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+)
+
+// ChangelogGroupBy the ways in which the commits of a changelog can be grouped
+var ChangelogGroupBy = []string{ChangelogGroupByKind, ChangelogGroupByLabel, ChangelogGroupByAuthor}
+
+// keepAChangelogSections the Keep a Changelog sections in order with the Conventional Commit types they contain.
+// Commits of any other type are listed as Changed and chores, tests and CI changes are left out
+var keepAChangelogSections = []struct {
+	title string
+	kinds []string
+}{
+	{"Added", []string{"feat"}},
+	{"Changed", []string{"perf", "refactor", "docs", ""}},
+	{"Deprecated", []string{"deprecate", "deprecated"}},
+	{"Removed", []string{"revert", "remove"}},
+	{"Fixed", []string{"fix"}},
+	{"Security", []string{"security"}},
+}
+
+var keepAChangelogIgnoredKinds = map[string]bool{
+	"chore": true,
+	"test":  true,
+	"style": true,
+	"ci":    true,
+	"build": true,
+}
+
+// Changelog is the data changelog templates are rendered with. It embeds the ReleaseSpec so that templates can use
+// all of its fields such as .Version, .Commits and .Issues along with the parsed and grouped commits
+type Changelog struct {
+	*v1.ReleaseSpec `json:",inline"`
+	Date            string            `json:"date,omitempty"`
+	GroupBy         string            `json:"groupBy,omitempty"`
+	Changes         []ChangelogCommit `json:"changes,omitempty"`
+	Groups          []ChangelogGroup  `json:"groups,omitempty"`
+
+	gitInfo *GitRepositoryInfo
+}
+
+// ChangelogGroup is a titled group of the commits of a release
+type ChangelogGroup struct {
+	Title   string            `json:"title"`
+	Commits []ChangelogCommit `json:"commits"`
+}
+
+// ChangelogCommit is a commit of a release along with its Conventional Commit details and the issues it references
+type ChangelogCommit struct {
+	v1.CommitSummary `json:",inline"`
+	Kind             string            `json:"kind,omitempty"`
+	Feature          string            `json:"feature,omitempty"`
+	Subject          string            `json:"subject,omitempty"`
+	Breaking         bool              `json:"breaking,omitempty"`
+	Description      string            `json:"description,omitempty"`
+	Issues           []v1.IssueSummary `json:"linkedIssues,omitempty"`
+}
+
+// NewChangelog creates the changelog of the release with the commits grouped by kind, label or author
+func NewChangelog(releaseSpec *v1.ReleaseSpec, gitInfo *GitRepositoryInfo, groupBy string) (*Changelog, error) {
+	if groupBy == "" {
+		groupBy = ChangelogGroupByKind
+	}
+	changelog := &Changelog{
+		ReleaseSpec: releaseSpec,
+		Date:        time.Now().Format("2006-01-02"),
+		GroupBy:     groupBy,
+		gitInfo:     gitInfo,
+	}
+
+	issueMap := map[string]*v1.IssueSummary{}
+	for _, list := range [][]v1.IssueSummary{releaseSpec.Issues, releaseSpec.PullRequests} {
+		for i := range list {
+			issueMap[list[i].ID] = &list[i]
+		}
+	}
+
+	commits := []ChangelogCommit{}
+	for i := range releaseSpec.Commits {
+		cs := &releaseSpec.Commits[i]
+		if cs.Message == "" {
+			continue
+		}
+		ci := ParseCommit(cs.Message)
+		if ci.Feature == "" {
+			// use the scope of commits such as fix(beer): as the feature
+			start := strings.Index(ci.Kind, "(")
+			end := strings.LastIndex(ci.Kind, ")")
+			if start >= 0 && end > start {
+				ci.Feature = strings.TrimSpace(ci.Kind[start+1 : end])
+			}
+		}
+		commit := ChangelogCommit{
+			CommitSummary: *cs,
+			Kind:          ci.Type(),
+			Feature:       ci.Feature,
+			Subject:       strings.Split(strings.TrimSpace(ci.Message), "\n")[0],
+			Breaking:      ci.Breaking,
+			Description:   strings.TrimSpace(describeCommit(gitInfo, cs, ci, issueMap)),
+		}
+		for _, id := range cs.IssueIDs {
+			issue := issueMap[id]
+			if issue != nil {
+				commit.Issues = append(commit.Issues, *issue)
+			}
+		}
+		commits = append(commits, commit)
+	}
+	changelog.Changes = commits
+
+	switch groupBy {
+	case ChangelogGroupByKind:
+		changelog.Groups = groupCommitsByKind(commits)
+	case ChangelogGroupByLabel:
+		changelog.Groups = groupCommitsByKey(commits, func(commit *ChangelogCommit) []string {
+			labels := []string{}
+			for _, issue := range commit.Issues {
+				for _, label := range issue.Labels {
+					if label.Name != "" {
+						labels = append(labels, label.Name)
+					}
+				}
+			}
+			return labels
+		})
+	case ChangelogGroupByAuthor:
+		changelog.Groups = groupCommitsByKey(commits, func(commit *ChangelogCommit) []string {
+			user := commit.Author
+			if user == nil {
+				user = commit.Committer
+			}
+			if user != nil {
+				for _, name := range []string{user.Login, user.Name, user.Email} {
+					if name != "" {
+						return []string{name}
+					}
+				}
+			}
+			return nil
+		})
+	default:
+		return nil, fmt.Errorf("unknown changelog grouping %s, supported values are %s", groupBy, strings.Join(ChangelogGroupBy, ", "))
+	}
+	return changelog, nil
+}
+
+// groupCommitsByKind groups the commits by their Conventional Commit type in the same order as GenerateMarkdown with
+// the commits which do not follow the conventions last
+func groupCommitsByKind(commits []ChangelogCommit) []ChangelogGroup {
+	groups := map[string]*ChangelogGroup{}
+	orders := map[string]int{}
+	for _, commit := range commits {
+		group := ConventionalCommitTitles[commit.Kind]
+		if group == nil && !strings.ContainsAny(commit.Kind, " \t") {
+			group = ConventionalCommitTypeToTitle(commit.Kind)
+		}
+		if group == nil || group.Title == "" {
+			group = &CommitGroup{Title: otherChangesTitle, Order: unknownKindOrder + 1}
+		}
+		cg := groups[group.Title]
+		if cg == nil {
+			cg = &ChangelogGroup{Title: group.Title}
+			groups[group.Title] = cg
+			orders[group.Title] = group.Order
+		}
+		cg.Commits = append(cg.Commits, commit)
+	}
+	titles := []string{}
+	for title := range groups {
+		titles = append(titles, title)
+	}
+	sort.Slice(titles, func(i, j int) bool {
+		oi, oj := orders[titles[i]], orders[titles[j]]
+		if oi != oj {
+			return oi < oj
+		}
+		return titles[i] < titles[j]
+	})
+	answer := []ChangelogGroup{}
+	for _, title := range titles {
+		answer = append(answer, *groups[title])
+	}
+	return answer
+}
+
+// groupCommitsByKey groups the commits by the sorted keys returned for each commit. Commits without a key are
+// added to a final group of other changes
+func groupCommitsByKey(commits []ChangelogCommit, keysFn func(*ChangelogCommit) []string) []ChangelogGroup {
+	groups := map[string]*ChangelogGroup{}
+	others := &ChangelogGroup{Title: otherChangesTitle}
+	for i := range commits {
+		commit := &commits[i]
+		keys := keysFn(commit)
+		if len(keys) == 0 {
+			others.Commits = append(others.Commits, *commit)
+			continue
+		}
+		added := map[string]bool{}
+		for _, key := range keys {
+			if added[key] {
+				continue
+			}
+			added[key] = true
+			group := groups[key]
+			if group == nil {
+				group = &ChangelogGroup{Title: key}
+				groups[key] = group
+			}
+			group.Commits = append(group.Commits, *commit)
+		}
+	}
+	titles := []string{}
+	for title := range groups {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	answer := []ChangelogGroup{}
+	for _, title := range titles {
+		answer = append(answer, *groups[title])
+	}
+	if len(others.Commits) > 0 {
+		answer = append(answer, *others)
+	}
+	return answer
+}
+
+// DefaultChangelogTemplate is the template used to render the changelog body when grouping commits by label or author
+const DefaultChangelogTemplate = `{{- if .Groups }}## Changes
+{{ range .Groups }}
+### {{ .Title }}
+
+{{ range .Commits }}* {{ .Description }}
+{{ end }}{{ end }}{{ end }}{{ if .Issues }}
+### Issues
+
+{{ range .Issues }}* {{ describeIssue . }}
+{{ end }}{{ end }}{{ if .PullRequests }}
+### Pull Requests
+
+{{ range .PullRequests }}* {{ describeIssue . }}
+{{ end }}{{ end }}`
+
+// RenderChangelogTemplate renders the Go template with the changelog
+func RenderChangelogTemplate(changelog *Changelog, name string, templateText string) (string, error) {
+	funcMap := template.FuncMap{
+		"describeIssue": func(issue v1.IssueSummary) string {
+			return describeIssue(changelog.gitInfo, &issue)
+		},
+		"describeUser": func(user *v1.UserDetails) string {
+			return strings.TrimSpace(describeUser(changelog.gitInfo, user))
+		},
+		"shortSHA": func(sha string) string {
+			if len(sha) > 7 {
+				return sha[0:7]
+			}
+			return sha
+		},
+		"subject": func(message string) string {
+			return strings.Split(strings.TrimSpace(message), "\n")[0]
+		},
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"title": strings.Title,
+		"join":  strings.Join,
+	}
+	tmpl, err := template.New(name).Funcs(funcMap).Parse(templateText)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the %s template: %s", name, err)
+	}
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, changelog)
+	if err != nil {
+		return "", fmt.Errorf("failed to render the %s template: %s", name, err)
+	}
+	return buffer.String(), nil
+}
+
+// GenerateKeepAChangelog generates the section of a Keep a Changelog (https://keepachangelog.com/) file for the release
+func GenerateKeepAChangelog(changelog *Changelog) string {
+	sections := map[string][]string{}
+	for _, group := range groupCommitsByKind(changelog.Changes) {
+		for _, commit := range group.Commits {
+			if keepAChangelogIgnoredKinds[commit.Kind] && !commit.Breaking {
+				continue
+			}
+			section := "Changed"
+			for _, s := range keepAChangelogSections {
+				if util.StringArrayIndex(s.kinds, commit.Kind) >= 0 {
+					section = s.title
+					break
+				}
+			}
+			description := commit.Description
+			if commit.Breaking {
+				description = "**BREAKING** " + description
+			}
+			sections[section] = append(sections[section], description)
+		}
+	}
+
+	var buffer bytes.Buffer
+	title := changelog.Version
+	if changelog.ReleaseNotesURL != "" {
+		title = "[" + title + "](" + changelog.ReleaseNotesURL + ")"
+	} else {
+		title = "[" + title + "]"
+	}
+	buffer.WriteString("## " + title + " - " + changelog.Date + "\n")
+	for _, s := range keepAChangelogSections {
+		lines := sections[s.title]
+		if len(lines) == 0 {
+			continue
+		}
+		buffer.WriteString("\n### " + s.title + "\n\n")
+		for _, line := range lines {
+			buffer.WriteString("- " + line + "\n")
+		}
+	}
+	return buffer.String()
+}
+
+// PrependKeepAChangelog adds the section of a release to the top of the existing Keep a Changelog file text after any
+// Unreleased section. The standard header is used if the file is empty
+func PrependKeepAChangelog(existing string, section string) string {
+	if strings.TrimSpace(existing) == "" {
+		return keepAChangelogHeader + "\n" + section
+	}
+	lines := strings.SplitAfter(existing, "\n")
+	idx := len(lines)
+	unreleased := false
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "## ") {
+			continue
+		}
+		if strings.Contains(strings.ToLower(line), "unreleased") {
+			unreleased = true
+			continue
+		}
+		idx = i
+		break
+	}
+	before := strings.Join(lines[0:idx], "")
+	after := strings.Join(lines[idx:], "")
+	if !strings.HasSuffix(before, "\n") {
+		before += "\n"
+	}
+	if !strings.HasSuffix(before, "\n\n") {
+		before += "\n"
+	}
+	if after != "" || unreleased {
+		section += "\n"
+	}
+	return before + section + after
+}
+
+// GenerateHTML renders the markdown of a changelog as a HTML document. Any HTML in the markdown is skipped
+func GenerateHTML(changelog *Changelog, markdown string) string {
+	title := strings.TrimSpace(changelog.Name + " " + changelog.Version)
+	renderer := blackfriday.HtmlRenderer(blackfriday.HTML_SKIP_HTML|blackfriday.HTML_SAFELINK, "", "")
+	extensions := blackfriday.EXTENSION_NO_INTRA_EMPHASIS | blackfriday.EXTENSION_FENCED_CODE |
+		blackfriday.EXTENSION_AUTOLINK | blackfriday.EXTENSION_STRIKETHROUGH | blackfriday.EXTENSION_TABLES
+	body := blackfriday.Markdown([]byte(markdown), renderer, extensions)
+	return `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>` + html.EscapeString(title) + `</title>
+</head>
+<body>
+<h1>` + html.EscapeString(title) + `</h1>
+` + string(body) + `</body>
+</html>
+`
+}
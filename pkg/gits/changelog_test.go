@@ -0,0 +1,177 @@
+package gits_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createChangelogReleaseSpec() *v1.ReleaseSpec {
+	return &v1.ReleaseSpec{
+		Name:    "cheese",
+		Version: "1.2.0",
+		Commits: []v1.CommitSummary{
+			{
+				Message:  "feat: wensleydale support",
+				SHA:      "1234567890",
+				Author:   &v1.UserDetails{Login: "jstrachan"},
+				IssueIDs: []string{"123"},
+			},
+			{
+				Message: "fix(brie): too runny\n\nfixes #345",
+				SHA:     "2345678901",
+				Author:  &v1.UserDetails{Login: "rawlingsj"},
+			},
+			{
+				Message: "chore: upgrade the dependencies",
+				SHA:     "3456789012",
+				Author:  &v1.UserDetails{Login: "jstrachan"},
+			},
+			{
+				Message: "refactor!: remove the cheddar API\n\nBREAKING CHANGE: use the wensleydale API instead",
+				SHA:     "4567890123",
+				Author:  &v1.UserDetails{Name: "James Rawlings"},
+			},
+			{
+				Message: "some regular change",
+				SHA:     "5678901234",
+			},
+		},
+		Issues: []v1.IssueSummary{
+			{
+				ID:     "123",
+				URL:    "https://github.com/jenkins-x/cheese/issues/123",
+				Title:  "support wensleydale",
+				Labels: []v1.IssueLabel{{Name: "enhancement"}},
+			},
+		},
+	}
+}
+
+func assertChangelogGroups(t *testing.T, groupBy string, expected map[string][]string, expectedOrder ...string) {
+	gitInfo, err := gits.ParseGitURL("https://github.com/jenkins-x/cheese")
+	require.NoError(t, err)
+	changelog, err := gits.NewChangelog(createChangelogReleaseSpec(), gitInfo, groupBy)
+	require.NoError(t, err)
+
+	titles := []string{}
+	for _, group := range changelog.Groups {
+		titles = append(titles, group.Title)
+		shas := []string{}
+		for _, commit := range group.Commits {
+			shas = append(shas, commit.SHA)
+		}
+		assert.Equal(t, expected[group.Title], shas, "commits of group %s when grouping by %s", group.Title, groupBy)
+	}
+	assert.Equal(t, expectedOrder, titles, "groups when grouping by %s", groupBy)
+}
+
+func TestChangelogGroups(t *testing.T) {
+	t.Parallel()
+	assertChangelogGroups(t, gits.ChangelogGroupByKind, map[string][]string{
+		"New Features":     {"1234567890"},
+		"Bug Fixes":        {"2345678901"},
+		"Code Refactoring": {"4567890123"},
+		"Chores":           {"3456789012"},
+		"Other Changes":    {"5678901234"},
+	}, "New Features", "Bug Fixes", "Code Refactoring", "Chores", "Other Changes")
+
+	assertChangelogGroups(t, gits.ChangelogGroupByLabel, map[string][]string{
+		"enhancement":   {"1234567890"},
+		"Other Changes": {"2345678901", "3456789012", "4567890123", "5678901234"},
+	}, "enhancement", "Other Changes")
+
+	assertChangelogGroups(t, gits.ChangelogGroupByAuthor, map[string][]string{
+		"James Rawlings": {"4567890123"},
+		"jstrachan":      {"1234567890", "3456789012"},
+		"rawlingsj":      {"2345678901"},
+		"Other Changes":  {"5678901234"},
+	}, "James Rawlings", "jstrachan", "rawlingsj", "Other Changes")
+
+	_, err := gits.NewChangelog(createChangelogReleaseSpec(), &gits.GitRepositoryInfo{}, "cheese")
+	assert.Error(t, err)
+}
+
+func TestRenderChangelogTemplate(t *testing.T) {
+	t.Parallel()
+	gitInfo, err := gits.ParseGitURL("https://github.com/jenkins-x/cheese")
+	require.NoError(t, err)
+	changelog, err := gits.NewChangelog(createChangelogReleaseSpec(), gitInfo, gits.ChangelogGroupByKind)
+	require.NoError(t, err)
+
+	text, err := gits.RenderChangelogTemplate(changelog, "test", `# {{ .Name }} {{ .Version }}
+{{ range .Changes }}{{ if .Breaking }}* {{ shortSHA .SHA }} {{ .Subject }}
+{{ end }}{{ end }}{{ range .Groups }}{{ .Title }}={{ len .Commits }};{{ end }}`)
+	require.NoError(t, err)
+	assert.Equal(t, "# cheese 1.2.0\n* 4567890 remove the cheddar API\nNew Features=1;Bug Fixes=1;Code Refactoring=1;Chores=1;Other Changes=1;", text)
+
+	_, err = gits.RenderChangelogTemplate(changelog, "test", "{{ .Cheese }")
+	assert.Error(t, err)
+
+	changelog, err = gits.NewChangelog(createChangelogReleaseSpec(), gitInfo, gits.ChangelogGroupByLabel)
+	require.NoError(t, err)
+	text, err = gits.RenderChangelogTemplate(changelog, "test", gits.DefaultChangelogTemplate)
+	require.NoError(t, err)
+	assert.Contains(t, text, "### enhancement\n\n* wensleydale support ([jstrachan](https://github.com/jstrachan)) [#123](https://github.com/jenkins-x/cheese/issues/123)\n")
+	assert.Contains(t, text, "### Issues\n\n* [#123](https://github.com/jenkins-x/cheese/issues/123) support wensleydale\n")
+}
+
+func TestKeepAChangelog(t *testing.T) {
+	t.Parallel()
+	gitInfo, err := gits.ParseGitURL("https://github.com/jenkins-x/cheese")
+	require.NoError(t, err)
+	changelog, err := gits.NewChangelog(createChangelogReleaseSpec(), gitInfo, gits.ChangelogGroupByAuthor)
+	require.NoError(t, err)
+	changelog.Date = "2018-10-17"
+
+	section := gits.GenerateKeepAChangelog(changelog)
+	assert.Equal(t, `## [1.2.0] - 2018-10-17
+
+### Added
+
+- wensleydale support ([jstrachan](https://github.com/jstrachan)) [#123](https://github.com/jenkins-x/cheese/issues/123)
+
+### Changed
+
+- **BREAKING** remove the cheddar API (James Rawlings)
+- some regular change
+
+### Fixed
+
+- brie: too runny ([rawlingsj](https://github.com/rawlingsj))
+`, section)
+
+	text := gits.PrependKeepAChangelog("", section)
+	assert.Contains(t, text, "# Changelog\n\nAll notable changes")
+	assert.Contains(t, text, "\n\n## [1.2.0] - 2018-10-17\n")
+
+	existing := "# Changelog\n\n## [Unreleased]\n\n- cheese\n\n## [1.1.0] - 2018-09-01\n\n- wine\n"
+	text = gits.PrependKeepAChangelog(existing, "## [1.2.0] - 2018-10-17\n\n- beer\n")
+	assert.Equal(t, "# Changelog\n\n## [Unreleased]\n\n- cheese\n\n## [1.2.0] - 2018-10-17\n\n- beer\n\n## [1.1.0] - 2018-09-01\n\n- wine\n", text)
+}
+
+func TestChangelogJSONAndHTML(t *testing.T) {
+	t.Parallel()
+	gitInfo, err := gits.ParseGitURL("https://github.com/jenkins-x/cheese")
+	require.NoError(t, err)
+	changelog, err := gits.NewChangelog(createChangelogReleaseSpec(), gitInfo, gits.ChangelogGroupByKind)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(changelog)
+	require.NoError(t, err)
+	values := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(data, &values))
+	assert.Equal(t, "1.2.0", values["version"])
+	assert.Len(t, values["commits"], 5)
+	assert.Len(t, values["groups"], 5)
+
+	html := gits.GenerateHTML(changelog, "## Changes\n\n* cheese & <wine>\n")
+	assert.Contains(t, html, "<title>cheese 1.2.0</title>")
+	assert.Contains(t, html, "<h2>Changes</h2>")
+	assert.Contains(t, html, "<li>cheese &amp;</li>")
+	assert.NotContains(t, html, "<wine>")
+}
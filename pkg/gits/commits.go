@@ -9,10 +9,11 @@ import (
 )
 
 type CommitInfo struct {
-	Kind    string
-	Feature string
-	Message string
-	group   *CommitGroup
+	Kind     string
+	Feature  string
+	Message  string
+	Breaking bool
+	group    *CommitGroup
 }
 
 type CommitGroup struct {
@@ -67,8 +68,13 @@ func ParseCommit(message string) *CommitInfo {
 	}
 
 	idx := strings.Index(message, ":")
-	if idx > 0 {
+	eol := strings.Index(message, "\n")
+	if idx > 0 && (eol < 0 || idx < eol) {
 		answer.Kind = message[0:idx]
+		if strings.HasSuffix(answer.Kind, "!") {
+			answer.Kind = strings.TrimSuffix(answer.Kind, "!")
+			answer.Breaking = true
+		}
 
 		rest := strings.TrimSpace(message[idx+1:])
 		if strings.HasPrefix(rest, "(") {
@@ -80,9 +86,24 @@ func ParseCommit(message string) *CommitInfo {
 		}
 		answer.Message = rest
 	}
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			answer.Breaking = true
+		}
+	}
 	return answer
 }
 
+// Type returns the conventional commit type without any scope such as feat for feat(beer)
+func (c *CommitInfo) Type() string {
+	kind := c.Kind
+	idx := strings.Index(kind, "(")
+	if idx >= 0 {
+		kind = kind[0:idx]
+	}
+	return strings.ToLower(strings.TrimSpace(kind))
+}
+
 func (c *CommitInfo) Group() *CommitGroup {
 	if c.group == nil {
 		c.group = ConventionalCommitTitles[strings.ToLower(c.Kind)]
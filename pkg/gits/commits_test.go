@@ -26,6 +26,26 @@ func TestParseCommits(t *testing.T) {
 		Feature: "",
 		Message: "wine is good too",
 	})
+	assertParseCommit(t, "feat!: no more cheese", &gits.CommitInfo{
+		Kind:     "feat",
+		Message:  "no more cheese",
+		Breaking: true,
+	})
+	assertParseCommit(t, "fix: cheese\n\nBREAKING CHANGE: the cheese is now blue", &gits.CommitInfo{
+		Kind:     "fix",
+		Message:  "cheese\n\nBREAKING CHANGE: the cheese is now blue",
+		Breaking: true,
+	})
+	assertParseCommit(t, "regular cheese\n\nsee: wine", &gits.CommitInfo{
+		Message: "regular cheese\n\nsee: wine",
+	})
+}
+
+func TestCommitType(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "feat", gits.ParseCommit("feat(beer): wine is good too").Type())
+	assert.Equal(t, "fix", gits.ParseCommit("Fix: cheese").Type())
+	assert.Equal(t, "", gits.ParseCommit("something regular").Type())
 }
 
 func assertParseCommit(t *testing.T, input string, expected *gits.CommitInfo) {
@@ -1,16 +1,18 @@
 package gits
 
 const (
+	KindAzureDevOps     = "azure"
 	KindBitBucketCloud  = "bitbucketcloud"
 	KindBitBucketServer = "bitbucketserver"
 	KindGitea           = "gitea"
 	KindGitlab          = "gitlab"
 	KindGitHub          = "github"
+	KindGerrit          = "gerrit"
 	KindUnknown         = "unknown"
 
 	BitbucketCloudURL = "https://bitbucket.org"
 )
 
 var (
-	KindGits = []string{KindBitBucketCloud, KindBitBucketServer, KindGitea, KindGitHub, KindGitlab}
+	KindGits = []string{KindAzureDevOps, KindBitBucketCloud, KindBitBucketServer, KindGerrit, KindGitea, KindGitHub, KindGitlab}
 )
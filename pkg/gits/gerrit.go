@@ -2,14 +2,32 @@ package gits
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	gerrit "github.com/andygrunwald/go-gerrit"
-	"github.com/google/go-github/github"
 	"github.com/jenkins-x/jx/pkg/auth"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
 )
 
+const (
+	// GerritVerifiedLabel is the label used to report commit statuses as votes on a change
+	GerritVerifiedLabel = "Verified"
+
+	gerritReviewTag     = "autogenerated:jenkins-x"
+	gerritWebHookRemote = "jenkins-x"
+	gerritTimeLayout    = "2006-01-02 15:04:05.000000000"
+)
+
+// GerritProvider implements GitProvider interface for a Gerrit server.
+//
+// Gerrit has no concept of organisations so a project is named "org/name" when an org is given.
+// Changes are mapped onto pull requests and votes on the Verified label are mapped onto commit statuses.
 type GerritProvider struct {
 	Client   *gerrit.Client
 	Username string
@@ -21,67 +39,464 @@ type GerritProvider struct {
 }
 
 func NewGerritProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
-	return nil, nil
+	client, err := gerrit.NewClient(server.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Gerrit uses the HTTP password generated in the user settings for REST API access
+	client.Authentication.SetBasicAuth(user.Username, user.ApiToken)
+
+	provider := GerritProvider{
+		Client:   client,
+		Server:   *server,
+		User:     *user,
+		Username: user.Username,
+		Context:  context.Background(),
+		Git:      git,
+	}
+
+	return &provider, nil
+}
+
+func gerritProjectName(org string, name string) string {
+	if org == "" {
+		return name
+	}
+	return org + "/" + name
+}
+
+func gerritChangeID(number int) string {
+	return strconv.Itoa(number)
+}
+
+func (p *GerritProvider) projectToGitRepository(projectName string) *GitRepository {
+	name := projectName
+	idx := strings.LastIndex(projectName, "/")
+	if idx >= 0 {
+		name = projectName[idx+1:]
+	}
+	return &GitRepository{
+		Name:     name,
+		HTMLURL:  util.UrlJoin(p.Server.URL, "admin/repos", projectName),
+		CloneURL: util.UrlJoin(p.Server.URL, projectName) + ".git",
+		SSHURL:   "",
+		Fork:     false,
+	}
+}
+
+func (p *GerritProvider) ListOrganisations() ([]GitOrganisation, error) {
+	answer := []GitOrganisation{}
+	projects, _, err := p.Client.Projects.ListProjects(&gerrit.ProjectOptions{})
+	if err != nil {
+		return answer, err
+	}
+
+	// organisations are the top level folders of the project hierarchy
+	orgs := map[string]string{}
+	for name := range *projects {
+		idx := strings.Index(name, "/")
+		if idx > 0 {
+			orgs[name[0:idx]] = name[0:idx]
+		}
+	}
+	names := util.SortedMapKeys(orgs)
+	for _, name := range names {
+		answer = append(answer, GitOrganisation{Login: name})
+	}
+	return answer, nil
 }
 
 func (p *GerritProvider) ListRepositories(org string) ([]*GitRepository, error) {
-	return nil, nil
+	answer := []*GitRepository{}
+	opt := &gerrit.ProjectOptions{
+		Description: true,
+	}
+	if org != "" {
+		opt.Prefix = org + "/"
+	}
+	projects, _, err := p.Client.Projects.ListProjects(opt)
+	if err != nil {
+		return answer, err
+	}
+
+	names := []string{}
+	for name := range *projects {
+		// only include direct children of the organisation
+		if strings.Contains(strings.TrimPrefix(name, opt.Prefix), "/") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		answer = append(answer, p.projectToGitRepository(name))
+	}
+	return answer, nil
 }
 
 func (p *GerritProvider) CreateRepository(org string, name string, private bool) (*GitRepository, error) {
-	return nil, nil
+	projectName := gerritProjectName(org, name)
+	input := &gerrit.ProjectInput{
+		Name:              projectName,
+		CreateEmptyCommit: true,
+	}
+	if private {
+		log.Warnf("Gerrit project visibility is controlled by access rights so %s is created with the inherited permissions\n", projectName)
+	}
+	project, _, err := p.Client.Projects.CreateProject(projectName, input)
+	if err != nil {
+		return nil, err
+	}
+	return p.projectToGitRepository(project.Name), nil
 }
 
 func (p *GerritProvider) GetRepository(org string, name string) (*GitRepository, error) {
-	return nil, nil
+	project, _, err := p.Client.Projects.GetProject(gerritProjectName(org, name))
+	if err != nil {
+		return nil, err
+	}
+	return p.projectToGitRepository(project.Name), nil
 }
 
+// DeleteRepository deletes the project using the delete-project plugin as Gerrit core cannot delete projects
 func (p *GerritProvider) DeleteRepository(org string, name string) error {
-	return nil
+	u := fmt.Sprintf("projects/%s/delete-project~delete", url.QueryEscape(gerritProjectName(org, name)))
+	input := map[string]interface{}{
+		"force":    false,
+		"preserve": false,
+	}
+	_, err := p.Client.Call("POST", u, input, nil)
+	return err
 }
 
 func (p *GerritProvider) ForkRepository(originalOrg string, name string, destinationOrg string) (*GitRepository, error) {
-	return nil, nil
+	return nil, fmt.Errorf("Forking of repositories is not supported for Gerrit")
 }
 
 func (p *GerritProvider) RenameRepository(org string, name string, newName string) (*GitRepository, error) {
-	return nil, nil
+	return nil, fmt.Errorf("Rename of repositories is not supported for Gerrit")
 }
 
 func (p *GerritProvider) ValidateRepositoryName(org string, name string) error {
-	return nil
+	projectName := gerritProjectName(org, name)
+	_, resp, err := p.Client.Projects.GetProject(projectName)
+	if resp != nil && resp.StatusCode == 404 {
+		return nil
+	}
+	if err == nil {
+		return fmt.Errorf("Repository %s already exists", projectName)
+	}
+	return err
+}
+
+// gerritMergeInput describes the branch to merge when creating a change
+type gerritMergeInput struct {
+	Source string `json:"source"`
+}
+
+// gerritChangeInput is the subset of the Gerrit ChangeInput entity used to create changes
+type gerritChangeInput struct {
+	Project string            `json:"project"`
+	Branch  string            `json:"branch"`
+	Subject string            `json:"subject"`
+	Topic   string            `json:"topic,omitempty"`
+	Merge   *gerritMergeInput `json:"merge,omitempty"`
 }
 
+// CreatePullRequest creates a change which merges the pushed head branch into the base branch
 func (p *GerritProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitPullRequest, error) {
-	return nil, nil
+	owner := data.GitRepositoryInfo.Organisation
+	repo := data.GitRepositoryInfo.Name
+	subject := data.Title
+	if data.Body != "" {
+		subject += "\n\n" + data.Body
+	}
+	input := &gerritChangeInput{
+		Project: gerritProjectName(owner, repo),
+		Branch:  data.Base,
+		Subject: subject,
+		Topic:   data.Head,
+		Merge: &gerritMergeInput{
+			Source: data.Head,
+		},
+	}
+	change := &gerrit.ChangeInfo{}
+	_, err := p.Client.Call("POST", "changes/", input, change)
+	if err != nil {
+		return nil, err
+	}
+	pr := p.toPullRequest(owner, repo, change)
+	pr.HeadRef = &data.Head
+	return pr, nil
+}
+
+func (p *GerritProvider) getChange(number int, fields ...string) (*gerrit.ChangeInfo, error) {
+	opt := &gerrit.ChangeOptions{
+		AdditionalFields: fields,
+	}
+	change, _, err := p.Client.Changes.GetChange(gerritChangeID(number), opt)
+	return change, err
+}
+
+func (p *GerritProvider) toPullRequest(owner string, repo string, change *gerrit.ChangeInfo) *GitPullRequest {
+	number := change.Number
+	merged := change.Status == "MERGED"
+	state := "open"
+	if change.Status != "NEW" {
+		state = "closed"
+	}
+	mergeable := change.Mergeable
+	lines := strings.SplitN(change.Subject, "\n", 2)
+
+	pr := &GitPullRequest{
+		URL:           util.UrlJoin(p.Server.URL, "c", gerritProjectName(owner, repo), "+", strconv.Itoa(number)),
+		Owner:         owner,
+		Repo:          repo,
+		Number:        &number,
+		Mergeable:     &mergeable,
+		Merged:        &merged,
+		State:         &state,
+		Title:         lines[0],
+		LastCommitSha: change.CurrentRevision,
+		Author: &GitUser{
+			Login: change.Owner.Username,
+			Name:  change.Owner.Name,
+			Email: change.Owner.Email,
+		},
+	}
+	if change.Topic != "" {
+		topic := change.Topic
+		pr.HeadRef = &topic
+	}
+	if revision, ok := change.Revisions[change.CurrentRevision]; ok {
+		if revision.Commit.Message != "" {
+			parts := strings.SplitN(revision.Commit.Message, "\n\n", 2)
+			if len(parts) > 1 {
+				pr.Body = strings.TrimSpace(parts[1])
+			}
+		}
+	}
+	if merged {
+		sha := change.CurrentRevision
+		pr.MergeCommitSHA = &sha
+		pr.MergedAt = parseGerritTime(change.Submitted)
+		pr.ClosedAt = pr.MergedAt
+	} else if change.Status == "ABANDONED" {
+		pr.ClosedAt = parseGerritTime(change.Updated)
+	}
+	return pr
+}
+
+func parseGerritTime(text string) *time.Time {
+	if text == "" {
+		return nil
+	}
+	t, err := time.Parse(gerritTimeLayout, text)
+	if err != nil {
+		log.Warnf("Failed to parse Gerrit timestamp %s: %s\n", text, err)
+		return nil
+	}
+	return &t
 }
 
 func (p *GerritProvider) UpdatePullRequestStatus(pr *GitPullRequest) error {
+	if pr.Number == nil {
+		return fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	change, err := p.getChange(*pr.Number, "CURRENT_REVISION", "CURRENT_COMMIT")
+	if err != nil {
+		return err
+	}
+	updated := p.toPullRequest(pr.Owner, pr.Repo, change)
+	pr.URL = updated.URL
+	pr.Author = updated.Author
+	pr.Mergeable = updated.Mergeable
+	pr.Merged = updated.Merged
+	pr.State = updated.State
+	pr.Title = updated.Title
+	pr.Body = updated.Body
+	pr.LastCommitSha = updated.LastCommitSha
+	pr.MergeCommitSHA = updated.MergeCommitSHA
+	pr.MergedAt = updated.MergedAt
+	pr.ClosedAt = updated.ClosedAt
+	if updated.HeadRef != nil {
+		pr.HeadRef = updated.HeadRef
+	}
 	return nil
 }
 
 func (p *GerritProvider) GetPullRequest(owner string, repo *GitRepositoryInfo, number int) (*GitPullRequest, error) {
-	return nil, nil
+	change, err := p.getChange(number, "CURRENT_REVISION", "CURRENT_COMMIT")
+	if err != nil {
+		return nil, err
+	}
+	return p.toPullRequest(owner, repo.Name, change), nil
 }
 
+// GetPullRequestCommits returns the commit of the current patch set as a Gerrit change always contains a single commit
 func (p *GerritProvider) GetPullRequestCommits(owner string, repo *GitRepositoryInfo, number int) ([]*GitCommit, error) {
-	return nil, nil
+	answer := []*GitCommit{}
+	commit, _, err := p.Client.Changes.GetCommit(gerritChangeID(number), "current", nil)
+	if err != nil {
+		return answer, err
+	}
+	answer = append(answer, &GitCommit{
+		SHA:     commit.Commit,
+		Message: commit.Message,
+		Author: &GitUser{
+			Name:  commit.Author.Name,
+			Email: commit.Author.Email,
+		},
+		Committer: &GitUser{
+			Name:  commit.Committer.Name,
+			Email: commit.Committer.Email,
+		},
+	})
+	return answer, nil
+}
+
+// gerritLabelState converts the votes on a label into a GitRepoStatus state
+func gerritLabelState(label gerrit.LabelInfo) string {
+	if label.Rejected.AccountID != 0 || label.Rejected.Username != "" {
+		return "failure"
+	}
+	if label.Approved.AccountID != 0 || label.Approved.Username != "" {
+		return "success"
+	}
+	return "pending"
 }
 
 func (p *GerritProvider) PullRequestLastCommitStatus(pr *GitPullRequest) (string, error) {
-	return "", nil
+	if pr.Number == nil {
+		return "", fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	change, err := p.getChange(*pr.Number, "LABELS")
+	if err != nil {
+		return "", err
+	}
+	label, ok := change.Labels[GerritVerifiedLabel]
+	if !ok {
+		// the project does not verify changes
+		return "success", nil
+	}
+	return gerritLabelState(label), nil
+}
+
+func (p *GerritProvider) findChangeForCommit(org string, repo string, sha string, fields ...string) (*gerrit.ChangeInfo, error) {
+	opt := &gerrit.QueryChangeOptions{}
+	opt.Query = []string{fmt.Sprintf("commit:%s project:%s", sha, gerritProjectName(org, repo))}
+	opt.AdditionalFields = fields
+	changes, _, err := p.Client.Changes.QueryChanges(opt)
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil || len(*changes) == 0 {
+		return nil, fmt.Errorf("no change found for commit %s in project %s", sha, gerritProjectName(org, repo))
+	}
+	return &(*changes)[0], nil
 }
 
 func (p *GerritProvider) ListCommitStatus(org string, repo string, sha string) ([]*GitRepoStatus, error) {
-	return nil, nil
-}
-
+	answer := []*GitRepoStatus{}
+	change, err := p.findChangeForCommit(org, repo, sha, "LABELS")
+	if err != nil {
+		return answer, err
+	}
+	names := []string{}
+	for name := range change.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		answer = append(answer, &GitRepoStatus{
+			ID:      name,
+			Context: name,
+			URL:     util.UrlJoin(p.Server.URL, "c", gerritProjectName(org, repo), "+", strconv.Itoa(change.Number)),
+			State:   gerritLabelState(change.Labels[name]),
+		})
+	}
+	return answer, nil
+}
+
+// UpdateCommitStatus votes on the Verified label of the change for the commit and adds a message with the status details
+func (p *GerritProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
+	var vote string
+	switch status.State {
+	case "pending":
+		vote = "0"
+	case "success":
+		vote = "+1"
+	case "error", "failure":
+		vote = "-1"
+	default:
+		return &GitRepoStatus{}, fmt.Errorf("unsupported commit status state %s", status.State)
+	}
+	change, err := p.findChangeForCommit(org, repo, sha)
+	if err != nil {
+		return &GitRepoStatus{}, err
+	}
+
+	message := fmt.Sprintf("%s: %s", status.Context, status.State)
+	if status.Description != "" {
+		message += "\n\n" + status.Description
+	}
+	if status.TargetURL != "" {
+		message += "\n\n" + status.TargetURL
+	}
+	input := &gerrit.ReviewInput{
+		Message: message,
+		Tag:     gerritReviewTag,
+		Labels: map[string]string{
+			GerritVerifiedLabel: vote,
+		},
+	}
+	_, _, err = p.Client.Changes.SetReview(gerritChangeID(change.Number), sha, input)
+	if err != nil {
+		return &GitRepoStatus{}, err
+	}
+	return &GitRepoStatus{
+		ID:          status.Context,
+		Context:     status.Context,
+		URL:         util.UrlJoin(p.Server.URL, "c", gerritProjectName(org, repo), "+", strconv.Itoa(change.Number)),
+		State:       status.State,
+		TargetURL:   status.TargetURL,
+		Description: status.Description,
+	}, nil
+}
+
+// MergePullRequest submits the change
 func (p *GerritProvider) MergePullRequest(pr *GitPullRequest, message string) error {
-	return nil
-}
-
+	if pr.Number == nil {
+		return fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	changeID := gerritChangeID(*pr.Number)
+	if message != "" {
+		_, _, err := p.Client.Changes.SetReview(changeID, "current", &gerrit.ReviewInput{
+			Message: message,
+			Tag:     gerritReviewTag,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	_, _, err := p.Client.Changes.SubmitChange(changeID, &gerrit.SubmitInput{})
+	return err
+}
+
+// CreateWebHook registers a remote with the webhooks plugin for the project
 func (p *GerritProvider) CreateWebHook(data *GitWebHookArguments) error {
-	return nil
+	owner := data.Owner
+	if owner == "" {
+		owner = data.Repo.Organisation
+	}
+	projectName := gerritProjectName(owner, data.Repo.Name)
+	u := fmt.Sprintf("config/server/webhooks~projects/%s/remotes/%s", url.QueryEscape(projectName), gerritWebHookRemote)
+	input := map[string]interface{}{
+		"url":    data.URL,
+		"events": []string{"patchset-created", "change-merged", "comment-added", "ref-updated"},
+	}
+	_, err := p.Client.Call("PUT", u, input, nil)
+	return err
 }
 
 func (p *GerritProvider) IsGitHub() bool {
@@ -105,75 +520,112 @@ func (p *GerritProvider) IsGerrit() bool {
 }
 
 func (p *GerritProvider) Kind() string {
-	return "gerrit"
+	return KindGerrit
 }
 
 func (p *GerritProvider) GetIssue(org string, name string, number int) (*GitIssue, error) {
-	return nil, nil
+	return nil, fmt.Errorf("Gerrit does not support issues")
 }
 
 func (p *GerritProvider) IssueURL(org string, name string, number int, isPull bool) string {
+	if isPull {
+		return util.UrlJoin(p.Server.URL, "c", gerritProjectName(org, name), "+", strconv.Itoa(number))
+	}
 	return ""
 }
 
 func (p *GerritProvider) SearchIssues(org string, name string, query string) ([]*GitIssue, error) {
-	return nil, nil
+	log.Warn("Gerrit does not support issues")
+	return []*GitIssue{}, nil
 }
 
 func (p *GerritProvider) SearchIssuesClosedSince(org string, name string, t time.Time) ([]*GitIssue, error) {
-	return nil, nil
+	issues, err := p.SearchIssues(org, name, "")
+	if err != nil {
+		return issues, err
+	}
+	return FilterIssuesClosedSince(issues, t), nil
 }
 
 func (p *GerritProvider) CreateIssue(owner string, repo string, issue *GitIssue) (*GitIssue, error) {
-	return nil, nil
+	return nil, fmt.Errorf("Gerrit does not support issues")
 }
 
 func (p *GerritProvider) HasIssues() bool {
 	return false
 }
 
+// AddPRComment adds the comment as a review message on the current patch set
 func (p *GerritProvider) AddPRComment(pr *GitPullRequest, comment string) error {
-	return nil
+	if pr.Number == nil {
+		return fmt.Errorf("Missing Number for GitPullRequest %#v", pr)
+	}
+	_, _, err := p.Client.Changes.SetReview(gerritChangeID(*pr.Number), "current", &gerrit.ReviewInput{
+		Message: comment,
+		Tag:     gerritReviewTag,
+	})
+	return err
 }
 
 func (p *GerritProvider) CreateIssueComment(owner string, repo string, number int, comment string) error {
+	log.Warn("Gerrit does not support issue comments")
 	return nil
 }
 
 func (p *GerritProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *GitRelease) error {
+	log.Warn("Gerrit doesn't support releases")
 	return nil
 }
 
 func (p *GerritProvider) ListReleases(org string, name string) ([]*GitRelease, error) {
-	return nil, nil
+	answer := []*GitRelease{}
+	log.Warn("Gerrit doesn't support releases")
+	return answer, nil
 }
 
+// JenkinsWebHookPath is exposed by the Jenkins Gerrit Code Review plugin
 func (p *GerritProvider) JenkinsWebHookPath(gitURL string, secret string) string {
-	return ""
+	return "/gerrit-webhook/"
+}
+
+func GerritAccessTokenURL(url string) string {
+	return util.UrlJoin(url, "/settings/#HTTPCredentials")
 }
 
 func (p *GerritProvider) Label() string {
-	return ""
+	return p.Server.Label()
 }
 
 func (p *GerritProvider) ServerURL() string {
-	return ""
+	return p.Server.URL
 }
 
+// BranchArchiveURL returns the archive URL served by the gitiles plugin
 func (p *GerritProvider) BranchArchiveURL(org string, name string, branch string) string {
-	return ""
+	return util.UrlJoin(p.ServerURL(), "plugins/gitiles", gerritProjectName(org, name), "+archive", branch+".tar.gz")
 }
 
 func (p *GerritProvider) CurrentUsername() string {
-	return ""
+	return p.Username
 }
 
 func (p *GerritProvider) UserAuth() auth.UserAuth {
-	return auth.UserAuth{}
+	return p.User
 }
 
 func (p *GerritProvider) UserInfo(username string) *GitUser {
-	return nil
+	account, _, err := p.Client.Accounts.GetAccount(username)
+	if err != nil {
+		log.Error("Unable to fetch user info for " + username + " due to " + err.Error() + "\n")
+		return nil
+	}
+
+	return &GitUser{
+		Login: account.Username,
+		Name:  account.Name,
+		Email: account.Email,
+		URL:   util.UrlJoin(p.Server.URL, "q", "owner:"+account.Username),
+	}
 }
 
 func (p *GerritProvider) AddCollaborator(user string, organisation string, repo string) error {
@@ -181,12 +633,24 @@ func (p *GerritProvider) AddCollaborator(user string, organisation string, repo
 	return nil
 }
 
-func (p *GerritProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
+func (p *GerritProvider) ListInvitations() ([]*GitInvitation, error) {
 	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for gerrit.\n")
-	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+	return []*GitInvitation{}, nil
 }
 
-func (p *GerritProvider) AcceptInvitation(ID int64) (*github.Response, error) {
+func (p *GerritProvider) AcceptInvitation(ID int64) error {
 	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for gerrit.\n")
-	return &github.Response{}, nil
+	return nil
+}
+
+// GetBranchProtection is not supported for Gerrit so no protection is returned
+func (p *GerritProvider) GetBranchProtection(org string, repo string, branch string) (*BranchProtection, error) {
+	log.Warnf("Branch protection is not supported for Gerrit\n")
+	return nil, nil
+}
+
+// SetBranchProtection is not supported for Gerrit so the branch is left unprotected
+func (p *GerritProvider) SetBranchProtection(org string, repo string, branch string, protection *BranchProtection) error {
+	log.Warnf("Branch protection is not supported for Gerrit\n")
+	return nil
 }
@@ -0,0 +1,261 @@
+package gits_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/suite"
+)
+
+const gerritCommitSHA = "184ebe53805e102605d11f6b143486d15c23a09c"
+
+type GerritProviderTestSuite struct {
+	suite.Suite
+	mux      *http.ServeMux
+	server   *httptest.Server
+	provider *gits.GerritProvider
+}
+
+var gerritRouter = util.Router{
+	"/a/projects/": util.MethodMap{
+		"GET": "projects.json",
+	},
+	"/a/projects/test-repo": util.MethodMap{
+		"GET": "project.json",
+	},
+	"/a/projects/test-repo/": util.MethodMap{
+		"PUT": "project.json",
+	},
+	"/a/changes/": util.MethodMap{
+		"GET":  "changes.json",
+		"POST": "change.json",
+	},
+	"/a/changes/1": util.MethodMap{
+		"GET": "change.json",
+	},
+	"/a/changes/1/submit": util.MethodMap{
+		"POST": "change.merged.json",
+	},
+	"/a/changes/1/revisions/current/commit": util.MethodMap{
+		"GET": "commit.json",
+	},
+	"/a/changes/1/revisions/current/review": util.MethodMap{
+		"POST": "review.json",
+	},
+	"/a/changes/1/revisions/" + gerritCommitSHA + "/review": util.MethodMap{
+		"POST": "review.json",
+	},
+	"/a/config/server/webhooks~projects/test-repo/remotes/jenkins-x": util.MethodMap{
+		"PUT": "webhook.json",
+	},
+	"/a/accounts/test-user": util.MethodMap{
+		"GET": "account.json",
+	},
+}
+
+func (suite *GerritProviderTestSuite) SetupSuite() {
+	suite.mux = http.NewServeMux()
+
+	for path, methodMap := range gerritRouter {
+		suite.mux.HandleFunc(path, util.GetMockAPIResponseFromFile("test_data/gerrit", methodMap))
+	}
+
+	suite.server = httptest.NewServer(suite.mux)
+	suite.Require().NotNil(suite.server)
+
+	as := auth.AuthServer{
+		URL:         suite.server.URL,
+		Name:        "Test Auth Server",
+		Kind:        gits.KindGerrit,
+		CurrentUser: "test-user",
+	}
+	ua := auth.UserAuth{
+		Username: "test-user",
+		ApiToken: "0123456789abdef",
+	}
+
+	git := gits.NewGitCLI()
+	gp, err := gits.CreateProvider(&as, &ua, git)
+
+	suite.Require().NotNil(gp)
+	suite.Require().Nil(err)
+
+	var ok bool
+	suite.provider, ok = gp.(*gits.GerritProvider)
+	suite.Require().True(ok)
+	suite.Require().NotNil(suite.provider)
+}
+
+func (suite *GerritProviderTestSuite) TestListOrganisations() {
+	orgs, err := suite.provider.ListOrganisations()
+
+	suite.Require().Nil(err)
+	suite.Require().Equal([]gits.GitOrganisation{{Login: "test-org"}}, orgs)
+}
+
+func (suite *GerritProviderTestSuite) TestListRepositories() {
+	repos, err := suite.provider.ListRepositories("")
+
+	suite.Require().Nil(err)
+	suite.Require().Len(repos, 1)
+	suite.Require().Equal("test-repo", repos[0].Name)
+	suite.Require().Equal(suite.server.URL+"/test-repo.git", repos[0].CloneURL)
+}
+
+func (suite *GerritProviderTestSuite) TestGetRepository() {
+	repo, err := suite.provider.GetRepository("", "test-repo")
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(repo)
+	suite.Require().Equal("test-repo", repo.Name)
+}
+
+func (suite *GerritProviderTestSuite) TestCreateRepository() {
+	repo, err := suite.provider.CreateRepository("", "test-repo", false)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(repo)
+	suite.Require().Equal("test-repo", repo.Name)
+}
+
+func (suite *GerritProviderTestSuite) TestValidateRepositoryName() {
+	err := suite.provider.ValidateRepositoryName("", "test-repo")
+	suite.Require().NotNil(err)
+}
+
+func (suite *GerritProviderTestSuite) TestCreatePullRequest() {
+	args := &gits.GitPullRequestArguments{
+		GitRepositoryInfo: &gits.GitRepositoryInfo{Name: "test-repo"},
+		Head:              "feature-branch",
+		Base:              "master",
+		Title:             "Add a feature",
+	}
+
+	pr, err := suite.provider.CreatePullRequest(args)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(pr)
+	suite.Require().Equal(1, *pr.Number)
+	suite.Require().Equal("open", *pr.State)
+	suite.Require().Equal("feature-branch", *pr.HeadRef)
+	suite.Require().Equal(gerritCommitSHA, pr.LastCommitSha)
+}
+
+func (suite *GerritProviderTestSuite) TestGetPullRequest() {
+	pr, err := suite.provider.GetPullRequest("", &gits.GitRepositoryInfo{Name: "test-repo"}, 1)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(pr)
+	suite.Require().Equal(1, *pr.Number)
+	suite.Require().Equal("Add a feature", pr.Title)
+	suite.Require().Equal("Some more details about the feature\n\nChange-Id: I8473b95934b5732ac55d26311a706c9c2bde9940", pr.Body)
+	suite.Require().Equal("test-user", pr.Author.Login)
+	suite.Require().False(*pr.Merged)
+	suite.Require().False(pr.IsClosed())
+}
+
+func (suite *GerritProviderTestSuite) TestPullRequestCommits() {
+	commits, err := suite.provider.GetPullRequestCommits("", &gits.GitRepositoryInfo{Name: "test-repo"}, 1)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(commits, 1)
+	suite.Require().Equal(gerritCommitSHA, commits[0].SHA)
+	suite.Require().Equal("test-user@example.com", commits[0].Author.Email)
+}
+
+func (suite *GerritProviderTestSuite) TestPullRequestLastCommitStatus() {
+	number := 1
+	pr := &gits.GitPullRequest{
+		Repo:   "test-repo",
+		Number: &number,
+	}
+	status, err := suite.provider.PullRequestLastCommitStatus(pr)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("success", status)
+}
+
+func (suite *GerritProviderTestSuite) TestListCommitStatus() {
+	statuses, err := suite.provider.ListCommitStatus("", "test-repo", gerritCommitSHA)
+
+	suite.Require().Nil(err)
+	suite.Require().Len(statuses, 2)
+	suite.Require().Equal("Code-Review", statuses[0].Context)
+	suite.Require().Equal("pending", statuses[0].State)
+	suite.Require().Equal(gits.GerritVerifiedLabel, statuses[1].Context)
+	suite.Require().Equal("failure", statuses[1].State)
+}
+
+func (suite *GerritProviderTestSuite) TestUpdateCommitStatus() {
+	status := &gits.GitRepoStatus{
+		Context:     "compliance-check",
+		State:       "success",
+		TargetURL:   "https://jenkins.example.com/job/test-repo/1",
+		Description: "Compliance checks passed",
+	}
+	result, err := suite.provider.UpdateCommitStatus("", "test-repo", gerritCommitSHA, status)
+
+	suite.Require().Nil(err)
+	suite.Require().Equal("compliance-check", result.Context)
+	suite.Require().Equal("success", result.State)
+
+	status.State = "unknown"
+	_, err = suite.provider.UpdateCommitStatus("", "test-repo", gerritCommitSHA, status)
+	suite.Require().NotNil(err)
+}
+
+func (suite *GerritProviderTestSuite) TestMergePullRequest() {
+	number := 1
+	pr := &gits.GitPullRequest{
+		Repo:   "test-repo",
+		Number: &number,
+	}
+	err := suite.provider.MergePullRequest(pr, "Merging from unit tests")
+
+	suite.Require().Nil(err)
+}
+
+func (suite *GerritProviderTestSuite) TestAddPRComment() {
+	number := 1
+	pr := &gits.GitPullRequest{
+		Repo:   "test-repo",
+		Number: &number,
+	}
+	err := suite.provider.AddPRComment(pr, "a comment")
+
+	suite.Require().Nil(err)
+}
+
+func (suite *GerritProviderTestSuite) TestCreateWebHook() {
+	data := &gits.GitWebHookArguments{
+		Repo: &gits.GitRepositoryInfo{Name: "test-repo"},
+		URL:  "https://my-jenkins.example.com/gerrit-webhook/",
+	}
+	err := suite.provider.CreateWebHook(data)
+
+	suite.Require().Nil(err)
+}
+
+func (suite *GerritProviderTestSuite) TestUserInfo() {
+	user := suite.provider.UserInfo("test-user")
+
+	suite.Require().NotNil(user)
+	suite.Require().Equal("test-user", user.Login)
+	suite.Require().Equal("test-user@example.com", user.Email)
+}
+
+func TestGerritProviderTestSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping GerritProviderTestSuite in short mode")
+	} else {
+		suite.Run(t, new(GerritProviderTestSuite))
+	}
+}
+
+func (suite *GerritProviderTestSuite) TearDownSuite() {
+	suite.server.Close()
+}
@@ -519,6 +519,48 @@ func (g *GitCLI) CreateTag(dir string, tag string, msg string) error {
 	return g.gitCmd("", "tag", "-fa", tag, "-m", msg)
 }
 
+// GetCommitMessages returns the full messages of the commits after the given revision up to HEAD in the repository
+// at the given directory. All the commits are returned if no revision is specified
+func (g *GitCLI) GetCommitMessages(dir string, fromRevision string) ([]string, error) {
+	answer := []string{}
+	revisions := "HEAD"
+	if fromRevision != "" {
+		revisions = fromRevision + "..HEAD"
+	}
+	text, err := g.gitCmdWithOutput(dir, "log", "--format=%B%x1e", revisions)
+	if err != nil {
+		return answer, err
+	}
+	for _, message := range strings.Split(text, "\x1e") {
+		message = strings.TrimSpace(message)
+		if message != "" {
+			answer = append(answer, message)
+		}
+	}
+	return answer, nil
+}
+
+// GetChangedFiles returns the paths relative to the root of the repository at the given directory of the files which
+// changed between the revisions. The working tree is compared if no revision to compare to is specified
+func (g *GitCLI) GetChangedFiles(dir string, fromRevision string, toRevision string) ([]string, error) {
+	answer := []string{}
+	args := []string{"diff", "--name-only", fromRevision}
+	if toRevision != "" {
+		args = append(args, toRevision)
+	}
+	text, err := g.gitCmdWithOutput(dir, args...)
+	if err != nil {
+		return answer, err
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			answer = append(answer, line)
+		}
+	}
+	return answer, nil
+}
+
 // PrintCreateRepositoryGenerateAccessToken prints the access token URL of a Git repository
 func (g *GitCLI) PrintCreateRepositoryGenerateAccessToken(server *auth.AuthServer, username string, o io.Writer) {
 	tokenUrl := ProviderAccessTokenURL(server.Kind, server.URL, username)
@@ -37,6 +37,7 @@ type GitFake struct {
 	Changes        bool
 	GitTags        []GitTag
 	Revision       string
+	ChangedFiles   []string
 }
 
 func (g *GitFake) FindGitConfigDir(dir string) (string, string, error) {
@@ -393,6 +394,22 @@ func (g *GitFake) CreateTag(dir string, tag string, msg string) error {
 	return nil
 }
 
+func (g *GitFake) GetCommitMessages(dir string, fromRevision string) ([]string, error) {
+	messages := []string{}
+	for _, commit := range g.Commits {
+		if commit.SHA == fromRevision {
+			messages = []string{}
+			continue
+		}
+		messages = append(messages, commit.Message)
+	}
+	return messages, nil
+}
+
+func (g *GitFake) GetChangedFiles(dir string, fromRevision string, toRevision string) ([]string, error) {
+	return g.ChangedFiles, nil
+}
+
 func (g *GitFake) GetRevisionBeforeDate(dir string, t time.Time) (string, error) {
 	return g.Revision, nil
 }
@@ -12,6 +12,11 @@ const (
 	GitHubHost = "github.com"
 	GitHubURL  = "https://github.com"
 
+	AzureDevOpsURL = "https://dev.azure.com"
+
+	// azureGitPathSegment separates the project from the repository name in Azure DevOps URLs
+	azureGitPathSegment = "_git"
+
 	gitPrefix = "git@"
 )
 
@@ -53,11 +58,27 @@ func (i *GitRepositoryInfo) HttpsURL() string {
 	if !strings.Contains(host, ":/") {
 		host = "https://" + host
 	}
+	if i.isAzureDevOps() {
+		return util.UrlJoin(host, i.Organisation, i.Project, azureGitPathSegment, i.Name)
+	}
 	return util.UrlJoin(host, i.Organisation, i.Name)
 }
 
-// HostURL returns the URL to the host
+// HostURL returns the URL to the host. For Azure DevOps the URL includes the organisation as each organisation
+// has its own REST API
 func (i *GitRepositoryInfo) HostURL() string {
+	answer := i.hostURL()
+	if i.isAzureDevOps() && i.Organisation != "" {
+		return util.UrlJoin(answer, i.Organisation)
+	}
+	return answer
+}
+
+func (i *GitRepositoryInfo) isAzureDevOps() bool {
+	return i.Host == strings.TrimPrefix(AzureDevOpsURL, "https://")
+}
+
+func (i *GitRepositoryInfo) hostURL() string {
 	answer := i.Host
 	if !strings.Contains(answer, ":/") {
 		// lets find the scheme from the URL
@@ -151,6 +172,17 @@ func parsePath(path string, info *GitRepositoryInfo) (*GitRepositoryInfo, error)
 	trimPath = strings.TrimSuffix(trimPath, ".git")
 	arr := strings.Split(trimPath, "/")
 	arrayLength := len(arr)
+	if arrayLength >= 3 && arr[arrayLength-2] == azureGitPathSegment {
+		// Azure DevOps URLs are of the form /{organisation}/{project}/_git/{repository}
+		info.Organisation = arr[arrayLength-3]
+		if arrayLength >= 4 && arr[arrayLength-4] != "" {
+			info.Organisation = arr[arrayLength-4]
+		}
+		info.Project = arr[arrayLength-3]
+		info.Name = arr[arrayLength-1]
+
+		return info, nil
+	}
 	if arrayLength >= 2 {
 		info.Organisation = arr[arrayLength-2]
 		info.Project = arr[arrayLength-2]
@@ -176,6 +208,9 @@ func SaasGitKind(gitServiceUrl string) string {
 	case BitbucketCloudURL:
 		return KindBitBucketCloud
 	default:
+		if strings.HasPrefix(gitServiceUrl, AzureDevOpsURL) {
+			return KindAzureDevOps
+		}
 		return ""
 	}
 }
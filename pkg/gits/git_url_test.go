@@ -50,6 +50,9 @@ func TestParseGitURL(t *testing.T) {
 		{
 			"http://test-user@auth.example.com/scm/bar/foo.git", "auth.example.com", "bar", "foo",
 		},
+		{
+			"https://test-org@dev.azure.com/test-org/bar/_git/foo", "dev.azure.com", "test-org", "foo",
+		},
 	}
 	for _, data := range testCases {
 		info, err := gits.ParseGitURL(data.url)
@@ -60,3 +63,14 @@ func TestParseGitURL(t *testing.T) {
 		assert.Equal(t, data.name, info.Name, "Name does not match for input %s", data.url)
 	}
 }
+
+func TestParseAzureDevOpsGitURL(t *testing.T) {
+	t.Parallel()
+	info, err := gits.ParseGitURL("https://test-org@dev.azure.com/test-org/bar/_git/foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-org", info.Organisation)
+	assert.Equal(t, "bar", info.Project)
+	assert.Equal(t, "foo", info.Name)
+	assert.Equal(t, "https://dev.azure.com/test-org", info.HostURL())
+	assert.Equal(t, "https://dev.azure.com/test-org/bar/_git/foo", info.HttpsURL())
+}
@@ -1,14 +1,12 @@
 package gits
 
 import (
-	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"code.gitea.io/sdk/gitea"
-	"github.com/google/go-github/github"
 	"github.com/jenkins-x/jx/pkg/auth"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
@@ -509,7 +507,7 @@ func (p *GiteaProvider) ListCommitStatus(org string, repo string, sha string) ([
 	}
 	for _, result := range results {
 		status := &GitRepoStatus{
-			ID:          string(result.ID),
+			ID:          strconv.FormatInt(result.ID, 10),
 			Context:     result.Context,
 			URL:         result.URL,
 			TargetURL:   result.TargetURL,
@@ -521,8 +519,25 @@ func (p *GiteaProvider) ListCommitStatus(org string, repo string, sha string) ([
 	return answer, nil
 }
 
-func (b *GiteaProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
-	return &GitRepoStatus{}, errors.New("TODO")
+func (p *GiteaProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
+	opts := gitea.CreateStatusOption{
+		State:       gitea.StatusState(status.State),
+		TargetURL:   status.TargetURL,
+		Description: status.Description,
+		Context:     status.Context,
+	}
+	result, err := p.Client.CreateStatus(org, repo, sha, opts)
+	if err != nil {
+		return &GitRepoStatus{}, err
+	}
+	return &GitRepoStatus{
+		ID:          strconv.FormatInt(result.ID, 10),
+		Context:     result.Context,
+		URL:         result.URL,
+		TargetURL:   result.TargetURL,
+		State:       string(result.State),
+		Description: result.Description,
+	}, nil
 }
 
 func (p *GiteaProvider) RenameRepository(org string, name string, newName string) (*GitRepository, error) {
@@ -665,17 +680,36 @@ func (p *GiteaProvider) UserInfo(username string) *GitUser {
 	}
 }
 
+// AddCollaborator adds the user as a collaborator with write permission on the repository
 func (p *GiteaProvider) AddCollaborator(user string, organisation string, repo string) error {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Gitea. Please add user: %v as a collaborator to this project.\n", user)
+	owner := organisation
+	if owner == "" {
+		owner = p.Username
+	}
+	log.Infof("Automatically adding the pipeline user: %v as a collaborator.\n", user)
+	permission := "write"
+	return p.Client.AddCollaborator(owner, repo, user, gitea.AddCollaboratorOption{
+		Permission: &permission,
+	})
+}
+
+// ListInvitations returns no invitations as Gitea adds collaborators to repositories directly
+func (p *GiteaProvider) ListInvitations() ([]*GitInvitation, error) {
+	return []*GitInvitation{}, nil
+}
+
+func (p *GiteaProvider) AcceptInvitation(ID int64) error {
 	return nil
 }
 
-func (p *GiteaProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Gitea.\n")
-	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+// GetBranchProtection is not supported for Gitea so no protection is returned
+func (p *GiteaProvider) GetBranchProtection(org string, repo string, branch string) (*BranchProtection, error) {
+	log.Warnf("Branch protection is not supported for Gitea\n")
+	return nil, nil
 }
 
-func (p *GiteaProvider) AcceptInvitation(ID int64) (*github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Gitea.\n")
-	return &github.Response{}, nil
+// SetBranchProtection is not supported for Gitea so the branch is left unprotected
+func (p *GiteaProvider) SetBranchProtection(org string, repo string, branch string, protection *BranchProtection) error {
+	log.Warnf("Branch protection is not supported for Gitea\n")
+	return nil
 }
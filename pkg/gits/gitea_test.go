@@ -0,0 +1,101 @@
+package gits_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/suite"
+)
+
+type GiteaProviderTestSuite struct {
+	suite.Suite
+	mux      *http.ServeMux
+	server   *httptest.Server
+	provider *gits.GiteaProvider
+}
+
+var giteaRouter = util.Router{
+	"/api/v1/repos/test-user/test-repo/statuses/5c8afc5fdabc7e82350f8d391c5767066aa1b6ac": util.MethodMap{
+		"POST": "status.json",
+	},
+	"/api/v1/repos/test-user/test-repo/collaborators/derek": util.MethodMap{
+		"PUT": "collaborator.nil.json",
+	},
+}
+
+func (suite *GiteaProviderTestSuite) SetupSuite() {
+	suite.mux = http.NewServeMux()
+
+	for path, methodMap := range giteaRouter {
+		suite.mux.HandleFunc(path, util.GetMockAPIResponseFromFile("test_data/gitea", methodMap))
+	}
+
+	suite.server = httptest.NewServer(suite.mux)
+	suite.Require().NotNil(suite.server)
+
+	as := auth.AuthServer{
+		URL:         suite.server.URL,
+		Name:        "Test Auth Server",
+		Kind:        "gitea",
+		CurrentUser: "test-user",
+	}
+	ua := auth.UserAuth{
+		Username: "test-user",
+		ApiToken: "0123456789abdef",
+	}
+
+	git := gits.NewGitCLI()
+	gp, err := gits.NewGiteaProvider(&as, &ua, git)
+
+	suite.Require().NotNil(gp)
+	suite.Require().Nil(err)
+
+	var ok bool
+	suite.provider, ok = gp.(*gits.GiteaProvider)
+	suite.Require().True(ok)
+	suite.Require().NotNil(suite.provider)
+}
+
+func (suite *GiteaProviderTestSuite) TestUpdateCommitStatus() {
+	status := &gits.GitRepoStatus{
+		Context:     "compliance-check",
+		State:       "pending",
+		TargetURL:   "https://jenkins.example.com/job/test-repo/1",
+		Description: "Compliance checks running",
+	}
+	result, err := suite.provider.UpdateCommitStatus("test-user", "test-repo", "5c8afc5fdabc7e82350f8d391c5767066aa1b6ac", status)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(result)
+	suite.Require().Equal("7", result.ID)
+	suite.Require().Equal("compliance-check", result.Context)
+	suite.Require().Equal("pending", result.State)
+	suite.Require().Equal("https://jenkins.example.com/job/test-repo/1", result.TargetURL)
+}
+
+func (suite *GiteaProviderTestSuite) TestAddCollaborator() {
+	err := suite.provider.AddCollaborator("derek", "test-user", "test-repo")
+	suite.Require().Nil(err)
+}
+
+func (suite *GiteaProviderTestSuite) TestListInvitations() {
+	invites, err := suite.provider.ListInvitations()
+	suite.Require().NotNil(invites)
+	suite.Require().Nil(err)
+}
+
+func TestGiteaProviderTestSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping GiteaProviderTestSuite in short mode")
+	} else {
+		suite.Run(t, new(GiteaProviderTestSuite))
+	}
+}
+
+func (suite *GiteaProviderTestSuite) TearDownSuite() {
+	suite.server.Close()
+}
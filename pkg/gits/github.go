@@ -946,13 +946,84 @@ func (p *GitHubProvider) AddCollaborator(user string, organisation string, repo
 	return nil
 }
 
-func (p *GitHubProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
-	return p.Client.Users.ListInvitations(p.Context, &github.ListOptions{})
+func (p *GitHubProvider) ListInvitations() ([]*GitInvitation, error) {
+	answer := []*GitInvitation{}
+	invites, _, err := p.Client.Users.ListInvitations(p.Context, &github.ListOptions{})
+	if err != nil {
+		return answer, err
+	}
+	for _, invite := range invites {
+		answer = append(answer, fromGithubInvitation(invite))
+	}
+	return answer, nil
 }
 
-func (p *GitHubProvider) AcceptInvitation(ID int64) (*github.Response, error) {
+func fromGithubInvitation(invite *github.RepositoryInvitation) *GitInvitation {
+	answer := &GitInvitation{
+		ID:          invite.GetID(),
+		Permissions: invite.GetPermissions(),
+		URL:         invite.GetURL(),
+		HTMLURL:     invite.GetHTMLURL(),
+		Invitee:     toGitHubUser(invite.Invitee),
+		Inviter:     toGitHubUser(invite.Inviter),
+	}
+	if invite.Repo != nil {
+		answer.Organisation = invite.Repo.GetOwner().GetLogin()
+		answer.Repo = invite.Repo.GetName()
+	}
+	if invite.CreatedAt != nil {
+		t := invite.CreatedAt.Time
+		answer.CreatedAt = &t
+	}
+	return answer
+}
+
+func (p *GitHubProvider) AcceptInvitation(ID int64) error {
 	log.Infof("Automatically accepted invitation: %v for the pipeline user.\n", ID)
-	return p.Client.Users.AcceptInvitation(p.Context, ID)
+	_, err := p.Client.Users.AcceptInvitation(p.Context, ID)
+	return err
+}
+
+// GetBranchProtection returns the protection of the branch or nil if the branch is not protected
+func (p *GitHubProvider) GetBranchProtection(org string, repo string, branch string) (*BranchProtection, error) {
+	protection, r, err := p.Client.Repositories.GetBranchProtection(p.Context, org, repo, branch)
+	if r != nil && r.StatusCode == 404 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	answer := &BranchProtection{}
+	if protection.RequiredStatusChecks != nil {
+		answer.RequiredStatusContexts = protection.RequiredStatusChecks.Contexts
+	}
+	if protection.RequiredPullRequestReviews != nil {
+		answer.RequiredApprovingReviewCount = protection.RequiredPullRequestReviews.RequiredApprovingReviewCount
+	}
+	if protection.EnforceAdmins != nil {
+		answer.EnforceAdmins = protection.EnforceAdmins.Enabled
+	}
+	return answer, nil
+}
+
+// SetBranchProtection protects the branch so that changes can only be merged once the given checks pass
+func (p *GitHubProvider) SetBranchProtection(org string, repo string, branch string, protection *BranchProtection) error {
+	request := &github.ProtectionRequest{
+		EnforceAdmins: protection.EnforceAdmins,
+	}
+	if len(protection.RequiredStatusContexts) > 0 {
+		request.RequiredStatusChecks = &github.RequiredStatusChecks{
+			Strict:   true,
+			Contexts: protection.RequiredStatusContexts,
+		}
+	}
+	if protection.RequiredApprovingReviewCount > 0 {
+		request.RequiredPullRequestReviews = &github.PullRequestReviewsEnforcementRequest{
+			RequiredApprovingReviewCount: protection.RequiredApprovingReviewCount,
+		}
+	}
+	_, _, err := p.Client.Repositories.UpdateBranchProtection(p.Context, org, repo, branch, request)
+	return err
 }
 
 func asBool(b *bool) bool {
@@ -1,14 +1,17 @@
 package gits
 
 import (
+	"bytes"
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/google/go-github/github"
 	"github.com/jenkins-x/jx/pkg/auth"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
@@ -368,15 +371,54 @@ func (g *GitlabProvider) ListCommitStatus(org string, repo string, sha string) (
 	return statuses, nil
 }
 
-func (b *GitlabProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
-	return &GitRepoStatus{}, errors.New("TODO")
+func (g *GitlabProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
+	pid, err := g.projectId(org, g.Username, repo)
+	if err != nil {
+		return &GitRepoStatus{}, err
+	}
+	state, err := toGitlabBuildState(status.State)
+	if err != nil {
+		return &GitRepoStatus{}, err
+	}
+	opt := &gitlab.SetCommitStatusOptions{
+		State:       state,
+		Name:        &status.Context,
+		Context:     &status.Context,
+		TargetURL:   &status.TargetURL,
+		Description: &status.Description,
+	}
+	result, _, err := g.Client.Commits.SetCommitStatus(pid, sha, opt)
+	if err != nil {
+		return &GitRepoStatus{}, err
+	}
+	return fromCommitStatus(result), nil
+}
+
+// toGitlabBuildState maps a GitRepoStatus state onto the GitLab commit status states
+func toGitlabBuildState(state string) (gitlab.BuildStateValue, error) {
+	switch state {
+	case "pending":
+		return gitlab.Pending, nil
+	case "success":
+		return gitlab.Success, nil
+	case "error", "failure":
+		return gitlab.Failed, nil
+	default:
+		return "", fmt.Errorf("unsupported commit status state %s", state)
+	}
 }
 
 func fromCommitStatus(status *gitlab.CommitStatus) *GitRepoStatus {
+	state := status.Status
+	if state == string(gitlab.Failed) {
+		state = "failure"
+	}
 	return &GitRepoStatus{
-		ID:          string(status.ID),
+		ID:          strconv.Itoa(status.ID),
+		Context:     status.Name,
 		URL:         status.TargetURL,
-		State:       status.Status,
+		State:       state,
+		TargetURL:   status.TargetURL,
 		Description: status.Description,
 	}
 }
@@ -610,19 +652,196 @@ func (p *GitlabProvider) IssueURL(org string, name string, number int, isPull bo
 	return ""
 }
 
+// AddCollaborator adds the user as a developer member of the project
 func (p *GitlabProvider) AddCollaborator(user string, organisation string, repo string) error {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for gitlab. Please add user: %v as a collaborator to this project.\n", user)
+	pid, err := p.projectId(organisation, p.Username, repo)
+	if err != nil {
+		return err
+	}
+	users, _, err := p.Client.Users.ListUsers(&gitlab.ListUsersOptions{Username: &user})
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return fmt.Errorf("no GitLab user found with username %s", user)
+	}
+	log.Infof("Automatically adding the pipeline user: %v as a member of the project.\n", user)
+	opt := &gitlab.AddProjectMemberOptions{
+		UserID:      &users[0].ID,
+		AccessLevel: gitlab.AccessLevel(gitlab.DeveloperPermissions),
+	}
+	_, resp, err := p.Client.ProjectMembers.AddProjectMember(pid, opt)
+	if resp != nil && resp.StatusCode == http.StatusConflict {
+		// the user is already a member of the project
+		return nil
+	}
+	return err
+}
+
+// ListInvitations returns no invitations as GitLab adds members to projects directly
+func (p *GitlabProvider) ListInvitations() ([]*GitInvitation, error) {
+	return []*GitInvitation{}, nil
+}
+
+func (p *GitlabProvider) AcceptInvitation(ID int64) error {
 	return nil
 }
 
-func (p *GitlabProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for gitlab.\n")
-	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+// gitlabApprovalsOptions are the merge request approval settings of a project
+type gitlabApprovalsOptions struct {
+	ApprovalsBeforeMerge *int `url:"approvals_before_merge,omitempty" json:"approvals_before_merge,omitempty"`
+}
+
+// gitlabAccessLevel is an access level of a protected branch. The vendored client does not expose the IDs
+// needed to update the access levels of a protected branch
+type gitlabAccessLevel struct {
+	ID          int                      `json:"id,omitempty"`
+	AccessLevel *gitlab.AccessLevelValue `json:"access_level,omitempty"`
+	Destroy     bool                     `json:"_destroy,omitempty"`
+}
+
+type gitlabProtectedBranch struct {
+	Name              string              `json:"name"`
+	PushAccessLevels  []gitlabAccessLevel `json:"push_access_levels"`
+	MergeAccessLevels []gitlabAccessLevel `json:"merge_access_levels"`
+}
+
+// gitlabProtectedBranchUpdate changes the access levels of a protected branch in place
+type gitlabProtectedBranchUpdate struct {
+	AllowedToPush  []gitlabAccessLevel `json:"allowed_to_push,omitempty"`
+	AllowedToMerge []gitlabAccessLevel `json:"allowed_to_merge,omitempty"`
 }
 
-func (p *GitlabProvider) AcceptInvitation(ID int64) (*github.Response, error) {
-	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for gitlab.\n")
-	return &github.Response{}, nil
+// GetBranchProtection returns the protection of the branch or nil if the branch is not protected.
+// GitLab requires the whole pipeline to succeed rather than individual status contexts so none are returned.
+func (p *GitlabProvider) GetBranchProtection(org string, repo string, branch string) (*BranchProtection, error) {
+	pid, err := p.projectId(org, p.Username, repo)
+	if err != nil {
+		return nil, err
+	}
+	protectedBranch, resp, err := p.Client.ProtectedBranches.GetProtectedBranch(pid, branch)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	project, _, err := p.Client.Projects.GetProject(pid)
+	if err != nil {
+		return nil, err
+	}
+	// nobody, not even maintainers, may push directly to the branch
+	enforceAdmins := len(protectedBranch.PushAccessLevels) > 0
+	for _, level := range protectedBranch.PushAccessLevels {
+		if level.AccessLevel != gitlab.NoPermissions {
+			enforceAdmins = false
+		}
+	}
+	return &BranchProtection{
+		RequiredApprovingReviewCount: project.ApprovalsBeforeMerge,
+		EnforceAdmins:                enforceAdmins,
+	}, nil
+}
+
+// SetBranchProtection protects the branch so that changes can only be merged once the given checks pass.
+// Required status contexts make the whole pipeline required as GitLab cannot require individual contexts.
+func (p *GitlabProvider) SetBranchProtection(org string, repo string, branch string, protection *BranchProtection) error {
+	pid, err := p.projectId(org, p.Username, repo)
+	if err != nil {
+		return err
+	}
+
+	pushAccessLevel := gitlab.MasterPermissions
+	if protection.EnforceAdmins {
+		pushAccessLevel = gitlab.NoPermissions
+	}
+	mergeAccessLevel := gitlab.DeveloperPermissions
+
+	protectedBranch := &gitlabProtectedBranch{}
+	req, err := p.Client.NewRequest(http.MethodGet, fmt.Sprintf("projects/%s/protected_branches/%s", pid, url.PathEscape(branch)), nil, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.Client.Do(req, protectedBranch)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		_, _, err = p.Client.ProtectedBranches.ProtectRepositoryBranches(pid, &gitlab.ProtectRepositoryBranchesOptions{
+			Name:             &branch,
+			PushAccessLevel:  gitlab.AccessLevel(pushAccessLevel),
+			MergeAccessLevel: gitlab.AccessLevel(mergeAccessLevel),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to protect branch %s: %s", branch, err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to get the protection of branch %s: %s", branch, err)
+	} else {
+		// update the existing protection in place so the branch is never left unprotected
+		update := gitlabProtectedBranchUpdate{
+			AllowedToPush:  gitlabAccessLevelChanges(protectedBranch.PushAccessLevels, pushAccessLevel),
+			AllowedToMerge: gitlabAccessLevelChanges(protectedBranch.MergeAccessLevels, mergeAccessLevel),
+		}
+		if len(update.AllowedToPush) > 0 || len(update.AllowedToMerge) > 0 {
+			body, err := json.Marshal(update)
+			if err != nil {
+				return err
+			}
+			// the vendored client only sends a JSON body for POST and PUT requests
+			req, err := p.Client.NewRequest(http.MethodPatch, fmt.Sprintf("projects/%s/protected_branches/%s", pid, url.PathEscape(branch)), nil, nil)
+			if err != nil {
+				return err
+			}
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			req.ContentLength = int64(len(body))
+			req.Header.Set("Content-Type", "application/json")
+			_, err = p.Client.Do(req, nil)
+			if err != nil {
+				return fmt.Errorf("failed to update the protection of branch %s: %s", branch, err)
+			}
+		}
+	}
+
+	// the pipeline requirement is left as it is when no status contexts are required
+	if len(protection.RequiredStatusContexts) > 0 {
+		_, _, err = p.Client.Projects.EditProject(pid, &gitlab.EditProjectOptions{
+			OnlyAllowMergeIfPipelineSucceeds: gitlab.Bool(true),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to require successful pipelines for %s: %s", repo, err)
+		}
+	}
+
+	if protection.RequiredApprovingReviewCount > 0 {
+		// merge request approvals are not supported by the vendored client
+		options := &gitlabApprovalsOptions{
+			ApprovalsBeforeMerge: &protection.RequiredApprovingReviewCount,
+		}
+		req, err := p.Client.NewRequest(http.MethodPost, fmt.Sprintf("projects/%s/approvals", pid), options, nil)
+		if err != nil {
+			return err
+		}
+		_, err = p.Client.Do(req, nil)
+		if err != nil {
+			return fmt.Errorf("failed to require %d approvals for %s: %s", protection.RequiredApprovingReviewCount, repo, err)
+		}
+	}
+	return nil
+}
+
+// gitlabAccessLevelChanges returns the changes which replace the current access levels with the given access level
+func gitlabAccessLevelChanges(current []gitlabAccessLevel, level gitlab.AccessLevelValue) []gitlabAccessLevel {
+	answer := []gitlabAccessLevel{}
+	found := false
+	for _, c := range current {
+		if c.AccessLevel != nil && *c.AccessLevel == level {
+			found = true
+			continue
+		}
+		answer = append(answer, gitlabAccessLevel{ID: c.ID, Destroy: true})
+	}
+	if !found {
+		answer = append(answer, gitlabAccessLevel{AccessLevel: gitlab.AccessLevel(level)})
+	}
+	return answer
 }
 
 // GitlabAccessTokenURL returns the URL to click on to generate a personal access token for the Git provider
@@ -20,6 +20,7 @@ const (
 	gitlabOrgName     = "testorg"
 	gitlabProjectName = "test-project"
 	gitlabProjectID   = "5690870"
+	gitlabCommitSHA   = "18f3e63d05582537db6d183d9d557be09e1f90c8"
 )
 
 type GitlabProviderSuite struct {
@@ -96,6 +97,26 @@ func configureGitlabMock(suite *GitlabProviderSuite, mux *http.ServeMux) {
 	gitlabRouter := util.Router{
 		fmt.Sprintf("/api/v4/projects/%s", gitlabProjectID): util.MethodMap{
 			"GET": "project.json",
+			"PUT": "project.json",
+		},
+		fmt.Sprintf("/api/v4/projects/%s/protected_branches", gitlabProjectID): util.MethodMap{
+			"POST": "protected-branch.json",
+		},
+		fmt.Sprintf("/api/v4/projects/%s/protected_branches/master", gitlabProjectID): util.MethodMap{
+			"GET":   "protected-branch.json",
+			"PATCH": "protected-branch.json",
+		},
+		fmt.Sprintf("/api/v4/projects/%s/approvals", gitlabProjectID): util.MethodMap{
+			"POST": "approvals.json",
+		},
+		fmt.Sprintf("/api/v4/projects/%s/statuses/%s", gitlabProjectID, gitlabCommitSHA): util.MethodMap{
+			"POST": "commit-status.json",
+		},
+		fmt.Sprintf("/api/v4/projects/%s/members", gitlabProjectID): util.MethodMap{
+			"POST": "project-member.json",
+		},
+		"/api/v4/users": util.MethodMap{
+			"GET": "users.json",
 		},
 	}
 	for path, methodMap := range gitlabRouter {
@@ -154,24 +175,79 @@ func (suite *GitlabProviderSuite) TestGetRepository() {
 	suite.Require().Equal(gitlabProjectName, repo.Name)
 }
 
+func (suite *GitlabProviderSuite) TestUpdateCommitStatus() {
+	status := &gits.GitRepoStatus{
+		Context:     "compliance-check",
+		State:       "success",
+		TargetURL:   "https://jenkins.example.com/job/test-project/1",
+		Description: "Compliance checks passed",
+	}
+	result, err := suite.provider.UpdateCommitStatus(gitlabUserName, gitlabProjectName, gitlabCommitSHA, status)
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(result)
+	suite.Require().Equal("93", result.ID)
+	suite.Require().Equal("compliance-check", result.Context)
+	suite.Require().Equal("success", result.State)
+	suite.Require().Equal("https://jenkins.example.com/job/test-project/1", result.TargetURL)
+}
+
 func (suite *GitlabProviderSuite) TestAddCollaborator() {
-	err := suite.provider.AddCollaborator("derek", orgName, "repo")
+	err := suite.provider.AddCollaborator("derek", gitlabOrgName, gitlabProjectName)
 	suite.Require().Nil(err)
 }
 
 func (suite *GitlabProviderSuite) TestListInvitations() {
-	invites, res, err := suite.provider.ListInvitations()
+	invites, err := suite.provider.ListInvitations()
 	suite.Require().NotNil(invites)
-	suite.Require().NotNil(res)
 	suite.Require().Nil(err)
 }
 
 func (suite *GitlabProviderSuite) TestAcceptInvitations() {
-	res, err := suite.provider.AcceptInvitation(1)
-	suite.Require().NotNil(res)
+	err := suite.provider.AcceptInvitation(1)
 	suite.Require().Nil(err)
 }
 
+func (suite *GitlabProviderSuite) TestGetBranchProtection() {
+	protection, err := suite.provider.GetBranchProtection(gitlabOrgName, gitlabProjectName, "master")
+
+	suite.Require().Nil(err)
+	suite.Require().NotNil(protection)
+	suite.Require().True(protection.EnforceAdmins)
+	suite.Require().Equal(0, protection.RequiredApprovingReviewCount)
+}
+
+func (suite *GitlabProviderSuite) TestSetBranchProtection() {
+	protection := &gits.BranchProtection{
+		RequiredStatusContexts:       []string{"continuous-integration/jenkins/pr-merge"},
+		RequiredApprovingReviewCount: 2,
+		EnforceAdmins:                true,
+	}
+	err := suite.provider.SetBranchProtection(gitlabOrgName, gitlabProjectName, "master", protection)
+	suite.Require().Nil(err)
+}
+
+func (suite *GitlabProviderSuite) TestSetBranchProtectionUpdatesInPlace() {
+	requests := recordRequests(suite.server, suite.mux)
+	defer func() {
+		suite.server.Config.Handler = suite.mux
+	}()
+
+	protection := &gits.BranchProtection{
+		EnforceAdmins: false,
+	}
+	err := suite.provider.SetBranchProtection(gitlabOrgName, gitlabProjectName, "master", protection)
+	suite.Require().Nil(err)
+
+	path := fmt.Sprintf("/api/v4/projects/%s/protected_branches/master", gitlabProjectID)
+	updates := findRecordedRequests(*requests, http.MethodPatch, path)
+	suite.Require().Len(updates, 1)
+	suite.JSONEq(`{"allowed_to_push": [{"id": 1, "_destroy": true}, {"access_level": 40}]}`, updates[0].Body,
+		"maintainers should be allowed to push and the merge access level left alone")
+	suite.Empty(findRecordedRequests(*requests, http.MethodDelete, path), "the branch should not be unprotected")
+	suite.Empty(findRecordedRequests(*requests, http.MethodPost, fmt.Sprintf("/api/v4/projects/%s/protected_branches", gitlabProjectID)))
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestGitlabProviderSuite(t *testing.T) {
@@ -4,7 +4,6 @@ import (
 	"io"
 	"time"
 
-	"github.com/google/go-github/github"
 	"github.com/jenkins-x/jx/pkg/auth"
 	gitcfg "gopkg.in/src-d/go-git.v4/config"
 )
@@ -130,11 +129,20 @@ type GitProvider interface {
 	// Returns user info, if possible
 	UserInfo(username string) *GitUser
 
-	AddCollaborator(string, string, string) error
-	// TODO Refactor to remove bespoke types when we implement another provider
-	ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error)
-	// TODO Refactor to remove bespoke types when we implement another provider
-	AcceptInvitation(int64) (*github.Response, error)
+	// AddCollaborator grants the user write access to the repository
+	AddCollaborator(user string, organisation string, repo string) error
+
+	// ListInvitations returns the pending repository invitations of the current user
+	ListInvitations() ([]*GitInvitation, error)
+
+	// AcceptInvitation accepts the repository invitation with the given ID for the current user
+	AcceptInvitation(ID int64) error
+
+	// GetBranchProtection returns the protection of the branch or nil if the branch is not protected
+	GetBranchProtection(org string, repo string, branch string) (*BranchProtection, error)
+
+	// SetBranchProtection protects the branch so that changes can only be merged once the given checks pass
+	SetBranchProtection(org string, repo string, branch string, protection *BranchProtection) error
 }
 
 // Gitter defines common git actions used by Jenkins X via git cli
@@ -204,6 +212,8 @@ type Gitter interface {
 	FetchTags(dir string) error
 	Tags(dir string) ([]string, error)
 	CreateTag(dir string, tag string, msg string) error
+	GetCommitMessages(dir string, fromRevision string) ([]string, error)
+	GetChangedFiles(dir string, fromRevision string, toRevision string) ([]string, error)
 
 	GetRevisionBeforeDate(dir string, t time.Time) (string, error)
 	GetRevisionBeforeDateText(dir string, dateText string) (string, error)
@@ -4,7 +4,6 @@
 package gits_test
 
 import (
-	github "github.com/google/go-github/github"
 	auth "github.com/jenkins-x/jx/pkg/auth"
 	gits "github.com/jenkins-x/jx/pkg/gits"
 	pegomock "github.com/petergtz/pegomock"
@@ -20,23 +19,19 @@ func NewMockGitProvider() *MockGitProvider {
 	return &MockGitProvider{fail: pegomock.GlobalFailHandler}
 }
 
-func (mock *MockGitProvider) AcceptInvitation(_param0 int64) (*github.Response, error) {
+func (mock *MockGitProvider) AcceptInvitation(_param0 int64) error {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockGitProvider().")
 	}
 	params := []pegomock.Param{_param0}
-	result := pegomock.GetGenericMockFrom(mock).Invoke("AcceptInvitation", params, []reflect.Type{reflect.TypeOf((**github.Response)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
-	var ret0 *github.Response
-	var ret1 error
+	result := pegomock.GetGenericMockFrom(mock).Invoke("AcceptInvitation", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
 	if len(result) != 0 {
 		if result[0] != nil {
-			ret0 = result[0].(*github.Response)
-		}
-		if result[1] != nil {
-			ret1 = result[1].(error)
+			ret0 = result[0].(error)
 		}
 	}
-	return ret0, ret1
+	return ret0
 }
 
 func (mock *MockGitProvider) AddCollaborator(_param0 string, _param1 string, _param2 string) error {
@@ -220,6 +215,25 @@ func (mock *MockGitProvider) ForkRepository(_param0 string, _param1 string, _par
 	return ret0, ret1
 }
 
+func (mock *MockGitProvider) GetBranchProtection(_param0 string, _param1 string, _param2 string) (*gits.BranchProtection, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockGitProvider().")
+	}
+	params := []pegomock.Param{_param0, _param1, _param2}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("GetBranchProtection", params, []reflect.Type{reflect.TypeOf((**gits.BranchProtection)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 *gits.BranchProtection
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(*gits.BranchProtection)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
 func (mock *MockGitProvider) GetIssue(_param0 string, _param1 string, _param2 int) (*gits.GitIssue, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockGitProvider().")
@@ -465,27 +479,23 @@ func (mock *MockGitProvider) ListCommitStatus(_param0 string, _param1 string, _p
 	return ret0, ret1
 }
 
-func (mock *MockGitProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
+func (mock *MockGitProvider) ListInvitations() ([]*gits.GitInvitation, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockGitProvider().")
 	}
 	params := []pegomock.Param{}
-	result := pegomock.GetGenericMockFrom(mock).Invoke("ListInvitations", params, []reflect.Type{reflect.TypeOf((*[]*github.RepositoryInvitation)(nil)).Elem(), reflect.TypeOf((**github.Response)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
-	var ret0 []*github.RepositoryInvitation
-	var ret1 *github.Response
-	var ret2 error
+	result := pegomock.GetGenericMockFrom(mock).Invoke("ListInvitations", params, []reflect.Type{reflect.TypeOf((*[]*gits.GitInvitation)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 []*gits.GitInvitation
+	var ret1 error
 	if len(result) != 0 {
 		if result[0] != nil {
-			ret0 = result[0].([]*github.RepositoryInvitation)
+			ret0 = result[0].([]*gits.GitInvitation)
 		}
 		if result[1] != nil {
-			ret1 = result[1].(*github.Response)
-		}
-		if result[2] != nil {
-			ret2 = result[2].(error)
+			ret1 = result[1].(error)
 		}
 	}
-	return ret0, ret1, ret2
+	return ret0, ret1
 }
 
 func (mock *MockGitProvider) ListOrganisations() ([]gits.GitOrganisation, error) {
@@ -651,6 +661,21 @@ func (mock *MockGitProvider) ServerURL() string {
 	return ret0
 }
 
+func (mock *MockGitProvider) SetBranchProtection(_param0 string, _param1 string, _param2 string, _param3 *gits.BranchProtection) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockGitProvider().")
+	}
+	params := []pegomock.Param{_param0, _param1, _param2, _param3}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("SetBranchProtection", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
 func (mock *MockGitProvider) UpdateCommitStatus(_param0 string, _param1 string, _param2 string, _param3 *gits.GitRepoStatus) (*gits.GitRepoStatus, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockGitProvider().")
@@ -1137,6 +1162,41 @@ func (c *GitProvider_ForkRepository_OngoingVerification) GetAllCapturedArguments
 	return
 }
 
+func (verifier *VerifierGitProvider) GetBranchProtection(_param0 string, _param1 string, _param2 string) *GitProvider_GetBranchProtection_OngoingVerification {
+	params := []pegomock.Param{_param0, _param1, _param2}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "GetBranchProtection", params)
+	return &GitProvider_GetBranchProtection_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type GitProvider_GetBranchProtection_OngoingVerification struct {
+	mock              *MockGitProvider
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *GitProvider_GetBranchProtection_OngoingVerification) GetCapturedArguments() (string, string, string) {
+	_param0, _param1, _param2 := c.GetAllCapturedArguments()
+	return _param0[len(_param0)-1], _param1[len(_param1)-1], _param2[len(_param2)-1]
+}
+
+func (c *GitProvider_GetBranchProtection_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []string, _param2 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]string, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]string, len(params[2]))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+	}
+	return
+}
+
 func (verifier *VerifierGitProvider) GetIssue(_param0 string, _param1 string, _param2 int) *GitProvider_GetIssue_OngoingVerification {
 	params := []pegomock.Param{_param0, _param1, _param2}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "GetIssue", params)
@@ -1786,6 +1846,45 @@ func (c *GitProvider_ServerURL_OngoingVerification) GetCapturedArguments() {
 func (c *GitProvider_ServerURL_OngoingVerification) GetAllCapturedArguments() {
 }
 
+func (verifier *VerifierGitProvider) SetBranchProtection(_param0 string, _param1 string, _param2 string, _param3 *gits.BranchProtection) *GitProvider_SetBranchProtection_OngoingVerification {
+	params := []pegomock.Param{_param0, _param1, _param2, _param3}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "SetBranchProtection", params)
+	return &GitProvider_SetBranchProtection_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type GitProvider_SetBranchProtection_OngoingVerification struct {
+	mock              *MockGitProvider
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *GitProvider_SetBranchProtection_OngoingVerification) GetCapturedArguments() (string, string, string, *gits.BranchProtection) {
+	_param0, _param1, _param2, _param3 := c.GetAllCapturedArguments()
+	return _param0[len(_param0)-1], _param1[len(_param1)-1], _param2[len(_param2)-1], _param3[len(_param3)-1]
+}
+
+func (c *GitProvider_SetBranchProtection_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []string, _param2 []string, _param3 []*gits.BranchProtection) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]string, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]string, len(params[2]))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+		_param3 = make([]*gits.BranchProtection, len(params[3]))
+		for u, param := range params[3] {
+			_param3[u] = param.(*gits.BranchProtection)
+		}
+	}
+	return
+}
+
 func (verifier *VerifierGitProvider) UpdateCommitStatus(_param0 string, _param1 string, _param2 string, _param3 *gits.GitRepoStatus) *GitProvider_UpdateCommitStatus_OngoingVerification {
 	params := []pegomock.Param{_param0, _param1, _param2, _param3}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "UpdateCommitStatus", params)
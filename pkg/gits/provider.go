@@ -87,6 +87,30 @@ type GitUser struct {
 	AvatarURL string
 }
 
+// GitInvitation is an invitation for a user to collaborate on a repository
+type GitInvitation struct {
+	ID           int64
+	Organisation string
+	Repo         string
+	Invitee      *GitUser
+	Inviter      *GitUser
+	// Permissions the invited user will have on the repository such as read, write or admin
+	Permissions string
+	URL         string
+	HTMLURL     string
+	CreatedAt   *time.Time
+}
+
+// BranchProtection describes the checks a branch requires before changes can be merged into it
+type BranchProtection struct {
+	// RequiredStatusContexts are the commit status contexts which must succeed before merging
+	RequiredStatusContexts []string
+	// RequiredApprovingReviewCount is the number of approving reviews required before merging
+	RequiredApprovingReviewCount int
+	// EnforceAdmins applies the protection to repository administrators too
+	EnforceAdmins bool
+}
+
 type GitRelease struct {
 	Name          string
 	TagName       string
@@ -147,6 +171,10 @@ func CreateProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (G
 		return NewGiteaProvider(server, user, git)
 	} else if server.Kind == KindGitlab {
 		return NewGitlabProvider(server, user, git)
+	} else if server.Kind == KindGerrit {
+		return NewGerritProvider(server, user, git)
+	} else if server.Kind == KindAzureDevOps {
+		return NewAzureDevOpsProvider(server, user, git)
 	} else {
 		return NewGitHubProvider(server, user, git)
 	}
@@ -179,6 +207,10 @@ func ProviderAccessTokenURL(kind string, url string, username string) string {
 		return GiteaAccessTokenURL(url)
 	case KindGitlab:
 		return GitlabAccessTokenURL(url)
+	case KindGerrit:
+		return GerritAccessTokenURL(url)
+	case KindAzureDevOps:
+		return AzureDevOpsAccessTokenURL(url)
 	default:
 		return GitHubAccessTokenURL(url)
 	}
@@ -7,7 +7,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/google/go-github/github"
 	"github.com/jenkins-x/jx/pkg/auth"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
@@ -60,6 +59,7 @@ type FakeRepository struct {
 	issueCount         int
 	Releases           map[string]*GitRelease
 	PullRequestCounter int
+	BranchProtections  map[string]*BranchProtection
 }
 
 type FakeProvider struct {
@@ -584,14 +584,43 @@ func (f *FakeProvider) AddCollaborator(user string, organisation string, repo st
 	return nil
 }
 
-func (f *FakeProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
+func (f *FakeProvider) ListInvitations() ([]*GitInvitation, error) {
 	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for git fake.\n")
-	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+	return []*GitInvitation{}, nil
 }
 
-func (f *FakeProvider) AcceptInvitation(ID int64) (*github.Response, error) {
+func (f *FakeProvider) AcceptInvitation(ID int64) error {
 	log.Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for git fake.\n")
-	return &github.Response{}, nil
+	return nil
+}
+
+func (f *FakeProvider) GetBranchProtection(org string, repo string, branch string) (*BranchProtection, error) {
+	fakeRepo, err := f.fakeRepository(org, repo)
+	if err != nil {
+		return nil, err
+	}
+	return fakeRepo.BranchProtections[branch], nil
+}
+
+func (f *FakeProvider) SetBranchProtection(org string, repo string, branch string, protection *BranchProtection) error {
+	fakeRepo, err := f.fakeRepository(org, repo)
+	if err != nil {
+		return err
+	}
+	if fakeRepo.BranchProtections == nil {
+		fakeRepo.BranchProtections = map[string]*BranchProtection{}
+	}
+	fakeRepo.BranchProtections[branch] = protection
+	return nil
+}
+
+func (f *FakeProvider) fakeRepository(org string, name string) (*FakeRepository, error) {
+	for _, repo := range f.Repositories[org] {
+		if repo.GitRepo.Name == name {
+			return repo, nil
+		}
+	}
+	return nil, fmt.Errorf("repository '%s' not found within the organization '%s'", name, org)
 }
 
 func (r *FakeRepository) String() string {
@@ -0,0 +1,397 @@
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultHistoryMax the default maximum number of revisions stored for each release
+const DefaultHistoryMax = 10
+
+// HelmSecrets implements helm actions without tiller like HelmTemplate while storing the manifest and values of
+// each revision of a release in a Secret like helm 3 so that releases have a history and can be rolled back.
+// Like the --history-max of helm only the latest HistoryMax revisions are kept, all of them if it is zero
+type HelmSecrets struct {
+	*HelmTemplate
+	Storage    *ReleaseStorage
+	HistoryMax int
+}
+
+// NewHelmSecrets creates a new HelmSecrets instance configured to the given client side Helmer
+func NewHelmSecrets(client *HelmCLI, workDir string, kubeClient kubernetes.Interface) *HelmSecrets {
+	return &HelmSecrets{
+		HelmTemplate: NewHelmTemplate(client, workDir, kubeClient),
+		Storage:      NewReleaseStorage(kubeClient),
+		HistoryMax:   DefaultHistoryMax,
+	}
+}
+
+// InstallChart installs a helm chart according with the given flags and stores the new revision of the release
+func (h *HelmSecrets) InstallChart(chart string, releaseName string, ns string, version *string, timeout *int,
+	values []string, valueFiles []string) error {
+	return h.applyAndStore(chart, releaseName, ns, version, true, true, values, valueFiles)
+}
+
+// UpgradeChart upgrades a helm chart according with given helm flags and stores the new revision of the release
+func (h *HelmSecrets) UpgradeChart(chart string, releaseName string, ns string, version *string, install bool,
+	timeout *int, force bool, wait bool, values []string, valueFiles []string) error {
+	return h.applyAndStore(chart, releaseName, ns, version, false, wait, values, valueFiles)
+}
+
+func (h *HelmSecrets) applyAndStore(chart string, releaseName string, ns string, version *string, create bool, wait bool,
+	values []string, valueFiles []string) error {
+	latest, err := h.Storage.Latest(ns, releaseName)
+	if err != nil {
+		return err
+	}
+	templated, err := h.applyChart(chart, releaseName, ns, version, create, wait, values, valueFiles)
+	if templated == nil {
+		return err
+	}
+	manifest, err2 := readManifest(templated.OutputDir)
+	if err2 != nil {
+		return util.CombineErrors(err, err2)
+	}
+	release := &Release{
+		Name:         releaseName,
+		Namespace:    ns,
+		Revision:     1,
+		Chart:        templated.ChartName,
+		ChartVersion: templated.VersionText,
+		Updated:      time.Now(),
+		Values:       releaseValues(values, valueFiles),
+		Manifest:     manifest,
+	}
+	if latest != nil {
+		release.Revision = latest.Revision + 1
+	}
+	return util.CombineErrors(err, h.storeRelease(release, err))
+}
+
+// storeRelease stores the new revision of the release marking the previous revision as superseded if it succeeded
+// and removing the revisions beyond the history max
+func (h *HelmSecrets) storeRelease(release *Release, applyErr error) error {
+	if applyErr != nil {
+		release.Status = StatusFailed
+		release.Description = fmt.Sprintf("Failed: %s", applyErr)
+		err := h.Storage.Create(release)
+		if err != nil {
+			return err
+		}
+		return h.Storage.Prune(release.Namespace, release.Name, h.HistoryMax)
+	}
+	release.Status = StatusDeployed
+	if release.Description == "" {
+		release.Description = "Upgrade complete"
+		if release.Revision == 1 {
+			release.Description = "Install complete"
+		}
+	}
+	err := h.Storage.Create(release)
+	if err != nil {
+		return err
+	}
+	err = h.Storage.Supersede(release.Namespace, release.Name, release.Revision)
+	if err != nil {
+		return err
+	}
+	return h.Storage.Prune(release.Namespace, release.Name, h.HistoryMax)
+}
+
+// DeleteRelease removes the given release. If purge is true the history of the release is also removed otherwise
+// its latest revision is marked as deleted
+func (h *HelmSecrets) DeleteRelease(ns string, releaseName string, purge bool) error {
+	err := h.HelmTemplate.DeleteRelease(ns, releaseName, purge)
+	if err != nil {
+		return err
+	}
+	if purge {
+		return h.Storage.Delete(ns, releaseName)
+	}
+	latest, err := h.Storage.Latest(ns, releaseName)
+	if err != nil || latest == nil {
+		return err
+	}
+	latest.Status = StatusDeleted
+	latest.Description = "Deletion complete"
+	return h.Storage.Update(latest)
+}
+
+// StatusRelease logs the status of the latest revision of the release returning an error if it is not installed.
+// Releases installed by HelmTemplate before their revisions were stored are found from the labels of their deployments
+func (h *HelmSecrets) StatusRelease(ns string, releaseName string) error {
+	latest, err := h.Storage.Latest(ns, releaseName)
+	if err != nil {
+		return err
+	}
+	if latest == nil {
+		statusMap, err := h.HelmTemplate.StatusReleases(ns)
+		if err != nil {
+			return err
+		}
+		status, ok := statusMap[releaseName]
+		if !ok {
+			return fmt.Errorf("release %s not found in namespace %s", releaseName, ns)
+		}
+		log.Infof("NAMESPACE: %s\nSTATUS: %s\n", ns, status)
+		return nil
+	}
+	log.Infof("LAST DEPLOYED: %s\nNAMESPACE: %s\nSTATUS: %s\nREVISION: %d\n", latest.Updated.Format(time.RFC1123),
+		latest.Namespace, latest.Status, latest.Revision)
+	return nil
+}
+
+// StatusReleases returns the status of all the releases in the namespace including releases installed by
+// HelmTemplate before their revisions were stored
+func (h *HelmSecrets) StatusReleases(ns string) (map[string]string, error) {
+	statusMap, err := h.HelmTemplate.StatusReleases(ns)
+	if err != nil {
+		return statusMap, err
+	}
+	releases, err := h.Storage.List(ns)
+	if err != nil {
+		return statusMap, err
+	}
+	for _, r := range releases {
+		if r.Status == StatusDeleted {
+			delete(statusMap, r.Name)
+		} else {
+			statusMap[r.Name] = r.Status
+		}
+	}
+	return statusMap, nil
+}
+
+// ListCharts returns the releases in all namespaces in the same tab separated format as helm list
+func (h *HelmSecrets) ListCharts() (string, error) {
+	releases, err := h.Storage.List("")
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	buffer.WriteString("NAME\tREVISION\tUPDATED\tSTATUS\tCHART\tNAMESPACE\n")
+	for _, r := range releases {
+		if r.Status == StatusDeleted {
+			continue
+		}
+		buffer.WriteString(fmt.Sprintf("%s\t%d\t%s\t%s\t%s-%s\t%s\n", r.Name, r.Revision, r.Updated.Format(time.ANSIC), r.Status,
+			r.Chart, r.ChartVersion, r.Namespace))
+	}
+	return buffer.String(), nil
+}
+
+// History returns all the revisions of the release ordered by revision
+func (h *HelmSecrets) History(ns string, releaseName string) ([]*Release, error) {
+	return h.Storage.History(ns, releaseName)
+}
+
+// DiffRevisions returns a unified diff of the values and manifests of two revisions of the release
+func (h *HelmSecrets) DiffRevisions(ns string, releaseName string, fromRevision int, toRevision int) (string, error) {
+	from, err := h.getRevision(ns, releaseName, fromRevision)
+	if err != nil {
+		return "", err
+	}
+	to, err := h.getRevision(ns, releaseName, toRevision)
+	if err != nil {
+		return "", err
+	}
+	return DiffReleases(from, to)
+}
+
+// Rollback applies the manifest of an earlier revision of the release storing it as a new revision. Helm hooks are
+// not run when rolling back
+func (h *HelmSecrets) Rollback(ns string, releaseName string, revision int, wait bool) error {
+	target, err := h.getRevision(ns, releaseName, revision)
+	if err != nil {
+		return err
+	}
+	if target.Manifest == "" {
+		return fmt.Errorf("revision %d of release %s has no manifest to roll back to", revision, releaseName)
+	}
+	latest, err := h.Storage.Latest(ns, releaseName)
+	if err != nil {
+		return err
+	}
+
+	_, _, _, err = h.getDirectories(releaseName)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(h.WorkDir, releaseName, "rollback")
+	err = util.RecreateDirs(dir)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte(target.Manifest), util.DefaultWritePermissions)
+	if err != nil {
+		return err
+	}
+	log.Infof("Rolling back release %s to revision %s\n", util.ColorInfo(releaseName), util.ColorInfo(revision))
+	err = h.kubectlApply(ns, target.Chart, releaseName, wait, false, dir)
+	if err == nil {
+		err = h.deleteResourcesNotInManifest(ns, releaseName, target.Manifest, wait)
+	}
+
+	release := *target
+	release.Revision = latest.Revision + 1
+	release.Updated = time.Now()
+	release.Description = fmt.Sprintf("Rollback to %d", revision)
+	return util.CombineErrors(err, h.storeRelease(&release, err))
+}
+
+// deleteResourcesNotInManifest removes the resources of the release which are not in the manifest. Resources are
+// compared by kind and name rather than by chart version as later revisions may have added resources to the release
+// without changing the version of the chart
+func (h *HelmSecrets) deleteResourcesNotInManifest(ns string, releaseName string, manifest string, wait bool) error {
+	keep, err := manifestResources(manifest)
+	if err != nil {
+		return err
+	}
+	selector := LabelReleaseName + "=" + releaseName
+	log.Infof("Removing Kubernetes resources which are not in the manifest using selector: %s\n", util.ColorInfo(selector))
+	for _, kinds := range []string{"all", "release"} {
+		output, err := h.runKubectlWithOutput("get", kinds, "--ignore-not-found", "--namespace", ns, "-l", selector,
+			"-o", `jsonpath={range .items[*]}{.kind}/{.metadata.name}{"\n"}{end}`)
+		if err != nil {
+			if kinds == "release" {
+				// lets ignore failures - probably due to CRD not yet existing
+				continue
+			}
+			return errors.Wrapf(err, "failed to list the resources of release %s", releaseName)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			resource := strings.ToLower(strings.TrimSpace(line))
+			if resource == "" || keep[resource] {
+				continue
+			}
+			args := []string{"delete", resource, "--ignore-not-found", "--namespace", ns}
+			if wait {
+				args = append(args, "--wait")
+			}
+			err = h.runKubectl(args...)
+			if err != nil {
+				return errors.Wrapf(err, "failed to delete %s", resource)
+			}
+		}
+	}
+	return nil
+}
+
+// manifestResources returns the lower case kind/name of each resource in the manifest
+func manifestResources(manifest string) (map[string]bool, error) {
+	answer := map[string]bool{}
+	for _, doc := range strings.Split("\n"+manifest, "\n---") {
+		resource := struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}{}
+		err := yaml.Unmarshal([]byte(doc), &resource)
+		if err != nil {
+			return answer, errors.Wrap(err, "failed to parse the manifest")
+		}
+		if resource.Kind != "" && resource.Metadata.Name != "" {
+			answer[strings.ToLower(resource.Kind+"/"+resource.Metadata.Name)] = true
+		}
+	}
+	return answer, nil
+}
+
+func (h *HelmSecrets) getRevision(ns string, releaseName string, revision int) (*Release, error) {
+	release, err := h.Storage.Get(ns, releaseName, revision)
+	if err != nil {
+		return nil, err
+	}
+	if release == nil {
+		return nil, fmt.Errorf("revision %d of release %s not found in namespace %s", revision, releaseName, ns)
+	}
+	return release, nil
+}
+
+// DiffReleases returns a unified diff of the values and manifests of two revisions of a release
+func DiffReleases(from *Release, to *Release) (string, error) {
+	fromName := fmt.Sprintf("%s revision %d", from.Name, from.Revision)
+	toName := fmt.Sprintf("%s revision %d", to.Name, to.Revision)
+	values, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from.Values),
+		B:        difflib.SplitLines(to.Values),
+		FromFile: fromName + " values",
+		ToFile:   toName + " values",
+		Context:  3,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to diff the values")
+	}
+	manifests, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from.Manifest),
+		B:        difflib.SplitLines(to.Manifest),
+		FromFile: fromName + " manifest",
+		ToFile:   toName + " manifest",
+		Context:  3,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to diff the manifests")
+	}
+	return values + manifests, nil
+}
+
+// readManifest returns all the YAML files generated for a release in the directory as a single manifest
+func readManifest(dir string) (string, error) {
+	files := []string{}
+	err := filepath.Walk(dir, func(path string, f os.FileInfo, err error) error {
+		if err == nil && !f.IsDir() && filepath.Ext(path) == ".yaml" {
+			files = append(files, path)
+		}
+		return err
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the generated YAML in %s", dir)
+	}
+	sort.Strings(files)
+	var buffer bytes.Buffer
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to load file %s", file)
+		}
+		relPath, err := filepath.Rel(dir, file)
+		if err != nil {
+			return "", err
+		}
+		buffer.WriteString("---\n# Source: " + filepath.ToSlash(relPath) + "\n")
+		buffer.WriteString(strings.TrimSuffix(string(data), "\n") + "\n")
+	}
+	return buffer.String(), nil
+}
+
+// releaseValues returns the values set on the command line followed by the contents of the values files
+func releaseValues(values []string, valueFiles []string) string {
+	var buffer bytes.Buffer
+	for _, value := range values {
+		buffer.WriteString("# --set " + value + "\n")
+	}
+	for _, file := range valueFiles {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Warnf("Failed to load values file %s: %s\n", file, err)
+			continue
+		}
+		buffer.WriteString("---\n# Source: " + file + "\n")
+		buffer.WriteString(strings.TrimSuffix(string(data), "\n") + "\n")
+	}
+	return buffer.String()
+}
@@ -0,0 +1,121 @@
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/apps/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReadManifestAndValues(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-helm-secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	templatesDir := filepath.Join(dir, "myapp", "templates")
+	require.NoError(t, os.MkdirAll(templatesDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(templatesDir, "svc.yaml"), []byte("kind: Service\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(templatesDir, "deploy.yaml"), []byte("kind: Deployment"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(templatesDir, "NOTES.txt"), []byte("notes"), 0644))
+
+	manifest, err := readManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "---\n# Source: myapp/templates/deploy.yaml\nkind: Deployment\n---\n# Source: myapp/templates/svc.yaml\nkind: Service\n", manifest)
+
+	valuesFile := filepath.Join(dir, "values.yaml")
+	require.NoError(t, ioutil.WriteFile(valuesFile, []byte("replicaCount: 2\n"), 0644))
+	values := releaseValues([]string{"image.tag=1.0.0"}, []string{valuesFile})
+	assert.Equal(t, "# --set image.tag=1.0.0\n---\n# Source: "+valuesFile+"\nreplicaCount: 2\n", values)
+}
+
+func TestHelmSecretsStoreRelease(t *testing.T) {
+	t.Parallel()
+	ns := "jx-staging"
+	kubeClient := fake.NewSimpleClientset(&v1beta1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "legacy",
+			Namespace: ns,
+			Labels: map[string]string{
+				LabelReleaseName: "legacy",
+			},
+		},
+	})
+	h := &HelmSecrets{
+		HelmTemplate: &HelmTemplate{KubeClient: kubeClient},
+		Storage:      NewReleaseStorage(kubeClient),
+		HistoryMax:   3,
+	}
+
+	release := &Release{Name: "myapp", Namespace: ns, Revision: 1, Chart: "myapp", ChartVersion: "1.0.0", Updated: time.Now(),
+		Values: "replicaCount: 1\n", Manifest: "kind: Service\nport: 80\n"}
+	require.NoError(t, h.storeRelease(release, nil))
+	upgrade := *release
+	upgrade.Revision = 2
+	upgrade.Description = ""
+	upgrade.ChartVersion = "1.1.0"
+	upgrade.Manifest = "kind: Service\nport: 8080\n"
+	require.NoError(t, h.storeRelease(&upgrade, nil))
+	failed := upgrade
+	failed.Revision = 3
+	require.NoError(t, h.storeRelease(&failed, os.ErrNotExist))
+
+	history, err := h.History(ns, "myapp")
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, StatusSuperseded, history[0].Status)
+	assert.Equal(t, "Install complete", history[0].Description)
+	assert.Equal(t, StatusDeployed, history[1].Status)
+	assert.Equal(t, "Upgrade complete", history[1].Description)
+	assert.Equal(t, StatusFailed, history[2].Status)
+
+	statusMap, err := h.StatusReleases(ns)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"legacy": "DEPLOYED", "myapp": StatusFailed}, statusMap)
+	assert.NoError(t, h.StatusRelease(ns, "myapp"))
+	assert.NoError(t, h.StatusRelease(ns, "legacy"), "releases installed before their revisions were stored")
+	assert.Error(t, h.StatusRelease(ns, "missing"))
+
+	output, err := h.ListCharts()
+	require.NoError(t, err)
+	assert.Contains(t, output, "myapp\t3\t")
+	assert.Contains(t, output, "\tFAILED\tmyapp-1.1.0\tjx-staging\n")
+
+	diff, err := h.DiffRevisions(ns, "myapp", 1, 2)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "--- myapp revision 1 manifest\n+++ myapp revision 2 manifest\n")
+	assert.Contains(t, diff, "-port: 80\n+port: 8080\n")
+	assert.NotContains(t, diff, "values")
+
+	_, err = h.DiffRevisions(ns, "myapp", 1, 5)
+	assert.Error(t, err)
+
+	rollback := *history[1]
+	rollback.Revision = 4
+	rollback.Description = "Rollback to 2"
+	require.NoError(t, h.storeRelease(&rollback, nil))
+	history, err = h.History(ns, "myapp")
+	require.NoError(t, err)
+	require.Len(t, history, 3, "the oldest revisions beyond the history max should be removed")
+	assert.Equal(t, 2, history[0].Revision)
+	assert.Equal(t, StatusSuperseded, history[0].Status)
+	assert.Equal(t, StatusDeployed, history[2].Status)
+	assert.Equal(t, "Rollback to 2", history[2].Description)
+}
+
+func TestManifestResources(t *testing.T) {
+	t.Parallel()
+	manifest := "---\n# Source: myapp/templates/deployment.yaml\napiVersion: apps/v1beta1\nkind: Deployment\nmetadata:\n  name: jx-myapp\n" +
+		"---\n# Source: myapp/templates/NOTES.yaml\n" +
+		"---\n# Source: myapp/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: myapp\n  labels:\n    chart: myapp-0.0.1\n"
+	resources, err := manifestResources(manifest)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"deployment/jx-myapp": true, "service/myapp": true}, resources)
+}
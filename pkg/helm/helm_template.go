@@ -167,114 +167,91 @@ func (h *HelmTemplate) Version(tls bool) (string, error) {
 // InstallChart installs a helm chart according with the given flags
 func (h *HelmTemplate) InstallChart(chart string, releaseName string, ns string, version *string, timeout *int,
 	values []string, valueFiles []string) error {
-
-	err := h.clearOutputDir(releaseName)
-	if err != nil {
-		return err
-	}
-	outputDir, _, chartsDir, err := h.getDirectories(releaseName)
-
-	chartDir, err := h.chartNameToFolder(chart, chartsDir)
-	if err != nil {
-		return err
-	}
-	err = h.Client.Template(chartDir, releaseName, ns, outputDir, false, values, valueFiles)
-	if err != nil {
-		return err
-	}
-
-	_, versionText, err := h.getChartNameAndVersion(chartDir, version)
-	if err != nil {
-		return err
-	}
-
-	helmHooks, err := h.addLabelsToFiles(releaseName, versionText)
-	if err != nil {
-		return err
-	}
-	helmPrePhase := "pre-install"
-	helmPostPhase := "post-install"
-	wait := true
-	create := true
-
-	err = h.runHooks(helmHooks, helmPrePhase, ns, chart, releaseName, wait, create)
-	if err != nil {
-		return err
-	}
-	err = h.kubectlApply(ns, chart, releaseName, wait, create, outputDir)
-	if err != nil {
-		h.deleteHooks(helmHooks, helmPrePhase, hookFailed, ns)
-		return err
-	}
-	h.deleteHooks(helmHooks, helmPrePhase, hookSucceeded, ns)
-
-	err = h.runHooks(helmHooks, helmPostPhase, ns, chart, releaseName, wait, create)
-	if err != nil {
-		h.deleteHooks(helmHooks, helmPostPhase, hookFailed, ns)
-		return err
-	}
-
-	err = h.deleteHooks(helmHooks, helmPostPhase, hookSucceeded, ns)
-	err2 := h.deleteOldResources(ns, releaseName, versionText, wait)
-
-	return util.CombineErrors(err, err2)
+	_, err := h.applyChart(chart, releaseName, ns, version, true, true, values, valueFiles)
+	return err
 }
 
 // UpgradeChart upgrades a helm chart according with given helm flags
 func (h *HelmTemplate) UpgradeChart(chart string, releaseName string, ns string, version *string, install bool,
 	timeout *int, force bool, wait bool, values []string, valueFiles []string) error {
+	_, err := h.applyChart(chart, releaseName, ns, version, false, wait, values, valueFiles)
+	return err
+}
+
+// templatedChart is a chart which has been generated into YAML for a release
+type templatedChart struct {
+	ChartName   string
+	VersionText string
+	OutputDir   string
+}
+
+// applyChart generates the YAML of the chart via helm template and applies it via kubectl running any install or
+// upgrade helm hooks. The templated chart is returned if the YAML was generated even if applying it failed
+func (h *HelmTemplate) applyChart(chart string, releaseName string, ns string, version *string, create bool, wait bool,
+	values []string, valueFiles []string) (*templatedChart, error) {
 
 	err := h.clearOutputDir(releaseName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	outputDir, _, chartsDir, err := h.getDirectories(releaseName)
+	if err != nil {
+		return nil, err
+	}
 
 	chartDir, err := h.chartNameToFolder(chart, chartsDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	err = h.Client.Template(chartDir, releaseName, ns, outputDir, false, values, valueFiles)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, versionText, err := h.getChartNameAndVersion(chartDir, version)
+	chartName, versionText, err := h.getChartNameAndVersion(chartDir, version)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	helmHooks, err := h.addLabelsToFiles(releaseName, versionText)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	answer := &templatedChart{
+		ChartName:   chartName,
+		VersionText: versionText,
+		OutputDir:   outputDir,
 	}
 
 	helmPrePhase := "pre-upgrade"
 	helmPostPhase := "post-upgrade"
-	create := false
+	if create {
+		helmPrePhase = "pre-install"
+		helmPostPhase = "post-install"
+	}
 
 	err = h.runHooks(helmHooks, helmPrePhase, ns, chart, releaseName, wait, create)
 	if err != nil {
-		return err
+		return answer, err
 	}
 
 	err = h.kubectlApply(ns, chart, releaseName, wait, create, outputDir)
 	if err != nil {
 		h.deleteHooks(helmHooks, helmPrePhase, hookFailed, ns)
-		return err
+		return answer, err
 	}
 	h.deleteHooks(helmHooks, helmPrePhase, hookSucceeded, ns)
 
 	err = h.runHooks(helmHooks, helmPostPhase, ns, chart, releaseName, wait, create)
 	if err != nil {
 		h.deleteHooks(helmHooks, helmPostPhase, hookFailed, ns)
-		return err
+		return answer, err
 	}
 
 	err = h.deleteHooks(helmHooks, helmPostPhase, hookSucceeded, ns)
 	err2 := h.deleteOldResources(ns, releaseName, versionText, wait)
 
-	return util.CombineErrors(err, err2)
+	return answer, util.CombineErrors(err, err2)
 }
 
 func (h *HelmTemplate) kubectlApply(ns string, chart string, releaseName string, wait bool, create bool, dir string) error {
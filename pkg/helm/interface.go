@@ -31,3 +31,11 @@ type Helmer interface {
 	SetHost(host string)
 	Env() map[string]string
 }
+
+// ReleaseHistory is implemented by Helmers which store the revisions of releases so that they can be listed,
+// compared and rolled back without tiller
+type ReleaseHistory interface {
+	History(ns string, releaseName string) ([]*Release, error)
+	DiffRevisions(ns string, releaseName string, fromRevision int, toRevision int) (string, error)
+	Rollback(ns string, releaseName string, revision int, wait bool) error
+}
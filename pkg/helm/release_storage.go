@@ -0,0 +1,279 @@
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// LabelReleaseOwner marks the Secrets which store the revisions of releases
+	LabelReleaseOwner = "jenkins.io/release-owner"
+
+	// LabelReleaseRevision stores the revision of a release in the label of its Secret
+	LabelReleaseRevision = "jenkins.io/release-revision"
+
+	// LabelReleaseStatus stores the status of a revision of a release in the label of its Secret
+	LabelReleaseStatus = "jenkins.io/release-status"
+
+	// ValueReleaseOwner the owner of the Secrets which store the revisions of releases
+	ValueReleaseOwner = "jx"
+
+	// StatusDeployed the status of the current revision of a release
+	StatusDeployed = "DEPLOYED"
+
+	// StatusSuperseded the status of a revision of a release which has been replaced by a later revision
+	StatusSuperseded = "SUPERSEDED"
+
+	// StatusFailed the status of a revision of a release which failed to install or upgrade
+	StatusFailed = "FAILED"
+
+	// StatusDeleted the status of the last revision of a release which was deleted without being purged
+	StatusDeleted = "DELETED"
+
+	releaseSecretKey = "release"
+)
+
+// Release is a revision of a helm release along with its manifest and values
+type Release struct {
+	Name         string    `json:"name"`
+	Namespace    string    `json:"namespace"`
+	Revision     int       `json:"revision"`
+	Status       string    `json:"status"`
+	Chart        string    `json:"chart"`
+	ChartVersion string    `json:"chartVersion"`
+	Description  string    `json:"description,omitempty"`
+	Updated      time.Time `json:"updated"`
+	Values       string    `json:"values,omitempty"`
+	Manifest     string    `json:"manifest,omitempty"`
+}
+
+// ReleaseStorage stores each revision of a release as a Secret in the namespace of the release
+type ReleaseStorage struct {
+	KubeClient kubernetes.Interface
+}
+
+// NewReleaseStorage creates a new ReleaseStorage using the given kubernetes client
+func NewReleaseStorage(kubeClient kubernetes.Interface) *ReleaseStorage {
+	return &ReleaseStorage{
+		KubeClient: kubeClient,
+	}
+}
+
+// ReleaseSecretName returns the name of the Secret which stores the revision of the release
+func ReleaseSecretName(releaseName string, revision int) string {
+	return fmt.Sprintf("jx.release.v1.%s.v%d", releaseName, revision)
+}
+
+// Create stores a new revision of a release
+func (s *ReleaseStorage) Create(release *Release) error {
+	secret, err := s.toSecret(release)
+	if err != nil {
+		return err
+	}
+	_, err = s.KubeClient.CoreV1().Secrets(release.Namespace).Create(secret)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create Secret %s in namespace %s", secret.Name, release.Namespace)
+	}
+	return nil
+}
+
+// Update updates a stored revision of a release such as to change its status
+func (s *ReleaseStorage) Update(release *Release) error {
+	secret, err := s.toSecret(release)
+	if err != nil {
+		return err
+	}
+	_, err = s.KubeClient.CoreV1().Secrets(release.Namespace).Update(secret)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update Secret %s in namespace %s", secret.Name, release.Namespace)
+	}
+	return nil
+}
+
+// Get returns the revision of the release or nil if it does not exist
+func (s *ReleaseStorage) Get(ns string, releaseName string, revision int) (*Release, error) {
+	name := ReleaseSecretName(releaseName, revision)
+	secret, err := s.KubeClient.CoreV1().Secrets(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get Secret %s in namespace %s", name, ns)
+	}
+	return fromSecret(secret)
+}
+
+// History returns all the revisions of the release ordered by revision
+func (s *ReleaseStorage) History(ns string, releaseName string) ([]*Release, error) {
+	return s.list(ns, LabelReleaseOwner+"="+ValueReleaseOwner+","+LabelReleaseName+"="+releaseName)
+}
+
+// Latest returns the latest revision of the release or nil if it has never been installed
+func (s *ReleaseStorage) Latest(ns string, releaseName string) (*Release, error) {
+	history, err := s.History(ns, releaseName)
+	if err != nil || len(history) == 0 {
+		return nil, err
+	}
+	return history[len(history)-1], nil
+}
+
+// List returns the latest revision of each release in the namespace or in all namespaces if the namespace is empty
+func (s *ReleaseStorage) List(ns string) ([]*Release, error) {
+	releases, err := s.list(ns, LabelReleaseOwner+"="+ValueReleaseOwner)
+	if err != nil {
+		return nil, err
+	}
+	latest := map[string]*Release{}
+	for _, r := range releases {
+		key := r.Namespace + "/" + r.Name
+		latest[key] = r
+	}
+	answer := []*Release{}
+	for _, r := range latest {
+		answer = append(answer, r)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		if answer[i].Namespace != answer[j].Namespace {
+			return answer[i].Namespace < answer[j].Namespace
+		}
+		return answer[i].Name < answer[j].Name
+	})
+	return answer, nil
+}
+
+// Delete removes all the stored revisions of the release
+func (s *ReleaseStorage) Delete(ns string, releaseName string) error {
+	history, err := s.History(ns, releaseName)
+	if err != nil {
+		return err
+	}
+	secrets := s.KubeClient.CoreV1().Secrets(ns)
+	for _, r := range history {
+		name := ReleaseSecretName(r.Name, r.Revision)
+		err = secrets.Delete(name, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete Secret %s in namespace %s", name, ns)
+		}
+	}
+	return nil
+}
+
+// Prune removes the oldest revisions of the release so that at most max revisions are kept. A max of zero keeps all
+// the revisions
+func (s *ReleaseStorage) Prune(ns string, releaseName string, max int) error {
+	if max <= 0 {
+		return nil
+	}
+	history, err := s.History(ns, releaseName)
+	if err != nil {
+		return err
+	}
+	secrets := s.KubeClient.CoreV1().Secrets(ns)
+	for i := 0; i < len(history)-max; i++ {
+		name := ReleaseSecretName(history[i].Name, history[i].Revision)
+		err = secrets.Delete(name, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete Secret %s in namespace %s", name, ns)
+		}
+	}
+	return nil
+}
+
+// Supersede marks all the deployed revisions of the release before the given revision as superseded
+func (s *ReleaseStorage) Supersede(ns string, releaseName string, revision int) error {
+	history, err := s.History(ns, releaseName)
+	if err != nil {
+		return err
+	}
+	for _, r := range history {
+		if r.Revision < revision && r.Status == StatusDeployed {
+			r.Status = StatusSuperseded
+			err = s.Update(r)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ReleaseStorage) list(ns string, selector string) ([]*Release, error) {
+	list, err := s.KubeClient.CoreV1().Secrets(ns).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the release Secrets in namespace %s", ns)
+	}
+	answer := []*Release{}
+	for i := range list.Items {
+		release, err := fromSecret(&list.Items[i])
+		if err != nil {
+			return answer, err
+		}
+		answer = append(answer, release)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Revision < answer[j].Revision
+	})
+	return answer, nil
+}
+
+func (s *ReleaseStorage) toSecret(release *Release) (*corev1.Secret, error) {
+	data, err := json.Marshal(release)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal release %s", release.Name)
+	}
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err = writer.Write(data)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compress release %s", release.Name)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ReleaseSecretName(release.Name, release.Revision),
+			Namespace: release.Namespace,
+			Labels: map[string]string{
+				LabelReleaseOwner:    ValueReleaseOwner,
+				LabelReleaseName:     release.Name,
+				LabelReleaseRevision: strconv.Itoa(release.Revision),
+				LabelReleaseStatus:   release.Status,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			releaseSecretKey: buffer.Bytes(),
+		},
+	}, nil
+}
+
+func fromSecret(secret *corev1.Secret) (*Release, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(secret.Data[releaseSecretKey]))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decompress the release in Secret %s", secret.Name)
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decompress the release in Secret %s", secret.Name)
+	}
+	release := &Release{}
+	err = json.Unmarshal(data, release)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the release in Secret %s", secret.Name)
+	}
+	return release, nil
+}
@@ -0,0 +1,90 @@
+package helm_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReleaseStorage(t *testing.T) {
+	t.Parallel()
+	kubeClient := fake.NewSimpleClientset()
+	storage := helm.NewReleaseStorage(kubeClient)
+	ns := "jx-staging"
+
+	for i := 1; i <= 3; i++ {
+		err := storage.Create(&helm.Release{
+			Name:         "myapp",
+			Namespace:    ns,
+			Revision:     i,
+			Status:       helm.StatusDeployed,
+			Chart:        "myapp",
+			ChartVersion: "0.0." + strconv.Itoa(i),
+			Updated:      time.Now(),
+			Manifest:     strings.Repeat("kind: Service\n", i),
+		})
+		require.NoError(t, err)
+		err = storage.Supersede(ns, "myapp", i)
+		require.NoError(t, err)
+	}
+	err := storage.Create(&helm.Release{Name: "other", Namespace: "jx-production", Revision: 1, Status: helm.StatusDeployed})
+	require.NoError(t, err)
+
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get("jx.release.v1.myapp.v2", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "myapp", secret.Labels[helm.LabelReleaseName])
+	assert.Equal(t, "2", secret.Labels[helm.LabelReleaseRevision])
+	assert.Equal(t, helm.StatusSuperseded, secret.Labels[helm.LabelReleaseStatus])
+
+	history, err := storage.History(ns, "myapp")
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	for i, r := range history {
+		assert.Equal(t, i+1, r.Revision)
+	}
+	assert.Equal(t, helm.StatusSuperseded, history[0].Status)
+	assert.Equal(t, helm.StatusDeployed, history[2].Status)
+	assert.Equal(t, "kind: Service\nkind: Service\n", history[1].Manifest)
+
+	latest, err := storage.Latest(ns, "myapp")
+	require.NoError(t, err)
+	assert.Equal(t, 3, latest.Revision)
+
+	release, err := storage.Get(ns, "myapp", 4)
+	require.NoError(t, err)
+	assert.Nil(t, release)
+
+	releases, err := storage.List("")
+	require.NoError(t, err)
+	require.Len(t, releases, 2)
+	assert.Equal(t, "other", releases[0].Name)
+	assert.Equal(t, "myapp", releases[1].Name)
+	assert.Equal(t, 3, releases[1].Revision)
+
+	err = storage.Prune(ns, "myapp", 0)
+	require.NoError(t, err)
+	history, err = storage.History(ns, "myapp")
+	require.NoError(t, err)
+	assert.Len(t, history, 3, "a history max of zero keeps all the revisions")
+
+	err = storage.Prune(ns, "myapp", 2)
+	require.NoError(t, err)
+	history, err = storage.History(ns, "myapp")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 2, history[0].Revision)
+	assert.Equal(t, 3, history[1].Revision)
+
+	err = storage.Delete(ns, "myapp")
+	require.NoError(t, err)
+	history, err = storage.History(ns, "myapp")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
@@ -0,0 +1,332 @@
+package issues
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/pkg/errors"
+)
+
+// BugzillaService is an IssueProvider using the Bugzilla REST API. The project is either a product or
+// product/component where the component is used when creating new bugs
+type BugzillaService struct {
+	Client    *http.Client
+	Server    *auth.AuthServer
+	UserAuth  *auth.UserAuth
+	Product   string
+	Component string
+}
+
+// BugzillaBug is a bug returned by the Bugzilla REST API
+type BugzillaBug struct {
+	ID               int           `json:"id"`
+	Summary          string        `json:"summary"`
+	Status           string        `json:"status"`
+	Resolution       string        `json:"resolution"`
+	Product          string        `json:"product"`
+	Component        string        `json:"component"`
+	Keywords         []string      `json:"keywords"`
+	CreationTime     *time.Time    `json:"creation_time"`
+	LastChangeTime   *time.Time    `json:"last_change_time"`
+	CreatorDetail    *BugzillaUser `json:"creator_detail"`
+	AssignedToDetail *BugzillaUser `json:"assigned_to_detail"`
+	IsOpen           *bool         `json:"is_open"`
+}
+
+// BugzillaUser is the detail of a Bugzilla user
+type BugzillaUser struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	Email    string `json:"email"`
+}
+
+type bugzillaBugs struct {
+	Bugs []BugzillaBug `json:"bugs"`
+}
+
+type bugzillaComments struct {
+	Bugs map[string]struct {
+		Comments []struct {
+			Text string `json:"text"`
+		} `json:"comments"`
+	} `json:"bugs"`
+}
+
+type bugzillaID struct {
+	ID int `json:"id"`
+}
+
+// bugzillaClosedStatuses the statuses of resolved bugs in the default Bugzilla workflow
+var bugzillaClosedStatuses = []string{"RESOLVED", "VERIFIED", "CLOSED"}
+
+// CreateBugzillaIssueProvider creates an IssueProvider for the Bugzilla server using the API key of the user
+// or anonymous access if there is none
+func CreateBugzillaIssueProvider(server *auth.AuthServer, userAuth *auth.UserAuth, project string, batchMode bool) (IssueProvider, error) {
+	if server.URL == "" {
+		return nil, fmt.Errorf("No base URL for server!")
+	}
+	product, component := project, ""
+	if i := strings.Index(project, "/"); i >= 0 {
+		product, component = project[:i], project[i+1:]
+	}
+	return &BugzillaService{
+		Client:    http.DefaultClient,
+		Server:    server,
+		UserAuth:  userAuth,
+		Product:   product,
+		Component: component,
+	}, nil
+}
+
+func (i *BugzillaService) GetIssue(key string) (*gits.GitIssue, error) {
+	n, err := issueKeyToNumber(key)
+	if err != nil {
+		return nil, err
+	}
+	result := &bugzillaBugs{}
+	err = i.do("GET", "/rest/bug/"+strconv.Itoa(n), nil, nil, result)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Bugs) == 0 {
+		return nil, fmt.Errorf("Could not find bug %s", key)
+	}
+	issue := i.bugzillaToGitIssue(&result.Bugs[0])
+	comments := &bugzillaComments{}
+	err = i.do("GET", "/rest/bug/"+strconv.Itoa(n)+"/comment", nil, nil, comments)
+	if err == nil {
+		// the first comment of a bug is its description
+		for _, bug := range comments.Bugs {
+			if len(bug.Comments) > 0 {
+				issue.Body = bug.Comments[0].Text
+			}
+		}
+	}
+	return issue, nil
+}
+
+func (i *BugzillaService) SearchIssues(query string) ([]*gits.GitIssue, error) {
+	params := url.Values{}
+	params.Set("resolution", "---")
+	if query != "" {
+		params.Set("summary", query)
+	}
+	return i.searchBugs(params)
+}
+
+func (i *BugzillaService) SearchIssuesClosedSince(t time.Time) ([]*gits.GitIssue, error) {
+	params := url.Values{}
+	for _, status := range bugzillaClosedStatuses {
+		params.Add("status", status)
+	}
+	params.Set("last_change_time", t.UTC().Format(time.RFC3339))
+	return i.searchBugs(params)
+}
+
+func (i *BugzillaService) CreateIssue(issue *gits.GitIssue) (*gits.GitIssue, error) {
+	if i.Product == "" || i.Component == "" {
+		return nil, fmt.Errorf("The issue tracker project must be of the form product/component to create bugs in Bugzilla")
+	}
+	body := map[string]interface{}{
+		"product":     i.Product,
+		"component":   i.Component,
+		"summary":     issue.Title,
+		"description": issue.Body,
+		"version":     "unspecified",
+	}
+	labels := []string{}
+	for _, label := range issue.Labels {
+		labels = append(labels, label.Name)
+	}
+	if len(labels) > 0 {
+		body["keywords"] = labels
+	}
+	result := &bugzillaID{}
+	err := i.do("POST", "/rest/bug", nil, body, result)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create bug")
+	}
+	key := strconv.Itoa(result.ID)
+	answer := *issue
+	answer.Key = key
+	answer.Number = &result.ID
+	answer.URL = i.IssueURL(key)
+	return &answer, nil
+}
+
+func (i *BugzillaService) CreateIssueComment(key string, comment string) error {
+	n, err := issueKeyToNumber(key)
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"comment": comment,
+	}
+	return i.do("POST", "/rest/bug/"+strconv.Itoa(n)+"/comment", nil, body, &bugzillaID{})
+}
+
+// TransitionIssue changes the status of the bug resolving it as fixed if the status is a closed status
+func (i *BugzillaService) TransitionIssue(key string, status string) error {
+	body := map[string]interface{}{
+		"status": status,
+	}
+	for _, s := range bugzillaClosedStatuses {
+		if strings.EqualFold(s, status) {
+			body["resolution"] = "FIXED"
+		}
+	}
+	return i.updateBug(key, body)
+}
+
+// SetIssueFixVersion sets the target milestone of the bug as Bugzilla has no fix versions. The milestone
+// must exist in the product
+func (i *BugzillaService) SetIssueFixVersion(key string, version string) error {
+	return i.updateBug(key, map[string]interface{}{
+		"target_milestone": version,
+	})
+}
+
+func (i *BugzillaService) updateBug(key string, body map[string]interface{}) error {
+	n, err := issueKeyToNumber(key)
+	if err != nil {
+		return err
+	}
+	result := map[string]interface{}{}
+	return i.do("PUT", "/rest/bug/"+strconv.Itoa(n), nil, body, &result)
+}
+
+func (i *BugzillaService) IssueURL(key string) string {
+	return strings.TrimSuffix(i.Server.URL, "/") + "/show_bug.cgi?id=" + url.QueryEscape(key)
+}
+
+func (i *BugzillaService) HomeURL() string {
+	if i.Product == "" {
+		return i.Server.URL
+	}
+	return strings.TrimSuffix(i.Server.URL, "/") + "/buglist.cgi?product=" + url.QueryEscape(i.Product)
+}
+
+func (i *BugzillaService) searchBugs(params url.Values) ([]*gits.GitIssue, error) {
+	answer := []*gits.GitIssue{}
+	if i.Product != "" {
+		params.Set("product", i.Product)
+	}
+	if i.Component != "" {
+		params.Set("component", i.Component)
+	}
+	result := &bugzillaBugs{}
+	err := i.do("GET", "/rest/bug", params, nil, result)
+	if err != nil {
+		return answer, err
+	}
+	for idx := range result.Bugs {
+		answer = append(answer, i.bugzillaToGitIssue(&result.Bugs[idx]))
+	}
+	return answer, nil
+}
+
+func (i *BugzillaService) bugzillaToGitIssue(bug *BugzillaBug) *gits.GitIssue {
+	key := strconv.Itoa(bug.ID)
+	id := bug.ID
+	state := "open"
+	closed := bug.Resolution != ""
+	if bug.IsOpen != nil {
+		closed = !*bug.IsOpen
+	}
+	if closed {
+		state = "closed"
+	}
+	answer := &gits.GitIssue{
+		Key:       key,
+		Number:    &id,
+		URL:       i.IssueURL(key),
+		Title:     bug.Summary,
+		State:     &state,
+		CreatedAt: bug.CreationTime,
+		UpdatedAt: bug.LastChangeTime,
+		User:      bugzillaUserToGitUser(bug.CreatorDetail),
+	}
+	if closed {
+		answer.ClosedAt = bug.LastChangeTime
+	}
+	for _, keyword := range bug.Keywords {
+		answer.Labels = append(answer.Labels, gits.GitLabel{Name: keyword})
+	}
+	assignee := bugzillaUserToGitUser(bug.AssignedToDetail)
+	if assignee != nil {
+		answer.Assignees = []gits.GitUser{*assignee}
+	}
+	return answer
+}
+
+func bugzillaUserToGitUser(user *BugzillaUser) *gits.GitUser {
+	if user == nil {
+		return nil
+	}
+	return &gits.GitUser{
+		Login: user.Name,
+		Name:  user.RealName,
+		Email: user.Email,
+	}
+}
+
+func (i *BugzillaService) do(method string, subPath string, params url.Values, body interface{}, result interface{}) error {
+	u := strings.TrimSuffix(i.Server.URL, "/") + subPath
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/json")
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	userAuth := i.UserAuth
+	if userAuth != nil {
+		if userAuth.ApiToken != "" {
+			req.Header.Add("X-BUGZILLA-API-KEY", userAuth.ApiToken)
+		} else if userAuth.Username != "" && userAuth.Password != "" {
+			req.Header.Add("X-BUGZILLA-LOGIN", userAuth.Username)
+			req.Header.Add("X-BUGZILLA-PASSWORD", userAuth.Password)
+		}
+	}
+	resp, err := i.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("error response from Bugzilla %s %s: %s %s", method, subPath, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, result)
+}
+
+// ServerName returns the URL of the Bugzilla server
+func (i *BugzillaService) ServerName() string {
+	return i.Server.URL
+}
@@ -0,0 +1,150 @@
+package issues_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/issues"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var bugzillaRouter = util.Router{
+	"/rest/bug/1234": util.MethodMap{
+		"GET": "bug.json",
+		"PUT": "updated.json",
+	},
+	"/rest/bug/1234/comment": util.MethodMap{
+		"GET":  "comments.json",
+		"POST": "created.json",
+	},
+}
+
+type bugzillaTestServer struct {
+	*httptest.Server
+	queries []string
+	bodies  []map[string]interface{}
+}
+
+func createBugzillaTestServer(t *testing.T) *bugzillaTestServer {
+	answer := &bugzillaTestServer{}
+	mux := http.NewServeMux()
+	for path, methodMap := range bugzillaRouter {
+		mux.HandleFunc(path, util.GetMockAPIResponseFromFile("test_data/bugzilla", methodMap))
+	}
+	mux.HandleFunc("/rest/bug", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "myapikey", r.Header.Get("X-BUGZILLA-API-KEY"))
+		if r.Method == "POST" {
+			body := map[string]interface{}{}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NoError(t, err)
+			answer.bodies = append(answer.bodies, body)
+			w.Write([]byte(`{"id": 1240}`))
+			return
+		}
+		answer.queries = append(answer.queries, r.URL.RawQuery)
+		util.GetMockAPIResponseFromFile("test_data/bugzilla", util.MethodMap{"GET": "search.json"})(w, r)
+	})
+	answer.Server = httptest.NewServer(mux)
+	return answer
+}
+
+func createBugzillaTestProvider(t *testing.T, server *bugzillaTestServer, project string) issues.IssueProvider {
+	provider, err := issues.CreateIssueProvider(issues.Bugzilla, &auth.AuthServer{
+		URL:  server.URL,
+		Kind: issues.Bugzilla,
+	}, &auth.UserAuth{
+		Username: "jenkins-x-bot",
+		ApiToken: "myapikey",
+	}, project, true, nil)
+	require.NoError(t, err)
+	assert.Equal(t, issues.Bugzilla, issues.GetIssueProvider(provider))
+	return provider
+}
+
+func TestBugzillaGetIssue(t *testing.T) {
+	t.Parallel()
+	server := createBugzillaTestServer(t)
+	defer server.Close()
+	provider := createBugzillaTestProvider(t, server, "Jenkins X/CLI")
+
+	issue, err := provider.GetIssue("1234")
+	require.NoError(t, err)
+	assert.Equal(t, "1234", issue.Key)
+	assert.Equal(t, server.URL+"/show_bug.cgi?id=1234", issue.URL)
+	assert.Equal(t, "Widget crashes on startup", issue.Title)
+	assert.Equal(t, "Running jx crashes the widget", issue.Body)
+	assert.Equal(t, "closed", *issue.State)
+	require.NotNil(t, issue.ClosedAt)
+	assert.Equal(t, time.Date(2018, 10, 3, 17, 30, 0, 0, time.UTC), issue.ClosedAt.UTC())
+	assert.Equal(t, "James Strachan", issue.User.Name)
+	require.Len(t, issue.Assignees, 1)
+	assert.Equal(t, "rawlingsj@example.com", issue.Assignees[0].Email)
+	assert.Equal(t, []gits.GitLabel{{Name: "regression"}}, issue.Labels)
+
+	_, err = provider.GetIssue("JX-1234")
+	assert.Error(t, err)
+}
+
+func TestBugzillaSearchIssues(t *testing.T) {
+	t.Parallel()
+	server := createBugzillaTestServer(t)
+	defer server.Close()
+	provider := createBugzillaTestProvider(t, server, "Jenkins X")
+
+	results, err := provider.SearchIssues("widget")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "1235", results[0].Key)
+	assert.Equal(t, "open", *results[1].State)
+
+	_, err = provider.SearchIssuesClosedSince(time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	require.Len(t, server.queries, 2)
+	assert.Equal(t, "product=Jenkins+X&resolution=---&summary=widget", server.queries[0])
+	assert.Equal(t, "last_change_time=2018-10-01T00%3A00%3A00Z&product=Jenkins+X&status=RESOLVED&status=VERIFIED&status=CLOSED", server.queries[1])
+	assert.Equal(t, server.URL+"/buglist.cgi?product=Jenkins+X", provider.HomeURL())
+}
+
+func TestBugzillaCreateIssue(t *testing.T) {
+	t.Parallel()
+	server := createBugzillaTestServer(t)
+	defer server.Close()
+
+	provider := createBugzillaTestProvider(t, server, "Jenkins X")
+	_, err := provider.CreateIssue(&gits.GitIssue{Title: "missing component"})
+	assert.Error(t, err, "bugs can only be created for a component")
+
+	provider = createBugzillaTestProvider(t, server, "Jenkins X/CLI")
+	issue, err := provider.CreateIssue(&gits.GitIssue{
+		Title:  "Widget is green",
+		Body:   "It should be blue",
+		Labels: []gits.GitLabel{{Name: "regression"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1240", issue.Key)
+	assert.Equal(t, server.URL+"/show_bug.cgi?id=1240", issue.URL)
+
+	require.Len(t, server.bodies, 1)
+	body := server.bodies[0]
+	assert.Equal(t, "Jenkins X", body["product"])
+	assert.Equal(t, "CLI", body["component"])
+	assert.Equal(t, "Widget is green", body["summary"])
+	assert.Equal(t, "It should be blue", body["description"])
+	assert.Equal(t, []interface{}{"regression"}, body["keywords"])
+
+	err = provider.CreateIssueComment("1234", "Released in 1.3.400")
+	require.NoError(t, err)
+
+	err = provider.TransitionIssue("1234", "VERIFIED")
+	require.NoError(t, err)
+	err = provider.SetIssueFixVersion("1234", "1.3.400")
+	require.NoError(t, err)
+}
@@ -9,18 +9,20 @@ import (
 	"github.com/jenkins-x/jx/pkg/util"
 )
 
+// GitIssueProvider is an IssueProvider for the issues of a git repository using the issue methods of its git provider
 type GitIssueProvider struct {
 	GitProvider gits.GitProvider
 	Owner       string
 	Repository  string
 }
 
+// CreateGitIssueProvider creates an IssueProvider for the issues of the repository of the git provider
 func CreateGitIssueProvider(gitProvider gits.GitProvider, owner string, repository string) (IssueProvider, error) {
 	if owner == "" {
 		return nil, fmt.Errorf("No owner specified")
 	}
 	if repository == "" {
-		return nil, fmt.Errorf("No owner specified")
+		return nil, fmt.Errorf("No repository specified")
 	}
 	return &GitIssueProvider{
 		GitProvider: gitProvider,
@@ -76,3 +78,13 @@ func (i *GitIssueProvider) CreateIssueComment(key string, comment string) error
 func (i *GitIssueProvider) HomeURL() string {
 	return util.UrlJoin(i.GitProvider.ServerURL(), i.Owner, i.Repository)
 }
+
+// TransitionIssue is not supported as git providers only open and close issues
+func (i *GitIssueProvider) TransitionIssue(key string, status string) error {
+	return fmt.Errorf("Issue transitions are not supported by git issue trackers")
+}
+
+// SetIssueFixVersion is not supported as git issues have no fix versions
+func (i *GitIssueProvider) SetIssueFixVersion(key string, version string) error {
+	return fmt.Errorf("Fix versions are not supported by git issue trackers")
+}
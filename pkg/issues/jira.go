@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -111,6 +112,79 @@ func (i *JiraService) CreateIssueComment(key string, comment string) error {
 	return fmt.Errorf("TODO")
 }
 
+// TransitionIssue moves the issue to the status using the transition of the workflow of the issue which either
+// has the name of the status or leads to it
+func (i *JiraService) TransitionIssue(key string, status string) error {
+	issue, _, err := i.JiraClient.Issue.Get(key, nil)
+	if err != nil {
+		return fmt.Errorf("Could not find issue %s: %s", key, err)
+	}
+	if issue.Fields != nil && issue.Fields.Status != nil && strings.EqualFold(issue.Fields.Status.Name, status) {
+		return nil
+	}
+	transitions, _, err := i.JiraClient.Issue.GetTransitions(key)
+	if err != nil {
+		return fmt.Errorf("Could not find the transitions of issue %s: %s", key, err)
+	}
+	names := []string{}
+	for _, t := range transitions {
+		if strings.EqualFold(t.To.Name, status) || strings.EqualFold(t.Name, status) {
+			_, err = i.JiraClient.Issue.DoTransition(key, t.ID)
+			if err != nil {
+				return fmt.Errorf("Failed to transition issue %s to %s: %s", key, status, err)
+			}
+			return nil
+		}
+		names = append(names, t.To.Name)
+	}
+	return fmt.Errorf("Issue %s cannot be moved to status %s. Available statuses are: %s", key, status, strings.Join(names, ", "))
+}
+
+// SetIssueFixVersion adds the version to the fix versions of the issue creating the version in the project if
+// it does not exist
+func (i *JiraService) SetIssueFixVersion(key string, version string) error {
+	project, _, err := i.JiraClient.Project.Get(i.Project)
+	if err != nil {
+		return fmt.Errorf("Could not find project %s: %s", i.Project, err)
+	}
+	found := false
+	for _, v := range project.Versions {
+		if v.Name == version {
+			found = true
+			break
+		}
+	}
+	if !found {
+		projectID, err := strconv.Atoi(project.ID)
+		if err != nil {
+			return fmt.Errorf("Could not parse the ID %s of project %s: %s", project.ID, i.Project, err)
+		}
+		_, _, err = i.JiraClient.Version.Create(&jira.Version{
+			Name:      version,
+			ProjectID: projectID,
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to create version %s in project %s: %s", version, i.Project, err)
+		}
+	}
+	data := map[string]interface{}{
+		"update": map[string]interface{}{
+			"fixVersions": []interface{}{
+				map[string]interface{}{
+					"add": map[string]string{
+						"name": version,
+					},
+				},
+			},
+		},
+	}
+	_, err = i.JiraClient.Issue.UpdateIssue(key, data)
+	if err != nil {
+		return fmt.Errorf("Failed to set the fix version of issue %s to %s: %s", key, version, err)
+	}
+	return nil
+}
+
 func (i *JiraService) IssueURL(key string) string {
 	return util.UrlJoin(i.Server.URL, "browse", key)
 }
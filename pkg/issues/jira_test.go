@@ -0,0 +1,116 @@
+package issues_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/issues"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jiraTestServer struct {
+	*httptest.Server
+	requests map[string]map[string]interface{}
+}
+
+func createJiraTestServer(t *testing.T) *jiraTestServer {
+	answer := &jiraTestServer{
+		requests: map[string]map[string]interface{}{},
+	}
+	responses := map[string]string{
+		"GET /rest/api/2/issue/JX-1":              `{"key": "JX-1", "fields": {"status": {"name": "In Progress"}}}`,
+		"GET /rest/api/2/issue/JX-2":              `{"key": "JX-2", "fields": {"status": {"name": "In Staging"}}}`,
+		"GET /rest/api/2/issue/JX-1/transitions":  `{"transitions": [{"id": "21", "name": "Review", "to": {"name": "In Review"}}, {"id": "31", "name": "Deploy", "to": {"name": "In Staging"}}]}`,
+		"POST /rest/api/2/issue/JX-1/transitions": ``,
+		"GET /rest/api/2/project/JX":              `{"id": "10000", "key": "JX", "versions": [{"id": "1", "name": "1.0.2"}]}`,
+		"POST /rest/api/2/version":                `{"id": "2", "name": "1.0.3", "projectId": 10000}`,
+		"PUT /rest/api/2/issue/JX-1":              ``,
+	}
+	answer.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		body, ok := responses[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method != "GET" {
+			data := map[string]interface{}{}
+			err := json.NewDecoder(r.Body).Decode(&data)
+			assert.NoError(t, err, "decoding %s", key)
+			answer.requests[key] = data
+		}
+		if body == "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	return answer
+}
+
+func createJiraTestProvider(t *testing.T, server *jiraTestServer) issues.IssueProvider {
+	provider, err := issues.CreateIssueProvider(issues.Jira, &auth.AuthServer{
+		URL:  server.URL,
+		Kind: issues.Jira,
+	}, &auth.UserAuth{
+		Username: "jenkins-x-bot",
+		ApiToken: "mytoken",
+	}, "JX", true, &gits.GitCLI{})
+	require.NoError(t, err)
+	return provider
+}
+
+func TestJiraTransitionIssue(t *testing.T) {
+	t.Parallel()
+	server := createJiraTestServer(t)
+	defer server.Close()
+	provider := createJiraTestProvider(t, server)
+
+	err := provider.TransitionIssue("JX-1", "in staging")
+	require.NoError(t, err)
+	transition := server.requests["POST /rest/api/2/issue/JX-1/transitions"]
+	require.NotNil(t, transition)
+	assert.Equal(t, map[string]interface{}{"id": "31"}, transition["transition"])
+
+	err = provider.TransitionIssue("JX-1", "Released")
+	assert.Error(t, err)
+
+	err = provider.TransitionIssue("JX-2", "In Staging")
+	assert.NoError(t, err, "issues already in the status should not be moved")
+}
+
+func TestJiraSetIssueFixVersion(t *testing.T) {
+	t.Parallel()
+	server := createJiraTestServer(t)
+	defer server.Close()
+	provider := createJiraTestProvider(t, server)
+
+	err := provider.SetIssueFixVersion("JX-1", "1.0.3")
+	require.NoError(t, err)
+
+	version := server.requests["POST /rest/api/2/version"]
+	require.NotNil(t, version, "the missing version should be created")
+	assert.Equal(t, "1.0.3", version["name"])
+	assert.Equal(t, float64(10000), version["projectId"])
+
+	update := server.requests["PUT /rest/api/2/issue/JX-1"]
+	require.NotNil(t, update)
+	assert.Equal(t, map[string]interface{}{
+		"fixVersions": []interface{}{
+			map[string]interface{}{
+				"add": map[string]interface{}{"name": "1.0.3"},
+			},
+		},
+	}, update["update"])
+
+	delete(server.requests, "POST /rest/api/2/version")
+	err = provider.SetIssueFixVersion("JX-1", "1.0.2")
+	require.NoError(t, err)
+	assert.Nil(t, server.requests["POST /rest/api/2/version"], "existing versions should not be created")
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/jenkins-x/jx/pkg/auth"
 	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/util"
 )
 
 type IssueProvider interface {
@@ -29,12 +30,22 @@ type IssueProvider interface {
 
 	// HomeURL returns the home URL of the issue tracker
 	HomeURL() string
+
+	// TransitionIssue moves the issue to the given status if it is not already in that status
+	TransitionIssue(key string, status string) error
+
+	// SetIssueFixVersion adds the version to the fix versions of the issue
+	SetIssueFixVersion(key string, version string) error
 }
 
 func CreateIssueProvider(kind string, server *auth.AuthServer, userAuth *auth.UserAuth, project string, batchMode bool, git gits.Gitter) (IssueProvider, error) {
 	switch kind {
 	case Jira:
 		return CreateJiraIssueProvider(server, userAuth, project, batchMode, git)
+	case Bugzilla:
+		return CreateBugzillaIssueProvider(server, userAuth, project, batchMode)
+	case Git:
+		return nil, fmt.Errorf("The %s issue provider needs a git provider so use CreateGitIssueProvider", kind)
 	default:
 		return nil, fmt.Errorf("Unsupported issue provider kind: %s", kind)
 	}
@@ -45,6 +56,8 @@ func ProviderAccessTokenURL(kind string, url string) string {
 	case Jira:
 		// TODO handle on premise servers too by detecting the URL is at atlassian.com
 		return "https://id.atlassian.com/manage/api-tokens"
+	case Bugzilla:
+		return util.UrlJoin(url, "userprefs.cgi?tab=apikey")
 	default:
 		return ""
 	}
@@ -52,9 +65,12 @@ func ProviderAccessTokenURL(kind string, url string) string {
 
 // GetIssueProvider returns the kind of issue provider
 func GetIssueProvider(tracker IssueProvider) string {
-	_, ok := tracker.(*JiraService)
-	if ok {
+	switch tracker.(type) {
+	case *JiraService:
 		return Jira
+	case *BugzillaService:
+		return Bugzilla
+	default:
+		return Git
 	}
-	return Git
 }
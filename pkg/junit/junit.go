@@ -0,0 +1,134 @@
+package junit
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// TestSuites is the root element of a JUnit XML report containing many test suites
+type TestSuites struct {
+	XMLName xml.Name    `xml:"testsuites"`
+	Suites  []TestSuite `xml:"testsuite"`
+}
+
+// TestSuite is a suite of test cases which may contain nested suites
+type TestSuite struct {
+	XMLName   xml.Name    `xml:"testsuite"`
+	Name      string      `xml:"name,attr"`
+	Time      float64     `xml:"time,attr"`
+	TestCases []TestCase  `xml:"testcase"`
+	Suites    []TestSuite `xml:"testsuite"`
+}
+
+// TestCase is a single test
+type TestCase struct {
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr"`
+	Time      float64  `xml:"time,attr"`
+	Failure   *Failure `xml:"failure"`
+	Error     *Failure `xml:"error"`
+	Skipped   *Skipped `xml:"skipped"`
+}
+
+// Failure is the failure or error of a test case
+type Failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Skipped indicates a test case was skipped
+type Skipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// Summary is the number of tests and failures in one or more JUnit reports
+type Summary struct {
+	Tests    int      `json:"tests"`
+	Failures int      `json:"failures"`
+	Errors   int      `json:"errors"`
+	Skipped  int      `json:"skipped"`
+	Failed   []string `json:"failed,omitempty"`
+}
+
+// Parse parses a JUnit XML report which has either a testsuites or a testsuite root element
+func Parse(data []byte) ([]TestSuite, error) {
+	if isTestSuites(data) {
+		suites := TestSuites{}
+		err := xml.Unmarshal(data, &suites)
+		return suites.Suites, err
+	}
+	suite := TestSuite{}
+	err := xml.Unmarshal(data, &suite)
+	if err != nil {
+		return nil, err
+	}
+	return []TestSuite{suite}, nil
+}
+
+// Summarize returns the summary of the test cases of the suites and their nested suites
+func Summarize(suites []TestSuite) Summary {
+	answer := Summary{}
+	for _, suite := range suites {
+		for _, tc := range suite.TestCases {
+			answer.Tests++
+			name := tc.Name
+			if tc.ClassName != "" {
+				name = tc.ClassName + "." + tc.Name
+			}
+			if tc.Failure != nil {
+				answer.Failures++
+				answer.Failed = append(answer.Failed, name)
+			} else if tc.Error != nil {
+				answer.Errors++
+				answer.Failed = append(answer.Failed, name)
+			} else if tc.Skipped != nil {
+				answer.Skipped++
+			}
+		}
+		answer.Add(Summarize(suite.Suites))
+	}
+	return answer
+}
+
+// Add adds the other summary to this summary
+func (s *Summary) Add(other Summary) {
+	s.Tests += other.Tests
+	s.Failures += other.Failures
+	s.Errors += other.Errors
+	s.Skipped += other.Skipped
+	s.Failed = append(s.Failed, other.Failed...)
+}
+
+// Passed returns true if there are no failures or errors
+func (s *Summary) Passed() bool {
+	return s.Failures == 0 && s.Errors == 0
+}
+
+// String returns a short description of the summary such as '10 tests, 1 failed, 2 skipped'
+func (s Summary) String() string {
+	parts := []string{fmt.Sprintf("%d tests", s.Tests)}
+	if s.Failures+s.Errors > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", s.Failures+s.Errors))
+	}
+	if s.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", s.Skipped))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// isTestSuites returns true if the root element of the XML document is testsuites
+func isTestSuites(data []byte) bool {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local == "testsuites"
+		}
+	}
+}
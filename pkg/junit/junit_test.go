@@ -0,0 +1,60 @@
+package junit_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/junit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTestSuites(t *testing.T) {
+	t.Parallel()
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="cheese" tests="3">
+    <testcase classname="cheese.Brie" name="testRunny" time="0.1"/>
+    <testcase classname="cheese.Brie" name="testSmelly" time="0.2">
+      <failure message="expected smelly" type="AssertionError">not smelly enough</failure>
+    </testcase>
+    <testcase classname="cheese.Brie" name="testMouldy">
+      <skipped/>
+    </testcase>
+  </testsuite>
+  <testsuite name="wine">
+    <testcase classname="wine.Red" name="testCorked">
+      <error message="boom"/>
+    </testcase>
+    <testsuite name="nested">
+      <testcase name="testVintage"/>
+    </testsuite>
+  </testsuite>
+</testsuites>`
+	suites, err := junit.Parse([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, suites, 2)
+	assert.Equal(t, "cheese", suites[0].Name)
+	assert.Equal(t, "not smelly enough", suites[0].TestCases[1].Failure.Text)
+
+	summary := junit.Summarize(suites)
+	assert.Equal(t, 5, summary.Tests)
+	assert.Equal(t, 1, summary.Failures)
+	assert.Equal(t, 1, summary.Errors)
+	assert.Equal(t, 1, summary.Skipped)
+	assert.Equal(t, []string{"cheese.Brie.testSmelly", "wine.Red.testCorked"}, summary.Failed)
+	assert.False(t, summary.Passed())
+	assert.Equal(t, "5 tests, 2 failed, 1 skipped", summary.String())
+}
+
+func TestParseTestSuite(t *testing.T) {
+	t.Parallel()
+	data := `<testsuite name="cheese"><testcase name="a"/><testcase name="b"/></testsuite>`
+	suites, err := junit.Parse([]byte(data))
+	require.NoError(t, err)
+	summary := junit.Summarize(suites)
+	assert.True(t, summary.Passed())
+	assert.Equal(t, "2 tests", summary.String())
+
+	_, err = junit.Parse([]byte("<testsuite"))
+	assert.Error(t, err)
+}
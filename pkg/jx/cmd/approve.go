@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+	"gopkg.in/AlecAivazis/survey.v1/terminal"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApproveOptions containers the CLI options
+type ApproveOptions struct {
+	CommonOptions
+
+	Application string
+	Version     string
+	Environment string
+	Comment     string
+
+	// Reject rejects the approval rather than approving it
+	Reject bool
+}
+
+// PendingApproval is an approval step of a PipelineActivity which is waiting for approval
+type PendingApproval struct {
+	Activity *v1.PipelineActivity
+	Approval *v1.ApprovalActivityStep
+}
+
+var (
+	approve_long = templates.LongDesc(`
+		Approves a promotion of a version of an application which is waiting for a manual approval in its workflow.
+
+		The user approving the promotion is recorded on the PipelineActivity so that there is an audit trail of who approved production changes.
+`)
+
+	approve_example = templates.Examples(`
+		# Approve the pending promotion of the current application
+		jx approve
+
+		# Approve the pending promotion of version 1.2.3 of myapp to production
+		jx approve myapp --version 1.2.3 --env production
+	`)
+
+	reject_long = templates.LongDesc(`
+		Rejects a promotion of a version of an application which is waiting for a manual approval in its workflow.
+
+		The workflow of the rejected promotion is aborted and the user rejecting it is recorded on the PipelineActivity.
+`)
+
+	reject_example = templates.Examples(`
+		# Reject the pending promotion of the current application
+		jx reject
+
+		# Reject the pending promotion of version 1.2.3 of myapp to production
+		jx reject myapp --version 1.2.3 --env production -m "breaks the checkout page"
+	`)
+)
+
+// NewCmdApprove creates the new command for: jx approve
+func NewCmdApprove(f Factory, in terminal.FileReader, out terminal.FileWriter, errOut io.Writer) *cobra.Command {
+	options := &ApproveOptions{
+		CommonOptions: CommonOptions{
+			Factory: f,
+			In:      in,
+			Out:     out,
+			Err:     errOut,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "approve [application]",
+		Short:   "Approves a promotion which is waiting for approval",
+		Long:    approve_long,
+		Example: approve_example,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			CheckErr(err)
+		},
+	}
+	options.addApproveFlags(cmd)
+	return cmd
+}
+
+// NewCmdReject creates the new command for: jx reject
+func NewCmdReject(f Factory, in terminal.FileReader, out terminal.FileWriter, errOut io.Writer) *cobra.Command {
+	options := &ApproveOptions{
+		CommonOptions: CommonOptions{
+			Factory: f,
+			In:      in,
+			Out:     out,
+			Err:     errOut,
+		},
+		Reject: true,
+	}
+	cmd := &cobra.Command{
+		Use:     "reject [application]",
+		Short:   "Rejects a promotion which is waiting for approval",
+		Long:    reject_long,
+		Example: reject_example,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			CheckErr(err)
+		},
+	}
+	options.addApproveFlags(cmd)
+	return cmd
+}
+
+func (o *ApproveOptions) addApproveFlags(cmd *cobra.Command) {
+	o.addCommonFlags(cmd)
+
+	cmd.Flags().StringVarP(&o.Application, optionApplication, "a", "", "The Application waiting for approval")
+	cmd.Flags().StringVarP(&o.Version, "version", "v", "", "The Version waiting for approval")
+	cmd.Flags().StringVarP(&o.Environment, optionEnvironment, "e", "", "The Environment the promotion is waiting for approval for")
+	cmd.Flags().StringVarP(&o.Comment, "comment", "m", "", "The comment recorded with the approval")
+}
+
+// Run implements this command
+func (o *ApproveOptions) Run() error {
+	app := o.Application
+	if app == "" {
+		args := o.Args
+		if len(args) == 0 {
+			var err error
+			app, err = o.DiscoverAppName()
+			if err != nil {
+				return err
+			}
+		} else {
+			app = args[0]
+		}
+	}
+	o.Application = app
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	pending, err := o.findPendingApproval(jxClient, ns)
+	if err != nil {
+		return err
+	}
+	approver, err := o.currentApprover(jxClient, ns)
+	if err != nil {
+		return err
+	}
+	approval := pending.Approval
+	if len(approval.Approvers) > 0 && util.StringArrayIndex(approval.Approvers, approver.Login) < 0 {
+		return fmt.Errorf("user %s is not one of the approvers %s of %s", approver.Login, strings.Join(approval.Approvers, ", "), describePendingApproval(pending))
+	}
+
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	activity, err := activities.Get(pending.Activity.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	_, approval, _ = kube.GetOrCreateApproval(activity, approval.Name)
+	if approval.Status != v1.ActivityStatusTypeWaitingForApproval {
+		return fmt.Errorf("approval %s of PipelineActivity %s is no longer waiting for approval as its status is %s", approval.Name, activity.Name, string(approval.Status))
+	}
+	approval.Approver = approver
+	approval.Comment = o.Comment
+	if o.Reject {
+		kube.AbortActivityStep(&approval.CoreActivityStep)
+	} else {
+		kube.CompleteActivityStep(&approval.CoreActivityStep)
+		activity.Spec.WorkflowStatus = v1.ActivityStatusTypeRunning
+	}
+	_, err = activities.Update(activity)
+	if err != nil {
+		return err
+	}
+	if o.Reject {
+		log.Infof("Rejected %s\n", describePendingApproval(pending))
+	} else {
+		log.Infof("Approved %s\n", describePendingApproval(pending))
+	}
+	return nil
+}
+
+// findPendingApproval finds the approval waiting for approval for the application, version and environment
+func (o *ApproveOptions) findPendingApproval(jxClient versioned.Interface, ns string) (*PendingApproval, error) {
+	pendings, err := GetPendingApprovals(jxClient, ns)
+	if err != nil {
+		return nil, err
+	}
+	matches := []*PendingApproval{}
+	for _, pending := range pendings {
+		if pending.Activity.RepositoryName() != o.Application {
+			continue
+		}
+		if o.Version != "" && pending.Activity.Spec.Version != o.Version {
+			continue
+		}
+		if o.Environment != "" && pending.Approval.Environment != o.Environment {
+			continue
+		}
+		matches = append(matches, pending)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no promotion of application %s is waiting for approval", util.ColorInfo(o.Application))
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	names := []string{}
+	m := map[string]*PendingApproval{}
+	for _, pending := range matches {
+		name := describePendingApproval(pending)
+		names = append(names, name)
+		m[name] = pending
+	}
+	if o.BatchMode {
+		return nil, fmt.Errorf("several promotions are waiting for approval, please specify the --version or --%s option: %s", optionEnvironment, strings.Join(names, ", "))
+	}
+	name, err := util.PickName(names, "Pick the promotion to approve: ", o.In, o.Out, o.Err)
+	if err != nil {
+		return nil, err
+	}
+	return m[name], nil
+}
+
+// currentApprover returns the details of the current user based on the git user and the matching User resource
+func (o *ApproveOptions) currentApprover(jxClient versioned.Interface, ns string) (*v1.UserDetails, error) {
+	authConfigSvc, err := o.CreateGitAuthConfigService()
+	if err != nil {
+		return nil, err
+	}
+	config := authConfigSvc.Config()
+	username := ""
+	server := config.GetServer(config.CurrentServer)
+	if server != nil {
+		username = server.CurrentUser
+		if username == "" && len(server.Users) > 0 {
+			username = server.Users[0].Username
+		}
+	}
+	if username == "" {
+		username = config.DefaultUsername
+	}
+	if username == "" {
+		return nil, fmt.Errorf("could not find the current git user to record the approval")
+	}
+
+	users, _, err := kube.GetUsers(jxClient, ns)
+	if err != nil {
+		log.Warnf("Failed to load the users: %s\n", err)
+	}
+	for _, user := range users {
+		details := user.Spec
+		if details.Login == "" {
+			details = user.User
+		}
+		if details.Login == username {
+			answer := details
+			return &answer, nil
+		}
+	}
+	return &v1.UserDetails{
+		Login: username,
+	}, nil
+}
+
+// GetPendingApprovals returns all the approvals of PipelineActivity resources which are waiting for approval
+func GetPendingApprovals(jxClient versioned.Interface, ns string) ([]*PendingApproval, error) {
+	answer := []*PendingApproval{}
+	activities, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return answer, err
+	}
+	for i := range activities.Items {
+		activity := &activities.Items[i]
+		if activity.Spec.WorkflowStatus.IsTerminated() {
+			continue
+		}
+		for _, step := range activity.Spec.Steps {
+			approval := step.Approval
+			if approval != nil && approval.Status == v1.ActivityStatusTypeWaitingForApproval {
+				answer = append(answer, &PendingApproval{
+					Activity: activity,
+					Approval: approval,
+				})
+			}
+		}
+	}
+	return answer, nil
+}
+
+func describePendingApproval(pending *PendingApproval) string {
+	text := pending.Activity.RepositoryName() + " " + pending.Activity.Spec.Version
+	if pending.Approval.Environment != "" {
+		text += " to " + pending.Approval.Environment
+	} else {
+		text += " at " + pending.Approval.Name
+	}
+	return text
+}
@@ -0,0 +1,135 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/jx/cmd"
+	cmd_mocks "github.com/jenkins-x/jx/pkg/jx/cmd/mocks"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/tests"
+	. "github.com/petergtz/pegomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestApprove(t *testing.T) {
+	o := &cmd.ApproveOptions{
+		Application: "myapp",
+		Environment: "production",
+		Comment:     "LGTM",
+	}
+	activity := createWaitingForApprovalActivity("myapp", "1.0.1", "production")
+	user := kube.CreateUser("jx", "jx-testing-user", "Test User", "test@example.com")
+	configureApproveTestOptions(t, o, []runtime.Object{activity, user})
+
+	err := o.Run()
+	require.NoError(t, err)
+
+	approval := getApproval(t, &o.CommonOptions, activity.Name)
+	assert.Equal(t, string(v1.ActivityStatusTypeSucceeded), string(approval.Status))
+	assert.NotNil(t, approval.CompletedTimestamp)
+	assert.Equal(t, "LGTM", approval.Comment)
+	require.NotNil(t, approval.Approver)
+	assert.Equal(t, "jx-testing-user", approval.Approver.Login)
+	assert.Equal(t, "test@example.com", approval.Approver.Email)
+}
+
+func TestReject(t *testing.T) {
+	o := &cmd.ApproveOptions{
+		Application: "myapp",
+		Version:     "1.0.1",
+		Reject:      true,
+	}
+	activity := createWaitingForApprovalActivity("myapp", "1.0.1", "production")
+	configureApproveTestOptions(t, o, []runtime.Object{activity})
+
+	err := o.Run()
+	require.NoError(t, err)
+
+	approval := getApproval(t, &o.CommonOptions, activity.Name)
+	assert.Equal(t, string(v1.ActivityStatusTypeAborted), string(approval.Status))
+	require.NotNil(t, approval.Approver)
+	assert.Equal(t, "jx-testing-user", approval.Approver.Login)
+}
+
+func TestApproveNotAnApprover(t *testing.T) {
+	o := &cmd.ApproveOptions{
+		Application: "myapp",
+	}
+	activity := createWaitingForApprovalActivity("myapp", "1.0.1", "production")
+	activity.Spec.Steps[0].Approval.Approvers = []string{"someone-else"}
+	configureApproveTestOptions(t, o, []runtime.Object{activity})
+
+	err := o.Run()
+	assert.Error(t, err)
+
+	approval := getApproval(t, &o.CommonOptions, activity.Name)
+	assert.Equal(t, string(v1.ActivityStatusTypeWaitingForApproval), string(approval.Status))
+}
+
+func TestApproveNoPendingApproval(t *testing.T) {
+	o := &cmd.ApproveOptions{
+		Application: "myapp",
+		Version:     "2.0.0",
+	}
+	activity := createWaitingForApprovalActivity("myapp", "1.0.1", "production")
+	configureApproveTestOptions(t, o, []runtime.Object{activity})
+
+	err := o.Run()
+	assert.Error(t, err)
+}
+
+func configureApproveTestOptions(t *testing.T, o *cmd.ApproveOptions, jxObjects []runtime.Object) {
+	RegisterMockTestingT(t)
+	cmd.ConfigureTestOptionsWithResources(&o.CommonOptions, nil, jxObjects, gits.NewGitCLI(), helm.NewHelmCLI("helm", helm.V2, "", true))
+	o.SkipAuthSecretsMerge = true
+
+	factory := cmd_mocks.NewMockFactory()
+	When(factory.CreateAuthConfigService(AnyString())).ThenReturn(tests.CreateAuthConfigService(), nil)
+	o.Factory = factory
+}
+
+func createWaitingForApprovalActivity(app string, version string, envName string) *v1.PipelineActivity {
+	return &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myorg-" + app + "-master-1",
+			Namespace: "jx",
+		},
+		Spec: v1.PipelineActivitySpec{
+			Pipeline:       "myorg/" + app + "/master",
+			Build:          "1",
+			Version:        version,
+			GitRepository:  app,
+			Workflow:       "myflow",
+			WorkflowStatus: v1.ActivityStatusTypeWaitingForApproval,
+			Steps: []v1.PipelineActivityStep{
+				{
+					Kind: v1.ActivityStepKindTypeApproval,
+					Approval: &v1.ApprovalActivityStep{
+						CoreActivityStep: v1.CoreActivityStep{
+							Name:   "approve-" + envName,
+							Status: v1.ActivityStatusTypeWaitingForApproval,
+						},
+						Environment: envName,
+					},
+				},
+			},
+		},
+	}
+}
+
+func getApproval(t *testing.T, o *cmd.CommonOptions, name string) *v1.ApprovalActivityStep {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	require.NoError(t, err)
+	activity, err := jxClient.JenkinsV1().PipelineActivities(ns).Get(name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotEmpty(t, activity.Spec.Steps)
+	approval := activity.Spec.Steps[0].Approval
+	require.NotNil(t, approval)
+	return approval
+}
@@ -70,6 +70,8 @@ func NewJXCommand(f Factory, in terminal.FileReader, out terminal.FileWriter, er
 	environmentsCommands := []*cobra.Command{
 		NewCmdPreview(f, in, out, err),
 		NewCmdPromote(f, in, out, err),
+		NewCmdApprove(f, in, out, err),
+		NewCmdReject(f, in, out, err),
 	}
 	environmentsCommands = append(environmentsCommands, findCommands("environment", createCommands, deleteCommands, editCommands, getCommands)...)
 
@@ -61,6 +61,7 @@ type CommonOptions struct {
 	ServiceAccount         string
 	Username               string
 	ExternalJenkinsBaseURL string
+	Output                 string
 
 	// common cached clients
 	KubeClientCached    kubernetes.Interface
@@ -160,6 +161,27 @@ func (o *CommonOptions) KubeClientAndDevNamespace() (kubernetes.Interface, strin
 	return kubeClient, o.devNamespace, err
 }
 
+// loadDockerRegistry returns the docker registry of the team from the docker registry ConfigMap in the dev namespace
+func (o *CommonOptions) loadDockerRegistry() (string, error) {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return "", err
+	}
+
+	configMapName := kube.ConfigMapJenkinsDockerRegistry
+	cm, err := kubeClient.CoreV1().ConfigMaps(ns).Get(configMapName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("Could not find ConfigMap %s in namespace %s: %s", configMapName, ns, err)
+	}
+	if cm.Data != nil {
+		dockerRegistry := cm.Data["docker.registry"]
+		if dockerRegistry != "" {
+			return dockerRegistry, nil
+		}
+	}
+	return "", fmt.Errorf("Could not find the docker.registry property in the ConfigMap: %s", configMapName)
+}
+
 func (o *CommonOptions) JXClient() (versioned.Interface, string, error) {
 	if o.Factory == nil {
 		return nil, "", errors.New("command factory is not initialized")
@@ -265,7 +287,7 @@ func (o *CommonOptions) Helm() helm.Helmer {
 		o.helm = helmCLI
 		if helmTemplate {
 			kubeClient, _, _ := o.KubeClient()
-			o.helm = helm.NewHelmTemplate(helmCLI, "", kubeClient)
+			o.helm = helm.NewHelmSecrets(helmCLI, "", kubeClient)
 		} else {
 			o.helm = helmCLI
 		}
@@ -3,9 +3,12 @@ package cmd
 import (
 	"github.com/jenkins-x/jx/pkg/auth"
 	"github.com/jenkins-x/jx/pkg/chats"
+	typev1 "github.com/jenkins-x/jx/pkg/client/clientset/versioned/typed/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/config"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 )
 
 func (o *CommonOptions) createChatProvider(chatConfig *config.ChatConfig) (chats.ChatProvider, error) {
@@ -24,7 +27,118 @@ func (o *CommonOptions) createChatProvider(chatConfig *config.ChatConfig) (chats
 	if err != nil {
 		return nil, err
 	}
-	return chats.CreateChatProvider(server.Kind, server, userAuth, o.BatchMode)
+	kind := server.Kind
+	if kind == "" {
+		kind = chatConfig.Kind
+	}
+	return chats.CreateChatProvider(kind, server, userAuth, o.BatchMode)
+}
+
+// notifyDeveloperChannel posts the message to the developer channel of the chat configuration in the jenkins-x.yml
+// of the directory. Failures are only logged as a notification should never fail the pipeline. Returns the ID of
+// the thread of the message if the chat provider supports threads
+func (o *CommonOptions) notifyDeveloperChannel(dir string, message *chats.Message) string {
+	projectConfig, _, err := config.LoadProjectConfig(dir)
+	if err != nil {
+		log.Warnf("Failed to load the project configuration to notify the developer channel: %s\n", err)
+		return ""
+	}
+	return o.notifyChatDeveloperChannel(projectConfig.Chat, message)
+}
+
+// notifyChatDeveloperChannel posts the message to the developer channel of the given chat configuration
+func (o *CommonOptions) notifyChatDeveloperChannel(chatConfig *config.ChatConfig, message *chats.Message) string {
+	if chatConfig == nil || chatConfig.URL == "" || chatConfig.DeveloperChannel == "" {
+		return ""
+	}
+	provider, err := o.createChatProvider(chatConfig)
+	if err != nil {
+		log.Warnf("Failed to create the chat provider for %s: %s\n", chatConfig.URL, err)
+		return ""
+	}
+	if provider == nil {
+		return ""
+	}
+	threadID, err := provider.PostMessage(chatConfig.DeveloperChannel, message)
+	if err != nil {
+		log.Warnf("Failed to notify the developer channel %s: %s\n", chatConfig.DeveloperChannel, err)
+		return ""
+	}
+	return threadID
+}
+
+// notifyActivityChatThread posts the message using notify as a reply in the chat thread recorded on the
+// PipelineActivity. The thread of the first message is recorded on the PipelineActivity so that the later
+// notifications about the pipeline, such as its promotions, are posted in the same thread
+func (o *CommonOptions) notifyActivityChatThread(activities typev1.PipelineActivityInterface, name string, message *chats.Message, notify func(message *chats.Message) string) {
+	activity, err := activities.Get(name, metav1.GetOptions{})
+	if err != nil {
+		log.Warnf("Failed to find PipelineActivity %s for its chat thread: %s\n", name, err)
+		notify(message)
+		return
+	}
+	message.ThreadID = activity.Annotations[kube.AnnotationChatThread]
+	threadID := notify(message)
+	if threadID == "" || threadID == message.ThreadID {
+		return
+	}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		a, err := activities.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if a.Annotations == nil {
+			a.Annotations = map[string]string{}
+		}
+		a.Annotations[kube.AnnotationChatThread] = threadID
+		_, err = activities.Update(a)
+		return err
+	})
+	if err != nil {
+		log.Warnf("Failed to record the chat thread on PipelineActivity %s: %s\n", name, err)
+	}
+}
+
+// notifyEnvironmentChatThread posts the message using notify as a reply in the chat thread recorded on the
+// Environment, recording the thread of the first message so that later notifications, such as the redeployments of
+// a preview, are posted in the same thread
+func (o *CommonOptions) notifyEnvironmentChatThread(environments typev1.EnvironmentInterface, name string, message *chats.Message, notify func(message *chats.Message) string) {
+	env, err := environments.Get(name, metav1.GetOptions{})
+	if err != nil {
+		log.Warnf("Failed to find Environment %s for its chat thread: %s\n", name, err)
+		notify(message)
+		return
+	}
+	message.ThreadID = env.Annotations[kube.AnnotationChatThread]
+	threadID := notify(message)
+	if threadID == "" || threadID == message.ThreadID {
+		return
+	}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		e, err := environments.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if e.Annotations == nil {
+			e.Annotations = map[string]string{}
+		}
+		e.Annotations[kube.AnnotationChatThread] = threadID
+		_, err = environments.Update(e)
+		return err
+	})
+	if err != nil {
+		log.Warnf("Failed to record the chat thread on Environment %s: %s\n", name, err)
+	}
+}
+
+// hasChatServers returns true if any chat servers are configured so that notifications can be posted
+func (o *CommonOptions) hasChatServers() bool {
+	authConfigSvc, err := o.CreateChatAuthConfigService()
+	if err != nil {
+		log.Warnf("Failed to load the chat servers: %s\n", err)
+		return false
+	}
+	return len(authConfigSvc.Config().Servers) > 0
 }
 
 func (o *CommonOptions) CreateChatAuthConfigService() (auth.AuthConfigService, error) {
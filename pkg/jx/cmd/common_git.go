@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"time"
 
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/auth"
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/issues"
@@ -13,7 +14,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/spf13/cobra"
 	gitcfg "gopkg.in/src-d/go-git.v4/config"
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -99,7 +100,7 @@ func (o *CommonOptions) updatePipelineGitCredentialsSecret(server *auth.AuthServ
 		// lets create a new secret
 		create = true
 		operation = "create"
-		secret = &v1.Secret{
+		secret = &corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:        name,
 				Annotations: annotations,
@@ -301,3 +302,35 @@ func (o *CommonOptions) gitProviderForGitServerURL(gitServiceUrl string, gitKind
 	}
 	return gits.CreateProviderForURL(authConfigSvc, gitKind, gitServiceUrl, o.Git(), o.BatchMode, o.In, o.Out, o.Err)
 }
+
+// ApplyTeamBranchProtection protects the branch of the repository using the branch protection policy of the team.
+// Prow installs are skipped as the Prow branch protector manages the protection of their repositories.
+func (o *CommonOptions) ApplyTeamBranchProtection(gitProvider gits.GitProvider, gitURL string) error {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return err
+	}
+	policy := settings.BranchProtection
+	if policy == nil || settings.PromotionEngine == v1.PromotionEngineProw {
+		return nil
+	}
+	gitInfo, err := gits.ParseGitURL(gitURL)
+	if err != nil {
+		return err
+	}
+	branch := policy.Branch
+	if branch == "" {
+		branch = "master"
+	}
+	protection := &gits.BranchProtection{
+		RequiredStatusContexts:       policy.RequiredStatusContexts,
+		RequiredApprovingReviewCount: policy.RequiredApprovingReviewCount,
+		EnforceAdmins:                policy.EnforceAdmins,
+	}
+	log.Infof("Protecting branch %s of %s/%s\n", util.ColorInfo(branch), gitInfo.Organisation, gitInfo.Name)
+	err = gitProvider.SetBranchProtection(gitInfo.Organisation, gitInfo.Name, branch, protection)
+	if err != nil {
+		return fmt.Errorf("failed to protect branch %s of %s: %s", branch, gitURL, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/jx/cmd"
+	cmd_mocks "github.com/jenkins-x/jx/pkg/jx/cmd/mocks"
+	"github.com/jenkins-x/jx/pkg/kube"
+	. "github.com/petergtz/pegomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextentions_mocks "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// configureBranchProtectionTestOptions uses fake clients for the team settings of the dev environment
+func configureBranchProtectionTestOptions(t *testing.T, o *cmd.CommonOptions, jxObjects []runtime.Object) {
+	RegisterMockTestingT(t)
+	cmd.ConfigureTestOptionsWithResources(o, nil, jxObjects, gits.NewGitCLI(), helm.NewHelmCLI("helm", helm.V2, "", true))
+
+	factory := cmd_mocks.NewMockFactory()
+	When(factory.CreateApiExtensionsClient()).ThenReturn(apiextentions_mocks.NewSimpleClientset(), nil)
+	o.Factory = factory
+}
+
+func TestApplyTeamBranchProtection(t *testing.T) {
+	devEnv := kube.NewPermanentEnvironment("dev")
+	devEnv.Spec.Namespace = "jx"
+	devEnv.Spec.Kind = v1.EnvironmentKindTypeDevelopment
+	devEnv.Spec.TeamSettings.BranchProtection = &v1.BranchProtectionPolicy{
+		RequiredStatusContexts:       []string{"continuous-integration/jenkins/pr-merge"},
+		RequiredApprovingReviewCount: 1,
+		EnforceAdmins:                true,
+	}
+
+	o := &cmd.CommonOptions{}
+	configureBranchProtectionTestOptions(t, o, []runtime.Object{devEnv})
+
+	repo := gits.NewFakeRepository("myorg", "myrepo")
+	provider := gits.NewFakeProvider(repo)
+
+	err := o.ApplyTeamBranchProtection(provider, "https://github.com/myorg/myrepo.git")
+	require.NoError(t, err)
+
+	protection, err := provider.GetBranchProtection("myorg", "myrepo", "master")
+	require.NoError(t, err)
+	require.NotNil(t, protection)
+	assert.Equal(t, []string{"continuous-integration/jenkins/pr-merge"}, protection.RequiredStatusContexts)
+	assert.Equal(t, 1, protection.RequiredApprovingReviewCount)
+	assert.True(t, protection.EnforceAdmins)
+}
+
+func TestApplyTeamBranchProtectionWithoutPolicy(t *testing.T) {
+	o := &cmd.CommonOptions{}
+	configureBranchProtectionTestOptions(t, o, nil)
+
+	repo := gits.NewFakeRepository("myorg", "myrepo")
+	provider := gits.NewFakeProvider(repo)
+
+	err := o.ApplyTeamBranchProtection(provider, "https://github.com/myorg/myrepo.git")
+	require.NoError(t, err)
+
+	protection, err := provider.GetBranchProtection("myorg", "myrepo", "master")
+	require.NoError(t, err)
+	assert.Nil(t, protection)
+}
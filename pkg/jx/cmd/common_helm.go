@@ -149,6 +149,15 @@ func (o *CommonOptions) deleteChart(releaseName string, purge bool) error {
 	return o.Helm().DeleteRelease(ns, releaseName, purge)
 }
 
+// releaseHistory returns the Helmer of the team if it stores the revisions of releases
+func (o *CommonOptions) releaseHistory() (helm.ReleaseHistory, error) {
+	history, ok := o.Helm().(helm.ReleaseHistory)
+	if !ok {
+		return nil, fmt.Errorf("the helm client of the team does not store the revisions of releases. Release history is available for teams installed with --no-tiller")
+	}
+	return history, nil
+}
+
 func (o *CommonOptions) FindHelmChart() (string, error) {
 	dir, err := os.Getwd()
 	if err != nil {
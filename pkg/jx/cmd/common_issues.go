@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jenkins-x/jx/pkg/auth"
 	"github.com/jenkins-x/jx/pkg/config"
@@ -9,6 +10,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/issues"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
 )
 
 func (o *CommonOptions) CreateIssueTrackerAuthConfigService() (auth.AuthConfigService, error) {
@@ -45,6 +47,9 @@ func (o *CommonOptions) createIssueProvider(dir string) (issues.IssueProvider, e
 	if pc != nil {
 		it := pc.IssueTracker
 		if it != nil {
+			if it.Kind == issues.Git {
+				return o.createGitIssueProvider(it, gitConfDir)
+			}
 			if it.URL != "" && it.Kind != "" {
 				authConfigSvc, err := o.CreateIssueTrackerAuthConfigService()
 				if err != nil {
@@ -60,13 +65,31 @@ func (o *CommonOptions) createIssueProvider(dir string) (issues.IssueProvider, e
 			}
 		}
 	}
+	return o.createGitIssueProvider(&config.IssueTrackerConfig{}, gitConfDir)
+}
 
-	if gitConfDir == "" {
-		return nil, fmt.Errorf("No issue tracker configured and no git directory could be found from dir %s\n", dir)
+// createGitIssueProvider creates an issue provider for the issues of a git repository. The repository defaults to the
+// upstream repository of the git directory unless the URL of the issue tracker is a repository URL or the project is
+// of the form owner/repository on the git server of the URL
+func (o *CommonOptions) createGitIssueProvider(it *config.IssueTrackerConfig, gitConfDir string) (issues.IssueProvider, error) {
+	gitUrl := it.URL
+	if gitUrl == "" {
+		if gitConfDir == "" {
+			return nil, fmt.Errorf("No issue tracker configured and no git directory could be found\n")
+		}
+		var err error
+		gitUrl, err = o.Git().DiscoverUpstreamGitURL(gitConfDir)
+		if err != nil {
+			return nil, fmt.Errorf("No issue tracker configured and could not find the upstream git URL for dir %s, due to: %s\n", gitConfDir, err)
+		}
 	}
-	gitUrl, err := o.Git().DiscoverUpstreamGitURL(gitConfDir)
-	if err != nil {
-		return nil, fmt.Errorf("No issue tracker configured and could not find the upstream git URL for dir %s, due to: %s\n", dir, err)
+	if it.Project != "" && strings.Contains(it.Project, "/") {
+		serverURL := gitUrl
+		gitInfo, err := gits.ParseGitURL(gitUrl)
+		if err == nil && gitInfo.Name != "" {
+			serverURL = gitInfo.HostURLWithoutUser()
+		}
+		gitUrl = util.UrlJoin(serverURL, it.Project)
 	}
 	gitInfo, err := gits.ParseGitURL(gitUrl)
 	if err != nil {
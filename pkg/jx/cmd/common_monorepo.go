@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SubProjectEnvVar the environment variable used to specify the app of a monorepo being built
+	SubProjectEnvVar = "SUB_PROJECT"
+)
+
+// monorepoAppFiles the files which indicate a sub directory of a monorepo is an app
+var monorepoAppFiles = []string{
+	"Dockerfile",
+	"pom.xml",
+	"build.gradle",
+	"package.json",
+	"Makefile",
+	"setup.py",
+	"requirements.txt",
+	"Cargo.toml",
+	"go.mod",
+	"Gopkg.toml",
+	"glide.yaml",
+	"Chart.yaml",
+}
+
+// monorepoIgnoreDirs the sub directories which are never apps of a monorepo
+var monorepoIgnoreDirs = []string{"charts", "vendor", "node_modules", "target", "build", "dist"}
+
+// findMonorepoAppDirs returns the sub directories of the given directory which look like apps
+func findMonorepoAppDirs(dir string) ([]string, error) {
+	answer := []string{}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return answer, err
+	}
+	for _, f := range files {
+		name := f.Name()
+		if !f.IsDir() || strings.HasPrefix(name, ".") || util.StringArrayIndex(monorepoIgnoreDirs, name) >= 0 {
+			continue
+		}
+		for _, file := range monorepoAppFiles {
+			exists, err := util.FileExists(filepath.Join(dir, name, file))
+			if err != nil {
+				return answer, err
+			}
+			if exists {
+				answer = append(answer, name)
+				break
+			}
+		}
+	}
+	sort.Strings(answer)
+	return answer, nil
+}
+
+// findMonorepoApp returns the app of the monorepo which contains the given directory or nil if the
+// directory is not inside an app of a monorepo
+func (o *CommonOptions) findMonorepoApp(dir string) (*config.AppConfig, error) {
+	if dir == "" {
+		var err error
+		dir, err = os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+	}
+	gitDir, _, err := o.Git().FindGitConfigDir(dir)
+	if err != nil || gitDir == "" {
+		return nil, err
+	}
+	projectConfig, _, err := config.LoadProjectConfig(gitDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(projectConfig.Apps) == 0 {
+		return nil, nil
+	}
+	rel, err := filepath.Rel(gitDir, dir)
+	if err != nil {
+		return nil, err
+	}
+	return projectConfig.FindAppForPath(rel), nil
+}
+
+// isMonorepo returns true if apps are registered in the jenkins-x.yml at the root of the git repository of the
+// current directory
+func (o *CommonOptions) isMonorepo() bool {
+	gitDir, _, err := o.Git().FindGitConfigDir("")
+	if err != nil || gitDir == "" {
+		return false
+	}
+	projectConfig, _, err := config.LoadProjectConfig(gitDir)
+	return err == nil && len(projectConfig.Apps) > 0
+}
+
+// getSubProject returns the name of the app of a monorepo being built or an empty string if
+// this is not a monorepo
+func (o *CommonOptions) getSubProject() string {
+	answer := os.Getenv(SubProjectEnvVar)
+	if answer != "" {
+		return answer
+	}
+	app, err := o.findMonorepoApp("")
+	if err != nil || app == nil {
+		return ""
+	}
+	return app.Name
+}
+
+// pipelineActivityName returns the name of the PipelineActivity for the pipeline and build which
+// includes the app name when building an app of a monorepo
+func (o *CommonOptions) pipelineActivityName(pipeline string, build string) string {
+	return kube.PipelineActivityName(pipeline, o.getSubProject(), build)
+}
+
+// podSubProject returns the app of a monorepo built by the pod from its $SUB_PROJECT environment variable
+func podSubProject(pod *corev1.Pod) string {
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, c := range containers {
+			for _, env := range c.Env {
+				if env.Name == SubProjectEnvVar && env.Value != "" {
+					return env.Value
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// versionTagName returns the git tag of the version which is prefixed with the app name for an app of a monorepo
+// so that each app is versioned independently
+func versionTagName(subProject string, version string) string {
+	tag := "v" + version
+	if subProject != "" {
+		tag = subProject + "/" + tag
+	}
+	return tag
+}
+
+// tagVersion returns the version of the git tag or an empty string if the tag is not a version tag of the app.
+// The tags of the apps of a monorepo are ignored when looking for the version of the whole monorepo
+func tagVersion(subProject string, monorepo bool, tag string) string {
+	if subProject != "" {
+		prefix := subProject + "/"
+		if !strings.HasPrefix(tag, prefix) {
+			return ""
+		}
+		tag = strings.TrimPrefix(tag, prefix)
+	} else if monorepo && strings.Contains(tag, "/") {
+		return ""
+	}
+	return strings.TrimPrefix(tag, "v")
+}
+
+// latestVersionTag returns the latest version and its git tag of the tags of the app or of the repository when the
+// app is empty. Pre-release versions are ignored unless preReleases is true
+func latestVersionTag(tags []string, subProject string, monorepo bool, preReleases bool) (string, string) {
+	var latest *version.Version
+	latestTag := ""
+	for _, tag := range tags {
+		v, _ := version.NewVersion(tagVersion(subProject, monorepo, tag))
+		if v == nil || (!preReleases && v.Prerelease() != "") {
+			continue
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+			latestTag = tag
+		}
+	}
+	if latest == nil {
+		return "", ""
+	}
+	return latest.String(), latestTag
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	// OutputFormatJSON renders the objects as JSON
+	OutputFormatJSON = "json"
+	// OutputFormatYAML renders the objects as YAML
+	OutputFormatYAML = "yaml"
+	// OutputFormatJSONPath renders the result of a JSONPath expression such as jsonpath={.items[*].metadata.name}
+	OutputFormatJSONPath = "jsonpath"
+	// OutputFormatTemplate renders a go template such as template={{range .items}}{{.metadata.name}}{{end}}
+	OutputFormatTemplate = "template"
+)
+
+// ServerSummary is the name, kind and URL of a server and its users without any of their tokens or passwords
+type ServerSummary struct {
+	Name  string              `json:"name"`
+	Kind  string              `json:"kind"`
+	URL   string              `json:"url"`
+	Users []ServerUserSummary `json:"users,omitempty"`
+}
+
+// ServerUserSummary is a user of a server
+type ServerUserSummary struct {
+	Username string `json:"username"`
+	HasToken bool   `json:"hasToken"`
+}
+
+func (o *CommonOptions) addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "The output format: json, yaml, jsonpath=<expression> or template=<go template>")
+}
+
+// renderResult renders the result in a given output format
+func (o *CommonOptions) renderResult(value interface{}, format string) error {
+	name, arg := format, ""
+	idx := strings.Index(format, "=")
+	if idx >= 0 {
+		name, arg = format[0:idx], format[idx+1:]
+	}
+	switch name {
+	case OutputFormatJSON:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		_, e := o.Out.Write(data)
+		return e
+	case OutputFormatYAML:
+		data, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		_, e := o.Out.Write(data)
+		return e
+	case OutputFormatJSONPath:
+		if arg == "" {
+			return fmt.Errorf("missing expression for output format %s=<expression>", name)
+		}
+		if !strings.HasPrefix(arg, "{") {
+			arg = "{" + arg + "}"
+		}
+		j := jsonpath.New("output")
+		j.AllowMissingKeys(true)
+		err := j.Parse(arg)
+		if err != nil {
+			return fmt.Errorf("failed to parse jsonpath expression %s: %s", arg, err)
+		}
+		data, err := toOutputData(value)
+		if err != nil {
+			return err
+		}
+		return j.Execute(o.Out, data)
+	case OutputFormatTemplate:
+		if arg == "" {
+			return fmt.Errorf("missing template for output format %s=<go template>", name)
+		}
+		t, err := template.New("output").Parse(arg)
+		if err != nil {
+			return fmt.Errorf("failed to parse template %s: %s", arg, err)
+		}
+		data, err := toOutputData(value)
+		if err != nil {
+			return err
+		}
+		return t.Execute(o.Out, data)
+	default:
+		return fmt.Errorf("Unsupported output format: %s", format)
+	}
+}
+
+// renderItems renders the items as a list so that they can be navigated in the same way as kubectl output
+func (o *CommonOptions) renderItems(items interface{}, format string) error {
+	return o.renderResult(map[string]interface{}{
+		"items": items,
+	}, format)
+}
+
+// toOutputData converts the value into the maps and slices of its JSON representation so that expressions
+// and templates use the same field names as the JSON output
+func toOutputData(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var answer interface{}
+	err = json.Unmarshal(data, &answer)
+	return answer, err
+}
+
+// toServerSummary returns the summary of the server using the kind if the server does not specify one
+func toServerSummary(server *auth.AuthServer, kind string) ServerSummary {
+	if server.Kind != "" {
+		kind = server.Kind
+	}
+	answer := ServerSummary{
+		Name: server.Name,
+		Kind: kind,
+		URL:  server.URL,
+	}
+	for _, u := range server.Users {
+		answer.Users = append(answer.Users, ServerUserSummary{
+			Username: u.Username,
+			HasToken: u.ApiToken != "" || u.BearerToken != "",
+		})
+	}
+	return answer
+}
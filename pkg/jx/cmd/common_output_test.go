@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderResult(t *testing.T) {
+	envs := []v1.Environment{
+		*kube.NewPermanentEnvironment("staging"),
+		*kube.NewPermanentEnvironment("production"),
+	}
+
+	assertRenderedItems(t, envs, "jsonpath={.items[*].metadata.name}", "staging production")
+	assertRenderedItems(t, envs, ".items[1].spec.namespace", "jx-production")
+	assertRenderedItems(t, envs, "template={{range .items}}{{.metadata.name}}={{.spec.namespace}};{{end}}", "staging=jx-staging;production=jx-production;")
+	assertRenderedItems(t, envs, "yaml", "items:\n- metadata:\n    creationTimestamp: null\n    name: staging\n")
+	assertRenderedItems(t, envs, "json", "{\"items\":[{\"metadata\":{\"name\":\"staging\",")
+
+	for _, format := range []string{"xml", "jsonpath", "jsonpath={.items[", "template={{.items"} {
+		o := &CommonOptions{}
+		_, err := renderToString(t, o, envs, format)
+		assert.Error(t, err, "output format %s", format)
+	}
+}
+
+func assertRenderedItems(t *testing.T, items interface{}, format string, expected string) {
+	o := &CommonOptions{}
+	if format[0] == '.' {
+		format = "jsonpath=" + format
+	}
+	text, err := renderToString(t, o, items, format)
+	require.NoError(t, err, "output format %s", format)
+	if format == "json" || format == "yaml" {
+		assert.Contains(t, text, expected, "output format %s", format)
+	} else {
+		assert.Equal(t, expected, text, "output format %s", format)
+	}
+}
+
+func renderToString(t *testing.T, o *CommonOptions, items interface{}, format string) (string, error) {
+	f, err := ioutil.TempFile("", "jx-output-")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	o.Out = f
+	err = o.renderItems(items, format)
+	if err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadFile(f.Name())
+	require.NoError(t, err)
+	return string(data), nil
+}
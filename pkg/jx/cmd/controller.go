@@ -50,6 +50,7 @@ func NewCmdController(f Factory, in terminal.FileReader, out terminal.FileWriter
 
 	cmd.AddCommand(NewCmdControllerBackup(f, in, out, errOut))
 	cmd.AddCommand(NewCmdControllerBuild(f, in, out, errOut))
+	cmd.AddCommand(NewCmdControllerNotify(f, in, out, errOut))
 	cmd.AddCommand(NewCmdControllerRole(f, in, out, errOut))
 	cmd.AddCommand(NewCmdControllerTeam(f, in, out, errOut))
 	cmd.AddCommand(NewCmdControllerWorkflow(f, in, out, errOut))
@@ -190,8 +190,8 @@ func (o *ControllerBuildOptions) createPromoteStepActivityKey(buildName string,
 	}
 	org := gitInfo.Organisation
 	repo := gitInfo.Name
-	name := org + "-" + repo + "-" + branch + "-" + build
 	pipeline := org + "/" + repo + "/" + branch
+	name := kube.PipelineActivityName(pipeline, podSubProject(pod), build)
 	return &kube.PromoteStepActivityKey{
 		PipelineActivityKey: kube.PipelineActivityKey{
 			Name:              name,
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/auth"
+	"github.com/jenkins-x/jx/pkg/chats"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+	"gopkg.in/AlecAivazis/survey.v1/terminal"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+)
+
+// ControllerNotifyOptions are the flags for the commands
+type ControllerNotifyOptions struct {
+	ControllerOptions
+
+	Namespace string
+	ChatURL   string
+
+	// DirectMessageProvider the provider used to send the direct messages which defaults to the Slack server
+	DirectMessageProvider chats.DirectMessageProvider
+}
+
+var (
+	controllerNotifyLong = templates.LongDesc(`
+		Runs the controller which sends Slack direct messages to the authors of the commits of a broken release build.
+
+		The authors are found from the commits of the Release of the failed PipelineActivity and are matched
+		to the User resources on their email or login. Users are sent a message if they have a slackUser
+		and have not opted out by annotating their User with ` + kube.AnnotationBrokenBuildNotifications + `=false
+`)
+
+	controllerNotifyExample = templates.Examples(`
+		# run the controller using the Slack server of the chat configuration
+		jx controller notify
+
+		# opt out of direct messages for broken builds
+		kubectl annotate user jstrachan ` + kube.AnnotationBrokenBuildNotifications + `=false
+`)
+)
+
+// NewCmdControllerNotify creates a command object for the "controller notify" command
+func NewCmdControllerNotify(f Factory, in terminal.FileReader, out terminal.FileWriter, errOut io.Writer) *cobra.Command {
+	options := &ControllerNotifyOptions{
+		ControllerOptions: ControllerOptions{
+			CommonOptions: CommonOptions{
+				Factory: f,
+				In:      in,
+				Out:     out,
+				Err:     errOut,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "notify",
+		Short:   "Runs the controller which sends direct messages to the authors of broken release builds",
+		Long:    controllerNotifyLong,
+		Example: controllerNotifyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace to watch or defaults to the current namespace")
+	cmd.Flags().StringVarP(&options.ChatURL, "chat-url", "", "", "The URL of the Slack server. Defaults to the first Slack server in the chat configuration")
+	return cmd
+}
+
+// Run implements this command
+func (o *ControllerNotifyOptions) Run() error {
+	err := o.registerPipelineActivityCRD()
+	if err != nil {
+		return err
+	}
+	err = o.registerReleaseCRD()
+	if err != nil {
+		return err
+	}
+	err = o.registerUserCRD()
+	if err != nil {
+		return err
+	}
+
+	jxClient, devNs, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	ns := o.Namespace
+	if ns == "" {
+		ns = devNs
+	}
+
+	if o.DirectMessageProvider == nil {
+		o.DirectMessageProvider, err = o.createDirectMessageProvider()
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Watching for failed PipelineActivity resources in namespace %s\n", util.ColorInfo(ns))
+	activity := &v1.PipelineActivity{}
+	listWatch := cache.NewListWatchFromClient(jxClient.JenkinsV1().RESTClient(), "pipelineactivities", ns, fields.Everything())
+	kube.SortListWatchByName(listWatch)
+	_, controller := cache.NewInformer(
+		listWatch,
+		activity,
+		time.Minute*10,
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				o.onActivity(obj, jxClient, ns)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				o.onActivity(newObj, jxClient, ns)
+			},
+			DeleteFunc: func(obj interface{}) {
+			},
+		},
+	)
+
+	stop := make(chan struct{})
+	go controller.Run(stop)
+
+	// Wait forever
+	select {}
+}
+
+func (o *ControllerNotifyOptions) onActivity(obj interface{}, jxClient versioned.Interface, ns string) {
+	activity, ok := obj.(*v1.PipelineActivity)
+	if !ok {
+		log.Infof("Object is not a PipelineActivity %#v\n", obj)
+		return
+	}
+	err := o.notifyBrokenBuild(jxClient, ns, activity)
+	if err != nil {
+		log.Warnf("Failed to notify the authors of PipelineActivity %s: %s\n", activity.Name, err)
+	}
+}
+
+// notifyBrokenBuild sends a direct message to each author of the commits of a failed release build. The notified
+// users are recorded on the PipelineActivity which marks it as processed so that users are only notified once
+func (o *ControllerNotifyOptions) notifyBrokenBuild(jxClient versioned.Interface, ns string, activity *v1.PipelineActivity) error {
+	if activity.Spec.Status != v1.ActivityStatusTypeFailed || !isReleaseBranch(activity.BranchName()) {
+		return nil
+	}
+	if _, processed := activity.Annotations[kube.AnnotationNotifiedUsers]; processed {
+		return nil
+	}
+
+	commits, err := kube.FindReleaseCommits(jxClient, ns, activity)
+	if err != nil {
+		return err
+	}
+	notified := map[string]bool{}
+	if len(commits) == 0 {
+		log.Infof("No Release commits found for PipelineActivity %s so cannot find the authors to notify\n", activity.Name)
+	} else {
+		userList, err := jxClient.JenkinsV1().Users(ns).List(metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		message := brokenBuildMessage(activity)
+		for _, user := range kube.FindUsersForCommits(userList.Items, commits) {
+			slackUser := kube.GetUserDetails(user).SlackUser
+			if slackUser == "" || notified[slackUser] {
+				continue
+			}
+			if strings.ToLower(user.Annotations[kube.AnnotationBrokenBuildNotifications]) == "false" {
+				log.Infof("User %s has opted out of broken build notifications\n", user.Name)
+				continue
+			}
+			_, err = o.DirectMessageProvider.PostDirectMessage(slackUser, message)
+			if err != nil {
+				log.Warnf("Failed to send a direct message to Slack user %s: %s\n", slackUser, err)
+				continue
+			}
+			log.Infof("Notified Slack user %s that PipelineActivity %s failed\n", util.ColorInfo(slackUser), activity.Name)
+			notified[slackUser] = true
+		}
+	}
+	names := []string{}
+	for name := range notified {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		a, err := activities.Get(activity.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if a.Annotations == nil {
+			a.Annotations = map[string]string{}
+		}
+		a.Annotations[kube.AnnotationNotifiedUsers] = strings.Join(names, ",")
+		_, err = activities.Update(a)
+		return err
+	})
+}
+
+// brokenBuildMessage returns the direct message describing the failed stage of the PipelineActivity
+func brokenBuildMessage(activity *v1.PipelineActivity) *chats.Message {
+	spec := &activity.Spec
+	url := spec.BuildLogsURL
+	if url == "" {
+		url = spec.BuildURL
+	}
+	lines := []string{}
+	stage := failedStageName(activity)
+	if stage != "" {
+		lines = append(lines, fmt.Sprintf("Stage %s failed", stage))
+	}
+	if spec.LastCommitSHA != "" {
+		commit := spec.LastCommitSHA
+		if len(commit) > 7 {
+			commit = commit[0:7]
+		}
+		if spec.LastCommitMessage != "" {
+			commit += " " + strings.TrimSpace(spec.LastCommitMessage)
+		}
+		lines = append(lines, "Last commit "+commit)
+	}
+	if url != "" {
+		lines = append(lines, "Build log "+url)
+	}
+	return &chats.Message{
+		Title:  fmt.Sprintf("Your commits broke pipeline %s #%s", spec.Pipeline, spec.Build),
+		Text:   strings.Join(lines, "\n"),
+		URL:    url,
+		Status: string(spec.Status),
+	}
+}
+
+// failedStageName returns the name of the first failed stage and its failed step if known
+func failedStageName(activity *v1.PipelineActivity) string {
+	for _, step := range activity.Spec.Steps {
+		stage := step.Stage
+		if stage == nil || stage.Status != v1.ActivityStatusTypeFailed {
+			continue
+		}
+		for _, s := range stage.Steps {
+			if s.Status == v1.ActivityStatusTypeFailed && s.Name != "" {
+				return stage.Name + " / " + s.Name
+			}
+		}
+		return stage.Name
+	}
+	return ""
+}
+
+// createDirectMessageProvider creates the provider for the Slack server of the chat configuration
+func (o *ControllerNotifyOptions) createDirectMessageProvider() (chats.DirectMessageProvider, error) {
+	authConfigSvc, err := o.CreateChatAuthConfigService()
+	if err != nil {
+		return nil, err
+	}
+	config := authConfigSvc.Config()
+	var server *auth.AuthServer
+	if o.ChatURL != "" {
+		server = config.GetServer(o.ChatURL)
+	} else {
+		for _, s := range config.Servers {
+			if s.Kind == chats.Slack {
+				server = s
+				break
+			}
+		}
+	}
+	if server == nil {
+		return nil, fmt.Errorf("no Slack server found in the chat configuration. Try: jx create chat server %s", chats.Slack)
+	}
+	userAuth, err := config.PickServerUserAuth(server, "user to send Slack direct messages", true, "", o.In, o.Out, o.Err)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := chats.CreateChatProvider(chats.Slack, server, userAuth, true)
+	if err != nil {
+		return nil, err
+	}
+	answer, ok := provider.(chats.DirectMessageProvider)
+	if !ok {
+		return nil, fmt.Errorf("the chat provider for %s does not support direct messages", server.URL)
+	}
+	return answer, nil
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/chats"
+	"github.com/jenkins-x/jx/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeDirectMessageProvider struct {
+	messages map[string][]*chats.Message
+}
+
+func (p *fakeDirectMessageProvider) PostDirectMessage(user string, message *chats.Message) (string, error) {
+	p.messages[user] = append(p.messages[user], message)
+	return "", nil
+}
+
+func TestNotifyBrokenBuild(t *testing.T) {
+	t.Parallel()
+	ns := "jx"
+	activity := &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "jenkins-x-myapp-master-3",
+			Namespace: ns,
+		},
+		Spec: v1.PipelineActivitySpec{
+			Pipeline:          "jenkins-x/myapp/master",
+			Build:             "3",
+			Version:           "1.0.3",
+			Status:            v1.ActivityStatusTypeFailed,
+			GitOwner:          "jenkins-x",
+			BuildLogsURL:      "http://nexus/logs/3.log",
+			LastCommitSHA:     "5d8bc3f1a2",
+			LastCommitMessage: "fix: the widget",
+			Steps: []v1.PipelineActivityStep{
+				{
+					Kind: v1.ActivityStepKindTypeStage,
+					Stage: &v1.StageActivityStep{
+						CoreActivityStep: v1.CoreActivityStep{
+							Name:   "Build Release",
+							Status: v1.ActivityStatusTypeFailed,
+						},
+						Steps: []v1.CoreActivityStep{
+							{
+								Name:   "Unit Tests",
+								Status: v1.ActivityStatusTypeFailed,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	release := &v1.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myapp-1.0.3",
+			Namespace: ns,
+		},
+		Spec: v1.ReleaseSpec{
+			Name:     "myapp",
+			Version:  "1.0.3",
+			GitOwner: "jenkins-x",
+			Commits: []v1.CommitSummary{
+				{SHA: "5d8bc3f1a2", Author: &v1.UserDetails{Email: "James@Example.com"}},
+				{SHA: "1a2b3c4d5e", Author: &v1.UserDetails{Login: "jstrachan"}, Committer: &v1.UserDetails{Login: "rawlingsj"}},
+				{SHA: "6f7a8b9c0d", Author: &v1.UserDetails{Email: "optout@example.com"}},
+				{SHA: "0d9c8b7a6f", Author: &v1.UserDetails{Email: "noslack@example.com"}},
+			},
+		},
+	}
+	users := []*v1.User{
+		createNotifyTestUser(ns, "jstrachan", "james@example.com", "U0123ABCD", ""),
+		createNotifyTestUser(ns, "rawlingsj", "rawlingsj@example.com", "rawlingsj", ""),
+		createNotifyTestUser(ns, "optout", "optout@example.com", "U0456EFGH", "false"),
+		createNotifyTestUser(ns, "noslack", "noslack@example.com", "", ""),
+	}
+
+	jxClient := fake.NewSimpleClientset(activity, release, users[0], users[1], users[2], users[3])
+	provider := &fakeDirectMessageProvider{messages: map[string][]*chats.Message{}}
+	o := &ControllerNotifyOptions{
+		DirectMessageProvider: provider,
+	}
+
+	err := o.notifyBrokenBuild(jxClient, ns, activity)
+	require.NoError(t, err)
+
+	assert.Len(t, provider.messages, 2)
+	require.Len(t, provider.messages["U0123ABCD"], 1)
+	require.Len(t, provider.messages["rawlingsj"], 1)
+	message := provider.messages["U0123ABCD"][0]
+	assert.Equal(t, "Your commits broke pipeline jenkins-x/myapp/master #3", message.Title)
+	assert.Equal(t, "Stage Build Release / Unit Tests failed\nLast commit 5d8bc3f fix: the widget\nBuild log http://nexus/logs/3.log", message.Text)
+	assert.Equal(t, "http://nexus/logs/3.log", message.URL)
+
+	updated, err := jxClient.JenkinsV1().PipelineActivities(ns).Get(activity.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "U0123ABCD,rawlingsj", updated.Annotations[kube.AnnotationNotifiedUsers])
+
+	err = o.notifyBrokenBuild(jxClient, ns, updated)
+	require.NoError(t, err)
+	assert.Len(t, provider.messages["U0123ABCD"], 1, "users should only be notified once")
+
+	succeeded := updated.DeepCopy()
+	succeeded.Annotations = nil
+	succeeded.Spec.Status = v1.ActivityStatusTypeSucceeded
+	err = o.notifyBrokenBuild(jxClient, ns, succeeded)
+	require.NoError(t, err)
+	assert.Len(t, provider.messages["U0123ABCD"], 1, "successful builds should not be notified")
+}
+
+func createNotifyTestUser(ns string, login string, email string, slackUser string, notifications string) *v1.User {
+	user := kube.CreateUser(ns, login, login, email)
+	user.Spec.SlackUser = slackUser
+	if notifications != "" {
+		user.Annotations = map[string]string{
+			kube.AnnotationBrokenBuildNotifications: notifications,
+		}
+	}
+	return user
+}
+
+func TestNotifyBrokenBuildWithoutRelease(t *testing.T) {
+	t.Parallel()
+	ns := "jx"
+	activity := &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "jenkins-x-myapp-master-4",
+			Namespace: ns,
+		},
+		Spec: v1.PipelineActivitySpec{
+			Pipeline:      "jenkins-x/myapp/master",
+			Build:         "4",
+			Status:        v1.ActivityStatusTypeFailed,
+			GitOwner:      "jenkins-x",
+			LastCommitSHA: "7e6d5c4b3a",
+		},
+	}
+	user := createNotifyTestUser(ns, "jstrachan", "james@example.com", "U0123ABCD", "")
+	jxClient := fake.NewSimpleClientset(activity, user)
+	provider := &fakeDirectMessageProvider{messages: map[string][]*chats.Message{}}
+	o := &ControllerNotifyOptions{
+		DirectMessageProvider: provider,
+	}
+
+	err := o.notifyBrokenBuild(jxClient, ns, activity)
+	require.NoError(t, err)
+	assert.Empty(t, provider.messages)
+
+	updated, err := jxClient.JenkinsV1().PipelineActivities(ns).Get(activity.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	value, processed := updated.Annotations[kube.AnnotationNotifiedUsers]
+	assert.True(t, processed, "the activity should be marked as processed")
+	assert.Equal(t, "", value)
+}
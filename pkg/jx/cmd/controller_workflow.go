@@ -19,7 +19,9 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/tools/cache"
@@ -171,6 +173,7 @@ func (o *ControllerWorkflowOptions) Run() error {
 			}
 			//o.pollGitPipelineStatuses(jxClient, ns)
 			o.ReloadAndPollGitPipelineStatuses(jxClient, ns)
+			o.pollPendingSteps(jxClient, ns)
 		}
 	}()
 
@@ -275,6 +278,7 @@ func (o *ControllerWorkflowOptions) onActivity(pipeline *v1.PipelineActivity, jx
 	}
 
 	if workflowName == "" {
+		o.resumeQueuedPromotion(pipeline)
 		o.removePipelineActivityIfNoManual(pipeline, activities)
 		return
 	}
@@ -311,33 +315,64 @@ func (o *ControllerWorkflowOptions) onActivity(pipeline *v1.PipelineActivity, jx
 
 		// lets walk the Workflow spec and see if we need to trigger any PRs or move the PipelineActivity forward
 		promoteStatusMap := createPromoteStatus(pipeline)
+		stepStatusMap := createStepStatus(flow, pipeline, promoteStatusMap)
 
 		allStepsComplete := true
-		for _, step := range flow.Spec.Steps {
-			promote := step.Promote
-			if promote != nil {
-				envName := promote.Environment
-				if envName != "" {
-					status := promoteStatusMap[envName]
-					if status == nil || status.PullRequest == nil || status.PullRequest.PullRequestURL == "" {
-						allStepsComplete = false
-						// can we generate a PR now?
-						if canExecuteStep(flow, pipeline, &step, promoteStatusMap, envName) {
-							log.Infof("Creating PR for environment %s from PipelineActivity %s as current status is %#v\n", envName, pipeline.Name, status)
-							po := o.createPromoteOptions(repoName, envName, pipelineName, build, version)
-
-							err := po.Run()
-							if err != nil {
-								log.Warnf("Failed to create PullRequest on pipeline %s repo %s version %s with workflow %s: %s\n", pipeline.Name, repoName, version, workflowName, err)
-							}
-						}
+		failedStep := ""
+		failedStatus := v1.ActivityStatusTypeNone
+		for i, step := range flow.Spec.Steps {
+			name := workflow.StepName(&step, i)
+			status := v1.ActivityStatusTypeNone
+			blocked := false
+			switch {
+			case step.Promote != nil:
+				status = o.executePromoteStep(flow, pipeline, &step, promoteStatusMap, stepStatusMap, step.Promote.Environment)
+				blocked = isBlockedPromotion(promoteStatusMap[step.Promote.Environment])
+			case step.Parallel != nil:
+				status = v1.ActivityStatusTypeSucceeded
+				for _, envName := range step.Parallel.Environments {
+					envStatus := o.executePromoteStep(flow, pipeline, &step, promoteStatusMap, stepStatusMap, envName)
+					if envStatus != v1.ActivityStatusTypeSucceeded && status != v1.ActivityStatusTypeFailed {
+						status = envStatus
 					}
-					if status != nil && status.Status != v1.ActivityStatusTypeSucceeded {
-						allStepsComplete = false
+					if isBlockedPromotion(promoteStatusMap[envName]) {
+						blocked = true
 					}
 				}
+			case step.Approval != nil:
+				status = o.executeApprovalStep(flow, pipeline, &step, name, activities, promoteStatusMap, stepStatusMap)
+			case step.Wait != nil:
+				status = o.executeWaitStep(flow, pipeline, &step, name, activities, promoteStatusMap, stepStatusMap)
+			case step.Job != nil:
+				status = o.executeJobStep(flow, pipeline, &step, name, jxClient, ns, promoteStatusMap, stepStatusMap)
+			default:
+				log.Warnf("Ignoring step %s of Workflow %s with unknown kind %s\n", name, flow.Name, string(step.Kind))
+				continue
+			}
+			stepStatusMap[name] = status
+			if status != v1.ActivityStatusTypeSucceeded {
+				allStepsComplete = false
+			}
+			// failed promotions can still be retried so only the other kinds of step or promotions which gave up
+			// waiting for the CVE policy to be satisfied fail the workflow
+			if (step.Promote == nil && step.Parallel == nil || blocked) && status.IsTerminated() && status != v1.ActivityStatusTypeSucceeded {
+				failedStep = name
+				failedStatus = status
+				break
 			}
 		}
+		if failedStep != "" {
+			err := o.updatePipeline(activities, pipeline, func(a *v1.PipelineActivity) bool {
+				if failedStatus == v1.ActivityStatusTypeAborted {
+					return setActivityStatus(a, v1.ActivityStatusTypeAborted, fmt.Sprintf("Step %s was aborted", failedStep))
+				}
+				return setActivityStatus(a, v1.ActivityStatusTypeFailed, fmt.Sprintf("Step %s did not succeed", failedStep))
+			})
+			if err != nil {
+				log.Warnf("Failed to update PipelineActivity %s due to step %s failing: %s\n", pipeline.Name, failedStep, err)
+			}
+			return
+		}
 		if allStepsComplete && (pipeline.Spec.Status != v1.ActivityStatusTypeSucceeded || pipeline.Spec.WorkflowStatus != v1.ActivityStatusTypeSucceeded) {
 			pipeline.Spec.Status = v1.ActivityStatusTypeSucceeded
 			pipeline.Spec.WorkflowStatus = v1.ActivityStatusTypeSucceeded
@@ -350,6 +385,221 @@ func (o *ControllerWorkflowOptions) onActivity(pipeline *v1.PipelineActivity, jx
 	}
 }
 
+// resumeQueuedPromotion promotes the first queued promotion of a PipelineActivity without a Workflow, such as one
+// created by 'jx promote --all-auto', once the promotions before it have succeeded. The promotion is queued
+// again if the Environment is still outside of its deployment windows
+func (o *ControllerWorkflowOptions) resumeQueuedPromotion(pipeline *v1.PipelineActivity) {
+	for _, step := range pipeline.Spec.Steps {
+		promote := step.Promote
+		if promote == nil || promote.Status == v1.ActivityStatusTypeSucceeded {
+			continue
+		}
+		if promote.Status == v1.ActivityStatusTypePending && promote.PullRequest == nil && promote.Update == nil && promote.Environment != "" {
+			log.Infof("Promoting queued Environment %s from PipelineActivity %s\n", promote.Environment, pipeline.Name)
+			po := o.createPromoteOptionsFromActivity(pipeline, promote.Environment)
+			err := po.Run()
+			if err != nil {
+				log.Warnf("Failed to promote PipelineActivity %s to Environment %s: %s\n", pipeline.Name, promote.Environment, err)
+			}
+		}
+		// the later promotions wait until this one has succeeded
+		return
+	}
+}
+
+// executePromoteStep creates the promotion Pull Request for the environment if its preconditions are met and
+// returns the status of the promotion
+func (o *ControllerWorkflowOptions) executePromoteStep(flow *v1.Workflow, pipeline *v1.PipelineActivity, step *v1.WorkflowStep,
+	promoteStatusMap map[string]*v1.PromoteActivityStep, stepStatusMap map[string]v1.ActivityStatusType, envName string) v1.ActivityStatusType {
+	if envName == "" {
+		return v1.ActivityStatusTypeSucceeded
+	}
+	status := promoteStatusMap[envName]
+	if isBlockedPromotion(status) && status.Status.IsTerminated() {
+		return status.Status
+	}
+	if status == nil || status.PullRequest == nil || status.PullRequest.PullRequestURL == "" {
+		// can we generate a PR now?
+		if canExecuteStep(flow, pipeline, step, promoteStatusMap, stepStatusMap, "promote to Environment: "+envName) {
+			log.Infof("Creating PR for environment %s from PipelineActivity %s as current status is %#v\n", envName, pipeline.Name, status)
+			po := o.createPromoteOptions(pipeline.RepositoryName(), envName, pipeline.Spec.Pipeline, pipeline.Spec.Build, pipeline.Spec.Version)
+
+			err := po.Run()
+			if err != nil {
+				log.Warnf("Failed to create PullRequest on pipeline %s repo %s version %s with workflow %s: %s\n", pipeline.Name, pipeline.RepositoryName(), pipeline.Spec.Version, flow.Name, err)
+			}
+		}
+		return v1.ActivityStatusTypePending
+	}
+	if status.Status == v1.ActivityStatusTypeNone {
+		return v1.ActivityStatusTypePending
+	}
+	return status.Status
+}
+
+// executeApprovalStep puts the PipelineActivity into the WaitingForApproval state until the approval step is approved or rejected
+func (o *ControllerWorkflowOptions) executeApprovalStep(flow *v1.Workflow, pipeline *v1.PipelineActivity, step *v1.WorkflowStep, name string,
+	activities typev1.PipelineActivityInterface, promoteStatusMap map[string]*v1.PromoteActivityStep, stepStatusMap map[string]v1.ActivityStatusType) v1.ActivityStatusType {
+	if status := stepStatusMap[name]; status != v1.ActivityStatusTypeNone {
+		return status
+	}
+	if !canExecuteStep(flow, pipeline, step, promoteStatusMap, stepStatusMap, "start approval: "+name) {
+		return v1.ActivityStatusTypePending
+	}
+	log.Infof("PipelineActivity %s is waiting for approval %s\n", pipeline.Name, name)
+	status := v1.ActivityStatusTypeWaitingForApproval
+	err := o.updatePipeline(activities, pipeline, func(a *v1.PipelineActivity) bool {
+		_, approval, _ := kube.GetOrCreateApproval(a, name)
+		if approval.Status != v1.ActivityStatusTypeNone {
+			status = approval.Status
+			return false
+		}
+		approval.Description = step.Description
+		approval.Environment = step.Approval.Environment
+		approval.Approvers = step.Approval.Approvers
+		approval.Message = step.Approval.Message
+		kube.StartActivityStep(&approval.CoreActivityStep)
+		approval.Status = v1.ActivityStatusTypeWaitingForApproval
+		a.Spec.WorkflowStatus = v1.ActivityStatusTypeWaitingForApproval
+		return true
+	})
+	if err != nil {
+		log.Warnf("Failed to start approval %s on PipelineActivity %s: %s\n", name, pipeline.Name, err)
+		return v1.ActivityStatusTypePending
+	}
+	return status
+}
+
+// executeWaitStep starts the soak period of the wait step and completes it once the duration has elapsed
+func (o *ControllerWorkflowOptions) executeWaitStep(flow *v1.Workflow, pipeline *v1.PipelineActivity, step *v1.WorkflowStep, name string,
+	activities typev1.PipelineActivityInterface, promoteStatusMap map[string]*v1.PromoteActivityStep, stepStatusMap map[string]v1.ActivityStatusType) v1.ActivityStatusType {
+	status := stepStatusMap[name]
+	if status.IsTerminated() {
+		return status
+	}
+	if status == v1.ActivityStatusTypeNone && !canExecuteStep(flow, pipeline, step, promoteStatusMap, stepStatusMap, "start wait: "+name) {
+		return v1.ActivityStatusTypePending
+	}
+	duration, parseErr := time.ParseDuration(step.Wait.Duration)
+	if parseErr != nil {
+		log.Warnf("Invalid duration %s for wait %s in Workflow %s: %s\n", step.Wait.Duration, name, flow.Name, parseErr)
+	}
+	err := o.updatePipeline(activities, pipeline, func(a *v1.PipelineActivity) bool {
+		_, wait, created := kube.GetOrCreateWait(a, name)
+		oldStatus := wait.Status
+		if created {
+			wait.Description = step.Description
+			wait.Duration = step.Wait.Duration
+			kube.StartActivityStep(&wait.CoreActivityStep)
+			log.Infof("PipelineActivity %s is waiting for %s\n", a.Name, wait.Duration)
+		}
+		if parseErr != nil {
+			kube.FailedActivityStep(&wait.CoreActivityStep)
+		} else if !wait.Status.IsTerminated() && time.Now().After(wait.StartedTimestamp.Add(duration)) {
+			kube.CompleteActivityStep(&wait.CoreActivityStep)
+		}
+		status = wait.Status
+		return created || status != oldStatus
+	})
+	if err != nil {
+		log.Warnf("Failed to update wait %s on PipelineActivity %s: %s\n", name, pipeline.Name, err)
+		return v1.ActivityStatusTypePending
+	}
+	return status
+}
+
+// executeJobStep creates the verification Job once the preconditions are met and then completes the step when the Job completes
+func (o *ControllerWorkflowOptions) executeJobStep(flow *v1.Workflow, pipeline *v1.PipelineActivity, step *v1.WorkflowStep, name string,
+	jxClient versioned.Interface, ns string, promoteStatusMap map[string]*v1.PromoteActivityStep, stepStatusMap map[string]v1.ActivityStatusType) v1.ActivityStatusType {
+	status := stepStatusMap[name]
+	if status.IsTerminated() {
+		return status
+	}
+	if status == v1.ActivityStatusTypeNone && !canExecuteStep(flow, pipeline, step, promoteStatusMap, stepStatusMap, "start job: "+name) {
+		return v1.ActivityStatusTypePending
+	}
+	kubeClient, _, err := o.KubeClient()
+	if err != nil {
+		log.Warnf("Failed to create the kubernetes client for job %s: %s\n", name, err)
+		return v1.ActivityStatusTypePending
+	}
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	err = o.updatePipeline(activities, pipeline, func(a *v1.PipelineActivity) bool {
+		_, jobStep, created := kube.GetOrCreateJob(a, name)
+		oldStatus := jobStep.Status
+		if created {
+			jobNs := ns
+			envName := step.Job.Environment
+			if envName != "" {
+				env, err := jxClient.JenkinsV1().Environments(ns).Get(envName, metav1.GetOptions{})
+				if err != nil {
+					log.Warnf("Failed to find environment %s for job %s: %s\n", envName, name, err)
+					kube.FailedActivityStep(&jobStep.CoreActivityStep)
+					status = jobStep.Status
+					return true
+				}
+				if env.Spec.Namespace != "" {
+					jobNs = env.Spec.Namespace
+				}
+			}
+			job := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: kube.ToValidName(a.Name + "-" + name),
+					Labels: map[string]string{
+						"jenkins.io/pipelineactivity": a.Name,
+						"jenkins.io/workflow-step":    name,
+					},
+				},
+				Spec: *step.Job.Spec.DeepCopy(),
+			}
+			if job.Spec.Template.Spec.RestartPolicy == "" {
+				job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+			}
+			jobStep.Description = step.Description
+			jobStep.Environment = envName
+			jobStep.Namespace = jobNs
+			jobStep.Job = job.Name
+			kube.StartActivityStep(&jobStep.CoreActivityStep)
+			// the update may be retried on conflicts so a Job created by an earlier attempt may already exist
+			_, err := kubeClient.BatchV1().Jobs(jobNs).Create(job)
+			if err != nil && !apierrors.IsAlreadyExists(err) {
+				log.Warnf("Failed to create Job %s in namespace %s: %s\n", job.Name, jobNs, err)
+				kube.FailedActivityStep(&jobStep.CoreActivityStep)
+			} else if err == nil {
+				log.Infof("Created Job %s in namespace %s for PipelineActivity %s\n", job.Name, jobNs, a.Name)
+			}
+		} else if !jobStep.Status.IsTerminated() {
+			job, err := kubeClient.BatchV1().Jobs(jobStep.Namespace).Get(jobStep.Job, metav1.GetOptions{})
+			if err != nil {
+				log.Warnf("Failed to find Job %s in namespace %s: %s\n", jobStep.Job, jobStep.Namespace, err)
+				kube.FailedActivityStep(&jobStep.CoreActivityStep)
+			} else if job.Status.Succeeded > 0 {
+				kube.CompleteActivityStep(&jobStep.CoreActivityStep)
+			} else if isJobFailed(job) {
+				log.Warnf("Job %s in namespace %s failed\n", job.Name, job.Namespace)
+				kube.FailedActivityStep(&jobStep.CoreActivityStep)
+			}
+		}
+		status = jobStep.Status
+		return created || status != oldStatus
+	})
+	if err != nil {
+		log.Warnf("Failed to update job %s on PipelineActivity %s: %s\n", name, pipeline.Name, err)
+		return v1.ActivityStatusTypePending
+	}
+	return status
+}
+
+// isJobFailed returns true if the Job has the failed condition
+func isJobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
 func (o *ControllerWorkflowOptions) createPromoteOptions(repoName string, envName string, pipelineName string, build string, version string) *PromoteOptions {
 	po := &PromoteOptions{
 		Application:       repoName,
@@ -378,7 +628,14 @@ func (o *ControllerWorkflowOptions) createPromoteOptionsFromActivity(pipeline *v
 	if repoName == "" && len(paths) > 1 {
 		repoName = paths[len(paths)-2]
 	}
-	return o.createPromoteOptions(repoName, envName, pipelineName, build, version)
+	// the chart of an app of a monorepo is named after the app
+	app := kube.PipelineActivityApp(pipeline)
+	if app != "" {
+		repoName = app
+	}
+	po := o.createPromoteOptions(repoName, envName, pipelineName, build, version)
+	po.SubProject = app
+	return po
 }
 
 func (o *ControllerWorkflowOptions) createGitProviderForPR(prURL string) (gits.GitProvider, *gits.GitRepositoryInfo, error) {
@@ -458,6 +715,42 @@ func (o *ControllerWorkflowOptions) ReloadAndPollGitPipelineStatuses(jxClient ve
 	}
 }
 
+// pollPendingSteps re-evaluates the workflows of the PipelineActivity resources which are waiting for a soak period
+// to elapse or a verification Job to complete
+func (o *ControllerWorkflowOptions) pollPendingSteps(jxClient versioned.Interface, ns string) {
+	pipelines, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		log.Warnf("failed to list PipelineActivity resources: %s\n", err)
+		return
+	}
+	for i := range pipelines.Items {
+		pipeline := &pipelines.Items[i]
+		if hasPendingSteps(pipeline) {
+			o.onActivity(pipeline, jxClient, ns)
+		}
+	}
+}
+
+// hasPendingSteps returns true if the PipelineActivity has a running wait or job step or a promotion queued for a
+// deployment window or blocked by a CVE policy
+func hasPendingSteps(pipeline *v1.PipelineActivity) bool {
+	if pipeline.Spec.WorkflowStatus.IsTerminated() {
+		return false
+	}
+	for _, step := range pipeline.Spec.Steps {
+		if step.Promote != nil && step.Promote.Status == v1.ActivityStatusTypePending && step.Promote.PullRequest == nil {
+			return true
+		}
+		if step.Wait != nil && !step.Wait.Status.IsTerminated() {
+			return true
+		}
+		if step.Job != nil && !step.Job.Status.IsTerminated() {
+			return true
+		}
+	}
+	return false
+}
+
 // pollGitStatusforPipeline polls the pending PipelineActivity resources to see if the
 // PR has merged or the pipeline on master has completed
 func (o *ControllerWorkflowOptions) pollGitStatusforPipeline(activity *v1.PipelineActivity, activities typev1.PipelineActivityInterface, environments typev1.EnvironmentInterface, ns string) {
@@ -674,15 +967,22 @@ func (o *ControllerWorkflowOptions) createReleaseInfo(activity *v1.PipelineActiv
 	}
 }
 
-func canExecuteStep(workflow *v1.Workflow, activity *v1.PipelineActivity, step *v1.WorkflowStep, statusMap map[string]*v1.PromoteActivityStep, promoteToEnv string) bool {
+func canExecuteStep(workflow *v1.Workflow, activity *v1.PipelineActivity, step *v1.WorkflowStep, statusMap map[string]*v1.PromoteActivityStep, stepStatusMap map[string]v1.ActivityStatusType, description string) bool {
 	for _, envName := range step.Preconditions.Environments {
 		status := statusMap[envName]
 		if status == nil {
-			log.Warnf("Cannot promote to Environment: %s as precondition Environment: %s as no status\n", promoteToEnv, envName)
+			log.Warnf("Cannot %s as precondition Environment: %s as no status\n", description, envName)
 			return false
 		}
 		if status.Status != v1.ActivityStatusTypeSucceeded {
-			log.Warnf("Cannot promote to Environment: %s as precondition Environment: %s has status %s", promoteToEnv, envName, string(status.Status))
+			log.Warnf("Cannot %s as precondition Environment: %s has status %s\n", description, envName, string(status.Status))
+			return false
+		}
+	}
+	for _, stepName := range step.Preconditions.Steps {
+		status := stepStatusMap[stepName]
+		if status != v1.ActivityStatusTypeSucceeded {
+			log.Warnf("Cannot %s as precondition step: %s has status %s\n", description, stepName, string(status))
 			return false
 		}
 	}
@@ -704,6 +1004,33 @@ func createPromoteStatus(pipeline *v1.PipelineActivity) map[string]*v1.PromoteAc
 	return answer
 }
 
+// createStepStatus returns a map indexed by step name of the status of the approval, wait, job and parallel steps of the workflow
+func createStepStatus(flow *v1.Workflow, pipeline *v1.PipelineActivity, promoteStatusMap map[string]*v1.PromoteActivityStep) map[string]v1.ActivityStatusType {
+	answer := map[string]v1.ActivityStatusType{}
+	for _, step := range pipeline.Spec.Steps {
+		if step.Approval != nil {
+			answer[step.Approval.Name] = step.Approval.Status
+		} else if step.Wait != nil {
+			answer[step.Wait.Name] = step.Wait.Status
+		} else if step.Job != nil {
+			answer[step.Job.Name] = step.Job.Status
+		}
+	}
+	for i, step := range flow.Spec.Steps {
+		if step.Parallel != nil {
+			status := v1.ActivityStatusTypeSucceeded
+			for _, envName := range step.Parallel.Environments {
+				promote := promoteStatusMap[envName]
+				if promote == nil || promote.Status != v1.ActivityStatusTypeSucceeded {
+					status = v1.ActivityStatusTypePending
+				}
+			}
+			answer[workflow.StepName(&step, i)] = status
+		}
+	}
+	return answer
+}
+
 // createPromoteStepActivityKey deduces the pipeline metadata from the Knative workflow pod
 func (o *ControllerWorkflowOptions) createPromoteStepActivityKey(buildName string, pod *corev1.Pod) *kube.PromoteStepActivityKey {
 	branch := ""
@@ -745,8 +1072,8 @@ func (o *ControllerWorkflowOptions) createPromoteStepActivityKey(buildName strin
 	}
 	org := gitInfo.Organisation
 	repo := gitInfo.Name
-	name := org + "-" + repo + "-" + branch + "-" + build
 	pipeline := org + "/" + repo + "/" + branch
+	name := kube.PipelineActivityName(pipeline, podSubProject(pod), build)
 	return &kube.PromoteStepActivityKey{
 		PipelineActivityKey: kube.PipelineActivityKey{
 			Name:              name,
@@ -772,6 +1099,11 @@ func PullRequestURLToNumber(text string) (int, error) {
 }
 
 func (o *ControllerWorkflowOptions) isReleaseBranch(branchName string) bool {
+	return isReleaseBranch(branchName)
+}
+
+// isReleaseBranch returns true if the branch is used to create releases
+func isReleaseBranch(branchName string) bool {
 	// TODO look in TeamSettings for a list of allowed release branch patterns
 	return branchName == "master"
 }
@@ -793,11 +1125,18 @@ func setActivityAborted(activity *v1.PipelineActivity) bool {
 	return true
 }
 
+func setActivityStatus(activity *v1.PipelineActivity, status v1.ActivityStatusType, message string) bool {
+	activity.Spec.Status = status
+	activity.Spec.WorkflowStatus = status
+	activity.Spec.WorkflowMessage = message
+	return true
+}
+
 func (o *ControllerWorkflowOptions) removePipelineActivity(activity *v1.PipelineActivity, activities typev1.PipelineActivityInterface) {
 	o.modifyAndRemovePipelineActivity(activity, activities, noopCallback)
 }
 
-// removePipelineActivityIfNoManual only remove the PipelineActivity if there is not any pending Promote
+// removePipelineActivityIfNoManual only remove the PipelineActivity if there is not any pending Promote or Approval
 func (o *ControllerWorkflowOptions) removePipelineActivityIfNoManual(activity *v1.PipelineActivity, activities typev1.PipelineActivityInterface) {
 	for _, step := range activity.Spec.Steps {
 		promote := step.Promote
@@ -806,6 +1145,10 @@ func (o *ControllerWorkflowOptions) removePipelineActivityIfNoManual(activity *v
 				return
 			}
 		}
+		approval := step.Approval
+		if approval != nil && approval.Status == v1.ActivityStatusTypeWaitingForApproval {
+			return
+		}
 	}
 	o.removePipelineActivity(activity, activities)
 }
@@ -830,6 +1173,23 @@ func modifyPipeline(activities typev1.PipelineActivityInterface, activity *v1.Pi
 	return nil
 }
 
+// updatePipeline reloads the latest version of the PipelineActivity and applies the callback, updating the
+// PipelineActivity if the callback returns true
+func (o *ControllerWorkflowOptions) updatePipeline(activities typev1.PipelineActivityInterface, activity *v1.PipelineActivity, callback func(activity *v1.PipelineActivity) bool) error {
+	latest, err := activities.Get(activity.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if callback(latest) {
+		latest, err = activities.Update(latest)
+		if err != nil {
+			return err
+		}
+	}
+	*activity = *latest
+	return nil
+}
+
 // isNewestPipeline returns true if this pipeline is the newest pipeline version for a repo
 func (o *ControllerWorkflowOptions) isNewestPipeline(activity *v1.PipelineActivity, activities typev1.PipelineActivityInterface) bool {
 	newest := true
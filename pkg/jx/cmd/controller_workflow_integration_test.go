@@ -16,10 +16,15 @@ import (
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/helm"
 	"github.com/jenkins-x/jx/pkg/jx/cmd"
+	cmd_mocks "github.com/jenkins-x/jx/pkg/jx/cmd/mocks"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/workflow"
+	. "github.com/petergtz/pegomock"
 	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextentions_mocks "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/uuid"
@@ -907,3 +912,634 @@ func NewCreateEnvPullRequestFn(provider *gits.FakeProvider) cmd.CreateEnvPullReq
 	}
 	return fakePrFn
 }
+
+func TestApprovalWorkflow(t *testing.T) {
+	testOrgName := "jstrachan"
+	testRepoName := "approvalrepo"
+	stagingRepoName := "environment-staging"
+	prodRepoName := "environment-production"
+
+	fakeRepo := gits.NewFakeRepository(testOrgName, testRepoName)
+	stagingRepo := gits.NewFakeRepository(testOrgName, stagingRepoName)
+	prodRepo := gits.NewFakeRepository(testOrgName, prodRepoName)
+
+	fakeGitProvider := gits.NewFakeProvider(fakeRepo, stagingRepo, prodRepo)
+
+	o := &cmd.ControllerWorkflowOptions{
+		NoWatch:          true,
+		FakePullRequests: NewCreateEnvPullRequestFn(fakeGitProvider),
+		FakeGitProvider:  fakeGitProvider,
+	}
+
+	staging := kube.NewPermanentEnvironmentWithGit("staging", "https://github.com/"+testOrgName+"/"+stagingRepoName+".git")
+	production := kube.NewPermanentEnvironmentWithGit("production", "https://github.com/"+testOrgName+"/"+prodRepoName+".git")
+
+	myFlowName := "myflow"
+
+	step1 := workflow.CreateWorkflowPromoteStep("staging")
+	step2 := workflow.CreateWorkflowApprovalStep("approve-production", "production", step1)
+	step3 := workflow.CreateWorkflowPromoteStep("production", step2)
+
+	configureWorkflowTestOptions(t, o, nil, []runtime.Object{
+		staging,
+		production,
+		workflow.CreateWorkflow("jx", myFlowName,
+			step1,
+			step2,
+			step3,
+		),
+	})
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	assert.NoError(t, err)
+
+	a, err := createTestPipelineActivity(jxClient, ns, testOrgName, testRepoName, "master", "1", myFlowName)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	err = o.Run()
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	assertHasPullRequestForEnv(t, activities, a.Name, "staging")
+	assertHasNoApproval(t, activities, a.Name, "approve-production")
+
+	if !assertSetPullRequestMerged(t, fakeGitProvider, stagingRepo, 1) {
+		return
+	}
+	if !assertSetPullRequestComplete(t, fakeGitProvider, stagingRepo, 1) {
+		return
+	}
+
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+
+	assertHasPromoteStatus(t, activities, a.Name, "staging", v1.ActivityStatusTypeSucceeded)
+	assertHasApprovalStatus(t, activities, a.Name, "approve-production", v1.ActivityStatusTypeWaitingForApproval)
+	assertHasNoPullRequestForEnv(t, activities, a.Name, "production")
+
+	// still waiting for approval so there should be no production PR
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+	assertHasNoPullRequestForEnv(t, activities, a.Name, "production")
+
+	setApprovalStatus(t, activities, a.Name, "approve-production", v1.ActivityStatusTypeSucceeded)
+
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+	assertHasPullRequestForEnv(t, activities, a.Name, "production")
+
+	if !assertSetPullRequestMerged(t, fakeGitProvider, prodRepo, 1) {
+		return
+	}
+	if !assertSetPullRequestComplete(t, fakeGitProvider, prodRepo, 1) {
+		return
+	}
+
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+
+	assertHasPromoteStatus(t, activities, a.Name, "production", v1.ActivityStatusTypeSucceeded)
+	assertAllPromoteStepsSuccessful(t, activities, a.Name)
+}
+
+func TestRejectedApprovalWorkflow(t *testing.T) {
+	testOrgName := "jstrachan"
+	testRepoName := "rejectrepo"
+	prodRepoName := "environment-production"
+
+	fakeRepo := gits.NewFakeRepository(testOrgName, testRepoName)
+	prodRepo := gits.NewFakeRepository(testOrgName, prodRepoName)
+
+	fakeGitProvider := gits.NewFakeProvider(fakeRepo, prodRepo)
+
+	o := &cmd.ControllerWorkflowOptions{
+		NoWatch:          true,
+		FakePullRequests: NewCreateEnvPullRequestFn(fakeGitProvider),
+		FakeGitProvider:  fakeGitProvider,
+	}
+
+	production := kube.NewPermanentEnvironmentWithGit("production", "https://github.com/"+testOrgName+"/"+prodRepoName+".git")
+
+	myFlowName := "myflow"
+
+	step1 := workflow.CreateWorkflowApprovalStep("approve-production", "production")
+	step2 := workflow.CreateWorkflowPromoteStep("production", step1)
+
+	configureWorkflowTestOptions(t, o, nil, []runtime.Object{
+		production,
+		workflow.CreateWorkflow("jx", myFlowName,
+			step1,
+			step2,
+		),
+	})
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	assert.NoError(t, err)
+
+	a, err := createTestPipelineActivity(jxClient, ns, testOrgName, testRepoName, "master", "1", myFlowName)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	err = o.Run()
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	assertHasApprovalStatus(t, activities, a.Name, "approve-production", v1.ActivityStatusTypeWaitingForApproval)
+
+	// jx reject aborts the approval
+	setApprovalStatus(t, activities, a.Name, "approve-production", v1.ActivityStatusTypeAborted)
+
+	err = o.Run()
+	assert.NoError(t, err)
+
+	assertHasNoPullRequestForEnv(t, activities, a.Name, "production")
+	assertWorkflowStatus(t, activities, a.Name, v1.ActivityStatusTypeAborted)
+}
+
+func TestWaitAndJobWorkflow(t *testing.T) {
+	testOrgName := "jstrachan"
+	testRepoName := "jobrepo"
+	stagingRepoName := "environment-staging"
+	prodRepoName := "environment-production"
+
+	fakeRepo := gits.NewFakeRepository(testOrgName, testRepoName)
+	stagingRepo := gits.NewFakeRepository(testOrgName, stagingRepoName)
+	prodRepo := gits.NewFakeRepository(testOrgName, prodRepoName)
+
+	fakeGitProvider := gits.NewFakeProvider(fakeRepo, stagingRepo, prodRepo)
+
+	o := &cmd.ControllerWorkflowOptions{
+		NoWatch:          true,
+		FakePullRequests: NewCreateEnvPullRequestFn(fakeGitProvider),
+		FakeGitProvider:  fakeGitProvider,
+	}
+
+	staging := kube.NewPermanentEnvironmentWithGit("staging", "https://github.com/"+testOrgName+"/"+stagingRepoName+".git")
+	production := kube.NewPermanentEnvironmentWithGit("production", "https://github.com/"+testOrgName+"/"+prodRepoName+".git")
+
+	myFlowName := "myflow"
+
+	jobSpec := batchv1.JobSpec{
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:  "verify",
+						Image: "busybox",
+					},
+				},
+			},
+		},
+	}
+	step1 := workflow.CreateWorkflowPromoteStep("staging")
+	step2 := workflow.CreateWorkflowWaitStep("soak-staging", "0s", step1)
+	step3 := workflow.CreateWorkflowJobStep("verify-staging", "staging", jobSpec, step2)
+	step4 := workflow.CreateWorkflowPromoteStep("production", step3)
+
+	configureWorkflowTestOptions(t, o, nil, []runtime.Object{
+		staging,
+		production,
+		workflow.CreateWorkflow("jx", myFlowName,
+			step1,
+			step2,
+			step3,
+			step4,
+		),
+	})
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	assert.NoError(t, err)
+	kubeClient, _, err := o.KubeClient()
+	assert.NoError(t, err)
+
+	a, err := createTestPipelineActivity(jxClient, ns, testOrgName, testRepoName, "master", "1", myFlowName)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	err = o.Run()
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	assertHasPullRequestForEnv(t, activities, a.Name, "staging")
+
+	if !assertSetPullRequestMerged(t, fakeGitProvider, stagingRepo, 1) {
+		return
+	}
+	if !assertSetPullRequestComplete(t, fakeGitProvider, stagingRepo, 1) {
+		return
+	}
+
+	// the soak period completes straight away and then the verification Job is created
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+
+	activity, err := activities.Get(a.Name, metav1.GetOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, wait, _ := kube.GetOrCreateWait(activity, "soak-staging")
+	assert.Equal(t, string(v1.ActivityStatusTypeSucceeded), string(wait.Status), "wait status")
+	_, jobStep, _ := kube.GetOrCreateJob(activity, "verify-staging")
+	assert.Equal(t, string(v1.ActivityStatusTypeRunning), string(jobStep.Status), "job status")
+	assert.Equal(t, "jx-staging", jobStep.Namespace, "job namespace")
+	assertHasNoPullRequestForEnv(t, activities, a.Name, "production")
+
+	job, err := kubeClient.BatchV1().Jobs(jobStep.Namespace).Get(jobStep.Job, metav1.GetOptions{})
+	if !assert.NoError(t, err, "verification Job should have been created") {
+		return
+	}
+	job.Status.Succeeded = 1
+	_, err = kubeClient.BatchV1().Jobs(jobStep.Namespace).Update(job)
+	assert.NoError(t, err)
+
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+
+	assertHasPullRequestForEnv(t, activities, a.Name, "production")
+}
+
+func TestJobStepWhenJobAlreadyExists(t *testing.T) {
+	testOrgName := "jstrachan"
+	testRepoName := "existingjobrepo"
+	stagingRepoName := "environment-staging"
+
+	fakeRepo := gits.NewFakeRepository(testOrgName, testRepoName)
+	stagingRepo := gits.NewFakeRepository(testOrgName, stagingRepoName)
+
+	fakeGitProvider := gits.NewFakeProvider(fakeRepo, stagingRepo)
+
+	o := &cmd.ControllerWorkflowOptions{
+		NoWatch:          true,
+		FakePullRequests: NewCreateEnvPullRequestFn(fakeGitProvider),
+		FakeGitProvider:  fakeGitProvider,
+	}
+
+	staging := kube.NewPermanentEnvironmentWithGit("staging", "https://github.com/"+testOrgName+"/"+stagingRepoName+".git")
+
+	myFlowName := "myflow"
+
+	jobSpec := batchv1.JobSpec{
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:  "verify",
+						Image: "busybox",
+					},
+				},
+			},
+		},
+	}
+
+	// a Job created by an earlier attempt to update the PipelineActivity which conflicted
+	existingJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kube.ToValidName(testOrgName + "-" + testRepoName + "-master-1-verify-staging"),
+			Namespace: "jx-staging",
+		},
+		Spec: jobSpec,
+	}
+
+	configureWorkflowTestOptions(t, o, []runtime.Object{existingJob}, []runtime.Object{
+		staging,
+		workflow.CreateWorkflow("jx", myFlowName,
+			workflow.CreateWorkflowJobStep("verify-staging", "staging", jobSpec),
+		),
+	})
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	assert.NoError(t, err)
+
+	a, err := createTestPipelineActivity(jxClient, ns, testOrgName, testRepoName, "master", "1", myFlowName)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	err = o.Run()
+	assert.NoError(t, err)
+
+	activity, err := jxClient.JenkinsV1().PipelineActivities(ns).Get(a.Name, metav1.GetOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, jobStep, _ := kube.GetOrCreateJob(activity, "verify-staging")
+	assert.Equal(t, string(v1.ActivityStatusTypeRunning), string(jobStep.Status), "job status")
+	assert.Equal(t, existingJob.Name, jobStep.Job, "job name")
+}
+
+func TestParallelStepWorkflow(t *testing.T) {
+	testOrgName := "jstrachan"
+	testRepoName := "fanoutrepo"
+
+	fakeRepo := gits.NewFakeRepository(testOrgName, testRepoName)
+	repoA := gits.NewFakeRepository(testOrgName, "environment-a")
+	repoB := gits.NewFakeRepository(testOrgName, "environment-b")
+	repoC := gits.NewFakeRepository(testOrgName, "environment-c")
+
+	fakeGitProvider := gits.NewFakeProvider(fakeRepo, repoA, repoB, repoC)
+
+	o := &cmd.ControllerWorkflowOptions{
+		NoWatch:          true,
+		FakePullRequests: NewCreateEnvPullRequestFn(fakeGitProvider),
+		FakeGitProvider:  fakeGitProvider,
+	}
+
+	envA := kube.NewPermanentEnvironmentWithGit("a", "https://github.com/"+testOrgName+"/environment-a.git")
+	envB := kube.NewPermanentEnvironmentWithGit("b", "https://github.com/"+testOrgName+"/environment-b.git")
+	envC := kube.NewPermanentEnvironmentWithGit("c", "https://github.com/"+testOrgName+"/environment-c.git")
+
+	myFlowName := "myflow"
+
+	step1 := workflow.CreateWorkflowParallelPromoteStep("regions", []string{"a", "b"})
+	step2 := workflow.CreateWorkflowPromoteStep("c", step1)
+
+	configureWorkflowTestOptions(t, o, nil, []runtime.Object{
+		envA,
+		envB,
+		envC,
+		workflow.CreateWorkflow("jx", myFlowName,
+			step1,
+			step2,
+		),
+	})
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	assert.NoError(t, err)
+
+	a, err := createTestPipelineActivity(jxClient, ns, testOrgName, testRepoName, "master", "1", myFlowName)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	err = o.Run()
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	assertHasPullRequestForEnv(t, activities, a.Name, "a")
+	assertHasPullRequestForEnv(t, activities, a.Name, "b")
+	assertHasNoPullRequestForEnv(t, activities, a.Name, "c")
+
+	for _, repo := range []*gits.FakeRepository{repoA, repoB} {
+		if !assertSetPullRequestMerged(t, fakeGitProvider, repo, 1) {
+			return
+		}
+		if !assertSetPullRequestComplete(t, fakeGitProvider, repo, 1) {
+			return
+		}
+	}
+
+	// each poll completes a single promotion
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+	assertHasPullRequestForEnv(t, activities, a.Name, "c")
+
+	if !assertSetPullRequestMerged(t, fakeGitProvider, repoC, 1) {
+		return
+	}
+	if !assertSetPullRequestComplete(t, fakeGitProvider, repoC, 1) {
+		return
+	}
+
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+	assertAllPromoteStepsSuccessful(t, activities, a.Name)
+}
+
+func TestDeploymentWindowWorkflow(t *testing.T) {
+	testOrgName := "jstrachan"
+	testRepoName := "windowrepo"
+	stagingRepoName := "environment-staging"
+
+	fakeRepo := gits.NewFakeRepository(testOrgName, testRepoName)
+	stagingRepo := gits.NewFakeRepository(testOrgName, stagingRepoName)
+
+	fakeGitProvider := gits.NewFakeProvider(fakeRepo, stagingRepo)
+
+	o := &cmd.ControllerWorkflowOptions{
+		NoWatch:          true,
+		FakePullRequests: NewCreateEnvPullRequestFn(fakeGitProvider),
+		FakeGitProvider:  fakeGitProvider,
+	}
+
+	staging := kube.NewPermanentEnvironmentWithGit("staging", "https://github.com/"+testOrgName+"/"+stagingRepoName+".git")
+	staging.Spec.DeploymentWindows = []v1.DeploymentWindow{
+		{
+			Name:     "new-year",
+			Schedule: "0 0 1 1 *",
+			Duration: "1s",
+		},
+	}
+
+	myFlowName := "myflow"
+
+	configureWorkflowTestOptions(t, o, nil, []runtime.Object{
+		staging,
+		workflow.CreateWorkflow("jx", myFlowName,
+			workflow.CreateWorkflowPromoteStep("staging"),
+		),
+	})
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	assert.NoError(t, err)
+
+	a, err := createTestPipelineActivity(jxClient, ns, testOrgName, testRepoName, "master", "1", myFlowName)
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	err = o.Run()
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	// the promotion is queued until the deployment window opens
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	assertHasPromoteStatus(t, activities, a.Name, "staging", v1.ActivityStatusTypePending)
+	activity, err := activities.Get(a.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	for _, step := range activity.Spec.Steps {
+		if step.Promote != nil {
+			assert.Nil(t, step.Promote.PullRequest, "queued promotion should not have a Pull Request")
+		}
+	}
+	assert.Empty(t, stagingRepo.PullRequests, "no Pull Request should be created outside the deployment window")
+
+	// lets open the deployment window
+	environments := jxClient.JenkinsV1().Environments(ns)
+	env, err := environments.Get("staging", metav1.GetOptions{})
+	assert.NoError(t, err)
+	env.Spec.DeploymentWindows[0].Schedule = "0 0 * * *"
+	env.Spec.DeploymentWindows[0].Duration = "24h"
+	_, err = environments.Update(env)
+	assert.NoError(t, err)
+
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+
+	assertHasPullRequestForEnv(t, activities, a.Name, "staging")
+	assertHasPromoteStatus(t, activities, a.Name, "staging", v1.ActivityStatusTypeRunning)
+}
+
+func TestDeploymentWindowAllAutomatic(t *testing.T) {
+	testOrgName := "jstrachan"
+	testRepoName := "autorepo"
+	stagingRepoName := "environment-staging"
+	prodRepoName := "environment-production"
+
+	fakeRepo := gits.NewFakeRepository(testOrgName, testRepoName)
+	stagingRepo := gits.NewFakeRepository(testOrgName, stagingRepoName)
+	prodRepo := gits.NewFakeRepository(testOrgName, prodRepoName)
+
+	fakeGitProvider := gits.NewFakeProvider(fakeRepo, stagingRepo, prodRepo)
+
+	o := &cmd.ControllerWorkflowOptions{
+		NoWatch:          true,
+		FakePullRequests: NewCreateEnvPullRequestFn(fakeGitProvider),
+		FakeGitProvider:  fakeGitProvider,
+	}
+
+	staging := kube.NewPermanentEnvironmentWithGit("staging", "https://github.com/"+testOrgName+"/"+stagingRepoName+".git")
+	production := kube.NewPermanentEnvironmentWithGit("production", "https://github.com/"+testOrgName+"/"+prodRepoName+".git")
+	staging.Spec.Order = 100
+	production.Spec.Order = 200
+	staging.Spec.DeploymentWindows = []v1.DeploymentWindow{
+		{
+			Name:     "new-year",
+			Schedule: "0 0 1 1 *",
+			Duration: "1s",
+		},
+	}
+
+	dev := kube.NewPermanentEnvironment("dev")
+	dev.Spec.Namespace = "jx"
+	dev.Spec.Kind = v1.EnvironmentKindTypeDevelopment
+	dev.Spec.PromotionStrategy = v1.PromotionStrategyTypeNever
+
+	configureWorkflowTestOptions(t, o, nil, []runtime.Object{dev, staging, production})
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	assert.NoError(t, err)
+
+	a, err := createTestPipelineActivity(jxClient, ns, testOrgName, testRepoName, "master", "1", "")
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+
+	// lets promote the way the release pipeline does with 'jx promote --all-auto'
+	po := &cmd.PromoteOptions{
+		Application:       testRepoName,
+		Pipeline:          a.Spec.Pipeline,
+		Build:             a.Spec.Build,
+		Version:           a.Spec.Version,
+		AllAutomatic:      true,
+		NoPoll:            true,
+		IgnoreLocalFiles:  true,
+		HelmRepositoryURL: helm.DefaultHelmRepositoryURL,
+		LocalHelmRepoName: kube.LocalHelmRepoName,
+		FakePullRequests:  NewCreateEnvPullRequestFn(fakeGitProvider),
+	}
+	po.CommonOptions = o.CommonOptions
+	po.BatchMode = true
+	err = po.Run()
+	assert.NoError(t, err)
+
+	// staging is queued until its deployment window opens and production waits for staging
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	assertHasPromoteStatus(t, activities, a.Name, "staging", v1.ActivityStatusTypePending)
+	assertHasPromoteStatus(t, activities, a.Name, "production", v1.ActivityStatusTypePending)
+	assert.Empty(t, stagingRepo.PullRequests, "no Pull Request should be created outside the deployment window")
+	assert.Empty(t, prodRepo.PullRequests, "production should not be promoted before staging")
+
+	err = o.Run()
+	assert.NoError(t, err)
+	assert.Empty(t, stagingRepo.PullRequests, "the deployment window is still closed")
+
+	// lets open the deployment window
+	environments := jxClient.JenkinsV1().Environments(ns)
+	env, err := environments.Get("staging", metav1.GetOptions{})
+	assert.NoError(t, err)
+	env.Spec.DeploymentWindows[0].Schedule = "0 0 * * *"
+	env.Spec.DeploymentWindows[0].Duration = "24h"
+	_, err = environments.Update(env)
+	assert.NoError(t, err)
+
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+	assertHasPullRequestForEnv(t, activities, a.Name, "staging")
+	assert.Empty(t, prodRepo.PullRequests, "production should not be promoted before staging")
+
+	if !assertSetPullRequestMerged(t, fakeGitProvider, stagingRepo, 1) {
+		return
+	}
+	if !assertSetPullRequestComplete(t, fakeGitProvider, stagingRepo, 1) {
+		return
+	}
+
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+	assertHasPromoteStatus(t, activities, a.Name, "staging", v1.ActivityStatusTypeSucceeded)
+	pollGitStatusAndReactToPipelineChanges(t, o, jxClient, ns)
+	assertHasPullRequestForEnv(t, activities, a.Name, "production")
+}
+
+// configureWorkflowTestOptions configures the controller with fake clients and git
+func configureWorkflowTestOptions(t *testing.T, o *cmd.ControllerWorkflowOptions, k8sObjects []runtime.Object, jxObjects []runtime.Object) {
+	RegisterMockTestingT(t)
+	cmd.ConfigureTestOptionsWithResources(&o.CommonOptions, k8sObjects, jxObjects, gits.NewGitCLI(), helm.NewHelmCLI("helm", helm.V2, "", true))
+	o.GitClient = &gits.GitFake{}
+
+	factory := cmd_mocks.NewMockFactory()
+	When(factory.CreateApiExtensionsClient()).ThenReturn(apiextentions_mocks.NewSimpleClientset(), nil)
+	o.Factory = factory
+}
+
+func assertHasApprovalStatus(t *testing.T, activities typev1.PipelineActivityInterface, name string, approvalName string, status v1.ActivityStatusType) {
+	activity, err := activities.Get(name, metav1.GetOptions{})
+	if err != nil {
+		assert.NoError(t, err, "Could not find PipelineActivity %s", name)
+		return
+	}
+	for _, step := range activity.Spec.Steps {
+		approval := step.Approval
+		if approval != nil && approval.Name == approvalName {
+			if !assert.Equal(t, string(status), string(approval.Status), "approval status for %s approval %s", name, approvalName) {
+				dumpFailedActivity(activity)
+			}
+			return
+		}
+	}
+	assert.Fail(t, "Missing Approval", "No Approval %s found on PipelineActivity %s", approvalName, name)
+	dumpFailedActivity(activity)
+}
+
+func assertHasNoApproval(t *testing.T, activities typev1.PipelineActivityInterface, name string, approvalName string) {
+	activity, err := activities.Get(name, metav1.GetOptions{})
+	if err != nil {
+		assert.NoError(t, err, "Could not find PipelineActivity %s", name)
+		return
+	}
+	for _, step := range activity.Spec.Steps {
+		approval := step.Approval
+		if approval != nil && approval.Name == approvalName {
+			assert.Fail(t, "Should not have an Approval %s but has %v", approvalName, approval)
+			return
+		}
+	}
+}
+
+func setApprovalStatus(t *testing.T, activities typev1.PipelineActivityInterface, name string, approvalName string, status v1.ActivityStatusType) {
+	activity, err := activities.Get(name, metav1.GetOptions{})
+	if !assert.NoError(t, err, "Could not find PipelineActivity %s", name) {
+		return
+	}
+	_, approval, _ := kube.GetOrCreateApproval(activity, approvalName)
+	approval.Status = status
+	_, err = activities.Update(activity)
+	assert.NoError(t, err, "Failed to update PipelineActivity %s", name)
+}
@@ -237,6 +237,10 @@ func (o *CreateEnvOptions) Run() error {
 			// register the webhook
 			return o.createWebhookProw(gitURL, gitProvider)
 		}
+		err = o.ApplyTeamBranchProtection(gitProvider, gitURL)
+		if err != nil {
+			log.Warnf("%s\n", err)
+		}
 		return o.ImportProject(gitURL, envDir, jenkins.DefaultJenkinsfile, o.BranchPattern, o.EnvJobCredentials, false, gitProvider, authConfigSvc, true, o.BatchMode)
 	}
 
@@ -23,6 +23,9 @@ var (
 		# Add a new Git server with a name
 		jx create git server bitbucket http://bitbucket.org -n MyBitBucket 
 
+		# Add a new Azure DevOps organisation
+		jx create git server azure https://dev.azure.com/myorg
+
 		For more documentation see: [https://jenkins-x.io/developing/git/](https://jenkins-x.io/developing/git/)
 
 	`)
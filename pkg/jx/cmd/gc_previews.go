@@ -7,15 +7,20 @@ import (
 	"github.com/spf13/cobra"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 
 	"strconv"
 
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	typev1 "github.com/jenkins-x/jx/pkg/client/clientset/versioned/typed/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
 )
 
 // GetOptions is the start of the data required to perform the operation.  As new fields are added, add them here instead of
@@ -23,8 +28,14 @@ import (
 type GCPreviewsOptions struct {
 	CommonOptions
 
-	DisableImport bool
-	OutDir        string
+	DisableImport    bool
+	OutDir           string
+	DryRun           bool
+	TimeToLive       string
+	IdleTimeout      string
+	HibernateAfter   string
+	IngressNamespace string
+	IngressSelector  string
 }
 
 var (
@@ -32,11 +43,29 @@ var (
 		Garbage collect Jenkins X preview environments.  If a pull request is merged or closed the associated preview
 		environment will be deleted.
 
+		Preview environments are also deleted when they have not been deployed for longer than their time to live or
+		have had no deployments, ingress requests or wake ups for longer than their idle timeout. These default to the
+		'previewEnvironments' configuration in the jenkins-x.yml of the repository or the '--ttl' and '--idle-timeout' flags.
+		Ingress requests are detected by comparing the request counts of the nginx ingress controller for the namespace
+		of each preview with the counts seen by the previous garbage collection.
+
+		Idle preview environments can also be hibernated by scaling their deployments to zero using the 'hibernateAfter'
+		configuration or the '--hibernate-after' flag. They wake up on the next push or via 'jx preview wake'.
+
 `)
 
 	GCPreviewsExample = templates.Examples(`
 		jx garbage collect previews
 		jx gc previews
+
+		# report which preview environments would be deleted without deleting them
+		jx gc previews --dry-run
+
+		# delete any preview environments which have been idle for more than a day
+		jx gc previews --idle-timeout 24h
+
+		# scale preview environments which have been idle for two hours to zero
+		jx gc previews --hibernate-after 2h
 `)
 )
 
@@ -63,12 +92,31 @@ func NewCmdGCPreviews(f Factory, in terminal.FileReader, out terminal.FileWriter
 			CheckErr(err)
 		},
 	}
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Reports the preview environments which would be deleted or hibernated without changing them")
+	cmd.Flags().StringVarP(&options.TimeToLive, "ttl", "", "", "The default duration such as 72h after its last deployment when a preview environment is deleted")
+	cmd.Flags().StringVarP(&options.IdleTimeout, "idle-timeout", "", "", "The default duration such as 24h without deployments, ingress requests or wake ups after which a preview environment is deleted")
+	cmd.Flags().StringVarP(&options.HibernateAfter, "hibernate-after", "", "", "The default duration such as 2h without deployments, ingress requests or wake ups after which the deployments of a preview environment are scaled to zero")
+	cmd.Flags().StringVarP(&options.IngressNamespace, "ingress-namespace", "", "kube-system", "The namespace of the nginx ingress controller used to detect ingress requests to preview environments")
+	cmd.Flags().StringVarP(&options.IngressSelector, "ingress-selector", "", kube.DefaultIngressControllerSelector, "The label selector of the nginx ingress controller pods")
 	options.addCommonFlags(cmd)
 	return cmd
 }
 
 // Run implements this command
 func (o *GCPreviewsOptions) Run() error {
+	ttl, err := parseOptionalDuration(o.TimeToLive, "ttl")
+	if err != nil {
+		return err
+	}
+	idleTimeout, err := parseOptionalDuration(o.IdleTimeout, "idle-timeout")
+	if err != nil {
+		return err
+	}
+	hibernateAfter, err := parseOptionalDuration(o.HibernateAfter, "hibernate-after")
+	if err != nil {
+		return err
+	}
+
 	f := o.Factory
 	client, currentNs, err := f.CreateJXClient()
 	if err != nil {
@@ -88,51 +136,203 @@ func (o *GCPreviewsOptions) Run() error {
 		return nil
 	}
 
+	previews := []v1.Environment{}
 	for _, e := range envs.Items {
 		if e.Spec.Kind == v1.EnvironmentKindTypePreview {
-			gitInfo, err := gits.ParseGitURL(e.Spec.Source.URL)
-			if err != nil {
-				return err
-			}
-			// we need pull request info to include
-			authConfigSvc, err := o.CreateGitAuthConfigService()
-			if err != nil {
-				return err
-			}
+			previews = append(previews, e)
+		}
+	}
 
-			gitKind, err := o.GitServerKind(gitInfo)
-			if err != nil {
-				return err
-			}
+	expired := []kube.ExpiredPreview{}
+	active := []v1.Environment{}
+	for i := range previews {
+		e := &previews[i]
+		state, err := o.pullRequestState(e)
+		if err != nil {
+			return err
+		}
+		lowerState := strings.ToLower(state)
+		if strings.HasPrefix(lowerState, "clos") || strings.HasPrefix(lowerState, "merged") || strings.HasPrefix(lowerState, "superseded") || strings.HasPrefix(lowerState, "declined") {
+			expired = append(expired, kube.ExpiredPreview{
+				Environment: e,
+				Reason:      fmt.Sprintf("pull request is %s", lowerState),
+			})
+		} else {
+			active = append(active, *e)
+		}
+	}
 
-			gitProvider, err := gitInfo.CreateProvider(authConfigSvc, gitKind, o.Git(), o.BatchMode, o.In, o.Out, o.Err)
-			if err != nil {
-				return err
-			}
-			prNum, err := strconv.Atoi(e.Spec.PreviewGitSpec.Name)
-			if err != nil {
-				log.Warn("Unable to convert PR " + e.Spec.PreviewGitSpec.Name + " to a number" + "\n")
-			}
-			pullRequest, err := gitProvider.GetPullRequest(gitInfo.Organisation, gitInfo, prNum)
-			if err != nil {
-				return err
-			}
+	kubeClient, _, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	if ttl > 0 || idleTimeout > 0 || hibernateAfter > 0 || o.previewsHaveExpiry(active) {
+		err = o.recordPreviewTraffic(kubeClient, client.JenkinsV1().Environments(currentNs), active)
+		if err != nil {
+			return err
+		}
+	}
+
+	idle, err := kube.FindExpiredPreviews(active, ttl, idleTimeout, time.Now())
+	if err != nil {
+		return err
+	}
+	expired = append(expired, idle...)
+
+	expiredNames := map[string]bool{}
+	for _, e := range expired {
+		expiredNames[e.Environment.Name] = true
+	}
+	remaining := []v1.Environment{}
+	for _, e := range active {
+		if !expiredNames[e.Name] {
+			remaining = append(remaining, e)
+		}
+	}
+	hibernate, err := kube.FindPreviewsToHibernate(remaining, hibernateAfter, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if o.DryRun {
+		if len(expired) == 0 && len(hibernate) == 0 {
+			log.Infoln("No preview environments would be deleted or hibernated")
+			return nil
+		}
+		table := o.CreateTable()
+		table.AddRow("PREVIEW", "ACTION", "LAST DEPLOYED", "LAST ACTIVE", "REASON")
+		for _, e := range expired {
+			table.AddRow(e.Environment.Name, "delete",
+				kube.GetPreviewLastDeployed(e.Environment).Format(time.RFC3339),
+				kube.GetPreviewLastActive(e.Environment).Format(time.RFC3339),
+				e.Reason)
+		}
+		for i := range hibernate {
+			e := &hibernate[i]
+			table.AddRow(e.Name, "hibernate",
+				kube.GetPreviewLastDeployed(e).Format(time.RFC3339),
+				kube.GetPreviewLastActive(e).Format(time.RFC3339),
+				"idle")
+		}
+		table.Render()
+		return nil
+	}
 
-			lowerState := strings.ToLower(*pullRequest.State)
-
-			if strings.HasPrefix(lowerState, "clos") || strings.HasPrefix(lowerState, "merged") || strings.HasPrefix(lowerState, "superseded") || strings.HasPrefix(lowerState, "declined") {
-				// lets delete the preview environment
-				deleteOpts := DeleteEnvOptions{
-					DeleteNamespace: true,
-					CommonOptions:   o.CommonOptions,
-				}
-				deleteOpts.CommonOptions.Args = []string{e.Name}
-				err = deleteOpts.Run()
-				if err != nil {
-					return fmt.Errorf("failed to delete preview environment %s: %v\n", e.Name, err)
-				}
+	for _, e := range expired {
+		log.Infof("Deleting preview environment %s: %s\n", util.ColorInfo(e.Environment.Name), e.Reason)
+		err = o.deletePreviewEnvironment(e.Environment.Name)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range hibernate {
+		err = o.hibernatePreview(kubeClient, client.JenkinsV1().Environments(currentNs), &hibernate[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// previewsHaveExpiry returns true if any of the previews has its own time to live, idle timeout or hibernate after
+// duration
+func (o *GCPreviewsOptions) previewsHaveExpiry(previews []v1.Environment) bool {
+	for _, e := range previews {
+		for _, name := range []string{kube.AnnotationPreviewTimeToLive, kube.AnnotationPreviewIdleTimeout, kube.AnnotationPreviewHibernateAfter} {
+			if e.Annotations[name] != "" {
+				return true
 			}
 		}
 	}
+	return false
+}
+
+// recordPreviewTraffic records when the previews last received ingress requests using the request counts of the
+// ingress controller. Failing to get the counts only disables detecting traffic so that idle previews are still
+// garbage collected
+func (o *GCPreviewsOptions) recordPreviewTraffic(kubeClient kubernetes.Interface, environments typev1.EnvironmentInterface, previews []v1.Environment) error {
+	counts, err := kube.GetIngressRequestCounts(kubeClient, o.IngressNamespace, o.IngressSelector)
+	if err != nil {
+		log.Warnf("Could not detect ingress requests to preview environments: %s\n", err)
+		return nil
+	}
+	now := time.Now()
+	for i := range previews {
+		e := &previews[i]
+		if !kube.RecordPreviewTraffic(e, counts[e.Spec.Namespace], now) || o.DryRun {
+			continue
+		}
+		updated, err := environments.Update(e)
+		if err != nil {
+			return fmt.Errorf("Failed to update Environment %s due to %s", e.Name, err)
+		}
+		previews[i] = *updated
+	}
 	return nil
 }
+
+// hibernatePreview scales the deployments of the preview environment to zero and lets the pull request know
+func (o *GCPreviewsOptions) hibernatePreview(kubeClient kubernetes.Interface, environments typev1.EnvironmentInterface, env *v1.Environment) error {
+	log.Infof("Hibernating idle preview environment %s last active at %s\n", util.ColorInfo(env.Name), kube.GetPreviewLastActive(env).Format(time.RFC3339))
+	err := kube.HibernatePreview(kubeClient, env)
+	if err != nil {
+		return err
+	}
+	_, err = environments.Update(env)
+	if err != nil {
+		return fmt.Errorf("Failed to update Environment %s due to %s", env.Name, err)
+	}
+	comment := fmt.Sprintf(":zzz: preview environment **%s** has been hibernated as it was idle. Push a commit or run `jx preview wake %s` to wake it up", env.Name, env.Name)
+	err = o.commentOnPreviewPullRequest(env, comment)
+	if err != nil {
+		log.Warnf("Failed to comment on the Pull Request: %s\n", err)
+	}
+	return nil
+}
+
+// pullRequestState returns the state of the pull request of the preview environment
+func (o *GCPreviewsOptions) pullRequestState(e *v1.Environment) (string, error) {
+	gitInfo, err := gits.ParseGitURL(e.Spec.Source.URL)
+	if err != nil {
+		return "", err
+	}
+	// we need pull request info to include
+	authConfigSvc, err := o.CreateGitAuthConfigService()
+	if err != nil {
+		return "", err
+	}
+
+	gitKind, err := o.GitServerKind(gitInfo)
+	if err != nil {
+		return "", err
+	}
+
+	gitProvider, err := gitInfo.CreateProvider(authConfigSvc, gitKind, o.Git(), o.BatchMode, o.In, o.Out, o.Err)
+	if err != nil {
+		return "", err
+	}
+	prNum, err := strconv.Atoi(e.Spec.PreviewGitSpec.Name)
+	if err != nil {
+		log.Warn("Unable to convert PR " + e.Spec.PreviewGitSpec.Name + " to a number" + "\n")
+	}
+	pullRequest, err := gitProvider.GetPullRequest(gitInfo.Organisation, gitInfo, prNum)
+	if err != nil {
+		return "", err
+	}
+	if pullRequest.State == nil {
+		return "", nil
+	}
+	return *pullRequest.State, nil
+}
+
+// parseOptionalDuration parses the duration of the flag returning zero if it is empty
+func parseOptionalDuration(value string, flag string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid duration format %s for option --%s: %s", value, flag, err)
+	}
+	return d, nil
+}
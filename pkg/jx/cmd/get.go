@@ -1,14 +1,12 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
 
-	"github.com/ghodss/yaml"
 	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/util"
 )
@@ -17,8 +15,6 @@ import (
 // referencing the cmd.Flags()
 type GetOptions struct {
 	CommonOptions
-
-	Output string
 }
 
 var (
@@ -110,29 +106,7 @@ func outputEmptyListWarning(out io.Writer) error {
 
 func (o *GetOptions) addGetFlags(cmd *cobra.Command) {
 	o.Cmd = cmd
-	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "The output format such as 'yaml'")
-}
-
-// renderResult renders the result in a given output format
-func (o *GetOptions) renderResult(value interface{}, format string) error {
-	switch format {
-	case "json":
-		data, err := json.Marshal(value)
-		if err != nil {
-			return err
-		}
-		_, e := o.Out.Write(data)
-		return e
-	case "yaml":
-		data, err := yaml.Marshal(value)
-		if err != nil {
-			return err
-		}
-		_, e := o.Out.Write(data)
-		return e
-	default:
-		return fmt.Errorf("Unsupported output format: %s", format)
-	}
+	o.addOutputFlags(cmd)
 }
 
 func formatInt32(n int32) string {
@@ -47,6 +47,9 @@ var (
 
 		# Watch the activities for application 'foo'
 		jx get act -f foo -w
+
+		# Output the activities for application 'foo' as YAML
+		jx get act -f foo -o yaml
 	`)
 )
 
@@ -76,6 +79,7 @@ func NewCmdGetActivity(f Factory, in terminal.FileReader, out terminal.FileWrite
 	cmd.Flags().StringVarP(&options.Filter, "filter", "f", "", "Text to filter the pipeline names")
 	cmd.Flags().StringVarP(&options.BuildNumber, "build", "b", "", "The build number to filter on")
 	cmd.Flags().BoolVarP(&options.Watch, "watch", "w", false, "Whether to watch the activities for changes")
+	options.addOutputFlags(cmd)
 	return cmd
 }
 
@@ -122,6 +126,15 @@ func (o *GetActivityOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		activities := []v1.PipelineActivity{}
+		for _, activity := range list.Items {
+			if o.matches(&activity) {
+				activities = append(activities, activity)
+			}
+		}
+		return o.renderItems(activities, o.Output)
+	}
 	for _, activity := range list.Items {
 		o.addTableRow(&table, &activity)
 	}
@@ -200,6 +213,15 @@ func (o *GetActivityOptions) onActivity(table *tbl.Table, obj interface{}, yamlS
 		old := yamlSpecMap[name]
 		if old == "" || old != text {
 			yamlSpecMap[name] = text
+			if o.Output != "" {
+				if o.matches(activity) {
+					err = o.renderResult(activity, o.Output)
+					if err != nil {
+						log.Warnf("Failed to render PipelineActivity %s: %s\n", name, err)
+					}
+				}
+				return
+			}
 			if o.addTableRow(table, activity) {
 				table.Render()
 				table.Clear()
@@ -218,6 +240,14 @@ func (o *CommonOptions) addStepRow(table *tbl.Table, parent *v1.PipelineActivity
 		addPreviewRow(table, preview, indent)
 	} else if promote != nil {
 		addPromoteRow(table, promote, indent)
+	} else if parent.Approval != nil {
+		addApprovalRow(table, parent.Approval, indent)
+	} else if parent.Wait != nil {
+		addStepRowItem(table, &parent.Wait.CoreActivityStep, indent, "Wait", util.ColorInfo(parent.Wait.Duration))
+	} else if parent.Job != nil {
+		addStepRowItem(table, &parent.Job.CoreActivityStep, indent, "Job", util.ColorInfo(parent.Job.Namespace+"/"+parent.Job.Job))
+	} else if parent.Rollback != nil {
+		addRollbackRow(table, parent.Rollback, indent)
 	} else {
 		log.Warnf("Unknown step kind %#v\n", parent)
 	}
@@ -268,6 +298,25 @@ func addPromoteRow(table *tbl.Table, parent *v1.PromoteActivityStep, indent stri
 	}
 }
 
+func addApprovalRow(table *tbl.Table, parent *v1.ApprovalActivityStep, indent string) {
+	description := parent.Message
+	if parent.Environment != "" {
+		description = "for promotion to " + util.ColorInfo(parent.Environment) + " " + description
+	}
+	if parent.Approver != nil {
+		description += " by " + util.ColorInfo(parent.Approver.Login)
+	}
+	addStepRowItem(table, &parent.CoreActivityStep, indent, "Approval", strings.TrimSpace(description))
+}
+
+func addRollbackRow(table *tbl.Table, parent *v1.RollbackActivityStep, indent string) {
+	description := "from " + util.ColorInfo(parent.Version) + " to " + util.ColorInfo(parent.PreviousVersion)
+	if parent.PullRequestURL != "" {
+		description += " PullRequest: " + util.ColorInfo(parent.PullRequestURL)
+	}
+	addStepRowItem(table, &parent.CoreActivityStep, indent, "Rollback", description)
+}
+
 func addStepRowItem(table *tbl.Table, step *v1.CoreActivityStep, indent string, name string, description string) {
 	text := step.Description
 	if description != "" {
@@ -18,6 +18,14 @@ type GetAddonOptions struct {
 	GetOptions
 }
 
+// AddonSummary is the chart and status of an addon
+type AddonSummary struct {
+	Name    string `json:"name"`
+	Chart   string `json:"chart"`
+	Enabled bool   `json:"enabled"`
+	Status  string `json:"status,omitempty"`
+}
+
 var (
 	get_addon_long = templates.LongDesc(`
 		Display the available addons
@@ -57,6 +65,7 @@ func NewCmdGetAddon(f Factory, in terminal.FileReader, out terminal.FileWriter,
 		},
 	}
 
+	options.addGetFlags(cmd)
 	return cmd
 }
 
@@ -84,6 +93,19 @@ func (o *GetAddonOptions) Run() error {
 
 	charts := kube.AddonCharts
 
+	if o.Output != "" {
+		addons := []AddonSummary{}
+		for _, k := range util.SortedMapKeys(charts) {
+			addons = append(addons, AddonSummary{
+				Name:    k,
+				Chart:   charts[k],
+				Enabled: addonEnabled[k],
+				Status:  statusMap[k],
+			})
+		}
+		return o.renderItems(addons, o.Output)
+	}
+
 	table := o.CreateTable()
 	table.AddRow("NAME", "CHART", "ENABLED", "STATUS")
 
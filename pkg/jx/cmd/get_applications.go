@@ -16,6 +16,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/util"
 	"k8s.io/api/apps/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 // GetApplicationsOptions containers the CLI options
@@ -52,6 +53,9 @@ var (
 
 		# List applications just showing the versions (hiding urls and pod counts)
 		jx get apps -u -p
+
+		# Output the applications in the Staging environment as JSON
+		jx get apps -e staging -o json
 	`)
 )
 
@@ -83,6 +87,7 @@ func NewCmdGetApplications(f Factory, in terminal.FileReader, out terminal.FileW
 	cmd.Flags().BoolVarP(&options.Previews, "preview", "w", false, "Show preview environments only")
 	cmd.Flags().StringVarP(&options.Environment, "env", "e", "", "Filter applications in the given environment")
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "Filter applications in the given namespace")
+	options.addOutputFlags(cmd)
 	return cmd
 }
 
@@ -91,6 +96,17 @@ type EnvApps struct {
 	Apps        map[string]v1beta1.Deployment
 }
 
+// ApplicationSummary is the version, pod counts and URL of an application in an environment
+type ApplicationSummary struct {
+	Name        string `json:"name"`
+	Environment string `json:"environment"`
+	Namespace   string `json:"namespace"`
+	Version     string `json:"version,omitempty"`
+	Replicas    int32  `json:"replicas"`
+	ReadyPods   int32  `json:"readyPods"`
+	URL         string `json:"url,omitempty"`
+}
+
 // Run implements this command
 func (o *GetApplicationsOptions) Run() error {
 	f := o.Factory
@@ -160,11 +176,38 @@ func (o *GetApplicationsOptions) Run() error {
 		}
 	}
 	util.ReverseStrings(namespaces)
+	sort.Strings(apps)
+
+	if o.Output != "" {
+		summaries := []ApplicationSummary{}
+		for _, appName := range apps {
+			for _, ea := range envApps {
+				d, ok := ea.Apps[appName]
+				if !ok {
+					continue
+				}
+				summary := ApplicationSummary{
+					Name:        appName,
+					Environment: ea.Environment.Name,
+					Namespace:   d.Namespace,
+					Version:     kube.GetVersion(&d.ObjectMeta),
+					ReadyPods:   d.Status.ReadyReplicas,
+				}
+				if d.Spec.Replicas != nil {
+					summary.Replicas = *d.Spec.Replicas
+				}
+				if !o.HideUrl {
+					summary.URL = applicationURL(kubeClient, &d, appName)
+				}
+				summaries = append(summaries, summary)
+			}
+		}
+		return o.renderItems(summaries, o.Output)
+	}
 	if len(apps) == 0 {
 		log.Infof("No applications found in environments %s\n", strings.Join(envNames, ", "))
 		return nil
 	}
-	sort.Strings(apps)
 
 	table := o.CreateTable()
 	title := "APPLICATION"
@@ -209,24 +252,7 @@ func (o *GetApplicationsOptions) Run() error {
 				row = append(row, pods)
 			}
 			if !o.HideUrl {
-				url, _ := kube.FindServiceURL(kubeClient, d.Namespace, appName)
-				if url == "" {
-					url, _ = kube.FindServiceURL(kubeClient, d.Namespace, d.Name)
-				}
-				if url == "" {
-					// handle helm3
-					chart := d.Labels["chart"]
-					if chart != "" {
-						idx := strings.LastIndex(chart, "-")
-						if idx > 0 {
-							svcName := chart[0:idx]
-							if svcName != appName && svcName != d.Name {
-								url, _ = kube.FindServiceURL(kubeClient, d.Namespace, svcName)
-							}
-						}
-					}
-				}
-				row = append(row, url)
+				row = append(row, applicationURL(kubeClient, &d, appName))
 			}
 		}
 		table.AddRow(row...)
@@ -234,3 +260,25 @@ func (o *GetApplicationsOptions) Run() error {
 	table.Render()
 	return nil
 }
+
+// applicationURL returns the URL of the service of the application deployment
+func applicationURL(kubeClient kubernetes.Interface, d *v1beta1.Deployment, appName string) string {
+	url, _ := kube.FindServiceURL(kubeClient, d.Namespace, appName)
+	if url == "" {
+		url, _ = kube.FindServiceURL(kubeClient, d.Namespace, d.Name)
+	}
+	if url == "" {
+		// handle helm3
+		chart := d.Labels["chart"]
+		if chart != "" {
+			idx := strings.LastIndex(chart, "-")
+			if idx > 0 {
+				svcName := chart[0:idx]
+				if svcName != appName && svcName != d.Name {
+					url, _ = kube.FindServiceURL(kubeClient, d.Namespace, svcName)
+				}
+			}
+		}
+	}
+	return url
+}
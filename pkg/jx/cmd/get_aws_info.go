@@ -63,6 +63,12 @@ func (o *GetAWSInfoOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.renderResult(map[string]string{
+			"accountID": id,
+			"region":    region,
+		}, o.Output)
+	}
 	log.Infof("AWS Account ID: %s\n", util.ColorInfo(id))
 	log.Infof("AWS Region:     %s\n", util.ColorInfo(region))
 	return nil
@@ -74,6 +74,9 @@ func (o *GetBranchPatternOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.renderResult(patterns, o.Output)
+	}
 	table := o.CreateTable()
 	table.AddRow("BRANCH PATTERNS")
 	table.AddRow(patterns.DefaultBranchPattern)
@@ -7,13 +7,12 @@ import (
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
 
 	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // GetBuildOptions the command line options
 type GetBuildOptions struct {
 	CommonOptions
-
-	Output string
 }
 
 var (
@@ -30,6 +29,9 @@ var (
 
 		# List all URLs for services in the current namespace
 		jx get url
+
+		# Display the builds of the team as YAML
+		jx get build -o yaml
 	`)
 )
 
@@ -59,10 +61,22 @@ func NewCmdGetBuild(f Factory, in terminal.FileReader, out terminal.FileWriter,
 	}
 
 	cmd.AddCommand(NewCmdGetBuildLogs(f, in, out, errOut))
+	options.addOutputFlags(cmd)
 	return cmd
 }
 
 // Run implements this command
 func (o *GetBuildOptions) Run() error {
-	return o.Cmd.Help()
+	if o.Output == "" {
+		return o.Cmd.Help()
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	list, err := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	return o.renderItems(list.Items, o.Output)
 }
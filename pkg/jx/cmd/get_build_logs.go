@@ -42,6 +42,9 @@ var (
 	get_build_log_example = templates.Examples(`
 		# List all registered Git server URLs
 		jx get git
+
+		# Display the details of the latest build of a pipeline as JSON rather than its log
+		jx get build log myorg/myapp/master -o json
 	`)
 )
 
@@ -76,6 +79,7 @@ func NewCmdGetBuildLogs(f Factory, in terminal.FileReader, out terminal.FileWrit
 	cmd.Flags().StringVarP(&options.Filter, "filter", "f", "", "Filters all the available jobs by those that contain the given text")
 	cmd.Flags().IntVarP(&options.Build, "build", "b", 0, "The build number to view")
 
+	options.addGetFlags(cmd)
 	return cmd
 }
 
@@ -142,6 +146,9 @@ func (o *GetBuildLogsOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.renderResult(last, o.Output)
+	}
 	log.Infof("%s %s\n", util.ColorStatus("view the log at:"), util.ColorInfo(util.UrlJoin(last.Url, "/console")))
 	return o.tailBuild(name, &last)
 }
@@ -225,6 +232,9 @@ func (o *GetBuildLogsOptions) getProwBuildLog(kubeClient kubernetes.Interface, j
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.renderResult(build, o.Output)
+	}
 	log.Infof("Getting the log of pipeline %s build %s\n", util.ColorInfo(name), util.ColorInfo("#"+strconv.Itoa(buildNumber)))
 
 	pods, err := builds.GetBuildPods(kubeClient, ns)
@@ -71,6 +71,12 @@ func (o *GetBuildPackOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.renderResult(map[string]string{
+			"gitURL": settings.BuildPackURL,
+			"gitRef": settings.BuildPackRef,
+		}, o.Output)
+	}
 	table := o.CreateTable()
 	table.AddRow("BUILD PACK GIT URL", "GIT REF")
 	table.AddRow(settings.BuildPackURL, settings.BuildPackRef)
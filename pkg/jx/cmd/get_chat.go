@@ -60,6 +60,7 @@ func NewCmdGetChat(f Factory, in terminal.FileReader, out terminal.FileWriter, e
 		},
 	}
 	cmd.Flags().StringVarP(&options.Kind, "kind", "k", "", "Filters the chats by the kinds: "+strings.Join(chats.ChatKinds, ", "))
+	options.addGetFlags(cmd)
 	return cmd
 }
 
@@ -71,6 +72,16 @@ func (o *GetChatOptions) Run() error {
 	}
 	config := authConfigSvc.Config()
 
+	if o.Output != "" {
+		servers := []ServerSummary{}
+		for _, s := range config.Servers {
+			if o.Kind == "" || o.Kind == s.Kind {
+				servers = append(servers, toServerSummary(s, ""))
+			}
+		}
+		return o.renderItems(servers, o.Output)
+	}
+
 	if len(config.Servers) == 0 {
 		log.Infof("No chat servers registered. To register a new chat servers use: %s\n", util.ColorInfo("jx create chat server"))
 		return nil
@@ -59,6 +59,7 @@ func NewCmdGetConfig(f Factory, in terminal.FileReader, out terminal.FileWriter,
 		},
 	}
 	options.addGetConfigFlags(cmd)
+	options.addGetFlags(cmd)
 	return cmd
 }
 
@@ -72,6 +73,9 @@ func (o *GetConfigOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.renderResult(pc, o.Output)
+	}
 	if pc.IsEmpty() {
 		log.Infoln("No project configuration for this directory.")
 		log.Infof("To edit the configuration use: %s\n", util.ColorInfo("jx edit config"))
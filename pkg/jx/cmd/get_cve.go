@@ -39,6 +39,9 @@ var (
 		jx get cve --app foo --version 1.0.0
 		jx get cve --app foo --environment staging
 		jx get cve --environment staging
+
+		# Output the vulnerabilities as JSON
+		jx get cve --image-name jenkinsxio/nexus --version 0.0.6 -o json
 	`)
 )
 
@@ -71,6 +74,7 @@ func NewCmdGetCVE(f Factory, in terminal.FileReader, out terminal.FileWriter, er
 	}
 
 	options.addCommonFlags(cmd)
+	options.addGetFlags(cmd)
 	options.addGetCVEFlags(cmd)
 
 	return cmd
@@ -96,34 +100,21 @@ func (o *GetCVEOptions) Run() error {
 		return fmt.Errorf("cannot create jx client: %v", err)
 	}
 
-	externalURL, err := o.ensureAddonServiceAvailable(kube.AddonServices[defaultAnchoreName])
-	if err != nil {
-		log.Warnf("no CVE provider service found, are you in your teams dev environment?  Type `jx env` to switch.\n")
-		return fmt.Errorf("if no CVE provider running, try running `jx create addon anchore` in your teams dev environment: %v", err)
-	}
-
 	// if no flags are set try and guess the image name from the current directory
 	if o.ImageID == "" && o.ImageName == "" && o.Env == "" {
 		return fmt.Errorf("no --image-name, --image-id or --environment flags set\n")
 	}
 
-	server, auth, err := o.CommonOptions.getAddonAuthByKind(kube.ValueKindCVE, externalURL)
-	if err != nil {
-		return fmt.Errorf("error getting anchore engine auth details, %v", err)
-	}
-
-	p, err := cve.NewAnchoreProvider(server, auth)
+	p, err := o.CreateCVEProvider()
 	if err != nil {
-		return fmt.Errorf("error creating anchore provider, %v", err)
+		return err
 	}
-	table := o.CreateTable()
-	table.AddRow("Image", util.ColorInfo("Severity"), "Vulnerability", "URL", "Package", "Fix")
 
 	query := cve.CVEQuery{
 		ImageID:     o.ImageID,
 		ImageName:   o.ImageName,
 		Environment: o.Env,
-		Vesion:      o.Version,
+		Version:     o.Version,
 	}
 
 	if o.Env != "" {
@@ -134,11 +125,60 @@ func (o *GetCVEOptions) Run() error {
 		query.TargetNamespace = targetNamespace
 	}
 
-	err = p.GetImageVulnerabilityTable(jxClient, o.KubeClientCached, &table, query)
+	images, err := cve.GetVulnerabilities(p, o.KubeClientCached, query)
 	if err != nil {
-		return fmt.Errorf("error getting vulnerability table for image %s: %v", query.ImageID, err)
+		return fmt.Errorf("error getting vulnerabilities for image %s: %v", query.ImageID, err)
 	}
 
+	if o.Output != "" {
+		return o.renderItems(images, o.Output)
+	}
+
+	table := o.CreateTable()
+	table.AddRow("Image", util.ColorInfo("Severity"), "Vulnerability", "URL", "Package", "Fix")
+	cve.AddVulnerabilityTableRows(&table, images)
 	table.Render()
 	return nil
 }
+
+// CreateCVEProvider creates the CVE provider for the first addon auth server with a CVE provider kind such as clair or trivy
+// falling back to the Anchore addon running in the team's dev environment
+func (o *CommonOptions) CreateCVEProvider() (cve.CVEProvider, error) {
+	authConfigSvc, err := o.CreateAddonAuthConfigService()
+	if err != nil {
+		return nil, err
+	}
+	config := authConfigSvc.Config()
+	for _, server := range config.Servers {
+		if util.StringArrayIndex(cve.ProviderKinds, server.Kind) < 0 {
+			continue
+		}
+		message := "user to access the " + server.Kind + " CVE provider at " + server.URL
+		userAuth, err := config.PickServerUserAuth(server, message, o.BatchMode, "", o.In, o.Out, o.Err)
+		if err != nil {
+			return nil, err
+		}
+		p, err := cve.NewCVEProvider(server, userAuth)
+		if err != nil {
+			return nil, fmt.Errorf("error creating %s provider, %v", server.Kind, err)
+		}
+		return p, nil
+	}
+
+	externalURL, err := o.ensureAddonServiceAvailable(kube.AddonServices[defaultAnchoreName])
+	if err != nil {
+		log.Warnf("no CVE provider service found, are you in your teams dev environment?  Type `jx env` to switch.\n")
+		return nil, fmt.Errorf("if no CVE provider running, try running `jx create addon anchore` in your teams dev environment or `jx create token addon --kind clair|trivy` for an existing server: %v", err)
+	}
+
+	server, auth, err := o.getAddonAuthByKind(kube.ValueKindCVE, externalURL)
+	if err != nil {
+		return nil, fmt.Errorf("error getting anchore engine auth details, %v", err)
+	}
+
+	p, err := cve.NewCVEProvider(server, auth)
+	if err != nil {
+		return nil, fmt.Errorf("error creating anchore provider, %v", err)
+	}
+	return p, nil
+}
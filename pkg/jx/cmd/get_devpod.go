@@ -10,6 +10,7 @@ import (
 
 	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // GetDevPodOptions the command line options
@@ -58,6 +59,7 @@ func NewCmdGetDevPod(f Factory, in terminal.FileReader, out terminal.FileWriter,
 		},
 	}
 
+	options.addGetFlags(cmd)
 	return cmd
 }
 
@@ -80,6 +82,16 @@ func (o *GetDevPodOptions) Run() error {
 
 	names, m, err := kube.GetDevPodNames(client, ns, u.Username)
 
+	if o.Output != "" {
+		pods := []*corev1.Pod{}
+		for _, k := range names {
+			if m[k] != nil {
+				pods = append(pods, m[k])
+			}
+		}
+		return o.renderItems(pods, o.Output)
+	}
+
 	table := o.CreateTable()
 	table.AddRow("NAME", "POD TEMPLATE", "AGE", "STATUS")
 
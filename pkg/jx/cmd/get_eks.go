@@ -5,7 +5,6 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/jenkins-x/jx/pkg/cloud/amazon"
-	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 	"io"
 	"os"
@@ -119,19 +118,20 @@ func (o *GetEksOptions) Run() error {
 			return err
 		}
 
-		if o.Output == "" {
-			fmt.Println("NAME")
-			fmt.Println(cluster)
-		} else if o.Output == "yaml" {
+		if o.Output == OutputFormatYAML {
+			// lets keep the YAML field names of the reservations the same as earlier releases
 			reservations, err := yaml.Marshal(instances.Reservations)
 			if err != nil {
 				return err
 			}
 			fmt.Println(string(reservations))
-		} else {
-			return errors.New("Invalid output format.")
+			return nil
 		}
-
+		if o.Output != "" {
+			return o.renderResult(instances.Reservations, o.Output)
+		}
+		fmt.Println("NAME")
+		fmt.Println(cluster)
 		return nil
 	}
 }
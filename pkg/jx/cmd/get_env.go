@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
@@ -101,12 +102,16 @@ func (o *GetEnvOptions) Run() error {
 			return util.InvalidArg(e, envNames)
 		}
 
+		if o.Output != "" {
+			return o.renderResult(env, o.Output)
+		}
+
 		// lets output one environment
 		spec := &env.Spec
 
 		table := o.CreateTable()
-		table.AddRow("NAME", "LABEL", "KIND", "NAMESPACE", "SOURCE", "REF", "PR")
-		table.AddRow(e, spec.Label, spec.Namespace, kindString(spec), spec.Source.URL, spec.Source.Ref, spec.PullRequestURL)
+		table.AddRow("NAME", "LABEL", "KIND", "NAMESPACE", "SOURCE", "REF", "PR", "NEXT WINDOW")
+		table.AddRow(e, spec.Label, spec.Namespace, kindString(spec), spec.Source.URL, spec.Source.Ref, spec.PullRequestURL, deploymentWindowString(env))
 		table.Render()
 		log.Blank()
 
@@ -133,7 +138,7 @@ func (o *GetEnvOptions) Run() error {
 		if err != nil {
 			return err
 		}
-		if len(envs.Items) == 0 {
+		if len(envs.Items) == 0 && o.Output == "" {
 			log.Infof("No environments found.\nTo create an environment use: jx create env\n")
 			return nil
 		}
@@ -149,15 +154,15 @@ func (o *GetEnvOptions) Run() error {
 		if o.PreviewOnly {
 			table.AddRow("PULL REQUEST", "NAMESPACE", "APPLICATION")
 		} else {
-			table.AddRow("NAME", "LABEL", "KIND", "PROMOTE", "NAMESPACE", "ORDER", "CLUSTER", "SOURCE", "REF", "PR")
+			table.AddRow("NAME", "LABEL", "KIND", "PROMOTE", "NAMESPACE", "ORDER", "CLUSTER", "SOURCE", "REF", "PR", "NEXT WINDOW")
 		}
 
 		for _, env := range environments {
 			spec := &env.Spec
 			if o.PreviewOnly {
-				table.AddRow(spec.PullRequestURL, spec.Namespace, util.ColorInfo(spec.PreviewGitSpec.ApplicationURL))
+				table.AddRow(spec.PullRequestURL, spec.Namespace, util.ColorInfo(kube.PreviewApplicationURLStatus(&env)))
 			} else {
-				table.AddRow(env.Name, spec.Label, kindString(spec), string(spec.PromotionStrategy), spec.Namespace, util.Int32ToA(spec.Order), spec.Cluster, spec.Source.URL, spec.Source.Ref, spec.PullRequestURL)
+				table.AddRow(env.Name, spec.Label, kindString(spec), string(spec.PromotionStrategy), spec.Namespace, util.Int32ToA(spec.Order), spec.Cluster, spec.Source.URL, spec.Source.Ref, spec.PullRequestURL, deploymentWindowString(&env))
 			}
 		}
 		table.Render()
@@ -165,6 +170,22 @@ func (o *GetEnvOptions) Run() error {
 	return nil
 }
 
+// deploymentWindowString returns when promotions into the environment can next happen if it has deployment windows or freezes
+func deploymentWindowString(env *v1.Environment) string {
+	if !kube.HasDeploymentWindows(env) {
+		return ""
+	}
+	now := time.Now()
+	next, err := kube.NextDeploymentWindow(env, now)
+	if err != nil {
+		return util.ColorError(err.Error())
+	}
+	if !next.After(now) {
+		return util.ColorInfo("open")
+	}
+	return next.Format(time.RFC1123)
+}
+
 func kindString(spec *v1.EnvironmentSpec) string {
 	answer := string(spec.Kind)
 	if answer == "" {
@@ -53,6 +53,7 @@ func NewCmdGetGit(f Factory, in terminal.FileReader, out terminal.FileWriter, er
 		},
 	}
 
+	options.addGetFlags(cmd)
 	return cmd
 }
 
@@ -64,6 +65,14 @@ func (o *GetGitOptions) Run() error {
 	}
 	config := authConfigSvc.Config()
 
+	if o.Output != "" {
+		servers := []ServerSummary{}
+		for _, s := range config.Servers {
+			servers = append(servers, toServerSummary(s, "github"))
+		}
+		return o.renderItems(servers, o.Output)
+	}
+
 	table := o.CreateTable()
 	table.AddRow("Name", "Kind", "URL")
 
@@ -70,6 +70,11 @@ func (o *GetHelmBinOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.renderResult(map[string]string{
+			"helmBinary": helm,
+		}, o.Output)
+	}
 	log.Infof("Your team uses the helm binary: %s\n", util.ColorInfo(helm))
 	log.Infof("To change this value use: %s\n", util.ColorInfo("jx edit helmbin helm3"))
 	return nil
@@ -3,7 +3,6 @@ package cmd
 import (
 	"io"
 	"os/user"
-	"regexp"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -113,6 +112,7 @@ func (o *GetIssueOptions) Run() error {
 	}
 
 	found := false
+	deployments := []IssueDeployment{}
 	for _, env := range envList.Items {
 		envNs, err := kube.GetEnvironmentNamespace(client, ns, env.Name)
 		if err != nil {
@@ -133,11 +133,21 @@ func (o *GetIssueOptions) Run() error {
 		}
 		for _, app := range apps {
 			if o.match(issue.URL, app) {
+				deployments = append(deployments, IssueDeployment{
+					Application: app,
+					Environment: env.Name,
+				})
 				table.AddRow(issue.URL, *issue.State, app, env.Name)
 				found = true
 			}
 		}
 	}
+	if o.Output != "" {
+		return o.renderResult(map[string]interface{}{
+			"issue":       issue,
+			"deployments": deployments,
+		}, o.Output)
+	}
 	if !found {
 		table.AddRow(issue.URL, *issue.State, "", "")
 	}
@@ -145,6 +155,12 @@ func (o *GetIssueOptions) Run() error {
 	return nil
 }
 
+// IssueDeployment is an application and environment the fix of an issue has been deployed to
+type IssueDeployment struct {
+	Application string `json:"application"`
+	Environment string `json:"environment"`
+}
+
 func (o *GetIssueOptions) findRelease(tracker issues.IssueProvider, issue *gits.GitIssue, releases []v1.Release) *v1.Release {
 	for _, rel := range releases {
 		prs := rel.Spec.PullRequests
@@ -175,14 +191,21 @@ func (o *GetIssueOptions) findRelease(tracker issues.IssueProvider, issue *gits.
 }
 
 func (o *GetIssueOptions) parseIssueIDs(issue v1.IssueSummary, issueKind string) []string {
-	regex := regexp.MustCompile(`(\#\d+)`)
-	if issueKind == issues.Jira {
-		regex = regexp.MustCompile(`[A-Z][A-Z]+-(\d+)`)
+	regex := GitHubIssueRegex
+	bugzilla := issueKind == issues.Bugzilla
+	switch issueKind {
+	case issues.Jira:
+		regex = JIRAIssueRegex
+	case issues.Bugzilla:
+		regex = BugzillaIssueRegex
 	}
 	issues := []string{}
 	foundIssues := map[string]bool{}
 	matches := regex.FindAllStringSubmatch(issue.Body, -1)
 	for _, match := range matches {
+		if bugzilla {
+			match = match[1:]
+		}
 		for _, result := range match {
 			id := strings.TrimPrefix(result, "#")
 			found, ok := foundIssues[id]
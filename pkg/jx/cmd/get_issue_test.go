@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/issues"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIssueIDs(t *testing.T) {
+	t.Parallel()
+	o := &GetIssueOptions{}
+	pr := v1.IssueSummary{
+		Body: "Fixes #12 and Bug 1234, see bz 1235 and JX-77",
+	}
+	assert.Equal(t, []string{"12"}, o.parseIssueIDs(pr, issues.Git))
+	assert.Equal(t, []string{"1234", "1235"}, o.parseIssueIDs(pr, issues.Bugzilla))
+	assert.Contains(t, o.parseIssueIDs(pr, issues.Jira), "JX-77")
+}
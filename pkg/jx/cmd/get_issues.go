@@ -75,6 +75,10 @@ func (o *GetIssuesOptions) Run() error {
 		return err
 	}
 
+	if o.Output != "" {
+		return o.renderItems(issues, o.Output)
+	}
+
 	table := o.CreateTable()
 	table.AddRow("ISSUE", "TITLE")
 	for _, i := range issues {
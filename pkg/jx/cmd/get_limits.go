@@ -32,6 +32,16 @@ type Rate struct {
 	Reset     int `json:"reset"`
 }
 
+// RateLimitSummary is the core rate limit of a user of a git server
+type RateLimitSummary struct {
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	Username  string `json:"username"`
+	Limit     int    `json:"limit"`
+	Remaining int    `json:"remaining"`
+	Reset     string `json:"reset,omitempty"`
+}
+
 // GetAddonOptions the command line options
 type GetLimitsOptions struct {
 	GetOptions
@@ -76,6 +86,7 @@ func NewCmdGetLimits(f Factory, in terminal.FileReader, out terminal.FileWriter,
 		},
 	}
 
+	options.addGetFlags(cmd)
 	return cmd
 }
 
@@ -87,6 +98,7 @@ func (o *GetLimitsOptions) Run() error {
 	}
 	config := authConfigSvc.Config()
 
+	limits := []RateLimitSummary{}
 	table := o.CreateTable()
 	table.AddRow("Name", "URL", "Username", "Limit", "Remaining", "Reset")
 
@@ -110,11 +122,22 @@ func (o *GetLimitsOptions) Run() error {
 					resetLabel = d.String()
 				}
 
+				limits = append(limits, RateLimitSummary{
+					Name:      s.Name,
+					URL:       s.URL,
+					Username:  u.Username,
+					Limit:     r.Resources.Core.Limit,
+					Remaining: r.Resources.Core.Remaining,
+					Reset:     resetLabel,
+				})
 				table.AddRow(s.Name, s.URL, u.Username, strconv.Itoa(r.Resources.Core.Limit), strconv.Itoa(r.Resources.Core.Remaining), resetLabel)
 			}
 		}
 
 	}
+	if o.Output != "" {
+		return o.renderItems(limits, o.Output)
+	}
 	table.Render()
 
 	return nil
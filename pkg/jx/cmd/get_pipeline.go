@@ -73,14 +73,13 @@ func (o *GetPipelineOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.renderItems(jobs, o.Output)
+	}
 	if len(jobs) == 0 {
 		return outputEmptyListWarning(o.Out)
 	}
 
-	if o.Output != "" {
-		return o.renderResult(jobs, o.Output)
-	}
-
 	table := o.CreateTable()
 	table.AddRow("Name", "URL", "LAST_BUILD", "STATUS", "DURATION")
 
@@ -56,6 +56,7 @@ func NewCmdGetPostPreviewJob(f Factory, in terminal.FileReader, out terminal.Fil
 		},
 	}
 	options.addCommonFlags(cmd)
+	options.addOutputFlags(cmd)
 	return cmd
 }
 
@@ -65,6 +66,9 @@ func (o *GetPostPreviewJobOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.renderItems(settings.PostPreviewJobs, o.Output)
+	}
 	table := o.CreateTable()
 	table.AddRow("NAME", "IMAGE", "BACKOFF_LIMIT", "COMMAND")
 
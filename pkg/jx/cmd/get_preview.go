@@ -105,7 +105,10 @@ func (o *GetPreviewOptions) CurrentPreviewUrl() error {
 	}
 	for _, env := range envList.Items {
 		if env.Spec.Kind == v1.EnvironmentKindTypePreview && env.Name == name {
-			log.Info(env.Spec.PreviewGitSpec.ApplicationURL)
+			if o.Output != "" {
+				return o.renderResult(&env, o.Output)
+			}
+			log.Info(kube.PreviewApplicationURLStatus(&env))
 			return nil
 		}
 	}
@@ -90,6 +90,10 @@ func (o *GetQuickstartLocationOptions) Run() error {
 		return err
 	}
 
+	if o.Output != "" {
+		return o.renderItems(locations, o.Output)
+	}
+
 	table := o.CreateTable()
 	table.AddRow("GIT SERVER", "KIND", "OWNER", "INCLUDES", "EXCLUDES")
 
@@ -85,6 +85,9 @@ func (o *GetReleaseOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.renderItems(releases, o.Output)
+	}
 	if len(releases) == 0 {
 		suffix := ""
 		if o.Filter != "" {
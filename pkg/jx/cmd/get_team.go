@@ -77,6 +77,9 @@ func (o *GetTeamOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.renderItems(teams, o.Output)
+	}
 	if len(teams) == 0 {
 		log.Info(`
 You do not belong to any teams.
@@ -111,6 +114,9 @@ func (o *GetTeamOptions) getPendingTeams() error {
 		return err
 	}
 
+	if o.Output != "" {
+		return o.renderItems(teams, o.Output)
+	}
 	if len(names) == 0 {
 		log.Info(`
 There are no pending Teams yet. Try create one via: jx create team --pending
@@ -8,6 +8,7 @@ import (
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/spf13/cobra"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
+	rbacv1 "k8s.io/api/rbac/v1"
 )
 
 // GetTeamRoleOptions containers the CLI options
@@ -66,6 +67,15 @@ func (o *GetTeamRoleOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		roles := []*rbacv1.Role{}
+		for _, name := range names {
+			if teamRoles[name] != nil {
+				roles = append(roles, teamRoles[name])
+			}
+		}
+		return o.renderItems(roles, o.Output)
+	}
 	if len(teamRoles) == 0 {
 		log.Info(`
 There are no Team roles defined so far!
@@ -47,6 +47,7 @@ func NewCmdGetToken(f Factory, in terminal.FileReader, out terminal.FileWriter,
 func (o *GetTokenOptions) addFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.Kind, "kind", "k", "", "Filters the services by the kind")
 	cmd.Flags().StringVarP(&o.Name, "name", "n", "", "Filters the services by the name")
+	o.addOutputFlags(cmd)
 }
 
 // Run implements this command
@@ -60,6 +61,16 @@ func (o *GetTokenOptions) displayUsersWithTokens(authConfigSvc auth.AuthConfigSe
 	filterKind := o.Kind
 	filterName := o.Name
 
+	if o.Output != "" {
+		servers := []ServerSummary{}
+		for _, s := range config.Servers {
+			if (filterKind == "" || filterKind == s.Kind) && (filterName == "" || filterName == s.Name) {
+				servers = append(servers, toServerSummary(s, ""))
+			}
+		}
+		return o.renderItems(servers, o.Output)
+	}
+
 	table := o.CreateTable()
 	table.AddRow("KIND", "NAME", "URL", "USERNAME", "TOKEN?")
 
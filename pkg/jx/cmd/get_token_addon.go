@@ -67,7 +67,7 @@ func (o *GetTokenAddonOptions) Run() error {
 		return err
 	}
 	config := authConfigSvc.Config()
-	if len(config.Servers) == 0 {
+	if len(config.Servers) == 0 && o.Output == "" {
 		log.Warnf("No addon servers registered. To register a new token for an addon server use: %s\n", util.ColorInfo("jx create token addon"))
 		return nil
 	}
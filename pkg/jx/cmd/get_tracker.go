@@ -60,6 +60,7 @@ func NewCmdGetTracker(f Factory, in terminal.FileReader, out terminal.FileWriter
 		},
 	}
 	cmd.Flags().StringVarP(&options.Kind, "kind", "k", "", "Filters the issue trackers by the kinds: "+strings.Join(issues.IssueTrackerKinds, ", "))
+	options.addGetFlags(cmd)
 	return cmd
 }
 
@@ -70,6 +71,16 @@ func (o *GetTrackerOptions) Run() error {
 		return err
 	}
 	config := authConfigSvc.Config()
+	if o.Output != "" {
+		servers := []ServerSummary{}
+		for _, s := range config.Servers {
+			if o.Kind == "" || o.Kind == s.Kind {
+				servers = append(servers, toServerSummary(s, ""))
+			}
+		}
+		return o.renderItems(servers, o.Output)
+	}
+
 	if len(config.Servers) == 0 {
 		log.Infof("No issue trackers registered. To register a new issue tracker use: %s\n", util.ColorInfo("jx create tracker server"))
 		return nil
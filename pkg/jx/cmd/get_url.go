@@ -58,6 +58,7 @@ func NewCmdGetURL(f Factory, in terminal.FileReader, out terminal.FileWriter, er
 		},
 	}
 	options.addGetUrlFlags(cmd)
+	options.addGetFlags(cmd)
 	return cmd
 }
 
@@ -84,6 +85,9 @@ func (o *GetURLOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.renderItems(urls, o.Output)
+	}
 	table := o.CreateTable()
 	table.AddRow("Name", "URL")
 
@@ -4,6 +4,7 @@ import (
 	"io"
 	"strings"
 
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
@@ -79,6 +80,15 @@ func (o *GetUserOptions) Run() error {
 		return err
 	}
 
+	if o.Output != "" {
+		items := []*v1.User{}
+		for _, name := range names {
+			if users[name] != nil {
+				items = append(items, users[name])
+			}
+		}
+		return o.renderItems(items, o.Output)
+	}
 	if len(names) == 0 {
 		log.Info(`
 There are no Users yet. Try create one via: jx create user
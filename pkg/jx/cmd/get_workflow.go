@@ -92,6 +92,10 @@ func (o *GetWorkflowOptions) Run() error {
 		return err
 	}
 
+	if o.Output != "" {
+		return o.renderItems(workflows.Items, o.Output)
+	}
+
 	table := o.CreateTable()
 	table.AddRow("WORKFLOW")
 	for _, workflow := range workflows.Items {
@@ -102,18 +106,21 @@ func (o *GetWorkflowOptions) Run() error {
 }
 
 func (o *GetWorkflowOptions) getWorkflow(name string, jxClient versioned.Interface, ns string) error {
-	workflow, err := workflow.GetWorkflow(name, jxClient, ns)
+	flow, err := workflow.GetWorkflow(name, jxClient, ns)
 	if err != nil {
 		return err
 	}
+	if o.Output != "" {
+		return o.renderResult(flow, o.Output)
+	}
 
-	log.Infof("Workflow: %s\n", workflow.Name)
+	log.Infof("Workflow: %s\n", flow.Name)
 	lines := []*StepSummary{}
 	var lastSummary *StepSummary
-	for _, step := range workflow.Spec.Steps {
+	for i, step := range flow.Spec.Steps {
 		promote := step.Promote
 		if promote != nil {
-			if len(step.Preconditions.Environments) > 0 {
+			if len(step.Preconditions.Environments) > 0 || len(step.Preconditions.Steps) > 0 {
 				lastSummary = nil
 			}
 			if lastSummary == nil {
@@ -124,16 +131,48 @@ func (o *GetWorkflowOptions) getWorkflow(name string, jxClient versioned.Interfa
 				lines = append(lines, lastSummary)
 			}
 			lastSummary.Resources = append(lastSummary.Resources, promote.Environment)
-			if len(step.Preconditions.Environments) > 0 {
+			if len(step.Preconditions.Environments) > 0 || len(step.Preconditions.Steps) > 0 {
 				lastSummary = nil
 			}
+			continue
+		}
+		lastSummary = nil
+		name := workflow.StepName(&step, i)
+		if step.Parallel != nil {
+			lines = append(lines, &StepSummary{
+				Action:    "promote",
+				Resources: step.Parallel.Environments,
+			})
+		} else if step.Approval != nil {
+			if step.Approval.Environment != "" {
+				lines = append(lines, &StepSummary{
+					Action:    "approve promotion",
+					Resources: []string{step.Approval.Environment},
+				})
+			} else {
+				lines = append(lines, &StepSummary{
+					Action: "approve " + name,
+				})
+			}
+		} else if step.Wait != nil {
+			lines = append(lines, &StepSummary{
+				Action: "wait " + step.Wait.Duration,
+			})
+		} else if step.Job != nil {
+			lines = append(lines, &StepSummary{
+				Action: "verify with job " + name,
+			})
 		}
 	}
 	for i, summary := range lines {
 		if i > 0 {
 			log.Info("    |\n")
 		}
-		log.Infof("%s to %s\n", summary.Action, strings.Join(summary.Resources, " + "))
+		if len(summary.Resources) == 0 {
+			log.Infof("%s\n", summary.Action)
+		} else {
+			log.Infof("%s to %s\n", summary.Action, strings.Join(summary.Resources, " + "))
+		}
 	}
 	return nil
 }
@@ -62,6 +62,30 @@ const (
 *.im?
 target
 work
+`
+
+	// monorepoJenkinsfileHeader the first line of the Jenkinsfile generated for a monorepo
+	monorepoJenkinsfileHeader = "// generated by jx import --monorepo"
+
+	// monorepoJenkinsfile builds each app returned by jx step changed-apps with the Jenkinsfile in the directory of
+	// the app and $SUB_PROJECT set to the app name so that each app is versioned and promoted on its own
+	monorepoJenkinsfile = `%s
+def appDirs = [
+%s]
+
+node {
+    checkout scm
+    def apps = sh(script: 'jx step changed-apps', returnStdout: true).trim().tokenize()
+    for (app in apps) {
+        stage(app) {
+            withEnv(["SUB_PROJECT=${app}"]) {
+                dir(appDirs[app]) {
+                    load '%s'
+                }
+            }
+        }
+    }
+}
 `
 )
 
@@ -104,6 +128,8 @@ type ImportOptions struct {
 	DisableMaven          bool
 	PipelineUserName      string
 	PipelineServer        string
+	Monorepo              bool
+	AppDirs               []string
 }
 
 var (
@@ -137,6 +163,12 @@ var (
 
         # Import all repositories from a GitHub organisation which contain the text foo
 		jx import --github --org myname --all --filter foo 
+
+		# Import a monorepo registering each sub directory containing an app
+		jx import --monorepo
+
+		# Import a monorepo registering the apps in the given sub directories
+		jx import --app-dir frontend --app-dir backend
 		`)
 )
 
@@ -190,6 +222,8 @@ func (options *ImportOptions) addImportFlags(cmd *cobra.Command, createProject b
 	cmd.Flags().StringVarP(&options.BranchPattern, "branches", "", "", "The branch pattern for branches to trigger CI/CD pipelines on")
 	cmd.Flags().BoolVarP(&options.ListDraftPacks, "list-packs", "", false, "list available draft packs")
 	cmd.Flags().StringVarP(&options.DraftPack, "pack", "", "", "The name of the pack to use")
+	cmd.Flags().BoolVarP(&options.Monorepo, "monorepo", "", false, "Imports a monorepo registering each sub directory which contains an app in the jenkins-x.yml so that only the changed apps are built")
+	cmd.Flags().StringArrayVarP(&options.AppDirs, "app-dir", "", []string{}, "The sub directories of a monorepo containing the apps to import. Implies --monorepo")
 	cmd.Flags().StringVarP(&options.DockerRegistryOrg, "docker-registry-org", "", "", "The name of the docker registry organisation to use. If not specified then the Git provider organisation will be used")
 	cmd.Flags().StringVarP(&options.ExternalJenkinsBaseURL, "external-jenkins-url", "", "", "The jenkins url that an external git provider needs to use")
 
@@ -373,21 +407,16 @@ func (options *ImportOptions) Run() error {
 	}
 	options.AppName = kube.ToValidName(strings.ToLower(options.AppName))
 
-	if !options.DisableDraft {
-		err = options.DraftCreate()
+	if options.Monorepo || len(options.AppDirs) > 0 {
+		err = options.importMonorepoApps()
+		if err != nil {
+			return err
+		}
+	} else {
+		err = options.importApp()
 		if err != nil {
 			return err
 		}
-
-	}
-	err = options.fixDockerIgnoreFile()
-	if err != nil {
-		return err
-	}
-
-	err = options.fixMaven()
-	if err != nil {
-		return err
 	}
 
 	if options.RepoURL == "" {
@@ -421,6 +450,127 @@ func (options *ImportOptions) Run() error {
 	return options.doImport()
 }
 
+// importApp defaults the Dockerfile, Helm chart and build files of the app in the directory
+func (options *ImportOptions) importApp() error {
+	if !options.DisableDraft {
+		err := options.DraftCreate()
+		if err != nil {
+			return err
+		}
+	}
+	err := options.fixDockerIgnoreFile()
+	if err != nil {
+		return err
+	}
+	return options.fixMaven()
+}
+
+// importMonorepoApps imports each app in a sub directory of the monorepo and registers them in the
+// jenkins-x.yml at the root of the repository so that only the apps which change are built and versioned
+func (options *ImportOptions) importMonorepoApps() error {
+	dir := options.Dir
+	appDirs := options.AppDirs
+	if len(appDirs) == 0 {
+		var err error
+		appDirs, err = findMonorepoAppDirs(dir)
+		if err != nil {
+			return errors.Wrapf(err, "failed to find the apps in %s", dir)
+		}
+		if len(appDirs) == 0 {
+			return fmt.Errorf("no apps found in the sub directories of %s", dir)
+		}
+	}
+	projectConfig, fileName, err := config.LoadProjectConfig(dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load %s", fileName)
+	}
+	for _, appDir := range appDirs {
+		appDir = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(appDir)), "/")
+		subDir := filepath.Join(dir, filepath.FromSlash(appDir))
+		exists, err := util.FileExists(subDir)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("the app directory %s does not exist", subDir)
+		}
+		appName := kube.ToValidName(strings.ToLower(filepath.Base(subDir)))
+		log.Infof("Importing app %s in directory %s\n", util.ColorInfo(appName), util.ColorInfo(appDir))
+
+		o2 := *options
+		o2.Dir = subDir
+		o2.AppName = appName
+		err = o2.importApp()
+		if err != nil {
+			return errors.Wrapf(err, "failed to import app %s", appName)
+		}
+		app := config.AppConfig{
+			Name: appName,
+			Dir:  appDir,
+		}
+		existing := projectConfig.GetApp(appName)
+		if existing != nil {
+			app.Paths = existing.Paths
+		}
+		projectConfig.AddApp(app)
+	}
+	err = projectConfig.SaveConfig(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save %s", fileName)
+	}
+	err = options.Git().Add(dir, config.ProjectConfigFileName)
+	if err != nil {
+		return err
+	}
+	if !options.DisableJenkinsfileCheck {
+		jenkinsfile, err := options.createMonorepoJenkinsfile(projectConfig.Apps)
+		if err != nil {
+			return err
+		}
+		if jenkinsfile != "" {
+			err = options.Git().Add(dir, jenkinsfile)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return options.Git().CommitIfChanges(dir, "Register the monorepo apps")
+}
+
+// createMonorepoJenkinsfile creates the Jenkinsfile at the root of the monorepo which builds each changed app with
+// the Jenkinsfile of the app. An existing Jenkinsfile which was not generated by jx import is left alone
+func (options *ImportOptions) createMonorepoJenkinsfile(apps []config.AppConfig) (string, error) {
+	name := options.Jenkinsfile
+	if name == "" {
+		name = jenkins.DefaultJenkinsfile
+	}
+	fileName := filepath.Join(options.Dir, name)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read %s", fileName)
+		}
+		if !strings.HasPrefix(string(data), monorepoJenkinsfileHeader) {
+			log.Warnf("Not generating the monorepo Jenkinsfile as %s already exists\n", fileName)
+			return "", nil
+		}
+	}
+	appDirs := ""
+	for _, app := range apps {
+		appDirs += fmt.Sprintf("    '%s': '%s',\n", app.Name, app.Dir)
+	}
+	err = ioutil.WriteFile(fileName, []byte(fmt.Sprintf(monorepoJenkinsfile, monorepoJenkinsfileHeader, appDirs, name)), util.DefaultWritePermissions)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to write %s", fileName)
+	}
+	log.Infof("Generated %s which builds the changed apps of the monorepo\n", util.ColorInfo(name))
+	return name, nil
+}
+
 // ImportProjectsFromGitHub import projects from github
 func (options *ImportOptions) ImportProjectsFromGitHub() error {
 	repos, err := gits.PickRepositories(options.GitProvider, options.Organisation, "Which repositories do you want to import", options.SelectAll, options.SelectFilter, options.In, options.Out, options.Err)
@@ -783,13 +933,13 @@ func (options *ImportOptions) CreateNewRemoteRepository() error {
 			// Get all invitations for the pipeline user
 			// Wrapped in retry to not immediately fail the quickstart creation if APIs are flaky.
 			f := func() error {
-				invites, _, err := pipelineUserProvider.ListInvitations()
+				invites, err := pipelineUserProvider.ListInvitations()
 				if err != nil {
 					return err
 				}
 				for _, x := range invites {
 					// Accept all invitations for the pipeline user
-					_, err = pipelineUserProvider.AcceptInvitation(*x.ID)
+					err = pipelineUserProvider.AcceptInvitation(x.ID)
 					if err != nil {
 						return err
 					}
@@ -1017,6 +1167,11 @@ func (options *ImportOptions) doImport() error {
 		return options.addProwConfig(gitURL)
 	}
 
+	err = options.ApplyTeamBranchProtection(gitProvider, gitURL)
+	if err != nil {
+		log.Warnf("%s\n", err)
+	}
+
 	return options.ImportProject(gitURL, options.Dir, jenkinsfile, options.BranchPattern, options.Credentials, false, gitProvider, authConfigSvc, false, options.BatchMode)
 }
 
@@ -264,13 +264,13 @@ func (options *InstallOptions) Run() error {
 		helmer := options.Helm()
 		helmCli, ok := helmer.(*helm.HelmCLI)
 		if ok && helmCli != nil {
-			options.helm = helm.NewHelmTemplate(helmCli, helmCli.CWD, client)
+			options.helm = helm.NewHelmSecrets(helmCli, helmCli.CWD, client)
 		} else {
-			helmTemplate, ok := helmer.(*helm.HelmTemplate)
-			if ok {
-				options.helm = helmTemplate
-			} else {
-				log.Warnf("Helm facade is not a *helm.HelmCLI or *helm.HelmTemplate: %#v\n", helmer)
+			switch helmer.(type) {
+			case *helm.HelmSecrets, *helm.HelmTemplate:
+				options.helm = helmer
+			default:
+				log.Warnf("Helm facade is not a *helm.HelmCLI, *helm.HelmSecrets or *helm.HelmTemplate: %#v\n", helmer)
 			}
 		}
 	}
@@ -11,12 +11,15 @@ import (
 	"time"
 
 	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/chats"
+	typev1 "github.com/jenkins-x/jx/pkg/client/clientset/versioned/typed/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/config"
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
 	batchv1 "k8s.io/api/batch/v1"
@@ -29,6 +32,16 @@ var (
 	previewLong = templates.LongDesc(`
 		Creates or updates a Preview Environment for the given Pull Request or Branch.
 
+		Any charts listed in 'previewEnvironments.dependencies' of the jenkins-x.yml such as databases or queues are
+		installed into the preview namespace before the application, followed by any 'previewEnvironments.seedJobs'
+		which have not yet run. The dependencies are deleted along with the Preview Environment.
+
+		Once the application is deployed the post preview Jobs of the team are run. A repository can add, replace or
+		exclude Jobs using 'previewEnvironments.postPreviewJobs' of the jenkins-x.yml. The result, duration and log tail
+		of each Job are added to the Pull Request as a comment and as a commit status per Job. When 'junitResults' is enabled
+		the Jobs run one at a time, as they share the results volume, and the JUnit XML results written to
+		$JX_JUNIT_RESULTS_DIR are included too.
+
 		For more documentation on Preview Environments see: [https://jenkins-x.io/about/features/#preview-environments](https://jenkins-x.io/about/features/#preview-environments)
 
 `)
@@ -116,6 +129,7 @@ func NewCmdPreview(f Factory, in terminal.FileReader, out terminal.FileWriter, e
 	options.HelmValuesConfig.AddExposeControllerValues(cmd, false)
 	options.PromoteOptions.addPromoteOptions(cmd)
 
+	cmd.AddCommand(NewCmdPreviewWake(f, in, out, errOut))
 	return cmd
 }
 
@@ -223,6 +237,7 @@ func (o *PreviewOptions) Run() error {
 		if err != nil {
 			return fmt.Errorf("cannot create Git provider %v", err)
 		}
+		o.GitProvider = gitProvider
 
 		if prNum > 0 {
 			pullRequest, err := gitProvider.GetPullRequest(o.GitInfo.Organisation, o.GitInfo, prNum)
@@ -284,11 +299,27 @@ func (o *PreviewOptions) Run() error {
 		}
 	}
 
+	previewConfig, err := o.previewEnvironmentConfig()
+	if err != nil {
+		return err
+	}
+
 	environmentsResource := jxClient.JenkinsV1().Environments(ns)
 	env, err := environmentsResource.Get(o.Name, metav1.GetOptions{})
 	if err == nil {
 		// lets check for updates...
-		update := false
+		// the last deployed time always changes
+		update := true
+		kube.SetPreviewLastDeployed(env, time.Now())
+		kube.SetPreviewExpiry(env, previewConfig.TimeToLive, previewConfig.IdleTimeout)
+		kube.SetPreviewHibernateAfter(env, previewConfig.HibernateAfter)
+		if env.Spec.PreviewGitSpec.Hibernated {
+			log.Infof("Waking hibernated preview environment %s\n", util.ColorInfo(env.Name))
+			err = kube.WakePreview(kubeClient, env, time.Now())
+			if err != nil {
+				return err
+			}
+		}
 
 		spec := &env.Spec
 		source := &spec.Source
@@ -366,6 +397,11 @@ func (o *PreviewOptions) Run() error {
 		}
 	}
 	if err != nil {
+		err = o.evictPreviews(environmentsResource, previewConfig.MaximumInstances)
+		if err != nil {
+			return err
+		}
+
 		// lets create a new preview environment
 		previewGitSpec := v1.PreviewGitSpec{
 			ApplicationName: o.Application,
@@ -400,6 +436,9 @@ func (o *PreviewOptions) Run() error {
 				PreviewGitSpec: previewGitSpec,
 			},
 		}
+		kube.SetPreviewLastDeployed(env, time.Now())
+		kube.SetPreviewExpiry(env, previewConfig.TimeToLive, previewConfig.IdleTimeout)
+		kube.SetPreviewHibernateAfter(env, previewConfig.HibernateAfter)
 		_, err = environmentsResource.Create(env)
 		if err != nil {
 			return fmt.Errorf("Failed to create environment in namespace %s due to: %s", ns, err)
@@ -412,6 +451,11 @@ func (o *PreviewOptions) Run() error {
 		return err
 	}
 
+	err = o.updatePreviewDependencies(kubeClient, environmentsResource, previewConfig)
+	if err != nil {
+		return err
+	}
+
 	if o.ReleaseName == "" {
 		o.ReleaseName = o.Namespace
 	}
@@ -491,7 +535,7 @@ func (o *PreviewOptions) Run() error {
 
 	if url != "" || o.PullRequestURL != "" {
 		if pipeline != "" && build != "" {
-			name := kube.ToValidName(pipeline + "-" + build)
+			name := o.pipelineActivityName(pipeline, build)
 			// lets see if we can update the pipeline
 			activities := jxClient.JenkinsV1().PipelineActivities(ns)
 			key := &kube.PromoteStepActivityKey{
@@ -540,6 +584,10 @@ func (o *PreviewOptions) Run() error {
 		log.Infof("Preview application is now available at: %s\n\n", util.ColorInfo(url))
 	}
 
+	o.notifyEnvironmentChatThread(environmentsResource, o.Name, o.previewMessage(url), func(message *chats.Message) string {
+		return o.notifyDeveloperChannel(o.Dir, message)
+	})
+
 	stepPRCommentOptions := StepPRCommentOptions{
 		Flags: StepPRCommentFlags{
 			Owner:      o.GitInfo.Organisation,
@@ -560,27 +608,146 @@ func (o *PreviewOptions) Run() error {
 	if err != nil {
 		log.Warnf("Failed to comment on the Pull Request: %s\n", err)
 	}
-	return o.RunPostPreviewSteps(kubeClient, o.Namespace, url, pipeline, build)
+	return o.RunPostPreviewSteps(kubeClient, o.Namespace, url, pipeline, build, previewConfig)
+}
+
+// previewEnvironmentConfig loads the preview environment configuration from the jenkins-x.yml of the project
+func (o *PreviewOptions) previewEnvironmentConfig() (*config.PreviewEnvironmentConfig, error) {
+	projectConfig, fileName, err := config.LoadProjectConfig(o.Dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s", fileName)
+	}
+	answer := projectConfig.PreviewEnvironments
+	if answer == nil {
+		answer = &config.PreviewEnvironmentConfig{}
+	}
+	err = answer.Validate()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", fileName)
+	}
+	return answer, nil
+}
+
+// evictPreviews deletes the least recently active preview environments of the repository so that a new preview
+// can be created without exceeding the maximum number of instances
+func (o *PreviewOptions) evictPreviews(environments typev1.EnvironmentInterface, maximumInstances int) error {
+	if maximumInstances <= 0 {
+		return nil
+	}
+	envs, err := environments.List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	previews := kube.GetRepositoryPreviews(envs.Items, o.SourceURL)
+	for _, env := range kube.PreviewsToEvict(previews, maximumInstances, o.Name) {
+		log.Infof("Deleting preview environment %s last active at %s as the repository has reached its maximum of %d preview environments\n",
+			util.ColorInfo(env.Name), kube.GetPreviewLastActive(&env).Format(time.RFC3339), maximumInstances)
+		err = o.deletePreviewEnvironment(env.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletePreviewEnvironment deletes the preview environment and its namespace
+func (o *CommonOptions) deletePreviewEnvironment(name string) error {
+	deleteOpts := DeleteEnvOptions{
+		DeleteNamespace: true,
+		CommonOptions:   *o,
+	}
+	deleteOpts.CommonOptions.Args = []string{name}
+	err := deleteOpts.Run()
+	if err != nil {
+		return fmt.Errorf("failed to delete preview environment %s: %v", name, err)
+	}
+	return nil
+}
+
+// commentOnPreviewPullRequest adds a comment to the pull request of the preview environment
+func (o *CommonOptions) commentOnPreviewPullRequest(env *v1.Environment, comment string) error {
+	gitInfo, err := gits.ParseGitURL(env.Spec.Source.URL)
+	if err != nil {
+		return err
+	}
+	stepPRCommentOptions := StepPRCommentOptions{
+		Flags: StepPRCommentFlags{
+			Owner:      gitInfo.Organisation,
+			Repository: gitInfo.Name,
+			Comment:    comment,
+			PR:         env.Spec.PreviewGitSpec.Name,
+		},
+		StepPROptions: StepPROptions{
+			StepOptions: StepOptions{
+				CommonOptions: CommonOptions{
+					BatchMode: true,
+					Factory:   o.Factory,
+				},
+			},
+		},
+	}
+	return stepPRCommentOptions.Run()
+}
+
+// previewMessage returns the chat message notifying that the preview environment has been created
+func (o *PreviewOptions) previewMessage(url string) *chats.Message {
+	title := fmt.Sprintf("Preview environment %s", o.Name)
+	text := ""
+	if o.GitInfo != nil {
+		text = fmt.Sprintf("%s/%s", o.GitInfo.Organisation, o.GitInfo.Name)
+		if o.PullRequestName != "" {
+			text += " PR-" + o.PullRequestName
+		}
+	}
+	if text != "" {
+		text += " is available in a preview environment"
+	}
+	if o.PullRequestURL != "" {
+		text = strings.TrimSpace(text + "\n" + o.PullRequestURL)
+	}
+	return &chats.Message{
+		Title: title,
+		Text:  text,
+		URL:   url,
+	}
 }
 
-// RunPostPreviewSteps lets run any post-preview steps that are configured for all apps in a team
-func (o *PreviewOptions) RunPostPreviewSteps(kubeClient kubernetes.Interface, ns string, url string, pipeline string, build string) error {
+// RunPostPreviewSteps lets run any post-preview steps that are configured for all apps in a team along with any
+// overrides in the jenkins-x.yml of the repository then reports the results of each Job on the
+// pull request. When JUnit results are collected the Jobs run one at a time as they share the results volume
+func (o *PreviewOptions) RunPostPreviewSteps(kubeClient kubernetes.Interface, ns string, url string, pipeline string, build string,
+	previewConfig *config.PreviewEnvironmentConfig) error {
 	teamSettings, err := o.TeamSettings()
 	if err != nil {
 		return err
 	}
+	jobsConfig := previewConfig.PostPreviewJobs
+	jobs := mergePostPreviewJobs(teamSettings.PostPreviewJobs, jobsConfig)
+	if len(jobs) == 0 {
+		return nil
+	}
 	envVars := map[string]string{
 		"JX_PREVIEW_URL": url,
 		"JX_PIPELINE":    pipeline,
 		"JX_BUILD":       build,
 	}
 
-	jobs := teamSettings.PostPreviewJobs
+	resultsDir := jobsConfig.GetJUnitResultsDir()
+	if resultsDir != "" {
+		err = ensureJUnitResultsClaim(kubeClient, ns)
+		if err != nil {
+			return err
+		}
+	}
+
 	jobResources := kubeClient.BatchV1().Jobs(ns)
 	createdJobs := []*batchv1.Job{}
 	for _, job := range jobs {
 		// TODO lets modify the job name?
 		job2 := o.modifyJob(&job, envVars)
+		if resultsDir != "" {
+			addJUnitResultsVolume(job2, resultsDir)
+		}
 		log.Infof("Triggering post preview Job %s in namespace %s\n", util.ColorInfo(job2.Name), util.ColorInfo(ns))
 
 		gracePeriod := int64(0)
@@ -604,8 +771,42 @@ func (o *PreviewOptions) RunPostPreviewSteps(kubeClient kubernetes.Interface, ns
 			return err
 		}
 		createdJobs = append(createdJobs, createdJob)
+		if resultsDir != "" {
+			// the ReadWriteOnce results volume can only be mounted on one node at a time so the Jobs run one at a time.
+			// The outcome of the Job is reported below so we can ignore the error
+			o.waitForJob(kubeClient, createdJob)
+		}
+	}
+
+	results := []*postPreviewJobResult{}
+	for _, job := range createdJobs {
+		if resultsDir == "" {
+			// the outcome of the Job is reported below so we can ignore the error
+			o.waitForJob(kubeClient, job)
+		}
+		results = append(results, o.getPostPreviewJobResult(kubeClient, job, jobsConfig.GetLogTailLines()))
+	}
+	if resultsDir != "" {
+		summaries, err := o.collectJUnitResults(kubeClient, ns, jobsConfig.GetJUnitCollectorImage())
+		if err != nil {
+			log.Warnf("Failed to collect the JUnit results of the post preview Jobs: %s\n", err)
+		}
+		for _, result := range results {
+			result.Tests = summaries[result.Name]
+		}
 	}
-	return o.waitForJobsToComplete(kubeClient, createdJobs)
+	o.reportPostPreviewResults(results, url)
+
+	failed := []string{}
+	for _, result := range results {
+		if !result.Succeeded() {
+			failed = append(failed, result.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("post preview Jobs failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
 }
 
 func (o *PreviewOptions) waitForJobsToComplete(kubeClient kubernetes.Interface, jobs []*batchv1.Job) error {
@@ -655,7 +856,7 @@ func (o *PreviewOptions) waitForJob(kubeClient kubernetes.Interface, job *batchv
 
 // modifyJob adds the given environment variables into all the containers in the job
 func (o *PreviewOptions) modifyJob(originalJob *batchv1.Job, envVars map[string]string) *batchv1.Job {
-	job := *originalJob
+	job := *originalJob.DeepCopy()
 	for k, v := range envVars {
 		templateSpec := &job.Spec.Template.Spec
 		for i, _ := range templateSpec.Containers {
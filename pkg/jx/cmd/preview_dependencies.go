@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	typev1 "github.com/jenkins-x/jx/pkg/client/clientset/versioned/typed/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxReleaseNameLength the maximum length of a helm release name
+const maxReleaseNameLength = 53
+
+// installPreviewDependencies installs the dependency charts of the preview environment and then runs any seed Jobs
+// which have not yet been run. It returns the helm releases of the dependencies
+func (o *PreviewOptions) installPreviewDependencies(kubeClient kubernetes.Interface, previewConfig *config.PreviewEnvironmentConfig) ([]string, error) {
+	releases := []string{}
+	for _, dependency := range previewConfig.Dependencies {
+		chart, err := o.addPreviewDependencyRepository(&dependency)
+		if err != nil {
+			return releases, err
+		}
+		releaseName := previewDependencyReleaseName(o.Namespace, dependency.Name)
+		var version *string
+		if dependency.Version != "" {
+			version = &dependency.Version
+		}
+		valueFiles := []string{}
+		for _, f := range dependency.ValueFiles {
+			valueFiles = append(valueFiles, filepath.Join(o.Dir, f))
+		}
+		log.Infof("Installing preview dependency %s using chart %s as release %s\n", util.ColorInfo(dependency.Name), util.ColorInfo(chart), util.ColorInfo(releaseName))
+		err = o.Helm().UpgradeChart(chart, releaseName, o.Namespace, version, true, nil, false, true, dependency.Values, valueFiles)
+		if err != nil {
+			return releases, errors.Wrapf(err, "failed to install preview dependency %s", dependency.Name)
+		}
+		releases = append(releases, releaseName)
+	}
+	if len(previewConfig.SeedJobs) == 0 {
+		return releases, nil
+	}
+
+	jobResources := kubeClient.BatchV1().Jobs(o.Namespace)
+	createdJobs := []*batchv1.Job{}
+	for _, seed := range previewConfig.SeedJobs {
+		job := newPreviewSeedJob(&seed, o.Namespace)
+		existing, err := jobResources.Get(job.Name, metav1.GetOptions{})
+		if err == nil {
+			if kube.IsJobSucceeded(existing) {
+				if o.Verbose {
+					log.Infof("Preview seed Job %s has already run\n", job.Name)
+				}
+				continue
+			}
+			if !kube.IsJobFinished(existing) {
+				log.Infof("Preview seed Job %s is still running\n", util.ColorInfo(job.Name))
+				createdJobs = append(createdJobs, existing)
+				continue
+			}
+			log.Infof("Deleting preview seed Job %s in namespace %s as it failed\n", util.ColorInfo(job.Name), util.ColorInfo(o.Namespace))
+			propagation := metav1.DeletePropagationBackground
+			err = jobResources.Delete(job.Name, &metav1.DeleteOptions{PropagationPolicy: &propagation})
+			if err != nil {
+				return releases, errors.Wrapf(err, "failed to delete failed preview seed Job %s", job.Name)
+			}
+		}
+		log.Infof("Triggering preview seed Job %s in namespace %s\n", util.ColorInfo(job.Name), util.ColorInfo(o.Namespace))
+		createdJob, err := jobResources.Create(job)
+		if err != nil {
+			return releases, errors.Wrapf(err, "failed to create preview seed Job %s", job.Name)
+		}
+		createdJobs = append(createdJobs, createdJob)
+	}
+	return releases, o.waitForJobsToComplete(kubeClient, createdJobs)
+}
+
+// updatePreviewDependencies installs the dependencies of the preview environment, deletes any dependencies which
+// have been removed from the configuration and records the releases on the Environment so they are deleted with it
+func (o *PreviewOptions) updatePreviewDependencies(kubeClient kubernetes.Interface, environments typev1.EnvironmentInterface, previewConfig *config.PreviewEnvironmentConfig) error {
+	env, err := environments.Get(o.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	previous := kube.GetPreviewDependencies(env)
+	if len(previous) == 0 && len(previewConfig.Dependencies) == 0 && len(previewConfig.SeedJobs) == 0 {
+		return nil
+	}
+	releases, err := o.installPreviewDependencies(kubeClient, previewConfig)
+	if err != nil {
+		return err
+	}
+	for _, release := range previous {
+		if util.StringArrayIndex(releases, release) < 0 {
+			log.Infof("Deleting removed preview dependency release %s\n", util.ColorInfo(release))
+			err = o.Helm().DeleteRelease(o.Namespace, release, true)
+			if err != nil {
+				log.Warnf("Failed to delete preview dependency release %s: %s\n", release, err)
+			}
+		}
+	}
+	kube.SetPreviewDependencies(env, releases)
+	_, err = environments.Update(env)
+	if err != nil {
+		return fmt.Errorf("Failed to update Environment %s due to %s", o.Name, err)
+	}
+	return nil
+}
+
+// addPreviewDependencyRepository adds the chart repository of the dependency if it has one and returns the chart to install
+func (o *PreviewOptions) addPreviewDependencyRepository(dependency *config.PreviewDependencyConfig) (string, error) {
+	chart := dependency.Chart
+	if dependency.Repository == "" {
+		return chart, nil
+	}
+	repoName := dependency.Name
+	idx := strings.Index(chart, "/")
+	if idx > 0 {
+		repoName = chart[0:idx]
+	} else {
+		chart = repoName + "/" + chart
+	}
+	err := o.addHelmRepoIfMissing(dependency.Repository, repoName)
+	if err != nil {
+		return chart, errors.Wrapf(err, "failed to add the chart repository %s of preview dependency %s", dependency.Repository, dependency.Name)
+	}
+	return chart, nil
+}
+
+// deletePreviewDependencies deletes the helm releases of the dependencies of the preview environment
+func (o *CommonOptions) deletePreviewDependencies(env *v1.Environment) {
+	for _, release := range kube.GetPreviewDependencies(env) {
+		log.Infof("Deleting preview dependency release %s\n", util.ColorInfo(release))
+		err := o.Helm().DeleteRelease(env.Spec.Namespace, release, true)
+		if err != nil {
+			log.Warnf("Failed to delete preview dependency release %s: %s\n", release, err)
+		}
+	}
+}
+
+// previewDependencyReleaseName returns the helm release name of the dependency in the preview namespace
+func previewDependencyReleaseName(ns string, name string) string {
+	suffix := "-" + name
+	if len(ns)+len(suffix) > maxReleaseNameLength && len(suffix) < maxReleaseNameLength {
+		ns = strings.TrimSuffix(ns[0:maxReleaseNameLength-len(suffix)], "-")
+	}
+	return kube.ToValidName(ns + suffix)
+}
+
+// newPreviewSeedJob creates the Job for the seed configuration in the preview namespace
+func newPreviewSeedJob(seed *config.PreviewSeedJobConfig, ns string) *batchv1.Job {
+	name := kube.ToValidName("seed-" + seed.Name)
+	backoffLimit := int32(2)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels: map[string]string{
+				kube.LabelJobKind: kube.ValueJobKindPreviewSeed,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						kube.LabelJobKind: kube.ValueJobKindPreviewSeed,
+					},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "seed",
+							Image:   seed.Image,
+							Command: seed.Command,
+							Args:    seed.Args,
+							Env:     []corev1.EnvVar(seed.Env),
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm/mocks"
+	"github.com/jenkins-x/jx/pkg/kube"
+	. "github.com/petergtz/pegomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8sTesting "k8s.io/client-go/testing"
+)
+
+func TestPreviewDependencyReleaseName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "jx-myorg-myapp-pr-1-postgres", previewDependencyReleaseName("jx-myorg-myapp-pr-1", "postgres"))
+
+	name := previewDependencyReleaseName("jx-myorganisation-my-really-long-application-name-pr-1234", "postgres")
+	assert.True(t, len(name) <= maxReleaseNameLength, "release name %s is too long", name)
+	assert.True(t, strings.HasSuffix(name, "-postgres"), "release name %s should end with the dependency name", name)
+}
+
+func TestNewPreviewSeedJob(t *testing.T) {
+	t.Parallel()
+	job := newPreviewSeedJob(&config.PreviewSeedJobConfig{
+		Name:    "Load Data",
+		Image:   "postgres:10",
+		Command: []string{"psql"},
+		Args:    []string{"-f", "/seed.sql"},
+		Env:     config.JobEnv{{Name: "PGHOST", Value: "postgres"}},
+	}, "jx-preview")
+
+	assert.Equal(t, "seed-load-data", job.Name)
+	assert.Equal(t, "jx-preview", job.Namespace)
+	assert.Equal(t, kube.ValueJobKindPreviewSeed, job.Labels[kube.LabelJobKind])
+	podSpec := job.Spec.Template.Spec
+	assert.Equal(t, corev1.RestartPolicyNever, podSpec.RestartPolicy)
+	require.Len(t, podSpec.Containers, 1)
+	assert.Equal(t, "postgres:10", podSpec.Containers[0].Image)
+	assert.Equal(t, []string{"-f", "/seed.sql"}, podSpec.Containers[0].Args)
+	assert.Equal(t, "postgres", kube.GetEnvVar(&podSpec.Containers[0], "PGHOST").Value)
+}
+
+func TestUpdatePreviewDependencies(t *testing.T) {
+	RegisterMockTestingT(t)
+
+	ns := "jx-myorg-myapp-pr-1"
+	env := kube.NewPreviewEnvironment("myorg-myapp-pr-1")
+	env.Namespace = "jx"
+	env.Spec.Namespace = ns
+	kube.SetPreviewDependencies(env, []string{ns + "-redis"})
+
+	// the data seed Job has already run so it is not created again but the failed schema seed Job is run again
+	seedJob := newPreviewSeedJob(&config.PreviewSeedJobConfig{Name: "data", Image: "postgres:10"}, ns)
+	completed := metav1.Now()
+	seedJob.Status.CompletionTime = &completed
+	seedJob.Status.Succeeded = 1
+	backoffLimit := int32(1)
+	failedJob := newPreviewSeedJob(&config.PreviewSeedJobConfig{Name: "schema", Image: "postgres:10"}, ns)
+	failedJob.Spec.BackoffLimit = &backoffLimit
+	failedJob.Status.Failed = 1
+
+	helmer := helm_test.NewMockHelmer()
+	o := &PreviewOptions{}
+	o.Name = env.Name
+	o.Namespace = ns
+	o.Dir = "/workspace"
+	o.PostPreviewJobTimeoutDuration = time.Minute
+	o.PostPreviewJobPollDuration = time.Millisecond
+	ConfigureTestOptionsWithResources(&o.CommonOptions, []runtime.Object{seedJob, failedJob}, []runtime.Object{env}, &gits.GitFake{}, helmer)
+
+	previewConfig := &config.PreviewEnvironmentConfig{
+		Dependencies: []config.PreviewDependencyConfig{
+			{
+				Name:       "postgres",
+				Chart:      "stable/postgresql",
+				Version:    "2.1.0",
+				Values:     []string{"postgresqlDatabase=cheese"},
+				ValueFiles: []string{"preview/postgres.yaml"},
+			},
+		},
+		SeedJobs: []config.PreviewSeedJobConfig{
+			{Name: "data", Image: "postgres:10"},
+			{Name: "schema", Image: "postgres:10"},
+		},
+	}
+
+	kubeClient, _, err := o.KubeClient()
+	require.NoError(t, err)
+	// the created Jobs complete straight away
+	createdJobs := []string{}
+	fakeClient := kubeClient.(*kubefake.Clientset)
+	fakeClient.PrependReactor("create", "jobs", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+		job := action.(k8sTesting.CreateAction).GetObject().(*batchv1.Job)
+		createdJobs = append(createdJobs, job.Name)
+		return false, nil, nil
+	})
+	fakeClient.PrependReactor("get", "jobs", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+		name := action.(k8sTesting.GetAction).GetName()
+		for _, created := range createdJobs {
+			if created == name {
+				job := newPreviewSeedJob(&config.PreviewSeedJobConfig{Name: "schema", Image: "postgres:10"}, ns)
+				job.Status.CompletionTime = &completed
+				job.Status.Succeeded = 1
+				return true, job, nil
+			}
+		}
+		return false, nil, nil
+	})
+	jxClient, _, err := o.JXClient()
+	require.NoError(t, err)
+	environments := jxClient.JenkinsV1().Environments("jx")
+
+	err = o.updatePreviewDependencies(kubeClient, environments, previewConfig)
+	require.NoError(t, err)
+
+	version := "2.1.0"
+	helmer.VerifyWasCalledOnce().UpgradeChart("stable/postgresql", ns+"-postgres", ns, &version, true, nil, false, true,
+		[]string{"postgresqlDatabase=cheese"}, []string{"/workspace/preview/postgres.yaml"})
+	helmer.VerifyWasCalledOnce().DeleteRelease(ns, ns+"-redis", true)
+
+	updated, err := environments.Get(env.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{ns + "-postgres"}, kube.GetPreviewDependencies(updated))
+	assert.Equal(t, []string{"seed-schema"}, createdJobs)
+}
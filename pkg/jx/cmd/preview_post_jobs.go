@@ -0,0 +1,487 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/junit"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	postPreviewResultsClaim        = "post-preview-results"
+	postPreviewResultsVolume       = "post-preview-results"
+	postPreviewResultsCollector    = "post-preview-results-collector"
+	postPreviewCollectorResultsDir = "/results"
+	postPreviewStatusContextPrefix = "post-preview/"
+	junitResultMarker              = "--- JUNIT "
+
+	// JUnitResultsDirEnvVar the environment variable of the post preview Job containers which contains the directory
+	// into which JUnit XML results should be written
+	JUnitResultsDirEnvVar = "JX_JUNIT_RESULTS_DIR"
+
+	postPreviewJobSucceeded = "Succeeded"
+	postPreviewJobFailed    = "Failed"
+	postPreviewJobTimedOut  = "TimedOut"
+)
+
+// postPreviewJobResult is the outcome of a post preview Job
+type postPreviewJobResult struct {
+	Name     string
+	Result   string
+	Duration time.Duration
+	LogTail  string
+	Tests    *junit.Summary
+}
+
+// Succeeded returns true if the Job succeeded and none of its tests failed
+func (r *postPreviewJobResult) Succeeded() bool {
+	return r.Result == postPreviewJobSucceeded && (r.Tests == nil || r.Tests.Passed())
+}
+
+// mergePostPreviewJobs returns the post preview Jobs of the team with the overrides of the repository applied
+func mergePostPreviewJobs(teamJobs []batchv1.Job, override *config.PostPreviewJobsConfig) []batchv1.Job {
+	answer := []batchv1.Job{}
+	if override == nil {
+		return append(answer, teamJobs...)
+	}
+	if override.Disabled {
+		return answer
+	}
+	repoJobs := map[string]*config.PostPreviewJobConfig{}
+	for i := range override.Jobs {
+		repoJobs[override.Jobs[i].Name] = &override.Jobs[i]
+	}
+	if !override.Replace {
+		for _, job := range teamJobs {
+			if util.StringArrayIndex(override.Exclude, job.Name) >= 0 {
+				continue
+			}
+			if repoJob := repoJobs[job.Name]; repoJob != nil {
+				answer = append(answer, *newPostPreviewJob(repoJob))
+				delete(repoJobs, job.Name)
+				continue
+			}
+			answer = append(answer, job)
+		}
+	}
+	for i := range override.Jobs {
+		repoJob := &override.Jobs[i]
+		if repoJobs[repoJob.Name] != nil {
+			answer = append(answer, *newPostPreviewJob(repoJob))
+		}
+	}
+	return answer
+}
+
+// newPostPreviewJob creates the Job for the post preview Job configuration of a repository
+func newPostPreviewJob(jobConfig *config.PostPreviewJobConfig) *batchv1.Job {
+	backoffLimit := int32(2)
+	labels := map[string]string{
+		kube.LabelCreatedBy: kube.ValueCreatedByJX,
+		kube.LabelJobKind:   kube.ValueJobKindPostPreview,
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   jobConfig.Name,
+			Labels: labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    kube.ToValidName(jobConfig.Name),
+							Image:   jobConfig.Image,
+							Command: jobConfig.Command,
+							Args:    jobConfig.Args,
+							Env:     []corev1.EnvVar(jobConfig.Env),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// addJUnitResultsVolume mounts a sub directory of the shared results volume named after the Job into the results
+// directory of all the containers of the Job
+func addJUnitResultsVolume(job *batchv1.Job, resultsDir string) {
+	podSpec := &job.Spec.Template.Spec
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: postPreviewResultsVolume,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: postPreviewResultsClaim,
+			},
+		},
+	})
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      postPreviewResultsVolume,
+			MountPath: resultsDir,
+			SubPath:   job.Name,
+		})
+		if kube.GetEnvVar(container, JUnitResultsDirEnvVar) == nil {
+			container.Env = append(container.Env, corev1.EnvVar{
+				Name:  JUnitResultsDirEnvVar,
+				Value: resultsDir,
+			})
+		}
+	}
+}
+
+// ensureJUnitResultsClaim creates the persistent volume claim shared by the post preview Jobs if it does not exist
+func ensureJUnitResultsClaim(kubeClient kubernetes.Interface, ns string) error {
+	claims := kubeClient.CoreV1().PersistentVolumeClaims(ns)
+	_, err := claims.Get(postPreviewResultsClaim, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	_, err = claims.Create(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: postPreviewResultsClaim,
+			Labels: map[string]string{
+				kube.LabelCreatedBy: kube.ValueCreatedByJX,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse("1Gi"),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the PersistentVolumeClaim %s in namespace %s", postPreviewResultsClaim, ns)
+	}
+	return nil
+}
+
+// collectJUnitResults runs a Pod which prints and then removes the JUnit XML results written to the shared volume by
+// the post preview Jobs and returns the summary of the results of each Job
+func (o *PreviewOptions) collectJUnitResults(kubeClient kubernetes.Interface, ns string, image string) (map[string]*junit.Summary, error) {
+	pods := kubeClient.CoreV1().Pods(ns)
+	gracePeriod := int64(0)
+	pods.Delete(postPreviewResultsCollector, &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+
+	script := fmt.Sprintf("find %s -name '*.xml' | while read f; do echo \"%s$f\"; cat \"$f\"; echo; done; rm -rf %s/*",
+		postPreviewCollectorResultsDir, junitResultMarker, postPreviewCollectorResultsDir)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: postPreviewResultsCollector,
+			Labels: map[string]string{
+				kube.LabelCreatedBy: kube.ValueCreatedByJX,
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "collector",
+					Image:   image,
+					Command: []string{"sh", "-c", script},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      postPreviewResultsVolume,
+							MountPath: postPreviewCollectorResultsDir,
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: postPreviewResultsVolume,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: postPreviewResultsClaim,
+						},
+					},
+				},
+			},
+		},
+	}
+	hasPod := func() (bool, error) {
+		_, err := pods.Get(postPreviewResultsCollector, metav1.GetOptions{})
+		return err != nil, nil
+	}
+	o.retryUntilTrueOrTimeout(time.Minute, time.Second, hasPod)
+
+	_, err := pods.Create(pod)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create Pod %s in namespace %s", postPreviewResultsCollector, ns)
+	}
+	defer pods.Delete(postPreviewResultsCollector, &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+
+	fn := func() (bool, error) {
+		p, err := pods.Get(postPreviewResultsCollector, metav1.GetOptions{})
+		if err != nil {
+			return true, err
+		}
+		return p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed, nil
+	}
+	err = o.retryUntilTrueOrTimeout(5*time.Minute, o.PostPreviewJobPollDuration, fn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed waiting for Pod %s in namespace %s", postPreviewResultsCollector, ns)
+	}
+	data, err := pods.GetLogs(postPreviewResultsCollector, &corev1.PodLogOptions{}).DoRaw()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the log of Pod %s in namespace %s", postPreviewResultsCollector, ns)
+	}
+	return parseCollectedJUnitResults(string(data)), nil
+}
+
+// parseCollectedJUnitResults parses the output of the results collector Pod returning the summary of the JUnit results
+// of each Job. Results which cannot be parsed are ignored
+func parseCollectedJUnitResults(output string) map[string]*junit.Summary {
+	answer := map[string]*junit.Summary{}
+	files := map[string]string{}
+	paths := []string{}
+	path := ""
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, junitResultMarker) {
+			path = strings.TrimSpace(strings.TrimPrefix(line, junitResultMarker))
+			paths = append(paths, path)
+			continue
+		}
+		if path != "" {
+			files[path] += line + "\n"
+		}
+	}
+	for _, path := range paths {
+		relPath := strings.TrimPrefix(strings.TrimPrefix(path, postPreviewCollectorResultsDir), "/")
+		idx := strings.Index(relPath, "/")
+		if idx <= 0 {
+			continue
+		}
+		jobName := relPath[0:idx]
+		suites, err := junit.Parse([]byte(files[path]))
+		if err != nil {
+			log.Warnf("Failed to parse JUnit results %s: %s\n", path, err)
+			continue
+		}
+		summary := answer[jobName]
+		if summary == nil {
+			summary = &junit.Summary{}
+			answer[jobName] = summary
+		}
+		summary.Add(junit.Summarize(suites))
+	}
+	return answer
+}
+
+// getPostPreviewJobResult returns the outcome, duration and log tail of the post preview Job
+func (o *PreviewOptions) getPostPreviewJobResult(kubeClient kubernetes.Interface, job *batchv1.Job, tailLines int) *postPreviewJobResult {
+	answer := &postPreviewJobResult{
+		Name:   job.Name,
+		Result: postPreviewJobTimedOut,
+	}
+	curJob, err := kubeClient.BatchV1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+	if err != nil {
+		log.Warnf("Failed to get Job %s in namespace %s: %s\n", job.Name, job.Namespace, err)
+		answer.Result = postPreviewJobFailed
+		return answer
+	}
+	if kube.IsJobFinished(curJob) {
+		if kube.IsJobSucceeded(curJob) {
+			answer.Result = postPreviewJobSucceeded
+		} else {
+			answer.Result = postPreviewJobFailed
+		}
+	}
+	answer.Duration = jobDuration(curJob, time.Now())
+	answer.LogTail, err = jobLogTail(kubeClient, curJob, tailLines)
+	if err != nil {
+		log.Warnf("Failed to get the log of Job %s in namespace %s: %s\n", job.Name, job.Namespace, err)
+	}
+	return answer
+}
+
+// jobDuration returns how long the Job ran for or has been running for at the given time
+func jobDuration(job *batchv1.Job, now time.Time) time.Duration {
+	if job.Status.StartTime == nil {
+		return 0
+	}
+	end := now
+	if job.Status.CompletionTime != nil {
+		end = job.Status.CompletionTime.Time
+	} else if kube.IsJobFinished(job) {
+		for _, condition := range job.Status.Conditions {
+			if condition.Type == batchv1.JobFailed {
+				end = condition.LastTransitionTime.Time
+			}
+		}
+	}
+	return end.Sub(job.Status.StartTime.Time).Round(time.Second)
+}
+
+// jobLogTail returns the last lines of the log of the most recent Pod of the Job
+func jobLogTail(kubeClient kubernetes.Interface, job *batchv1.Job, tailLines int) (string, error) {
+	pods := kubeClient.CoreV1().Pods(job.Namespace)
+	podList, err := pods.List(metav1.ListOptions{LabelSelector: "job-name=" + job.Name})
+	if err != nil {
+		return "", err
+	}
+	if len(podList.Items) == 0 {
+		return "", nil
+	}
+	sort.Slice(podList.Items, func(i, j int) bool {
+		return podList.Items[i].CreationTimestamp.After(podList.Items[j].CreationTimestamp.Time)
+	})
+	lines := int64(tailLines)
+	data, err := pods.GetLogs(podList.Items[0].Name, &corev1.PodLogOptions{TailLines: &lines}).DoRaw()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// postPreviewResultsComment returns the markdown pull request comment describing the results of the post preview Jobs
+func postPreviewResultsComment(previewName string, results []*postPreviewJobResult) string {
+	failed := false
+	for _, r := range results {
+		if !r.Succeeded() {
+			failed = true
+		}
+	}
+	var buffer strings.Builder
+	if failed {
+		buffer.WriteString(fmt.Sprintf(":x: post preview Jobs failed against preview environment **%s**\n\n", previewName))
+	} else {
+		buffer.WriteString(fmt.Sprintf(":white_check_mark: post preview Jobs passed against preview environment **%s**\n\n", previewName))
+	}
+	buffer.WriteString("| Job | Result | Duration | Tests |\n")
+	buffer.WriteString("| --- | --- | --- | --- |\n")
+	for _, r := range results {
+		icon := ":white_check_mark:"
+		if !r.Succeeded() {
+			icon = ":x:"
+		}
+		tests := ""
+		if r.Tests != nil {
+			tests = r.Tests.String()
+		}
+		buffer.WriteString(fmt.Sprintf("| %s | %s %s | %s | %s |\n", r.Name, icon, r.Result, r.Duration, tests))
+	}
+	for _, r := range results {
+		if r.LogTail == "" && (r.Tests == nil || len(r.Tests.Failed) == 0) {
+			continue
+		}
+		buffer.WriteString(fmt.Sprintf("\n<details><summary>%s</summary>\n\n", r.Name))
+		if r.Tests != nil && len(r.Tests.Failed) > 0 {
+			buffer.WriteString("Failed tests:\n\n")
+			for _, name := range r.Tests.Failed {
+				buffer.WriteString(fmt.Sprintf("* `%s`\n", name))
+			}
+			buffer.WriteString("\n")
+		}
+		if r.LogTail != "" {
+			buffer.WriteString("```\n" + r.LogTail + "\n```\n")
+		}
+		buffer.WriteString("</details>\n")
+	}
+	return buffer.String()
+}
+
+// postPreviewCommitStatus returns the commit status of the result of a post preview Job
+func postPreviewCommitStatus(result *postPreviewJobResult, targetURL string) *gits.GitRepoStatus {
+	state := "success"
+	if result.Result == postPreviewJobTimedOut {
+		state = "error"
+	} else if !result.Succeeded() {
+		state = "failure"
+	}
+	description := fmt.Sprintf("%s in %s", result.Result, result.Duration)
+	if result.Tests != nil {
+		description += ": " + result.Tests.String()
+	}
+	return &gits.GitRepoStatus{
+		Context:     postPreviewStatusContextPrefix + result.Name,
+		State:       state,
+		TargetURL:   targetURL,
+		Description: description,
+	}
+}
+
+// reportPostPreviewResults comments on the pull request with the results of the post preview Jobs and adds a commit
+// status for each Job to the commit of the pull request
+func (o *PreviewOptions) reportPostPreviewResults(results []*postPreviewJobResult, url string) {
+	if o.GitInfo == nil || o.PullRequestName == "" {
+		return
+	}
+	stepPRCommentOptions := StepPRCommentOptions{
+		Flags: StepPRCommentFlags{
+			Owner:      o.GitInfo.Organisation,
+			Repository: o.GitInfo.Name,
+			Comment:    postPreviewResultsComment(o.Name, results),
+			PR:         o.PullRequestName,
+		},
+		StepPROptions: StepPROptions{
+			StepOptions: StepOptions{
+				CommonOptions: CommonOptions{
+					BatchMode: true,
+					Factory:   o.Factory,
+				},
+			},
+		},
+	}
+	err := stepPRCommentOptions.Run()
+	if err != nil {
+		log.Warnf("Failed to comment on the Pull Request with the post preview Job results: %s\n", err)
+	}
+
+	if o.GitProvider == nil {
+		return
+	}
+	sha := o.pullRequestCommitSha()
+	if sha == "" {
+		log.Warnf("Could not find the commit of Pull Request %s so cannot add the post preview Job statuses\n", o.PullRequestName)
+		return
+	}
+	for _, result := range results {
+		_, err = o.GitProvider.UpdateCommitStatus(o.GitInfo.Organisation, o.GitInfo.Name, sha, postPreviewCommitStatus(result, url))
+		if err != nil {
+			log.Warnf("Failed to update the commit status of post preview Job %s: %s\n", result.Name, err)
+		}
+	}
+}
+
+// pullRequestCommitSha returns the SHA of the commit of the pull request being previewed
+func (o *PreviewOptions) pullRequestCommitSha() string {
+	sha := os.Getenv(PULL_PULL_SHA)
+	if sha != "" {
+		return sha
+	}
+	prNum, err := strconv.Atoi(o.PullRequestName)
+	if err != nil {
+		return ""
+	}
+	pullRequest, err := o.GitProvider.GetPullRequest(o.GitInfo.Organisation, o.GitInfo, prNum)
+	if err != nil || pullRequest == nil {
+		return ""
+	}
+	return pullRequest.LastCommitSha
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/junit"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMergePostPreviewJobs(t *testing.T) {
+	t.Parallel()
+	teamJobs := []batchv1.Job{
+		*newPostPreviewJob(&config.PostPreviewJobConfig{Name: "owasp", Image: "owasp/zap2docker-weekly"}),
+		*newPostPreviewJob(&config.PostPreviewJobConfig{Name: "smoke", Image: "maven:3"}),
+	}
+
+	assertPostPreviewJobNames(t, mergePostPreviewJobs(teamJobs, nil), "owasp", "smoke")
+	assertPostPreviewJobNames(t, mergePostPreviewJobs(teamJobs, &config.PostPreviewJobsConfig{Disabled: true}))
+	assertPostPreviewJobNames(t, mergePostPreviewJobs(teamJobs, &config.PostPreviewJobsConfig{Exclude: []string{"owasp"}}), "smoke")
+
+	override := &config.PostPreviewJobsConfig{
+		Jobs: []config.PostPreviewJobConfig{
+			{Name: "perf", Image: "loadimpact/k6"},
+			{Name: "smoke", Image: "node:10", Args: []string{"npm", "test"}},
+		},
+	}
+	jobs := mergePostPreviewJobs(teamJobs, override)
+	assertPostPreviewJobNames(t, jobs, "owasp", "smoke", "perf")
+	assert.Equal(t, "node:10", jobs[1].Spec.Template.Spec.Containers[0].Image)
+
+	override.Replace = true
+	assertPostPreviewJobNames(t, mergePostPreviewJobs(teamJobs, override), "perf", "smoke")
+}
+
+func TestAddJUnitResultsVolume(t *testing.T) {
+	t.Parallel()
+	job := newPostPreviewJob(&config.PostPreviewJobConfig{Name: "smoke", Image: "maven:3"})
+	addJUnitResultsVolume(job, "/results/junit")
+
+	podSpec := job.Spec.Template.Spec
+	require.Len(t, podSpec.Volumes, 1)
+	assert.Equal(t, postPreviewResultsClaim, podSpec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	container := podSpec.Containers[0]
+	require.Len(t, container.VolumeMounts, 1)
+	assert.Equal(t, "/results/junit", container.VolumeMounts[0].MountPath)
+	assert.Equal(t, "smoke", container.VolumeMounts[0].SubPath)
+	assert.Equal(t, "/results/junit", kube.GetEnvVar(&container, JUnitResultsDirEnvVar).Value)
+}
+
+func TestParseCollectedJUnitResults(t *testing.T) {
+	t.Parallel()
+	output := `--- JUNIT /results/smoke/TEST-a.xml
+<testsuite name="a"><testcase name="one"/><testcase name="two"><failure/></testcase></testsuite>
+
+--- JUNIT /results/smoke/nested/TEST-b.xml
+<testsuite name="b"><testcase name="three"/></testsuite>
+
+--- JUNIT /results/owasp/report.xml
+not xml
+`
+	summaries := parseCollectedJUnitResults(output)
+	require.Len(t, summaries, 1)
+	smoke := summaries["smoke"]
+	require.NotNil(t, smoke)
+	assert.Equal(t, 3, smoke.Tests)
+	assert.Equal(t, 1, smoke.Failures)
+	assert.Equal(t, []string{"two"}, smoke.Failed)
+}
+
+func TestJobDuration(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	now := start.Add(10 * time.Minute)
+	job := &batchv1.Job{}
+	assert.Equal(t, time.Duration(0), jobDuration(job, now))
+
+	job.Status.StartTime = &metav1.Time{Time: start}
+	assert.Equal(t, 10*time.Minute, jobDuration(job, now))
+
+	backoffLimit := int32(1)
+	job.Spec.BackoffLimit = &backoffLimit
+	job.Status.Failed = 1
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Time{Time: start.Add(2 * time.Minute)}},
+	}
+	assert.Equal(t, 2*time.Minute, jobDuration(job, now))
+
+	job.Status.CompletionTime = &metav1.Time{Time: start.Add(3 * time.Minute)}
+	assert.Equal(t, 3*time.Minute, jobDuration(job, now))
+}
+
+func TestPostPreviewResults(t *testing.T) {
+	t.Parallel()
+	results := []*postPreviewJobResult{
+		{Name: "owasp", Result: postPreviewJobSucceeded, Duration: 2 * time.Minute},
+		{
+			Name:     "smoke",
+			Result:   postPreviewJobSucceeded,
+			Duration: 30 * time.Second,
+			LogTail:  "Tests run: 10, Failures: 1",
+			Tests:    &junit.Summary{Tests: 10, Failures: 1, Failed: []string{"com.acme.SmokeTest.testHome"}},
+		},
+		{Name: "perf", Result: postPreviewJobTimedOut, Duration: 2 * time.Hour},
+	}
+	assert.True(t, results[0].Succeeded())
+	assert.False(t, results[1].Succeeded())
+
+	comment := postPreviewResultsComment("myorg-myapp-pr-1", results)
+	assert.True(t, strings.HasPrefix(comment, ":x: post preview Jobs failed"), "comment %s", comment)
+	assert.Contains(t, comment, "| owasp | :white_check_mark: Succeeded | 2m0s |  |")
+	assert.Contains(t, comment, "| smoke | :x: Succeeded | 30s | 10 tests, 1 failed |")
+	assert.Contains(t, comment, "* `com.acme.SmokeTest.testHome`")
+	assert.Contains(t, comment, "```\nTests run: 10, Failures: 1\n```")
+	assert.Equal(t, ":white_check_mark: post preview Jobs passed against preview environment **myorg-myapp-pr-1**\n\n",
+		strings.SplitAfterN(postPreviewResultsComment("myorg-myapp-pr-1", results[0:1]), "\n\n", 2)[0])
+
+	status := postPreviewCommitStatus(results[0], "http://myapp.jx-pr-1.example.com")
+	assert.Equal(t, "post-preview/owasp", status.Context)
+	assert.Equal(t, "success", status.State)
+	assert.Equal(t, "Succeeded in 2m0s", status.Description)
+	assert.Equal(t, "failure", postPreviewCommitStatus(results[1], "").State)
+	assert.Equal(t, "Succeeded in 30s: 10 tests, 1 failed", postPreviewCommitStatus(results[1], "").Description)
+	assert.Equal(t, "error", postPreviewCommitStatus(results[2], "").State)
+}
+
+func assertPostPreviewJobNames(t *testing.T, jobs []batchv1.Job, expected ...string) {
+	names := []string{}
+	for _, job := range jobs {
+		names = append(names, job.Name)
+	}
+	if expected == nil {
+		expected = []string{}
+	}
+	assert.Equal(t, expected, names)
+}
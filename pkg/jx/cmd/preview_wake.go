@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+	"gopkg.in/AlecAivazis/survey.v1/terminal"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PreviewWakeOptions the options for waking a hibernated preview environment
+type PreviewWakeOptions struct {
+	PreviewOptions
+}
+
+var (
+	previewWakeLong = templates.LongDesc(`
+		Wakes up a preview environment which was hibernated after being idle by scaling its deployments back up.
+
+		Preview environments are also woken up automatically the next time the pull request is built.
+`)
+
+	previewWakeExample = templates.Examples(`
+		# wake up the preview environment of the current pull request
+		jx preview wake
+
+		# wake up a preview environment by name
+		jx preview wake myorg-myapp-pr-1
+`)
+)
+
+// NewCmdPreviewWake creates a command object for the "preview wake" command
+func NewCmdPreviewWake(f Factory, in terminal.FileReader, out terminal.FileWriter, errOut io.Writer) *cobra.Command {
+	options := &PreviewWakeOptions{
+		PreviewOptions: PreviewOptions{
+			PromoteOptions: PromoteOptions{
+				CommonOptions: CommonOptions{
+					Factory: f,
+					In:      in,
+					Out:     out,
+					Err:     errOut,
+				},
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "wake [name]",
+		Short:   "Wakes up a hibernated preview environment",
+		Long:    previewWakeLong,
+		Example: previewWakeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			CheckErr(err)
+		},
+	}
+	options.addPreviewOptions(cmd)
+	options.addCommonFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *PreviewWakeOptions) Run() error {
+	kubeClient, currentNs, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return err
+	}
+	ns, _, err := kube.GetDevNamespace(kubeClient, currentNs)
+	if err != nil {
+		return err
+	}
+
+	if len(o.Args) > 0 {
+		o.Name = o.Args[0]
+	}
+	if o.Name == "" {
+		err = o.defaultValues(ns, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	environments := jxClient.JenkinsV1().Environments(ns)
+	env, err := environments.Get(o.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not find preview environment %s: %s", o.Name, err)
+	}
+	if !kube.IsPreviewEnvironment(env) {
+		return fmt.Errorf("environment %s is not a preview environment", o.Name)
+	}
+	if !env.Spec.PreviewGitSpec.Hibernated {
+		log.Infof("Preview environment %s is not hibernated\n", util.ColorInfo(env.Name))
+		return nil
+	}
+
+	err = kube.WakePreview(kubeClient, env, time.Now())
+	if err != nil {
+		return err
+	}
+	env, err = environments.Update(env)
+	if err != nil {
+		return fmt.Errorf("Failed to update Environment %s due to %s", o.Name, err)
+	}
+	url := env.Spec.PreviewGitSpec.ApplicationURL
+	log.Infof("Woke up preview environment %s\n", util.ColorInfo(env.Name))
+
+	comment := fmt.Sprintf(":sunny: preview environment **%s** has woken up", env.Name)
+	if url != "" {
+		comment += fmt.Sprintf(" and is available [here](%s)", url)
+	}
+	err = o.commentOnPreviewPullRequest(env, comment)
+	if err != nil {
+		log.Warnf("Failed to comment on the Pull Request: %s\n", err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -11,17 +12,23 @@ import (
 
 	"github.com/blang/semver"
 	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/chats"
 	typev1 "github.com/jenkins-x/jx/pkg/client/clientset/versioned/typed/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/cve"
 	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/issues"
 	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"gopkg.in/AlecAivazis/survey.v1"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 )
 
 const (
@@ -60,12 +67,18 @@ type PromoteOptions struct {
 	PullRequestPollTime string
 	Filter              string
 	Alias               string
+	Image               string
+	ImageDigest         string
+	// SubProject the app of a monorepo being promoted. Defaults to $SUB_PROJECT or the app of the current directory
+	SubProject string
 
 	// allow git to be configured externally before a PR is created
 	ConfigureGitCallback ConfigureGitFolderFn
 
 	// for testing
 	FakePullRequests CreateEnvPullRequestFn
+	DynamicClient    dynamic.Interface
+	CVEProvider      cve.CVEProvider
 
 	// calculated fields
 	TimeoutDuration         *time.Duration
@@ -74,6 +87,7 @@ type PromoteOptions struct {
 	GitInfo                 *gits.GitRepositoryInfo
 	jenkinsURL              string
 	releaseResource         *v1.Release
+	applicationConfig       *config.ProjectConfig
 	ReleaseInfo             *ReleaseInfo
 }
 
@@ -82,6 +96,8 @@ type ReleaseInfo struct {
 	FullAppName     string
 	Version         string
 	PullRequestInfo *ReleasePullRequestInfo
+	// Queued is true if the promotion was queued until the next deployment window of the Environment opens
+	Queued bool
 }
 
 type ReleasePullRequestInfo struct {
@@ -155,6 +171,8 @@ func (options *PromoteOptions) addPromoteOptions(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&options.Application, optionApplication, "a", "", "The Application to promote")
 	cmd.Flags().StringVarP(&options.Filter, "filter", "f", "", "The search filter to find charts to promote")
 	cmd.Flags().StringVarP(&options.Alias, "alias", "", "", "The optional alias used in the 'requirements.yaml' file")
+	cmd.Flags().StringVarP(&options.Image, "image", "", "", "The docker image checked against the CVE policy of the Environment. Defaults to the organisation and application name")
+	cmd.Flags().StringVarP(&options.ImageDigest, "image-digest", "", "", "The manifest digest of the docker image checked against the CVE policy. Defaults to the digest of the version tag in the docker registry")
 	cmd.Flags().StringVarP(&options.Pipeline, "pipeline", "", "", "The Pipeline string in the form 'folderName/repoName/branch' which is used to update the PipelineActivity. If not specified its defaulted from  the '$BUILD_NUMBER' environment variable")
 	cmd.Flags().StringVarP(&options.Build, "build", "", "", "The Build number which is used to update the PipelineActivity. If not specified its defaulted from  the '$BUILD_NUMBER' environment variable")
 	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The Version to promote")
@@ -317,19 +335,30 @@ func (o *PromoteOptions) PromoteAllAutomatic() error {
 	}
 	kube.SortEnvironments(environments)
 
-	for _, env := range environments {
+	queued := ""
+	for i := range environments {
+		env := &environments[i]
 		kind := env.Spec.Kind
 		if env.Spec.PromotionStrategy == v1.PromotionStrategyTypeAutomatic && kind.IsPermanent() {
+			if queued != "" {
+				o.queueAfterEnvironment(env, queued)
+				continue
+			}
 			ns := env.Spec.Namespace
 			if ns == "" {
 				return fmt.Errorf("No namespace for environment %s", env.Name)
 			}
-			releaseInfo, err := o.Promote(ns, &env, false)
+			releaseInfo, err := o.Promote(ns, env, false)
 			if err != nil {
 				return err
 			}
 			o.ReleaseInfo = releaseInfo
-			err = o.WaitForPromotion(ns, &env, releaseInfo)
+			if releaseInfo != nil && releaseInfo.Queued {
+				// the later environments are promoted by the workflow controller in order once this one is promoted
+				queued = env.Name
+				continue
+			}
+			err = o.WaitForPromotion(ns, env, releaseInfo)
 			if err != nil {
 				return err
 			}
@@ -338,6 +367,28 @@ func (o *PromoteOptions) PromoteAllAutomatic() error {
 	return nil
 }
 
+// queueAfterEnvironment marks the promotion to the Environment as Pending until the promotion to the queued
+// Environment has succeeded so that the workflow controller promotes the environments in order
+func (o *PromoteOptions) queueAfterEnvironment(env *v1.Environment, queued string) {
+	message := fmt.Sprintf("Waiting for the promotion to Environment %s", queued)
+	version := o.Version
+	queuePromote := func(a *v1.PipelineActivity, s *v1.PipelineActivityStep, ps *v1.PromoteActivityStep) error {
+		if ps.Status == v1.ActivityStatusTypeNone || ps.Status == v1.ActivityStatusTypePending {
+			ps.Status = v1.ActivityStatusTypePending
+			ps.Description = message
+		}
+		if version != "" && a.Spec.Version == "" {
+			a.Spec.Version = version
+		}
+		return nil
+	}
+	err := o.createPromoteKey(env).OnPromote(o.Activities, queuePromote)
+	if err != nil {
+		log.Warnf("Failed to update PipelineActivity: %s\n", err)
+	}
+	log.Infof("Queued the promotion of %s to %s until it has been promoted to %s\n", util.ColorInfo(o.Application), util.ColorInfo(env.Name), util.ColorInfo(queued))
+}
+
 func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAuto bool) (*ReleaseInfo, error) {
 	surveyOpts := survey.WithStdio(o.In, o.Out, o.Err)
 	app := o.Application
@@ -384,10 +435,24 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 		}
 	}
 
+	open, err := o.queueOutsideDeploymentWindow(env)
+	if err != nil || !open {
+		releaseInfo.Queued = err == nil
+		return releaseInfo, err
+	}
+	err = o.CheckCVEPolicy(env)
+	if err != nil {
+		return releaseInfo, err
+	}
+
 	promoteKey := o.createPromoteKey(env)
 	if env != nil {
 		source := &env.Spec.Source
 		if source.URL != "" && env.Spec.Kind.IsPermanent() {
+			if env.Spec.ProgressiveDelivery != nil {
+				// the chart is installed by the pipeline of the GitOps repository rather than by the promotion
+				return releaseInfo, fmt.Errorf("progressive delivery is not supported for Environment %s as it is promoted via its git repository %s. Remove the progressiveDelivery or the source URL of the Environment", env.Name, source.URL)
+			}
 			err := o.PromoteViaPullRequest(env, releaseInfo)
 			if err == nil {
 				startPromotePR := func(a *v1.PipelineActivity, s *v1.PipelineActivityStep, ps *v1.PromoteActivityStep, p *v1.PromotePullRequestStep) error {
@@ -405,13 +470,18 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 				if err != nil {
 					log.Warnf("Failed to update PipelineActivity: %s\n", err)
 				}
+				prURL := ""
+				if pr := releaseInfo.PullRequestInfo; pr != nil && pr.PullRequest != nil {
+					prURL = pr.PullRequest.URL
+				}
+				o.notifyPromotion(env, o.promoteMessage(targetNS, env, version, "Pull Request created", prURL, v1.ActivityStatusTypePending))
 				// lets sleep a little before we try poll for the PR status
 				time.Sleep(waitAfterPullRequestCreated)
 			}
 			return releaseInfo, err
 		}
 	}
-	err := o.verifyHelmConfigured()
+	err = o.verifyHelmConfigured()
 	if err != nil {
 		return releaseInfo, err
 	}
@@ -434,19 +504,80 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 	}
 	promoteKey.OnPromoteUpdate(o.Activities, startPromote)
 
-	err = o.Helm().UpgradeChart(fullAppName, releaseName, targetNS, &version, true, nil, false, true, nil, nil)
+	if env != nil && env.Spec.ProgressiveDelivery != nil {
+		err = o.PromoteProgressively(targetNS, env, fullAppName, releaseName, version)
+	} else {
+		err = o.Helm().UpgradeChart(fullAppName, releaseName, targetNS, &version, true, nil, false, true, nil, nil)
+	}
 	if err == nil {
 		err = o.commentOnIssues(targetNS, env, promoteKey)
 		if err != nil {
 			log.Warnf("Failed to comment on issues for release %s: %s\n", releaseName, err)
 		}
+		o.notifyPromotion(env, o.promoteMessage(targetNS, env, version, "", "", v1.ActivityStatusTypeSucceeded))
 		err = promoteKey.OnPromoteUpdate(o.Activities, kube.CompletePromotionUpdate)
 	} else {
+		o.notifyPromotion(env, o.promoteMessage(targetNS, env, version, err.Error(), "", v1.ActivityStatusTypeFailed))
 		err = promoteKey.OnPromoteUpdate(o.Activities, kube.FailedPromotionUpdate)
 	}
 	return releaseInfo, err
 }
 
+// promoteMessage returns the chat message notifying the status of the promotion to the environment
+func (o *PromoteOptions) promoteMessage(targetNS string, env *v1.Environment, version string, text string, url string, status v1.ActivityStatusType) *chats.Message {
+	envName := targetNS
+	if env != nil {
+		envName = env.Spec.Label
+		if envName == "" {
+			envName = env.Name
+		}
+	}
+	app := o.Application
+	if version != "" {
+		app = app + " " + version
+	}
+	return &chats.Message{
+		Title:  fmt.Sprintf("Promotion of %s to %s", app, envName),
+		Text:   text,
+		URL:    url,
+		Status: string(status),
+	}
+}
+
+// queueOutsideDeploymentWindow marks the promotion as Pending if the Environment is outside of its deployment windows
+// or frozen so that the workflow controller promotes it once the next window opens. Returns false if the promotion was queued
+func (o *PromoteOptions) queueOutsideDeploymentWindow(env *v1.Environment) (bool, error) {
+	if !kube.HasDeploymentWindows(env) {
+		return true, nil
+	}
+	now := time.Now()
+	next, err := kube.NextDeploymentWindow(env, now)
+	if err != nil {
+		return false, err
+	}
+	if !next.After(now) {
+		return true, nil
+	}
+	message := fmt.Sprintf("Waiting for the deployment window of Environment %s at %s", env.Name, next.Format(time.RFC1123))
+	version := o.Version
+	queuePromote := func(a *v1.PipelineActivity, s *v1.PipelineActivityStep, ps *v1.PromoteActivityStep) error {
+		if ps.Status == v1.ActivityStatusTypeNone || ps.Status == v1.ActivityStatusTypePending {
+			ps.Status = v1.ActivityStatusTypePending
+			ps.Description = message
+		}
+		if version != "" && a.Spec.Version == "" {
+			a.Spec.Version = version
+		}
+		return nil
+	}
+	err = o.createPromoteKey(env).OnPromote(o.Activities, queuePromote)
+	if err != nil {
+		log.Warnf("Failed to update PipelineActivity: %s\n", err)
+	}
+	log.Infof("Queued the promotion of %s to %s until %s when the workflow controller will promote it\n", util.ColorInfo(o.Application), util.ColorInfo(env.Name), util.ColorInfo(next.Format(time.RFC1123)))
+	return false, nil
+}
+
 func (o *PromoteOptions) PromoteViaPullRequest(env *v1.Environment, releaseInfo *ReleaseInfo) error {
 	version := o.Version
 	versionName := version
@@ -768,6 +899,70 @@ func (o *PromoteOptions) verifyHelmConfigured() error {
 	return o.registerLocalHelmRepo(o.LocalHelmRepoName, ns)
 }
 
+// notifyPromotion posts the message to the developer channel configured in the jenkins-x.yml of the application,
+// replying in the chat thread of the PipelineActivity being promoted
+func (o *PromoteOptions) notifyPromotion(env *v1.Environment, message *chats.Message) {
+	notify := func(message *chats.Message) string {
+		if !o.IgnoreLocalFiles {
+			return o.notifyDeveloperChannel("", message)
+		}
+		projectConfig := o.applicationConfig
+		if projectConfig == nil {
+			// lets not clone the application when there is no chat server to notify
+			if !o.hasChatServers() {
+				return ""
+			}
+			err := o.withApplicationDir(env, func(dir string) error {
+				var err error
+				projectConfig, _, err = config.LoadProjectConfig(dir)
+				return err
+			})
+			if err != nil {
+				log.Warnf("Failed to load the project configuration of %s to notify the developer channel: %s\n", o.Application, err)
+				return ""
+			}
+			o.applicationConfig = projectConfig
+		}
+		return o.notifyChatDeveloperChannel(projectConfig.Chat, message)
+	}
+	if o.Activities == nil {
+		notify(message)
+		return
+	}
+	o.notifyActivityChatThread(o.Activities, o.createPromoteKey(env).Name, message, notify)
+}
+
+// withApplicationDir invokes fn with the directory of the git repository of the application being promoted. When the
+// local files are ignored, such as for promotions driven by the workflow controller, the git repository of the
+// PipelineActivity is cloned into a temporary directory which is removed once fn returns
+func (o *PromoteOptions) withApplicationDir(env *v1.Environment, fn func(dir string) error) error {
+	if !o.IgnoreLocalFiles {
+		return fn("")
+	}
+	if o.Activities == nil {
+		return fmt.Errorf("no PipelineActivity client to find the git repository")
+	}
+	name := o.createPromoteKey(env).Name
+	activity, err := o.Activities.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find PipelineActivity %s", name)
+	}
+	gitURL := activity.Spec.GitURL
+	if gitURL == "" {
+		return fmt.Errorf("PipelineActivity %s has no git URL", name)
+	}
+	dir, err := ioutil.TempDir("", "jx-promote-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create a temporary directory")
+	}
+	defer os.RemoveAll(dir)
+	err = o.Git().Clone(gitURL, dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to clone %s", gitURL)
+	}
+	return fn(dir)
+}
+
 func (o *PromoteOptions) createPromoteKey(env *v1.Environment) *kube.PromoteStepActivityKey {
 	pipeline := o.Pipeline
 	build := o.Build
@@ -808,9 +1003,12 @@ func (o *PromoteOptions) createPromoteKey(env *v1.Environment) *kube.PromoteStep
 			log.Warnf("Could not discover the latest PipelineActivity build %s\n", err)
 		}
 	}
-	name := pipeline
+	subProject := o.SubProject
+	if subProject == "" && !o.IgnoreLocalFiles {
+		subProject = o.getSubProject()
+	}
+	name := kube.PipelineActivityName(pipeline, subProject, build)
 	if build != "" {
-		name += "-" + build
 		if buildURL == "" || buildLogsURL == "" {
 			jenkinsURL := o.getJenkinsURL()
 			if jenkinsURL != "" {
@@ -833,7 +1031,6 @@ func (o *PromoteOptions) createPromoteKey(env *v1.Environment) *kube.PromoteStep
 			}
 		}
 	}
-	name = kube.ToValidName(name)
 	if o.Verbose {
 		log.Infof("Using pipeline: %s build: %s\n", util.ColorInfo(pipeline), util.ColorInfo("#"+build))
 	}
@@ -1072,6 +1269,7 @@ func (o *PromoteOptions) commentOnIssues(targetNS string, environment *v1.Enviro
 	release, err := jxClient.JenkinsV1().Releases(ens).Get(releaseName, metav1.GetOptions{})
 	if err == nil && release != nil {
 		o.releaseResource = release
+		o.transitionIssuesForEnvironment(environment, release)
 		issues := release.Spec.Issues
 
 		versionMessage := version
@@ -1103,6 +1301,61 @@ func (o *PromoteOptions) commentOnIssues(targetNS string, environment *v1.Enviro
 	return nil
 }
 
+// transitionIssuesForEnvironment applies the issue transition of the environment in the issue tracker configuration
+// of the project to the issues of the release
+func (o *PromoteOptions) transitionIssuesForEnvironment(environment *v1.Environment, release *v1.Release) {
+	if len(release.Spec.Issues) == 0 {
+		return
+	}
+	err := o.withApplicationDir(environment, func(dir string) error {
+		pc, _, err := config.LoadProjectConfig(dir)
+		if err != nil {
+			return errors.Wrap(err, "failed to load the project configuration")
+		}
+		if pc.IssueTracker == nil {
+			return nil
+		}
+		transition := pc.IssueTracker.GetTransition(environment.Name, environment.Spec.Label)
+		if transition == nil || (transition.Status == "" && !transition.FixVersion) {
+			return nil
+		}
+		tracker, err := o.createIssueProvider(dir)
+		if err != nil {
+			return errors.Wrap(err, "failed to create the issue tracker")
+		}
+		o.transitionIssues(tracker, transition, release)
+		return nil
+	})
+	if err != nil {
+		log.Warnf("Failed to transition the issues of %s: %s\n", o.Application, err)
+	}
+}
+
+// transitionIssues moves the issues of the release to the status of the transition and sets their fix version
+func (o *PromoteOptions) transitionIssues(tracker issues.IssueProvider, transition *config.IssueTransitionConfig, release *v1.Release) {
+	version := release.Spec.Version
+	for _, issue := range release.Spec.Issues {
+		key := issue.ID
+		if key == "" {
+			continue
+		}
+		if transition.Status != "" {
+			err := tracker.TransitionIssue(key, transition.Status)
+			if err != nil {
+				log.Warnf("Failed to move issue %s to %s: %s\n", key, transition.Status, err)
+			} else {
+				log.Infof("Moved issue %s to %s\n", util.ColorInfo(key), util.ColorInfo(transition.Status))
+			}
+		}
+		if transition.FixVersion && version != "" {
+			err := tracker.SetIssueFixVersion(key, version)
+			if err != nil {
+				log.Warnf("Failed to set the fix version of issue %s to %s: %s\n", key, version, err)
+			}
+		}
+	}
+}
+
 func (o *PromoteOptions) SearchForChart(filter string) (string, error) {
 	answer := ""
 	charts, err := o.Helm().SearchCharts(filter)
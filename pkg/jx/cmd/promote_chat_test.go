@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/chats"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm/mocks"
+	"github.com/jenkins-x/jx/pkg/kube"
+	. "github.com/petergtz/pegomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestPromoteWithApplicationDirClonesActivityRepository(t *testing.T) {
+	RegisterMockTestingT(t)
+	env := kube.NewPermanentEnvironment("staging")
+	o := &PromoteOptions{
+		Application:      "myapp",
+		Pipeline:         "myorg/myapp/master",
+		Build:            "1",
+		IgnoreLocalFiles: true,
+	}
+	ConfigureTestOptionsWithResources(&o.CommonOptions, nil, []runtime.Object{env}, &gits.GitFake{}, helm_test.NewMockHelmer())
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	require.NoError(t, err)
+	o.Activities = jxClient.JenkinsV1().PipelineActivities(ns)
+	_, err = o.Activities.Create(&v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "myorg-myapp-master-1",
+		},
+		Spec: v1.PipelineActivitySpec{
+			Pipeline: "myorg/myapp/master",
+			Build:    "1",
+			GitURL:   "https://github.com/myorg/myapp.git",
+		},
+	})
+	require.NoError(t, err)
+
+	dirs := []string{}
+	for i := 0; i < 2; i++ {
+		err = o.withApplicationDir(env, func(dir string) error {
+			assert.DirExists(t, dir)
+			dirs = append(dirs, dir)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+	require.Len(t, dirs, 2)
+	assert.NotEqual(t, dirs[0], dirs[1], "each promotion should clone into its own directory")
+	for _, dir := range dirs {
+		_, err = os.Stat(dir)
+		assert.True(t, os.IsNotExist(err), "the clone %s should be removed", dir)
+	}
+
+	o.IgnoreLocalFiles = false
+	err = o.withApplicationDir(env, func(dir string) error {
+		assert.Equal(t, "", dir)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestNotifyActivityChatThread(t *testing.T) {
+	o := &CommonOptions{}
+	ConfigureTestOptionsWithResources(o, nil, nil, &gits.GitFake{}, helm_test.NewMockHelmer())
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	require.NoError(t, err)
+	activities := jxClient.JenkinsV1().PipelineActivities(ns)
+	_, err = activities.Create(&v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "myorg-myapp-master-1",
+		},
+	})
+	require.NoError(t, err)
+
+	threadIDs := []string{}
+	notify := func(message *chats.Message) string {
+		threadIDs = append(threadIDs, message.ThreadID)
+		if message.ThreadID != "" {
+			return message.ThreadID
+		}
+		return "1542196800.000100"
+	}
+	o.notifyActivityChatThread(activities, "myorg-myapp-master-1", &chats.Message{Title: "Pipeline completed"}, notify)
+	o.notifyActivityChatThread(activities, "myorg-myapp-master-1", &chats.Message{Title: "Promoted to Staging"}, notify)
+	assert.Equal(t, []string{"", "1542196800.000100"}, threadIDs)
+
+	activity, err := activities.Get("myorg-myapp-master-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "1542196800.000100", activity.Annotations[kube.AnnotationChatThread])
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CheckCVEPolicy queries the CVE provider for the vulnerabilities of the image being promoted and checks them
+// against the CVE policy of the Environment. The result is recorded as a Fact on the PipelineActivity.
+// Returns an error if the policy is violated and its action is to block the promotion. A blocked promotion is left
+// Pending so that the workflow controller checks the policy again until the vulnerabilities are fixed or allowed.
+// The promotion fails once the policy has been checked MaxChecks times
+func (o *PromoteOptions) CheckCVEPolicy(env *v1.Environment) error {
+	if env == nil || env.Spec.CVEPolicy == nil {
+		return nil
+	}
+	policy := env.Spec.CVEPolicy
+	warn := policy.Action == v1.CVEPolicyActionTypeWarn
+	image := o.imageName()
+
+	images, err := o.getImageVulnerabilities(image)
+	if err != nil {
+		if warn {
+			log.Warnf("Could not check image %s against the CVE policy of Environment %s: %s\n", image, env.Name, err)
+			return nil
+		}
+		return errors.Wrapf(err, "could not check image %s against the CVE policy of Environment %s", image, env.Name)
+	}
+	firstSeen := map[string]time.Time{}
+	if o.Activities != nil {
+		list, err := o.Activities.List(metav1.ListOptions{})
+		if err != nil {
+			log.Warnf("Failed to load the PipelineActivity resources to find when vulnerabilities were first seen: %s\n", err)
+		} else {
+			promoteKey := o.createPromoteKey(env)
+			firstSeen = cve.FirstSeen(list.Items, promoteKey.Pipeline)
+		}
+	}
+	violations, err := cve.CheckPolicy(policy, images, firstSeen, time.Now())
+	if err != nil {
+		return err
+	}
+
+	blocked := len(violations) > 0 && !warn
+	message := ""
+	if len(violations) > 0 {
+		message = fmt.Sprintf("image %s violates the CVE policy of Environment %s: %s", image, env.Name, cve.DescribeViolations(violations))
+	}
+	maxChecks := policy.MaxChecks
+	if maxChecks <= 0 {
+		maxChecks = cve.DefaultPolicyMaxChecks
+	}
+	failed := false
+	fact := cve.CreatePolicyFact(env.Name, policy, images, violations)
+	recordFact := func(a *v1.PipelineActivity, s *v1.PipelineActivityStep, ps *v1.PromoteActivityStep) error {
+		cve.SetFact(a, fact)
+		if o.Version != "" && a.Spec.Version == "" {
+			a.Spec.Version = o.Version
+		}
+		if blocked {
+			ps.PolicyViolation = message
+			ps.PolicyChecks++
+			ps.Description = message
+			failed = ps.PolicyChecks >= maxChecks
+			if failed {
+				ps.Description = fmt.Sprintf("%s. Gave up after checking the CVE policy %d times", message, ps.PolicyChecks)
+				kube.FailedActivityStep(&ps.CoreActivityStep)
+			} else {
+				ps.Status = v1.ActivityStatusTypePending
+			}
+		} else if isBlockedPromotion(ps) {
+			// the promotion was previously blocked so lets clear the reason now it can start
+			ps.PolicyViolation = ""
+			ps.PolicyChecks = 0
+			ps.Description = ""
+			ps.Status = v1.ActivityStatusTypePending
+			ps.CompletedTimestamp = nil
+		}
+		return nil
+	}
+	err = o.createPromoteKey(env).OnPromote(o.Activities, recordFact)
+	if err != nil {
+		log.Warnf("Failed to record the CVE policy check on the PipelineActivity: %s\n", err)
+	}
+
+	if len(violations) == 0 {
+		log.Infof("Image %s satisfies the CVE policy of Environment %s\n", util.ColorInfo(image), util.ColorInfo(env.Name))
+		return nil
+	}
+	if warn {
+		log.Warnf("%s\n", message)
+		return nil
+	}
+	if failed {
+		o.notifyPromotion(env, o.promoteMessage(env.Spec.Namespace, env, o.Version, message, "", v1.ActivityStatusTypeFailed))
+	}
+	return fmt.Errorf("%s", message)
+}
+
+func (o *PromoteOptions) getImageVulnerabilities(image string) ([]cve.ImageVulnerabilities, error) {
+	provider := o.CVEProvider
+	if provider == nil {
+		var err error
+		provider, err = o.CreateCVEProvider()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return provider.GetImageVulnerabilities(cve.CVEQuery{
+		ImageName:   image,
+		ImageDigest: o.imageDigest(image),
+		Version:     o.Version,
+	})
+}
+
+// imageDigest returns the manifest digest of the image being promoted which providers such as Clair need to find its
+// vulnerabilities. Unless it is specified the digest of the version tag is resolved from the docker registry of the team
+func (o *PromoteOptions) imageDigest(image string) string {
+	if o.ImageDigest != "" {
+		return o.ImageDigest
+	}
+	name, tag, digest := cve.ParseImage(image)
+	if digest != "" {
+		return digest
+	}
+	if tag == "" {
+		tag = o.Version
+	}
+	if tag == "" {
+		return ""
+	}
+	if registry, _ := cve.SplitImageRegistry(name); registry == "docker.io" && !strings.HasPrefix(name, "docker.io/") {
+		dockerRegistry, err := o.loadDockerRegistry()
+		if err != nil {
+			log.Warnf("Could not find the docker registry of the team so looking up image %s on Docker Hub: %s\n", name, err)
+		} else {
+			name = dockerRegistry + "/" + name
+		}
+	}
+	digest, err := cve.ResolveImageDigest(nil, name, tag)
+	if err != nil {
+		log.Warnf("Could not resolve the digest of image %s:%s: %s\n", name, tag, err)
+		return ""
+	}
+	return digest
+}
+
+// imageName returns the name of the docker image being promoted which defaults to the organisation of the pipeline and the application name
+func (o *PromoteOptions) imageName() string {
+	if o.Image != "" {
+		return o.Image
+	}
+	org := ""
+	if o.GitInfo != nil {
+		org = o.GitInfo.Organisation
+	}
+	if org == "" {
+		paths := strings.Split(o.Pipeline, "/")
+		if len(paths) > 1 {
+			org = paths[0]
+		}
+	}
+	if org == "" {
+		return o.Application
+	}
+	return org + "/" + o.Application
+}
+
+// isBlockedPromotion returns true if the CVE policy of the Environment is blocking the promotion
+func isBlockedPromotion(ps *v1.PromoteActivityStep) bool {
+	return ps != nil && ps.PolicyViolation != ""
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/issues"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTransitionIssueProvider struct {
+	issues.IssueProvider
+
+	statuses    map[string]string
+	fixVersions map[string][]string
+}
+
+func (p *fakeTransitionIssueProvider) TransitionIssue(key string, status string) error {
+	if key == "JX-404" {
+		return fmt.Errorf("issue %s not found", key)
+	}
+	p.statuses[key] = status
+	return nil
+}
+
+func (p *fakeTransitionIssueProvider) SetIssueFixVersion(key string, version string) error {
+	p.fixVersions[key] = append(p.fixVersions[key], version)
+	return nil
+}
+
+func TestPromoteTransitionIssues(t *testing.T) {
+	t.Parallel()
+	release := &v1.Release{
+		Spec: v1.ReleaseSpec{
+			Version: "1.0.3",
+			Issues: []v1.IssueSummary{
+				{ID: "JX-1"},
+				{ID: "JX-404"},
+				{ID: "JX-2"},
+				{},
+			},
+		},
+	}
+	tracker := &fakeTransitionIssueProvider{
+		statuses:    map[string]string{},
+		fixVersions: map[string][]string{},
+	}
+	o := &PromoteOptions{}
+
+	o.transitionIssues(tracker, &config.IssueTransitionConfig{Environment: "staging", Status: "In Staging"}, release)
+	assert.Equal(t, map[string]string{"JX-1": "In Staging", "JX-2": "In Staging"}, tracker.statuses)
+	assert.Empty(t, tracker.fixVersions)
+
+	o.transitionIssues(tracker, &config.IssueTransitionConfig{Environment: "production", Status: "Released", FixVersion: true}, release)
+	assert.Equal(t, "Released", tracker.statuses["JX-2"])
+	assert.Equal(t, map[string][]string{"JX-1": {"1.0.3"}, "JX-404": {"1.0.3"}, "JX-2": {"1.0.3"}}, tracker.fixVersions)
+}
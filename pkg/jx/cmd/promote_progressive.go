@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/dynamic"
+)
+
+// PromoteProgressively rolls out the version of the chart into the Environment using its progressive delivery strategy
+func (o *PromoteOptions) PromoteProgressively(targetNS string, env *v1.Environment, fullAppName string, releaseName string, version string) error {
+	spec := env.Spec.ProgressiveDelivery
+	interval := time.Duration(0)
+	if spec.StepInterval != "" {
+		var err error
+		interval, err = time.ParseDuration(spec.StepInterval)
+		if err != nil {
+			return errors.Wrapf(err, "invalid stepInterval %s on Environment %s", spec.StepInterval, env.Name)
+		}
+	}
+	service := spec.Service
+	if service == "" {
+		service = o.Application
+	}
+	prometheusURL, err := o.prometheusURL(spec.Analysis)
+	if err != nil {
+		return err
+	}
+	analyse := func() error {
+		if interval > 0 {
+			log.Infof("Waiting %s before analysing the metrics\n", interval.String())
+			time.Sleep(interval)
+		}
+		return kube.AnalysePromotion(spec.Analysis, prometheusURL)
+	}
+
+	switch spec.Strategy {
+	case v1.ProgressiveDeliveryStrategyTypeCanary:
+		return o.promoteCanary(targetNS, spec, service, fullAppName, releaseName, version, analyse)
+	case v1.ProgressiveDeliveryStrategyTypeBlueGreen:
+		return o.promoteBlueGreen(targetNS, service, fullAppName, releaseName, version, analyse)
+	default:
+		return fmt.Errorf("unknown progressive delivery strategy %s on Environment %s. Supported values: %s", string(spec.Strategy), env.Name, strings.Join(v1.ProgressiveDeliveryStrategyTypeValues, ", "))
+	}
+}
+
+// promoteCanary deploys the version as a canary release with its own Service then shifts traffic to it one step
+// at a time using the Istio VirtualService, analysing the metrics before each step. Once all the steps pass the
+// main release is upgraded and the canary removed
+func (o *PromoteOptions) promoteCanary(targetNS string, spec *v1.ProgressiveDeliverySpec, service string, fullAppName string, releaseName string, version string, analyse func() error) error {
+	client, err := o.dynamicClient()
+	if err != nil {
+		return err
+	}
+	kubeClient, _, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	canaryRelease := releaseName + "-" + kube.CanarySubset
+	err = o.Helm().UpgradeChart(fullAppName, canaryRelease, targetNS, &version, true, nil, false, true, serviceNameValues(canaryRelease), nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to install canary release %s", canaryRelease)
+	}
+
+	rollback := func(err error) error {
+		log.Warnf("Rolling back canary release %s of version %s: %s\n", canaryRelease, version, err)
+		err2 := kube.SetCanaryWeight(client, targetNS, service, 0)
+		if err2 != nil {
+			log.Warnf("Failed to move the traffic of %s back to the stable release: %s\n", service, err2)
+		}
+		err2 = o.Helm().DeleteRelease(targetNS, canaryRelease, true)
+		if err2 != nil {
+			log.Warnf("Failed to delete canary release %s: %s\n", canaryRelease, err2)
+		}
+		return errors.Wrapf(err, "canary of version %s in namespace %s failed", version, targetNS)
+	}
+
+	canaryService, err := kube.FindReleaseService(kubeClient, targetNS, canaryRelease)
+	if err != nil {
+		return rollback(err)
+	}
+	err = kube.EnsureCanaryRouting(client, targetNS, service, releaseName, canaryService.Name, canaryRelease)
+	if err != nil {
+		return rollback(err)
+	}
+
+	steps := spec.CanarySteps
+	if len(steps) == 0 {
+		steps = kube.DefaultCanarySteps
+	}
+	for i, weight := range steps {
+		if i > 0 {
+			err = analyse()
+			if err != nil {
+				return rollback(err)
+			}
+		}
+		log.Infof("Sending %s of the traffic of %s to version %s\n", util.ColorInfo(fmt.Sprintf("%d%%", weight)), util.ColorInfo(service), util.ColorInfo(version))
+		err = kube.SetCanaryWeight(client, targetNS, service, weight)
+		if err != nil {
+			return rollback(err)
+		}
+	}
+	err = analyse()
+	if err != nil {
+		return rollback(err)
+	}
+
+	log.Infof("Canary of version %s succeeded so upgrading release %s\n", util.ColorInfo(version), util.ColorInfo(releaseName))
+	err = o.Helm().UpgradeChart(fullAppName, releaseName, targetNS, &version, true, nil, false, true, nil, nil)
+	if err != nil {
+		return rollback(err)
+	}
+	err = kube.SetCanaryWeight(client, targetNS, service, 0)
+	if err != nil {
+		return err
+	}
+	return o.Helm().DeleteRelease(targetNS, canaryRelease, true)
+}
+
+// promoteBlueGreen deploys the version alongside the currently active release, analyses the metrics and then
+// switches the selector of the Service over to the new release before removing the workloads of the old one.
+// Each colour release has its own Service so the Service receiving the traffic is not owned by either of them
+func (o *PromoteOptions) promoteBlueGreen(targetNS string, service string, fullAppName string, releaseName string, version string, analyse func() error) error {
+	kubeClient, _, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	active, err := kube.GetActiveRelease(kubeClient, targetNS, service)
+	if err != nil {
+		return err
+	}
+	next := kube.BlueGreenReleaseName(releaseName, active)
+	log.Infof("Deploying version %s as release %s\n", util.ColorInfo(version), util.ColorInfo(next))
+	err = o.Helm().UpgradeChart(fullAppName, next, targetNS, &version, true, nil, false, true, serviceNameValues(next), nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to install release %s", next)
+	}
+	err = analyse()
+	if err != nil {
+		log.Warnf("Removing release %s of version %s: %s\n", next, version, err)
+		err2 := o.Helm().DeleteRelease(targetNS, next, true)
+		if err2 != nil {
+			log.Warnf("Failed to delete release %s: %s\n", next, err2)
+		}
+		return errors.Wrapf(err, "analysis of version %s in namespace %s failed", version, targetNS)
+	}
+
+	log.Infof("Switching Service %s to release %s\n", util.ColorInfo(service), util.ColorInfo(next))
+	err = kube.SwitchServiceRelease(kubeClient, targetNS, service, next)
+	if err != nil {
+		return err
+	}
+	// remove the workloads of the release which served the traffic before along with the other colour. The releases
+	// are kept so that the release which created the Service before blue/green was enabled does not remove it
+	previous := []string{}
+	if active != "" && active != next {
+		previous = append(previous, active)
+	}
+	otherColour := kube.BlueGreenReleaseName(releaseName, next)
+	if otherColour != active {
+		previous = append(previous, otherColour)
+	}
+	for _, release := range previous {
+		log.Infof("Removing the workloads of the previous release %s\n", util.ColorInfo(release))
+		err = kube.DeleteReleaseWorkloads(kubeClient, targetNS, release)
+		if err != nil {
+			log.Warnf("Failed to delete the workloads of the previous release %s: %s\n", release, err)
+		}
+	}
+	return nil
+}
+
+// serviceNameValues returns the helm values which name the Service of the chart after the release so that it
+// can be installed alongside the release which owns the Service receiving the traffic
+func serviceNameValues(release string) []string {
+	return []string{kube.ServiceNameValue + "=" + release}
+}
+
+// prometheusURL returns the URL of the Prometheus server used to analyse promotions
+func (o *PromoteOptions) prometheusURL(analysis *v1.PromotionAnalysis) (string, error) {
+	if analysis == nil || analysis.Query == "" {
+		return "", nil
+	}
+	if analysis.PrometheusURL != "" {
+		return analysis.PrometheusURL, nil
+	}
+	kubeClient, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return "", err
+	}
+	if o.Factory != nil && o.Factory.IsInCluster() {
+		return fmt.Sprintf("http://%s.%s", kube.DefaultPrometheusService, devNs), nil
+	}
+	answer, err := kube.FindServiceURL(kubeClient, devNs, kube.DefaultPrometheusService)
+	if err != nil || answer == "" {
+		return "", fmt.Errorf("could not find the URL of Prometheus service %s in namespace %s. Try 'jx create addon prometheus' or specify the prometheusURL of the analysis", kube.DefaultPrometheusService, devNs)
+	}
+	return answer, nil
+}
+
+// dynamicClient lazily creates the client used to update the Istio resources
+func (o *PromoteOptions) dynamicClient() (dynamic.Interface, error) {
+	if o.DynamicClient == nil {
+		config, err := o.Factory.CreateKubeConfig()
+		if err != nil {
+			return nil, err
+		}
+		o.DynamicClient, err = dynamic.NewForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return o.DynamicClient, nil
+}
@@ -0,0 +1,188 @@
+package cmd_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm/mocks"
+	"github.com/jenkins-x/jx/pkg/jx/cmd"
+	"github.com/jenkins-x/jx/pkg/kube"
+	. "github.com/petergtz/pegomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestPromoteBlueGreen(t *testing.T) {
+	server := createPrometheusServer(t, "0.01")
+	defer server.Close()
+
+	o, helmer, env := createBlueGreenPromoteOptions(t, server.URL)
+
+	err := o.PromoteProgressively("jx-production", env, "myrepo/myapp", "jx-production-myapp", "1.2.3")
+	require.NoError(t, err)
+
+	version := "1.2.3"
+	helmer.VerifyWasCalledOnce().UpgradeChart("myrepo/myapp", "jx-production-myapp-green", "jx-production", &version, true, nil, false, true,
+		[]string{"service.name=jx-production-myapp-green"}, nil)
+	assertServiceRelease(t, o, "jx-production-myapp-green")
+	assertDeployments(t, o, "jx-production-myapp-green")
+	helmer.VerifyWasCalled(Never()).DeleteRelease(AnyString(), AnyString(), AnyBool())
+}
+
+func TestPromoteBlueGreenCreatesService(t *testing.T) {
+	server := createPrometheusServer(t, "0.01")
+	defer server.Close()
+
+	o, _, env := createBlueGreenPromoteOptions(t, server.URL)
+	kubeClient, _, err := o.KubeClient()
+	require.NoError(t, err)
+	err = kubeClient.CoreV1().Services("jx-production").Delete("myapp", &metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	err = o.PromoteProgressively("jx-production", env, "myrepo/myapp", "jx-production-myapp", "1.2.3")
+	require.NoError(t, err)
+
+	assertServiceRelease(t, o, "jx-production-myapp-blue")
+	svc, err := kubeClient.CoreV1().Services("jx-production").Get("myapp", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "jx-production-myapp-blue-myapp", svc.Spec.Selector["app"])
+	require.Len(t, svc.Spec.Ports, 1)
+	assert.Equal(t, int32(80), svc.Spec.Ports[0].Port)
+}
+
+func TestPromoteBlueGreenFailedAnalysis(t *testing.T) {
+	server := createPrometheusServer(t, "0.2")
+	defer server.Close()
+
+	o, helmer, env := createBlueGreenPromoteOptions(t, server.URL)
+
+	err := o.PromoteProgressively("jx-production", env, "myrepo/myapp", "jx-production-myapp", "1.2.3")
+	require.Error(t, err)
+
+	assertServiceRelease(t, o, "jx-production-myapp-blue")
+	helmer.VerifyWasCalledOnce().DeleteRelease("jx-production", "jx-production-myapp-green", true)
+	helmer.VerifyWasCalled(Never()).DeleteRelease("jx-production", "jx-production-myapp-blue", true)
+	assertDeployments(t, o, "jx-production-myapp-blue", "jx-production-myapp-green")
+}
+
+func TestPromoteBlueGreenRemovesOtherColour(t *testing.T) {
+	server := createPrometheusServer(t, "0.01")
+	defer server.Close()
+
+	o, helmer, env := createBlueGreenPromoteOptions(t, server.URL)
+	kubeClient, _, err := o.KubeClient()
+	require.NoError(t, err)
+	svc, err := kubeClient.CoreV1().Services("jx-production").Get("myapp", metav1.GetOptions{})
+	require.NoError(t, err)
+	svc.Spec.Selector = map[string]string{"app": "myapp"}
+	_, err = kubeClient.CoreV1().Services("jx-production").Update(svc)
+	require.NoError(t, err)
+
+	err = o.PromoteProgressively("jx-production", env, "myrepo/myapp", "jx-production-myapp", "1.2.3")
+	require.NoError(t, err)
+
+	assertServiceRelease(t, o, "jx-production-myapp-blue")
+	assertDeployments(t, o, "jx-production-myapp-blue")
+	helmer.VerifyWasCalled(Never()).DeleteRelease(AnyString(), AnyString(), AnyBool())
+}
+
+func TestPromoteProgressivelyRejectsGitOpsEnvironment(t *testing.T) {
+	o, _, env := createBlueGreenPromoteOptions(t, "http://prometheus")
+	env.Spec.Source.URL = "https://github.com/myorg/environment-production.git"
+	o.BatchMode = true
+	o.Version = "1.2.3"
+
+	_, err := o.Promote("jx-production", env, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "progressive delivery is not supported")
+}
+
+func createBlueGreenPromoteOptions(t *testing.T, prometheusURL string) (*cmd.PromoteOptions, *helm_test.MockHelmer, *v1.Environment) {
+	RegisterMockTestingT(t)
+	env := kube.NewPermanentEnvironment("production")
+	env.Spec.ProgressiveDelivery = &v1.ProgressiveDeliverySpec{
+		Strategy: v1.ProgressiveDeliveryStrategyTypeBlueGreen,
+		Analysis: &v1.PromotionAnalysis{
+			PrometheusURL: prometheusURL,
+			Query:         "myapp_error_rate",
+			MaxValue:      "0.05",
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myapp",
+			Namespace: "jx-production",
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				kube.LabelRelease: "jx-production-myapp-blue",
+			},
+		},
+	}
+
+	resources := []runtime.Object{svc}
+	for _, colour := range []string{"blue", "green"} {
+		release := "jx-production-myapp-" + colour
+		labels := map[string]string{kube.LabelRelease: release}
+		resources = append(resources, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      release,
+				Namespace: "jx-production",
+				Labels:    labels,
+			},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": release + "-myapp"},
+				Ports:    []corev1.ServicePort{{Port: 80}},
+			},
+		}, &appsv1beta1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      release + "-myapp",
+				Namespace: "jx-production",
+				Labels:    labels,
+			},
+		})
+	}
+
+	helmer := helm_test.NewMockHelmer()
+	o := &cmd.PromoteOptions{
+		Application: "myapp",
+	}
+	cmd.ConfigureTestOptionsWithResources(&o.CommonOptions, resources, []runtime.Object{env}, &gits.GitFake{}, helmer)
+	return o, helmer, env
+}
+
+// assertDeployments asserts the releases which still have Deployments
+func assertDeployments(t *testing.T, o *cmd.PromoteOptions, releases ...string) {
+	kubeClient, _, err := o.KubeClient()
+	require.NoError(t, err)
+	list, err := kubeClient.AppsV1beta1().Deployments("jx-production").List(metav1.ListOptions{})
+	require.NoError(t, err)
+	actual := []string{}
+	for _, d := range list.Items {
+		actual = append(actual, d.Labels[kube.LabelRelease])
+	}
+	assert.ElementsMatch(t, releases, actual)
+}
+
+func createPrometheusServer(t *testing.T, value string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "myapp_error_rate", r.URL.Query().Get("query"))
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1540000000.0,"%s"]}]}}`, value)
+	}))
+}
+
+func assertServiceRelease(t *testing.T, o *cmd.PromoteOptions, expected string) {
+	kubeClient, _, err := o.KubeClient()
+	require.NoError(t, err)
+	svc, err := kubeClient.CoreV1().Services("jx-production").Get("myapp", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, expected, svc.Spec.Selector[kube.LabelRelease])
+}
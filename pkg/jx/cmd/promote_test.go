@@ -0,0 +1,180 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/cve"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm/mocks"
+	"github.com/jenkins-x/jx/pkg/jx/cmd"
+	"github.com/jenkins-x/jx/pkg/kube"
+	. "github.com/petergtz/pegomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestPromoteQueuedOutsideDeploymentWindow(t *testing.T) {
+	env := kube.NewPermanentEnvironment("production")
+	env.Spec.Source.URL = "https://github.com/myorg/environment-production.git"
+	env.Spec.DeploymentWindows = []v1.DeploymentWindow{
+		{
+			Name:     "new-year",
+			Schedule: "0 0 1 1 *",
+			Duration: "1s",
+		},
+	}
+
+	o := &cmd.PromoteOptions{
+		Application:      "myapp",
+		Pipeline:         "myorg/myapp/master",
+		Build:            "1",
+		Version:          "1.2.3",
+		IgnoreLocalFiles: true,
+		FakePullRequests: func(env *v1.Environment, modifyRequirementsFn cmd.ModifyRequirementsFn, branchNameText string, title string, message string, pullRequestInfo *cmd.ReleasePullRequestInfo) (*cmd.ReleasePullRequestInfo, error) {
+			assert.Fail(t, "no Pull Request should be created outside of the deployment window")
+			return pullRequestInfo, nil
+		},
+	}
+	configurePromoteTestOptions(t, o, env)
+
+	releaseInfo, err := o.Promote(env.Spec.Namespace, env, false)
+	require.NoError(t, err)
+	require.NotNil(t, releaseInfo)
+	assert.Nil(t, releaseInfo.PullRequestInfo)
+
+	_, promote := getPromoteStep(t, o, "myorg-myapp-master-1")
+	assert.Equal(t, "production", promote.Environment)
+	assert.Equal(t, v1.ActivityStatusTypePending, promote.Status)
+	assert.Contains(t, promote.Description, "deployment window")
+	assert.Nil(t, promote.PullRequest)
+}
+
+const testImageDigest = "sha256:b9f03c3c4b196d46639bee0ec9cd0f6dbea8cc39d32767c8312f04317c3b18f4"
+
+// fakeCVEProvider returns the same vulnerabilities for every query
+type fakeCVEProvider struct {
+	images  []cve.ImageVulnerabilities
+	queries []cve.CVEQuery
+}
+
+func (p *fakeCVEProvider) GetImageVulnerabilities(query cve.CVEQuery) ([]cve.ImageVulnerabilities, error) {
+	p.queries = append(p.queries, query)
+	return p.images, nil
+}
+
+func TestPromoteBlockedByCVEPolicy(t *testing.T) {
+	o, env, provider := createCVEPolicyPromoteOptions(t, v1.CVEPolicyActionTypeBlock)
+
+	_, err := o.Promote(env.Spec.Namespace, env, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CVE-2018-0001")
+
+	require.Len(t, provider.queries, 1)
+	assert.Equal(t, "myorg/myapp", provider.queries[0].ImageName)
+	assert.Equal(t, "1.2.3", provider.queries[0].Version)
+	assert.Equal(t, testImageDigest, provider.queries[0].ImageDigest)
+
+	activity, promote := getPromoteStep(t, o, "myorg-myapp-master-1")
+	assert.Equal(t, v1.ActivityStatusTypePending, promote.Status, "a blocked promotion should be checked again")
+	assert.Contains(t, promote.PolicyViolation, "CVE-2018-0001")
+	assert.Equal(t, int32(1), promote.PolicyChecks)
+	assert.Nil(t, promote.PullRequest)
+	require.Len(t, activity.Spec.Facts, 1)
+	fact := activity.Spec.Facts[0]
+	assert.Equal(t, v1.FactTypeCVE, fact.FactType)
+	assert.Equal(t, "cve-policy-production", fact.Name)
+}
+
+func TestPromoteBlockedByCVEPolicyFailsAfterMaxChecks(t *testing.T) {
+	o, env, _ := createCVEPolicyPromoteOptions(t, v1.CVEPolicyActionTypeBlock)
+	env.Spec.CVEPolicy.MaxChecks = 2
+
+	_, err := o.Promote(env.Spec.Namespace, env, false)
+	require.Error(t, err)
+	_, promote := getPromoteStep(t, o, "myorg-myapp-master-1")
+	assert.Equal(t, v1.ActivityStatusTypePending, promote.Status)
+
+	_, err = o.Promote(env.Spec.Namespace, env, false)
+	require.Error(t, err)
+	_, promote = getPromoteStep(t, o, "myorg-myapp-master-1")
+	assert.Equal(t, v1.ActivityStatusTypeFailed, promote.Status, "the promotion should fail once the policy was checked MaxChecks times")
+	assert.Equal(t, int32(2), promote.PolicyChecks)
+	assert.Contains(t, promote.Description, "Gave up")
+}
+
+func TestPromoteWarnsOnCVEPolicy(t *testing.T) {
+	o, env, _ := createCVEPolicyPromoteOptions(t, v1.CVEPolicyActionTypeWarn)
+	pullRequests := 0
+	o.FakePullRequests = func(env *v1.Environment, modifyRequirementsFn cmd.ModifyRequirementsFn, branchNameText string, title string, message string, pullRequestInfo *cmd.ReleasePullRequestInfo) (*cmd.ReleasePullRequestInfo, error) {
+		pullRequests++
+		return &cmd.ReleasePullRequestInfo{
+			PullRequest: &gits.GitPullRequest{
+				URL: "https://github.com/myorg/environment-production/pull/1",
+			},
+		}, nil
+	}
+
+	_, err := o.Promote(env.Spec.Namespace, env, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pullRequests)
+
+	activity, promote := getPromoteStep(t, o, "myorg-myapp-master-1")
+	assert.NotEqual(t, v1.ActivityStatusTypeFailed, promote.Status)
+	require.Len(t, activity.Spec.Facts, 1)
+	assert.True(t, activity.Spec.Facts[0].Statements[0].MeasurementValue)
+}
+
+func createCVEPolicyPromoteOptions(t *testing.T, action v1.CVEPolicyActionType) (*cmd.PromoteOptions, *v1.Environment, *fakeCVEProvider) {
+	env := kube.NewPermanentEnvironment("production")
+	env.Spec.Source.URL = "https://github.com/myorg/environment-production.git"
+	env.Spec.CVEPolicy = &v1.CVEPolicy{
+		MaxSeverity: "Medium",
+		Action:      action,
+	}
+	provider := &fakeCVEProvider{
+		images: []cve.ImageVulnerabilities{
+			{
+				Image: "docker.io/myorg/myapp:1.2.3",
+				Vulnerabilities: []cve.Vulnerability{
+					{Vuln: "CVE-2018-0001", Severity: "High", Package: "openssl"},
+					{Vuln: "CVE-2018-0002", Severity: "Low", Package: "curl"},
+				},
+			},
+		},
+	}
+	o := &cmd.PromoteOptions{
+		Application:      "myapp",
+		Pipeline:         "myorg/myapp/master",
+		Build:            "1",
+		Version:          "1.2.3",
+		IgnoreLocalFiles: true,
+		ImageDigest:      testImageDigest,
+		CVEProvider:      provider,
+	}
+	configurePromoteTestOptions(t, o, env)
+	return o, env, provider
+}
+
+func configurePromoteTestOptions(t *testing.T, o *cmd.PromoteOptions, env *v1.Environment) {
+	RegisterMockTestingT(t)
+	cmd.ConfigureTestOptionsWithResources(&o.CommonOptions, nil, []runtime.Object{env}, &gits.GitFake{}, helm_test.NewMockHelmer())
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	require.NoError(t, err)
+	o.Activities = jxClient.JenkinsV1().PipelineActivities(ns)
+}
+
+func getPromoteStep(t *testing.T, o *cmd.PromoteOptions, name string) (*v1.PipelineActivity, *v1.PromoteActivityStep) {
+	activity, err := o.Activities.Get(name, metav1.GetOptions{})
+	require.NoError(t, err)
+	var promote *v1.PromoteActivityStep
+	for _, step := range activity.Spec.Steps {
+		if step.Promote != nil {
+			promote = step.Promote
+		}
+	}
+	require.NotNil(t, promote, "no promote step found on PipelineActivity %s", activity.Name)
+	return activity, promote
+}
@@ -43,6 +43,7 @@ func NewCmdStep(f Factory, in terminal.FileReader, out terminal.FileWriter, errO
 
 	cmd.AddCommand(NewCmdStepBlog(f, in, out, errOut))
 	cmd.AddCommand(NewCmdStepChangelog(f, in, out, errOut))
+	cmd.AddCommand(NewCmdStepChangedApps(f, in, out, errOut))
 	cmd.AddCommand(NewCmdCreateBuild(f, in, out, errOut))
 	cmd.AddCommand(NewCmdStepGit(f, in, out, errOut))
 	cmd.AddCommand(NewCmdStepGpgCredentials(f, in, out, errOut))
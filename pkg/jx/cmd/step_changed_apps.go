@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/AlecAivazis/survey.v1/terminal"
+)
+
+var (
+	stepChangedAppsLong = templates.LongDesc(`
+		Lists the apps of a monorepo which have changed between two git revisions so that only those apps are built and versioned.
+
+		The apps are defined in the 'apps' section of the jenkins-x.yml file at the root of the repository. An app has changed if any
+		changed file is inside its directory or matches any of its additional paths. Each app is compared with its own latest version
+		tag, such as 'myapp/v1.2.3' created by 'jx step tag', unless a previous revision is specified.
+`)
+
+	stepChangedAppsExample = templates.Examples(`
+		# list the apps changed since their last release tags
+		jx step changed-apps
+
+		# list the apps changed by a pull request
+		jx step changed-apps --previous-rev origin/master
+
+		# output the changed apps as JSON
+		jx step changed-apps -o json
+`)
+)
+
+// StepChangedAppsOptions contains the command line flags
+type StepChangedAppsOptions struct {
+	StepOptions
+
+	Dir              string
+	PreviousRevision string
+	CurrentRevision  string
+}
+
+// NewCmdStepChangedApps Creates a new Command object
+func NewCmdStepChangedApps(f Factory, in terminal.FileReader, out terminal.FileWriter, errOut io.Writer) *cobra.Command {
+	options := &StepChangedAppsOptions{
+		StepOptions: StepOptions{
+			CommonOptions: CommonOptions{
+				Factory: f,
+				In:      in,
+				Out:     out,
+				Err:     errOut,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "changed-apps",
+		Short:   "Lists the apps of a monorepo which have changed between two git revisions",
+		Long:    stepChangedAppsLong,
+		Example: stepChangedAppsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory in the git repository. Defaults to the current directory")
+	cmd.Flags().StringVarP(&options.PreviousRevision, "previous-rev", "p", "", "The previous git revision. Defaults to $GIT_PREVIOUS_SUCCESSFUL_COMMIT or the latest version tag of each app")
+	cmd.Flags().StringVarP(&options.CurrentRevision, "rev", "r", "HEAD", "The current git revision")
+	options.addOutputFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *StepChangedAppsOptions) Run() error {
+	apps, err := o.changedApps()
+	if err != nil {
+		return err
+	}
+	if o.Output != "" {
+		return o.renderItems(apps, o.Output)
+	}
+	for _, app := range apps {
+		fmt.Fprintln(o.Out, app.Name)
+	}
+	return nil
+}
+
+func (o *StepChangedAppsOptions) changedApps() ([]config.AppConfig, error) {
+	dir := o.Dir
+	if dir == "" {
+		var err error
+		dir, err = os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+	}
+	gitDir, _, err := o.Git().FindGitConfigDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the git repository of %s", dir)
+	}
+	if gitDir == "" {
+		return nil, fmt.Errorf("no git repository found for %s", dir)
+	}
+	projectConfig, fileName, err := config.LoadProjectConfig(gitDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s", fileName)
+	}
+	if len(projectConfig.Apps) == 0 {
+		log.Warnf("No apps defined in %s\n", fileName)
+		return []config.AppConfig{}, nil
+	}
+
+	previous := o.PreviousRevision
+	if previous == "" {
+		previous = os.Getenv("GIT_PREVIOUS_SUCCESSFUL_COMMIT")
+	}
+	if previous != "" {
+		files, err := o.changedFiles(gitDir, previous)
+		if err != nil {
+			return nil, err
+		}
+		return projectConfig.ChangedApps(files), nil
+	}
+
+	// each app is versioned independently so compare each app with its own latest version tag
+	tags, err := o.Git().Tags(gitDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the git tags of %s", gitDir)
+	}
+	answer := []config.AppConfig{}
+	for _, app := range projectConfig.Apps {
+		_, tag := latestVersionTag(tags, app.Name, true, false)
+		if tag == "" {
+			if o.Verbose {
+				log.Infof("No version tag found for app %s so it has changed\n", app.Name)
+			}
+			answer = append(answer, app)
+			continue
+		}
+		files, err := o.changedFiles(gitDir, tag)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range files {
+			if app.Contains(path) {
+				answer = append(answer, app)
+				break
+			}
+		}
+	}
+	return answer, nil
+}
+
+// changedFiles returns the files changed between the previous revision and the current revision
+func (o *StepChangedAppsOptions) changedFiles(gitDir string, previous string) ([]string, error) {
+	files, err := o.Git().GetChangedFiles(gitDir, previous, o.CurrentRevision)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the files changed between %s and %s", previous, o.CurrentRevision)
+	}
+	if o.Verbose {
+		log.Infof("Found %d files changed between %s and %s\n", len(files), util.ColorInfo(previous), util.ColorInfo(o.CurrentRevision))
+	}
+	return files, nil
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/config"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepChangedApps(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-step-changed-apps")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	projectConfig := &config.ProjectConfig{
+		Apps: []config.AppConfig{
+			{Name: "frontend", Dir: "frontend"},
+			{Name: "backend", Dir: "backend", Paths: []string{"libs/*.go"}},
+			{Name: "docs", Dir: "docs"},
+		},
+	}
+	require.NoError(t, projectConfig.SaveConfig(filepath.Join(dir, config.ProjectConfigFileName)))
+
+	o := &StepChangedAppsOptions{
+		Dir:              dir,
+		PreviousRevision: "v1.0.0",
+		CurrentRevision:  "HEAD",
+	}
+	o.GitClient = &gits.GitFake{
+		ChangedFiles: []string{"frontend/src/index.js", "libs/common.go", "README.md"},
+	}
+	apps, err := o.changedApps()
+	require.NoError(t, err)
+	assertAppNames(t, apps, "frontend", "backend")
+
+	o.GitClient = &gits.GitFake{
+		ChangedFiles: []string{"README.md"},
+	}
+	apps, err = o.changedApps()
+	require.NoError(t, err)
+	assertAppNames(t, apps)
+
+	// without a previous revision or git tag all the apps have changed
+	o.PreviousRevision = ""
+	apps, err = o.changedApps()
+	require.NoError(t, err)
+	assertAppNames(t, apps, "frontend", "backend", "docs")
+
+	// apps are compared with their own version tags so only the app without a tag has changed
+	o.GitClient = &gits.GitFake{
+		ChangedFiles: []string{"README.md"},
+		GitTags: []gits.GitTag{
+			{Name: "v2.0.0"},
+			{Name: "frontend/v1.0.0"},
+			{Name: "backend/v1.2.0"},
+		},
+	}
+	apps, err = o.changedApps()
+	require.NoError(t, err)
+	assertAppNames(t, apps, "docs")
+}
+
+func TestLatestVersionTag(t *testing.T) {
+	t.Parallel()
+	tags := []string{"v1.0.0", "v1.1.0", "v1.2.0-rc.1", "frontend/v2.0.0", "frontend/v2.1.0", "backend/v0.1.0"}
+
+	latest, tag := latestVersionTag(tags, "", true, false)
+	assert.Equal(t, "1.1.0", latest)
+	assert.Equal(t, "v1.1.0", tag)
+
+	latest, tag = latestVersionTag(tags, "", true, true)
+	assert.Equal(t, "1.2.0-rc.1", latest)
+	assert.Equal(t, "v1.2.0-rc.1", tag)
+
+	latest, tag = latestVersionTag(tags, "", false, false)
+	assert.Equal(t, "1.1.0", latest)
+	assert.Equal(t, "v1.1.0", tag)
+
+	latest, tag = latestVersionTag(tags, "frontend", true, false)
+	assert.Equal(t, "2.1.0", latest)
+	assert.Equal(t, "frontend/v2.1.0", tag)
+
+	latest, tag = latestVersionTag(tags, "docs", true, false)
+	assert.Equal(t, "", latest)
+	assert.Equal(t, "", tag)
+
+	assert.Equal(t, "v1.2.3", versionTagName("", "1.2.3"))
+	assert.Equal(t, "frontend/v1.2.3", versionTagName("frontend", "1.2.3"))
+}
+
+func TestFindMonorepoAppDirs(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-monorepo-app-dirs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"frontend/package.json":         "{}",
+		"backend/pom.xml":               "<project/>",
+		"charts/cheese/Chart.yaml":      "name: cheese",
+		"node_modules/foo/package.json": "{}",
+		".hidden/Dockerfile":            "FROM scratch",
+		"docs/README.md":                "# docs",
+	}
+	for name, text := range files {
+		fileName := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(fileName), DefaultWritePermissions))
+		require.NoError(t, ioutil.WriteFile(fileName, []byte(text), DefaultWritePermissions))
+	}
+
+	appDirs, err := findMonorepoAppDirs(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend", "frontend"}, appDirs)
+}
+
+func TestCreateMonorepoJenkinsfile(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "test-monorepo-jenkinsfile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	o := &ImportOptions{Dir: dir}
+	apps := []config.AppConfig{
+		{Name: "frontend", Dir: "frontend"},
+		{Name: "backend", Dir: "services/backend"},
+	}
+	name, err := o.createMonorepoJenkinsfile(apps)
+	require.NoError(t, err)
+	assert.Equal(t, "Jenkinsfile", name)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+	text := string(data)
+	assert.Contains(t, text, "jx step changed-apps")
+	assert.Contains(t, text, `withEnv(["SUB_PROJECT=${app}"])`)
+	assert.Contains(t, text, "'backend': 'services/backend',")
+
+	// a Jenkinsfile which was not generated is left alone
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte("pipeline {}"), DefaultWritePermissions))
+	name, err = o.createMonorepoJenkinsfile(apps)
+	require.NoError(t, err)
+	assert.Equal(t, "", name)
+	data, err = ioutil.ReadFile(filepath.Join(dir, "Jenkinsfile"))
+	require.NoError(t, err)
+	assert.Equal(t, "pipeline {}", string(data))
+}
+
+func assertAppNames(t *testing.T, apps []config.AppConfig, expected ...string) {
+	names := []string{}
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+	if expected == nil {
+		expected = []string{}
+	}
+	assert.Equal(t, expected, names)
+}
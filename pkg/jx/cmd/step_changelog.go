@@ -3,6 +3,7 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -47,7 +48,13 @@ type StepChangelogOptions struct {
 	HeaderFile          string
 	Footer              string
 	FooterFile          string
+	Template            string
+	TemplateFile        string
+	GroupBy             string
 	OutputMarkdownFile  string
+	OutputChangelogFile string
+	OutputJSONFile      string
+	OutputHTMLFile      string
 	OverwriteCRD        bool
 	GenerateCRD         bool
 	GenerateReleaseYaml bool
@@ -109,6 +116,8 @@ e.g. define environment variables GIT_USERNAME and GIT_API_TOKEN
 
 		If you have just created a git tag this command will try default to the changes between the last tag and the previous one. You can always specify the exact Git references (tag/sha) directly via '--previous-rev' and '--rev'
 
+		The whole changelog body can be rendered from your own Go template via '--template' or '--template-file'. The template is executed with a Changelog object which has all the fields of the ReleaseSpec (e.g. .Version, .Commits, .Issues and .PullRequests) along with .Changes containing the parsed Conventional Commits and .Groups containing the commits grouped by kind, issue label or author (see '--group-by')
+
 		The changelog is generated by parsing the git commits. It will also detect any text like 'fixes #123' to link to issue fixes. You can also use Conventional Commits notation: https://conventionalcommits.org/ to get a nicer formatted changelog. e.g. using commits like 'fix:(my feature) this my fix' or 'feat:(cheese) something'
 
 		This command also generates a Release Custom Resource Definition you can include in your helm chart to give metadata about the changelog of the application along with metadata about the release (git tag, url, commits, issues fixed etc). Including this metadata in a helm charts means we can do things like automatically comment on issues when they hit Staging or Production; or give detailed descriptions of what things have changed when using GitOps to update versions in an environment by referencing the fixed issues in the Pull Request.
@@ -129,10 +138,18 @@ e.g. define environment variables GIT_USERNAME and GIT_API_TOKEN
 		# specify the version and a header template
 		jx step changelog --header-file docs/dev/changelog-header.md --version 1.2.3
 
+		# render the changelog with your own template grouping the commits by the labels of their issues
+		jx step changelog --template-file docs/dev/changelog.tmpl --group-by label
+
+		# also prepend the release to a Keep a Changelog CHANGELOG.md and generate JSON and HTML release notes
+		jx step changelog --output-changelog CHANGELOG.md --output-json release-notes.json --output-html release-notes.html
+
 `)
 
 	GitHubIssueRegex = regexp.MustCompile(`(\#\d+)`)
 	JIRAIssueRegex   = regexp.MustCompile(`[A-Z][A-Z]+-(\d+)`)
+	// BugzillaIssueRegex matches bug references such as "Bug 1234" or "bz#1234"
+	BugzillaIssueRegex = regexp.MustCompile(`(?i)\b(?:bug|bz)\s*#?\s*(\d+)`)
 )
 
 func NewCmdStepChangelog(f Factory, in terminal.FileReader, out terminal.FileWriter, errOut io.Writer) *cobra.Command {
@@ -171,6 +188,9 @@ func NewCmdStepChangelog(f Factory, in terminal.FileReader, out terminal.FileWri
 	cmd.Flags().StringVarP(&options.Build, "build", "", "", "The Build number which is used to update the PipelineActivity. If not specified its defaulted from  the '$BUILD_NUMBER' environment variable")
 	cmd.Flags().StringVarP(&options.Dir, "dir", "", "", "The directory of the Git repository. Defaults to the current working directory")
 	cmd.Flags().StringVarP(&options.OutputMarkdownFile, "output-markdown", "", "", "The file to generate for the changelog output if not updating a Git provider release")
+	cmd.Flags().StringVarP(&options.OutputChangelogFile, "output-changelog", "", "", "The Keep a Changelog file such as CHANGELOG.md to add the release to. See https://keepachangelog.com/")
+	cmd.Flags().StringVarP(&options.OutputJSONFile, "output-json", "", "", "The file to generate the changelog as JSON")
+	cmd.Flags().StringVarP(&options.OutputHTMLFile, "output-html", "", "", "The file to generate the changelog as a HTML document")
 	cmd.Flags().BoolVarP(&options.OverwriteCRD, "overwrite", "o", false, "overwrites the Release CRD YAML file if it exists")
 	cmd.Flags().BoolVarP(&options.GenerateCRD, "crd", "c", false, "Generate the CRD in the chart")
 	cmd.Flags().BoolVarP(&options.GenerateReleaseYaml, "generate-yaml", "y", true, "Generate the Release YAML in the local helm chart")
@@ -181,6 +201,9 @@ func NewCmdStepChangelog(f Factory, in terminal.FileReader, out terminal.FileWri
 	cmd.Flags().StringVarP(&options.HeaderFile, "header-file", "", "", "The file name of the changelog header in markdown for the changelog. Can use go template expressions on the ReleaseSpec object: https://golang.org/pkg/text/template/")
 	cmd.Flags().StringVarP(&options.Footer, "footer", "", "", "The changelog footer in markdown for the changelog. Can use go template expressions on the ReleaseSpec object: https://golang.org/pkg/text/template/")
 	cmd.Flags().StringVarP(&options.FooterFile, "footer-file", "", "", "The file name of the changelog footer in markdown for the changelog. Can use go template expressions on the ReleaseSpec object: https://golang.org/pkg/text/template/")
+	cmd.Flags().StringVarP(&options.Template, "template", "", "", "The go template used to render the changelog body instead of the default markdown. It is executed with the Changelog object: https://golang.org/pkg/text/template/")
+	cmd.Flags().StringVarP(&options.TemplateFile, "template-file", "", "", "The file name of the go template used to render the changelog body instead of the default markdown. It is executed with the Changelog object: https://golang.org/pkg/text/template/")
+	cmd.Flags().StringVarP(&options.GroupBy, "group-by", "", gits.ChangelogGroupByKind, fmt.Sprintf("How to group the commits of the changelog. Possible values: %s", strings.Join(gits.ChangelogGroupBy, ", ")))
 
 	return cmd
 }
@@ -346,7 +369,11 @@ func (o *StepChangelogOptions) Run() error {
 	}
 
 	// lets try to update the release
-	markdown, err := gits.GenerateMarkdown(&release.Spec, gitInfo)
+	changelog, err := gits.NewChangelog(&release.Spec, gitInfo, o.GroupBy)
+	if err != nil {
+		return err
+	}
+	markdown, err := o.generateMarkdown(changelog, gitInfo)
 	if err != nil {
 		return err
 	}
@@ -359,6 +386,10 @@ func (o *StepChangelogOptions) Run() error {
 		return err
 	}
 	markdown = header + markdown + footer
+	err = o.writeChangelogOutputs(changelog, markdown)
+	if err != nil {
+		return err
+	}
 	if version != "" && o.UpdateRelease && foundGitProvider {
 		releaseInfo := &gits.GitRelease{
 			Name:    version,
@@ -451,7 +482,7 @@ func (o *StepChangelogOptions) Run() error {
 	build := o.Build
 	pipeline, build = o.getPipelineName(gitInfo, pipeline, build, appName)
 	if pipeline != "" && build != "" {
-		name := kube.ToValidName(pipeline + "-" + build)
+		name := o.pipelineActivityName(pipeline, build)
 		// lets see if we can update the pipeline
 		activities := jxClient.JenkinsV1().PipelineActivities(devNs)
 		lastCommitSha := ""
@@ -518,22 +549,32 @@ func (o *StepChangelogOptions) addCommit(spec *v1.ReleaseSpec, commit *object.Co
 func (o *StepChangelogOptions) addIssuesAndPullRequests(spec *v1.ReleaseSpec, commit *v1.CommitSummary, rawCommit *object.Commit) error {
 	tracker := o.State.Tracker
 
+	if tracker == nil {
+		return nil
+	}
+	issueKind := issues.GetIssueProvider(tracker)
 	gitProvider := o.State.GitProvider
-	if gitProvider == nil || !gitProvider.HasIssues() {
+	if issueKind == issues.Git && (gitProvider == nil || !gitProvider.HasIssues()) {
 		return nil
 	}
 	regex := GitHubIssueRegex
-	issueKind := issues.GetIssueProvider(tracker)
 	if !o.State.LoggedIssueKind {
 		o.State.LoggedIssueKind = true
 		log.Infof("Finding issues in commit messages using %s format\n", issueKind)
 	}
-	if issueKind == issues.Jira {
+	switch issueKind {
+	case issues.Jira:
 		regex = JIRAIssueRegex
+	case issues.Bugzilla:
+		regex = BugzillaIssueRegex
 	}
 	message := fullCommitMessageText(rawCommit)
 	matches := regex.FindAllStringSubmatch(message, -1)
 	for _, match := range matches {
+		if issueKind == issues.Bugzilla {
+			// only the bug number is the key of a Bugzilla issue
+			match = match[1:]
+		}
 		for _, result := range match {
 			result = strings.TrimPrefix(result, "#")
 			if _, ok := o.State.FoundIssueNames[result]; !ok {
@@ -673,6 +714,68 @@ func (o *StepChangelogOptions) toUserDetails(signature object.Signature) *v1.Use
 	}
 }
 
+// generateMarkdown renders the changelog body using the template if one is specified
+func (o *StepChangelogOptions) generateMarkdown(changelog *gits.Changelog, gitInfo *gits.GitRepositoryInfo) (string, error) {
+	templateText := o.Template
+	if templateText == "" && o.TemplateFile != "" {
+		data, err := ioutil.ReadFile(o.TemplateFile)
+		if err != nil {
+			return "", err
+		}
+		templateText = string(data)
+	}
+	if templateText == "" {
+		if changelog.GroupBy == gits.ChangelogGroupByKind {
+			return gits.GenerateMarkdown(changelog.ReleaseSpec, gitInfo)
+		}
+		templateText = gits.DefaultChangelogTemplate
+	}
+	return gits.RenderChangelogTemplate(changelog, "changelog", templateText)
+}
+
+// writeChangelogOutputs generates any Keep a Changelog, JSON or HTML outputs of the changelog
+func (o *StepChangelogOptions) writeChangelogOutputs(changelog *gits.Changelog, markdown string) error {
+	if o.OutputChangelogFile != "" {
+		existing := ""
+		exists, err := util.FileExists(o.OutputChangelogFile)
+		if err != nil {
+			return err
+		}
+		if exists {
+			data, err := ioutil.ReadFile(o.OutputChangelogFile)
+			if err != nil {
+				return err
+			}
+			existing = string(data)
+		}
+		text := gits.PrependKeepAChangelog(existing, gits.GenerateKeepAChangelog(changelog))
+		err = ioutil.WriteFile(o.OutputChangelogFile, []byte(text), DefaultWritePermissions)
+		if err != nil {
+			return fmt.Errorf("Failed to save changelog file %s: %s", o.OutputChangelogFile, err)
+		}
+		log.Infof("Added the release to %s\n", util.ColorInfo(o.OutputChangelogFile))
+	}
+	if o.OutputJSONFile != "" {
+		data, err := json.MarshalIndent(changelog, "", "  ")
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(o.OutputJSONFile, data, DefaultWritePermissions)
+		if err != nil {
+			return fmt.Errorf("Failed to save changelog JSON file %s: %s", o.OutputJSONFile, err)
+		}
+		log.Infof("generated: %s\n", util.ColorInfo(o.OutputJSONFile))
+	}
+	if o.OutputHTMLFile != "" {
+		err := ioutil.WriteFile(o.OutputHTMLFile, []byte(gits.GenerateHTML(changelog, markdown)), DefaultWritePermissions)
+		if err != nil {
+			return fmt.Errorf("Failed to save changelog HTML file %s: %s", o.OutputHTMLFile, err)
+		}
+		log.Infof("generated: %s\n", util.ColorInfo(o.OutputHTMLFile))
+	}
+	return nil
+}
+
 func (o *StepChangelogOptions) getTemplateResult(releaseSpec *v1.ReleaseSpec, templateName string, templateText string, templateFile string) (string, error) {
 	if templateText == "" {
 		if templateFile == "" {
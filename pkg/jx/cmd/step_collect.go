@@ -250,7 +250,7 @@ func (o *GitHubPagesStepCollectOptions) collect(options StepCollectOptions) (err
 	build := options.getBuildNumber()
 	pipeline, build = options.getPipelineName(gitRepoInfo, pipeline, build, appName)
 	if pipeline != "" && build != "" {
-		name := kube.ToValidName(pipeline + "-" + build)
+		name := options.pipelineActivityName(pipeline, build)
 		key := &kube.PromoteStepActivityKey{
 			PipelineActivityKey: kube.PipelineActivityKey{
 				Name:     name,
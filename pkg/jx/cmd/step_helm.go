@@ -58,8 +58,10 @@ func NewCmdStepHelm(f Factory, in terminal.FileReader, out terminal.FileWriter,
 	cmd.AddCommand(NewCmdStepHelmApply(f, in, out, errOut))
 	cmd.AddCommand(NewCmdStepHelmBuild(f, in, out, errOut))
 	cmd.AddCommand(NewCmdStepHelmEnv(f, in, out, errOut))
+	cmd.AddCommand(NewCmdStepHelmHistory(f, in, out, errOut))
 	cmd.AddCommand(NewCmdStepHelmInstall(f, in, out, errOut))
 	cmd.AddCommand(NewCmdStepHelmRelease(f, in, out, errOut))
+	cmd.AddCommand(NewCmdStepHelmRollback(f, in, out, errOut))
 	cmd.AddCommand(NewCmdStepHelmVersion(f, in, out, errOut))
 	return cmd
 }
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+	"gopkg.in/AlecAivazis/survey.v1/terminal"
+)
+
+// StepHelmHistoryOptions contains the command line flags
+type StepHelmHistoryOptions struct {
+	StepHelmOptions
+
+	Namespace string
+	Diff      int
+	To        int
+}
+
+var (
+	StepHelmHistoryLong = templates.LongDesc(`
+		Displays the revisions of a helm release or the differences between two revisions.
+
+		The history of releases is available for teams which do not use tiller. Each revision of a release stores
+		its manifest and values in a Secret in the namespace of the release.
+`)
+
+	StepHelmHistoryExample = templates.Examples(`
+		# display the revisions of a release
+		jx step helm history jx-staging-myapp -n jx-staging
+
+		# display the changes between revision 3 of a release and its latest revision
+		jx step helm history jx-staging-myapp -n jx-staging --diff 3
+
+		# display the changes between revisions 3 and 5 of a release
+		jx step helm history jx-staging-myapp -n jx-staging --diff 3 --to 5
+`)
+)
+
+func NewCmdStepHelmHistory(f Factory, in terminal.FileReader, out terminal.FileWriter, errOut io.Writer) *cobra.Command {
+	options := StepHelmHistoryOptions{
+		StepHelmOptions: StepHelmOptions{
+			StepOptions: StepOptions{
+				CommonOptions: CommonOptions{
+					Factory: f,
+					In:      in,
+					Out:     out,
+					Err:     errOut,
+				},
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "history [release]",
+		Short:   "Displays the revisions of a helm release",
+		Aliases: []string{"hist"},
+		Long:    StepHelmHistoryLong,
+		Example: StepHelmHistoryExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace of the release. Defaults to the current namespace")
+	cmd.Flags().IntVarP(&options.Diff, "diff", "", 0, "Display the changes since this revision rather than the revisions")
+	cmd.Flags().IntVarP(&options.To, "to", "", 0, "The revision to compare with the --diff revision. Defaults to the latest revision")
+	options.addOutputFlags(cmd)
+	return cmd
+}
+
+func (o *StepHelmHistoryOptions) Run() error {
+	if len(o.Args) == 0 {
+		return fmt.Errorf("missing release name argument")
+	}
+	releaseName := o.Args[0]
+	if o.Namespace == "" {
+		_, ns, err := o.KubeClient()
+		if err != nil {
+			return err
+		}
+		o.Namespace = ns
+	}
+	history, err := o.releaseHistory()
+	if err != nil {
+		return err
+	}
+	releases, err := history.History(o.Namespace, releaseName)
+	if err != nil {
+		return err
+	}
+	if len(releases) == 0 {
+		return fmt.Errorf("release %s not found in namespace %s", releaseName, o.Namespace)
+	}
+
+	if o.Diff > 0 {
+		to := o.To
+		if to <= 0 {
+			to = releases[len(releases)-1].Revision
+		}
+		diff, err := history.DiffRevisions(o.Namespace, releaseName, o.Diff, to)
+		if err != nil {
+			return err
+		}
+		_, err = o.Out.Write([]byte(diff))
+		return err
+	}
+
+	if o.Output != "" {
+		return o.renderItems(releases, o.Output)
+	}
+	table := o.CreateTable()
+	table.AddRow("REVISION", "UPDATED", "STATUS", "CHART", "VERSION", "DESCRIPTION")
+	for _, r := range releases {
+		table.AddRow(strconv.Itoa(r.Revision), r.Updated.Format(time.RFC822), util.ColorStatus(r.Status), r.Chart, r.ChartVersion, r.Description)
+	}
+	table.Render()
+	return nil
+}
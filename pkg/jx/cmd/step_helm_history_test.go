@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/helm/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepHelmHistory(t *testing.T) {
+	ns := "jx-staging"
+	out, err := ioutil.TempFile("", "test-step-helm-history")
+	require.NoError(t, err)
+	defer os.Remove(out.Name())
+
+	o := &StepHelmHistoryOptions{}
+	o.Out = out
+	o.Namespace = ns
+	o.Args = []string{"myapp"}
+	ConfigureTestOptionsWithResources(&o.CommonOptions, nil, nil, &gits.GitFake{}, helm_test.NewMockHelmer())
+
+	err = o.Run()
+	assert.Error(t, err, "the mock helmer does not store release history")
+
+	kubeClient, _, err := o.KubeClient()
+	require.NoError(t, err)
+	helmer := helm.NewHelmSecrets(helm.NewHelmCLI("helm", helm.V2, "", false), "", kubeClient)
+	o.helm = helmer
+	for i, manifest := range []string{"port: 80\n", "port: 8080\n"} {
+		err = helmer.Storage.Create(&helm.Release{Name: "myapp", Namespace: ns, Revision: i + 1, Status: helm.StatusDeployed,
+			Updated: time.Now(), Manifest: manifest})
+		require.NoError(t, err)
+	}
+
+	o.Diff = 1
+	err = o.Run()
+	require.NoError(t, err)
+	data, err := ioutil.ReadFile(out.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "-port: 80\n+port: 8080\n")
+
+	rollback := &StepHelmRollbackOptions{}
+	rollback.CommonOptions = o.CommonOptions
+	rollback.Namespace = ns
+	rollback.Args = []string{"myapp", "3"}
+	assert.Error(t, rollback.Run(), "there is no revision 3")
+}
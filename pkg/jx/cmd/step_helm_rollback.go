@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
+	"github.com/spf13/cobra"
+	"gopkg.in/AlecAivazis/survey.v1/terminal"
+)
+
+// StepHelmRollbackOptions contains the command line flags
+type StepHelmRollbackOptions struct {
+	StepHelmOptions
+
+	Namespace string
+	NoWait    bool
+	DryRun    bool
+}
+
+var (
+	StepHelmRollbackLong = templates.LongDesc(`
+		Rolls back a helm release to an earlier revision by applying the manifest of that revision.
+
+		Rollback is available for teams which do not use tiller. The rollback is stored as a new revision of the release.
+		Helm hooks are not run when rolling back.
+`)
+
+	StepHelmRollbackExample = templates.Examples(`
+		# roll back a release to revision 3
+		jx step helm rollback jx-staging-myapp 3 -n jx-staging
+
+		# display the changes a roll back to revision 3 would make
+		jx step helm rollback jx-staging-myapp 3 -n jx-staging --dry-run
+`)
+)
+
+func NewCmdStepHelmRollback(f Factory, in terminal.FileReader, out terminal.FileWriter, errOut io.Writer) *cobra.Command {
+	options := StepHelmRollbackOptions{
+		StepHelmOptions: StepHelmOptions{
+			StepOptions: StepOptions{
+				CommonOptions: CommonOptions{
+					Factory: f,
+					In:      in,
+					Out:     out,
+					Err:     errOut,
+				},
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "rollback [release] [revision]",
+		Short:   "Rolls back a helm release to an earlier revision",
+		Long:    StepHelmRollbackLong,
+		Example: StepHelmRollbackExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The namespace of the release. Defaults to the current namespace")
+	cmd.Flags().BoolVarP(&options.NoWait, "no-wait", "", false, "Do not wait for the resources of the release to be updated")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Display the changes the roll back would make without applying them")
+	return cmd
+}
+
+func (o *StepHelmRollbackOptions) Run() error {
+	if len(o.Args) < 2 {
+		return fmt.Errorf("usage: jx step helm rollback [release] [revision]")
+	}
+	releaseName := o.Args[0]
+	revision, err := strconv.Atoi(o.Args[1])
+	if err != nil || revision <= 0 {
+		return fmt.Errorf("invalid revision %s", o.Args[1])
+	}
+	if o.Namespace == "" {
+		_, ns, err := o.KubeClient()
+		if err != nil {
+			return err
+		}
+		o.Namespace = ns
+	}
+	history, err := o.releaseHistory()
+	if err != nil {
+		return err
+	}
+	releases, err := history.History(o.Namespace, releaseName)
+	if err != nil {
+		return err
+	}
+	if len(releases) == 0 {
+		return fmt.Errorf("release %s not found in namespace %s", releaseName, o.Namespace)
+	}
+	latest := releases[len(releases)-1].Revision
+
+	if o.DryRun {
+		diff, err := history.DiffRevisions(o.Namespace, releaseName, latest, revision)
+		if err != nil {
+			return err
+		}
+		_, err = o.Out.Write([]byte(diff))
+		return err
+	}
+	err = history.Rollback(o.Namespace, releaseName, revision, !o.NoWait)
+	if err != nil {
+		return err
+	}
+	log.Infof("Rolled back release %s to revision %s as revision %s\n", util.ColorInfo(releaseName), util.ColorInfo(revision), util.ColorInfo(latest+1))
+	return nil
+}
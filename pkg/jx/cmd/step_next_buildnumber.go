@@ -77,7 +77,7 @@ func (o *StepNextBuildNumberOptions) Run() error {
 	if repository == "" {
 		return util.MissingOption(optionRepo)
 	}
-	build, _, err := kube.GenerateBuildNumber(activities, owner, repository, branch)
+	build, _, err := kube.GenerateAppBuildNumber(activities, owner, repository, branch, o.getSubProject())
 	if err != nil {
 		return err
 	}
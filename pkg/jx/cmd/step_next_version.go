@@ -6,15 +6,16 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
 	"strings"
 
 	"encoding/json"
 
 	"github.com/blang/semver"
 	version "github.com/hashicorp/go-version"
+	"github.com/jenkins-x/jx/pkg/gits"
 	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/log"
 	"github.com/spf13/cobra"
@@ -26,8 +27,30 @@ const (
 	chartyaml   = "Chart.yaml"
 	pomxml      = "pom.xml"
 	makefile    = "Makefile"
+
+	setuppy          = "setup.py"
+	cargotoml        = "Cargo.toml"
+	gradleproperties = "gradle.properties"
 )
 
+// versionPatterns the regular expressions matching the version of the files that can be updated where the second
+// group is the version itself
+var versionPatterns = map[string]*regexp.Regexp{
+	packagejson:      regexp.MustCompile(`(?m)^(\s*"version"\s*:\s*")([^"]*)(")`),
+	chartyaml:        regexp.MustCompile(`(?m)^(version\s*:\s*)(\S+)()`),
+	pomxml:           regexp.MustCompile(`(<version>)([^<]*)(</version>)`),
+	makefile:         regexp.MustCompile(`(?m)^(VERSION\s*[:?]?=\s*)(\S+)()`),
+	setuppy:          regexp.MustCompile(`(\bversion\s*=\s*['"])([^'"]*)(['"])`),
+	cargotoml:        regexp.MustCompile(`(?m)^(version\s*=\s*")([^"]*)(")`),
+	gradleproperties: regexp.MustCompile(`(?m)^(version\s*[=:]\s*)(\S+)()`),
+}
+
+// pomSections the elements of a pom.xml after the project version which can contain the versions of other artifacts
+var pomSections = []string{"<dependencyManagement>", "<dependencies>", "<build>", "<reporting>", "<profiles>"}
+
+// preReleaseInvalidChars matches the characters which are not allowed in a pre-release identifier
+var preReleaseInvalidChars = regexp.MustCompile(`[^0-9A-Za-z]+`)
+
 // StepNextVersionOptions contains the command line flags
 type StepNextVersionOptions struct {
 	Filename      string
@@ -35,6 +58,8 @@ type StepNextVersionOptions struct {
 	Tag           bool
 	UseGitTagOnly bool
 	NewVersion    string
+	Semantic      bool
+	PreRelease    string
 	StepOptions
 }
 
@@ -49,6 +74,12 @@ type PackageJSON struct {
 var (
 	StepNextVersionLong = templates.LongDesc(`
 		This pipeline step command works out a semantic version, writes a file ./VERSION and optionally updates a file
+
+		By default the patch version of the latest git tag is incremented. With --semantic the Conventional Commits
+		(https://conventionalcommits.org/) since the latest tag are used instead: a BREAKING CHANGE or a type ending with !
+		bumps the major version, a feat bumps the minor version and anything else bumps the patch version.
+		Versions built from branches other than master get a pre-release suffix such as 1.2.0-my-branch.3 and are the only
+		versions which take pre-release tags such as v1.3.0-rc.1 into account with --semantic
 `)
 
 	StepNextVersionExample = templates.Examples(`
@@ -56,6 +87,7 @@ var (
 		jx step next-version --filename package.json
 		jx step next-version --filename package.json --tag
 		jx step next-version --filename package.json --tag --version 1.2.3
+		jx step next-version --semantic --filename Cargo.toml --tag
 `)
 )
 
@@ -73,11 +105,13 @@ func NewCmdStepNextVersion(f Factory, in terminal.FileReader, out terminal.FileW
 			CheckErr(err)
 		},
 	}
-	cmd.Flags().StringVarP(&options.Filename, "filename", "f", "", "Filename that contains version property to update, e.g. package.json")
+	cmd.Flags().StringVarP(&options.Filename, "filename", "f", "", "Filename that contains version property to update, one of [pom.xml,package.json,Chart.yaml,Makefile,setup.py,Cargo.toml,gradle.properties]")
 	cmd.Flags().StringVarP(&options.NewVersion, "version", "", "", "optional version to use rather than generating a new one")
 	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "the directory to look for files that contain a pom.xml or Makefile with the project version to bump")
 	cmd.Flags().BoolVarP(&options.Tag, "tag", "t", false, "tag and push new version")
 	cmd.Flags().BoolVarP(&options.UseGitTagOnly, "use-git-tag-only", "", false, "only use a git tag so work out new semantic version, else specify filename [pom.xml,package.json,Makefile,Chart.yaml]")
+	cmd.Flags().BoolVarP(&options.Semantic, "semantic", "", false, "use the conventional commits since the latest tag to decide whether to bump the major, minor or patch version")
+	cmd.Flags().StringVarP(&options.PreRelease, "pre-release", "", "", "the pre-release identifier added to versions built from branches other than master. Defaults to the branch name")
 
 	options.addCommonFlags(cmd)
 	return cmd
@@ -87,7 +121,11 @@ func (o *StepNextVersionOptions) Run() error {
 
 	var err error
 	if o.NewVersion == "" {
-		o.NewVersion, err = o.getNewVersionFromTag()
+		if o.Semantic {
+			o.NewVersion, err = o.getNewVersionFromCommits()
+		} else {
+			o.NewVersion, err = o.getNewVersionFromTag()
+		}
 		if err != nil {
 			return err
 		}
@@ -125,12 +163,12 @@ func (o *StepNextVersionOptions) Run() error {
 
 // GetVersion gets the version from a source file
 func (o *StepNextVersionOptions) GetVersion() (string, error) {
-	if o.UseGitTagOnly {
+	if o.UseGitTagOnly || (o.Semantic && o.Filename == "") {
 		return "", nil
 	}
 	if o.Filename == "" {
 		// try and work out
-		return "", fmt.Errorf("no filename flag set to work out next semantic version.  choose pom.xml, Chart.yaml, package.json, Makefile or set the flag use-git-tag-only")
+		return "", fmt.Errorf("no filename flag set to work out next semantic version.  choose pom.xml, Chart.yaml, package.json, Makefile, setup.py, Cargo.toml, gradle.properties or set the flag use-git-tag-only")
 	}
 
 	switch o.Filename {
@@ -221,6 +259,23 @@ func (o *StepNextVersionOptions) GetVersion() (string, error) {
 				}
 			}
 		}
+	case setuppy, cargotoml, gradleproperties:
+		data, err := ioutil.ReadFile(filepath.Join(o.Dir, o.Filename))
+		if err != nil {
+			return "", err
+		}
+
+		if o.Verbose {
+			log.Infof("found %s\n", o.Filename)
+		}
+		v := findVersion(string(data), o.Filename)
+		if v != "" {
+			if o.Verbose {
+				log.Infof("existing %s version %s\n", o.Filename, v)
+			}
+			return v, nil
+		}
+
 	default:
 		return "", fmt.Errorf("no recognised file to obtain current version from")
 	}
@@ -228,67 +283,41 @@ func (o *StepNextVersionOptions) GetVersion() (string, error) {
 	return "", fmt.Errorf("cannot find version for file %s\n", o.Filename)
 }
 
-func (o *StepNextVersionOptions) getLatestTag() (string, error) {
+// getLatestTag returns the latest version and the name of the tag it was found in. Only the tags prefixed with the
+// app name are used for an app of a monorepo. Pre-release tags such as v1.3.0-rc.1 are ignored unless preReleases
+// is true
+func (o *StepNextVersionOptions) getLatestTag(preReleases bool) (string, string, error) {
 	// if repo isn't provided by flags fall back to using current repo if run from a git project
-	var versionsRaw []string
-
 	err := o.Git().FetchTags("")
 	if err != nil {
-		return "", fmt.Errorf("error fetching tags: %v", err)
+		return "", "", fmt.Errorf("error fetching tags: %v", err)
 	}
 	tags, err := o.Git().Tags("")
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	if len(tags) == 0 {
 		// if no current flags exist then lets start at 0.0.0
-		return "0.0.0", fmt.Errorf("no existing tags found")
+		return "0.0.0", "", fmt.Errorf("no existing tags found")
 	}
 
-	// build an array of all the tags
-	versionsRaw = make([]string, len(tags))
-	for i, tag := range tags {
-		if o.Verbose {
+	if o.Verbose {
+		for _, tag := range tags {
 			log.Infof("found tag %s\n", tag)
 		}
-		tag = strings.TrimPrefix(tag, "v")
-		if tag != "" {
-			versionsRaw[i] = tag
-		}
-	}
-
-	// turn the array into a new collection of versions that we can sort
-	var versions []*version.Version
-	for _, raw := range versionsRaw {
-		v, _ := version.NewVersion(raw)
-		if v != nil {
-			versions = append(versions, v)
-		}
 	}
-
-	if len(versions) == 0 {
+	latest, tag := latestVersionTag(tags, o.getSubProject(), o.isMonorepo(), preReleases)
+	if latest == "" {
 		// if no current flags exist then lets start at 0.0.0
-		return "0.0.0", fmt.Errorf("no existing tags found")
-	}
-
-	// return the latest tag
-	col := version.Collection(versions)
-	if o.Verbose {
-		log.Infof("version collection %v\n", col)
+		return "0.0.0", "", fmt.Errorf("no existing tags found")
 	}
-
-	sort.Sort(col)
-	latest := len(versions)
-	if versions[latest-1] == nil {
-		return "0.0.0", fmt.Errorf("no existing tags found")
-	}
-	return versions[latest-1].String(), nil
+	return latest, tag, nil
 }
 
 func (o *StepNextVersionOptions) getNewVersionFromTag() (string, error) {
 
 	// get the latest github tag
-	tag, err := o.getLatestTag()
+	tag, _, err := o.getLatestTag(true)
 	if err != nil && tag == "" {
 		return "", err
 	}
@@ -297,10 +326,121 @@ func (o *StepNextVersionOptions) getNewVersionFromTag() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	sv.Patch++
+	return o.applyBaseVersion(sv)
+}
+
+// getNewVersionFromCommits bumps the latest tag using the conventional commits since that tag and adds a pre-release
+// suffix when not on a release branch
+func (o *StepNextVersionOptions) getNewVersionFromCommits() (string, error) {
+	branch, err := o.preReleaseBranch()
+	if err != nil {
+		return "", err
+	}
+	latest, tag, err := o.getLatestTag(branch != "")
+	if err != nil && latest == "" {
+		return "", err
+	}
+	sv, err := semver.Parse(latest)
+	if err != nil {
+		return "", err
+	}
+
+	messages, err := o.Git().GetCommitMessages(o.Dir, tag)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the commits since tag %s: %s", tag, err)
+	}
+	if o.Verbose {
+		log.Infof("found %d commits since tag %s\n", len(messages), tag)
+	}
+
+	newVersion, err := o.applyBaseVersion(NextSemanticVersion(sv, messages))
+	if err != nil {
+		return "", err
+	}
+
+	if branch == "" {
+		return newVersion, nil
+	}
+	identifier := o.PreRelease
+	if identifier == "" {
+		identifier = branch
+	}
+	return newVersion + PreReleaseSuffix(identifier, len(messages)), nil
+}
+
+// preReleaseBranch returns the branch being built when it gets pre-release versions or an empty string for release
+// branches
+func (o *StepNextVersionOptions) preReleaseBranch() (string, error) {
+	branch := os.Getenv("BRANCH_NAME")
+	if branch == "" {
+		var err error
+		branch, err = o.Git().Branch(o.Dir)
+		if err != nil {
+			return "", err
+		}
+	}
+	if branch == "HEAD" || isReleaseBranch(branch) {
+		return "", nil
+	}
+	return branch, nil
+}
+
+// NextSemanticVersion returns the version after the current version for the given conventional commit messages.
+// Breaking changes bump the major version, features bump the minor version and any other commits bump the patch version.
+// When the current version is a pre-release, such as 1.3.0-rc.1, its version has not been released yet so it is only
+// bumped if the commits need a bigger change than the pre-release already has
+func NextSemanticVersion(current semver.Version, messages []string) semver.Version {
+	answer := semver.Version{
+		Major: current.Major,
+		Minor: current.Minor,
+		Patch: current.Patch,
+	}
+	breaking := false
+	feature := false
+	for _, message := range messages {
+		commit := gits.ParseCommit(message)
+		if commit.Breaking {
+			breaking = true
+		} else if commit.Type() == "feat" {
+			feature = true
+		}
+	}
+	preRelease := len(current.Pre) > 0
+	switch {
+	case breaking:
+		if !preRelease || answer.Minor != 0 || answer.Patch != 0 {
+			answer.Major++
+		}
+		answer.Minor = 0
+		answer.Patch = 0
+	case feature:
+		if !preRelease || answer.Patch != 0 {
+			answer.Minor++
+		}
+		answer.Patch = 0
+	default:
+		if !preRelease {
+			answer.Patch++
+		}
+	}
+	return answer
+}
+
+// PreReleaseSuffix returns the pre-release suffix for the identifier, such as a branch name, and the number of commits
+func PreReleaseSuffix(identifier string, commits int) string {
+	identifier = strings.Trim(preReleaseInvalidChars.ReplaceAllString(strings.ToLower(identifier), "-"), "-")
+	if identifier == "" {
+		identifier = "pre"
+	}
+	return fmt.Sprintf("-%s.%d", identifier, commits)
+}
 
+// applyBaseVersion returns the version from the version file instead if its major or minor version has been changed
+func (o *StepNextVersionOptions) applyBaseVersion(sv semver.Version) (string, error) {
 	majorVersion := sv.Major
 	minorVersion := sv.Minor
-	patchVersion := sv.Patch + 1
+	patchVersion := sv.Patch
 
 	// check if major or minor version has been changed
 	baseVersion, err := o.GetVersion()
@@ -338,39 +478,17 @@ func (o *StepNextVersionOptions) getNewVersionFromTag() (string, error) {
 	return fmt.Sprintf("%d.%d.%d", majorVersion, minorVersion, patchVersion), nil
 }
 
-// SetVersion Sets the version...
+// SetVersion writes the new version into the version file then commits it
 func (o *StepNextVersionOptions) SetVersion() error {
-	var err error
-	var matchField string
-	var regex *regexp.Regexp
 	filename := filepath.Join(o.Dir, o.Filename)
 	b, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return err
 	}
-	switch o.Filename {
-	case packagejson:
-		regex = regexp.MustCompile(`[0-9][0-9]{0,2}.[0-9][0-9]{0,2}(.[0-9][0-9]{0,2})?(.[0-9][0-9]{0,2})?(-development)?`)
-		matchField = "\"version\": \""
-
-	case chartyaml:
-		regex = regexp.MustCompile(`[0-9][0-9]{0,2}.[0-9][0-9]{0,2}(.[0-9][0-9]{0,2})?(.[0-9][0-9]{0,2})?(-.*)?`)
-		matchField = "version: "
-
-	default:
-		return fmt.Errorf("unrecognised filename %s, supported files are %s %s", o.Filename, packagejson, chartyaml)
-	}
-
-	lines := strings.Split(string(b), "\n")
-
-	for i, line := range lines {
-		if strings.Contains(line, matchField) {
-			lines[i] = regex.ReplaceAllString(line, o.NewVersion)
-		} else {
-			lines[i] = line
-		}
+	output, err := ReplaceVersion(string(b), o.Filename, o.NewVersion)
+	if err != nil {
+		return err
 	}
-	output := strings.Join(lines, "\n")
 	err = ioutil.WriteFile(filename, []byte(output), 0644)
 	if err != nil {
 		return err
@@ -388,6 +506,55 @@ func (o *StepNextVersionOptions) SetVersion() error {
 	return nil
 }
 
+// ReplaceVersion replaces the project version in the text of the given kind of version file
+func ReplaceVersion(text string, filename string, newVersion string) (string, error) {
+	regex := versionPatterns[filepath.Base(filename)]
+	if regex == nil {
+		return "", fmt.Errorf("unrecognised filename %s, supported files are %s %s %s %s %s %s %s", filename,
+			pomxml, packagejson, chartyaml, makefile, setuppy, cargotoml, gradleproperties)
+	}
+	start, end := versionSearchRange(text, filename)
+	loc := regex.FindStringSubmatchIndex(text[start:end])
+	if loc == nil {
+		return "", fmt.Errorf("no version found in %s", filename)
+	}
+	return text[:start+loc[4]] + newVersion + text[start+loc[5]:], nil
+}
+
+// findVersion returns the project version in the text of the given kind of version file or an empty string
+func findVersion(text string, filename string) string {
+	regex := versionPatterns[filepath.Base(filename)]
+	if regex == nil {
+		return ""
+	}
+	start, end := versionSearchRange(text, filename)
+	match := regex.FindStringSubmatch(text[start:end])
+	if match == nil {
+		return ""
+	}
+	return strings.TrimSpace(match[2])
+}
+
+// versionSearchRange returns the range of the text to look for the version in so that the versions of a parent pom and
+// of the dependencies and plugins of a pom without a version of its own are skipped
+func versionSearchRange(text string, filename string) (int, int) {
+	start := 0
+	end := len(text)
+	if filepath.Base(filename) == pomxml {
+		idx := strings.Index(text, "</parent>")
+		if idx >= 0 {
+			start = idx
+		}
+		for _, section := range pomSections {
+			idx = strings.Index(text[start:end], section)
+			if idx >= 0 {
+				end = start + idx
+			}
+		}
+	}
+	return start, end
+}
+
 func (o *StepNextVersionOptions) setPackageVersion(b []byte) error {
 	jsPackage := PackageJSON{}
 	err := json.Unmarshal(b, &jsPackage)
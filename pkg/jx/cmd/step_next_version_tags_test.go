@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLatestTagPreReleases(t *testing.T) {
+	t.Parallel()
+	o := &StepNextVersionOptions{}
+	gitter := &gits.GitFake{
+		GitTags: []gits.GitTag{
+			{Name: "v1.2.0"},
+			{Name: "v1.2.1"},
+			{Name: "v1.3.0-rc.1"},
+			{Name: "v1.2.2-feature-cheese.3"},
+		},
+	}
+	ConfigureTestOptions(&o.CommonOptions, gitter, helm_test.NewMockHelmer())
+
+	latest, tag, err := o.getLatestTag(false)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.1", latest)
+	assert.Equal(t, "v1.2.1", tag)
+
+	latest, tag, err = o.getLatestTag(true)
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0-rc.1", latest)
+	assert.Equal(t, "v1.3.0-rc.1", tag)
+
+	// without --semantic pre-release tags are still used
+	o.UseGitTagOnly = true
+	newVersion, err := o.getNewVersionFromTag()
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.1", newVersion)
+}
@@ -1,10 +1,14 @@
 package cmd_test
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
+	"github.com/blang/semver"
 	"github.com/jenkins-x/jx/pkg/jx/cmd"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMakefile(t *testing.T) {
@@ -48,3 +52,89 @@ func TestChart(t *testing.T) {
 
 	assert.Equal(t, "0.0.1-SNAPSHOT", v, "error with GetVersion for a pom.xml")
 }
+
+func TestVersionFiles(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		dir      string
+		filename string
+		version  string
+		expected string
+	}{
+		{"python", "setup.py", "0.3.1", "    version='1.2.3',\n"},
+		{"rust", "Cargo.toml", "0.3.1", "version = \"1.2.3\"\n"},
+		{"gradle", "gradle.properties", "0.3.1-SNAPSHOT", "version=1.2.3\n"},
+		{"java", "pom.xml", "1.0-SNAPSHOT", "<version>1.2.3</version>"},
+		{"make", "Makefile", "1.2.0-SNAPSHOT", "VERSION := 1.2.3"},
+		{"helm", "Chart.yaml", "0.0.1-SNAPSHOT", "version: 1.2.3\n"},
+		{"javascript", "package.json", "0.0.1", "\"version\": \"1.2.3\",\n"},
+	}
+	for _, tc := range testCases {
+		o := cmd.StepNextVersionOptions{
+			Dir:      filepath.Join("test_data", "next_version", tc.dir),
+			Filename: tc.filename,
+		}
+		v, err := o.GetVersion()
+		require.NoError(t, err, "GetVersion for %s", tc.filename)
+		assert.Equal(t, tc.version, v, "GetVersion for %s", tc.filename)
+
+		data, err := ioutil.ReadFile(filepath.Join(o.Dir, tc.filename))
+		require.NoError(t, err)
+		text, err := cmd.ReplaceVersion(string(data), tc.filename, "1.2.3")
+		require.NoError(t, err, "ReplaceVersion for %s", tc.filename)
+		assert.Contains(t, text, tc.expected, "ReplaceVersion for %s", tc.filename)
+		assert.Equal(t, len(data)-len(tc.version)+len("1.2.3"), len(text), "only the project version of %s should be replaced", tc.filename)
+	}
+
+	_, err := cmd.ReplaceVersion("version: 1.0.0", "values.yaml", "1.2.3")
+	assert.Error(t, err)
+
+	pom := `<project>
+    <parent>
+        <groupId>io.test</groupId>
+        <artifactId>parent</artifactId>
+        <version>1.0.0</version>
+    </parent>
+    <artifactId>child</artifactId>
+    <dependencies>
+        <dependency>
+            <groupId>junit</groupId>
+            <artifactId>junit</artifactId>
+            <version>4.12</version>
+        </dependency>
+    </dependencies>
+</project>`
+	_, err = cmd.ReplaceVersion(pom, "pom.xml", "1.2.3")
+	assert.Error(t, err, "the version of a dependency should not be replaced in a pom.xml without a version")
+}
+
+func TestNextSemanticVersion(t *testing.T) {
+	t.Parallel()
+	current := semver.MustParse("1.2.3")
+	assertNextSemanticVersion(t, current, "1.2.4")
+	assertNextSemanticVersion(t, current, "1.2.4", "fix: cheese", "chore(deps): upgrade", "something regular")
+	assertNextSemanticVersion(t, current, "1.3.0", "fix: cheese", "feat: wine")
+	assertNextSemanticVersion(t, current, "1.3.0", "feat(beer): lager")
+	assertNextSemanticVersion(t, current, "2.0.0", "feat: wine", "refactor!: drop the cheese API")
+	assertNextSemanticVersion(t, current, "2.0.0", "feat(beer)!: no more lager")
+	assertNextSemanticVersion(t, current, "2.0.0", "fix: cheese\n\nBREAKING CHANGE: the cheese is now blue")
+
+	preRelease := semver.MustParse("1.3.0-rc.1")
+	assertNextSemanticVersion(t, preRelease, "1.3.0", "fix: cheese")
+	assertNextSemanticVersion(t, preRelease, "1.3.0", "feat: wine")
+	assertNextSemanticVersion(t, preRelease, "2.0.0", "feat(beer)!: no more lager")
+	assertNextSemanticVersion(t, semver.MustParse("1.3.1-rc.1"), "1.4.0", "feat: wine")
+	assertNextSemanticVersion(t, semver.MustParse("2.0.0-rc.1"), "2.0.0", "feat(beer)!: no more lager")
+}
+
+func assertNextSemanticVersion(t *testing.T, current semver.Version, expected string, messages ...string) {
+	actual := cmd.NextSemanticVersion(current, messages)
+	assert.Equal(t, expected, actual.String(), "next version of %s for commits %v", current.String(), messages)
+}
+
+func TestPreReleaseSuffix(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "-pr-123.4", cmd.PreReleaseSuffix("PR-123", 4))
+	assert.Equal(t, "-feature-cheese.0", cmd.PreReleaseSuffix("feature/cheese", 0))
+	assert.Equal(t, "-pre.2", cmd.PreReleaseSuffix("/", 2))
+}
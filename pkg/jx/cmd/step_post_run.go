@@ -3,6 +3,10 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/chats"
 
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
@@ -23,17 +27,22 @@ type StepPostRunOptions struct {
 
 	DisableImport bool
 	OutDir        string
+	Status        string
 }
 
 var ()
 
 var (
 	StepPostRunLong = templates.LongDesc(`
-		This pipeline step executes any post build actions added during Pipeline execution
+		This pipeline step executes any post build actions added during Pipeline execution then notifies the
+		developer channel of the chat configuration in the jenkins-x.yml that the pipeline has completed
 `)
 
 	StepPostRunExample = templates.Examples(`
 		jx step post run
+
+		# notify the developer channel that the pipeline failed
+		jx step post run --status Failed
 `)
 )
 
@@ -64,6 +73,7 @@ func NewCmdStepPostRun(f Factory, in terminal.FileReader, out terminal.FileWrite
 	}
 
 	cmd.Flags().BoolVarP(&options.Verbose, "verbose", "", false, "Enables verbose logging")
+	cmd.Flags().StringVarP(&options.Status, "status", "", "", "The status of the pipeline such as Succeeded or Failed for the developer channel notification. Defaults to the status of the PipelineActivity")
 	return cmd
 }
 
@@ -98,7 +108,7 @@ func (o *StepPostRunOptions) Run() (err error) {
 	build := o.getBuildNumber()
 	pipeline, build = o.getPipelineName(gitInfo, pipeline, build, appName)
 	if pipeline != "" && build != "" {
-		name := kube.ToValidName(pipeline + "-" + build)
+		name := o.pipelineActivityName(pipeline, build)
 		key := &kube.PromoteStepActivityKey{
 			PipelineActivityKey: kube.PipelineActivityKey{
 				Name:     name,
@@ -114,9 +124,48 @@ func (o *StepPostRunOptions) Run() (err error) {
 			log.Infof("Running Extension %s\n", util.ColorInfo(fmt.Sprintf("%s.%s", pe.Namespace, pe.Name)))
 			err = pe.Execute(o.Verbose)
 			if err != nil {
-				return err
+				break
 			}
 		}
+		o.notifyActivityChatThread(activities, name, o.pipelineCompletedMessage(a), func(message *chats.Message) string {
+			return o.notifyDeveloperChannel("", message)
+		})
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
+
+// pipelineCompletedMessage returns the chat message notifying that the pipeline of the activity has completed
+func (o *StepPostRunOptions) pipelineCompletedMessage(a *v1.PipelineActivity) *chats.Message {
+	spec := &a.Spec
+	status := o.Status
+	if status == "" {
+		status = string(spec.Status)
+	}
+	if status == "" || status == string(v1.ActivityStatusTypeRunning) {
+		status = "Completed"
+	}
+	url := spec.BuildLogsURL
+	if url == "" {
+		url = spec.BuildURL
+	}
+	lines := []string{}
+	if spec.Version != "" {
+		version := "Version " + spec.Version
+		if spec.ReleaseNotesURL != "" {
+			version += " " + spec.ReleaseNotesURL
+		}
+		lines = append(lines, version)
+	}
+	if spec.LastCommitMessage != "" {
+		lines = append(lines, strings.TrimSpace(spec.LastCommitMessage))
+	}
+	return &chats.Message{
+		Title:  fmt.Sprintf("Pipeline %s #%s", spec.Pipeline, spec.Build),
+		Text:   strings.Join(lines, "\n"),
+		URL:    url,
+		Status: status,
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineCompletedMessage(t *testing.T) {
+	t.Parallel()
+	a := &v1.PipelineActivity{
+		Spec: v1.PipelineActivitySpec{
+			Pipeline:          "jenkins-x/myapp/master",
+			Build:             "3",
+			Version:           "1.0.3",
+			Status:            v1.ActivityStatusTypeRunning,
+			BuildURL:          "http://jenkins/job/myapp/3",
+			LastCommitMessage: "fix: the widget\n",
+		},
+	}
+	o := &StepPostRunOptions{}
+	message := o.pipelineCompletedMessage(a)
+	assert.Equal(t, "Pipeline jenkins-x/myapp/master #3", message.Title)
+	assert.Equal(t, "Version 1.0.3\nfix: the widget", message.Text)
+	assert.Equal(t, "http://jenkins/job/myapp/3", message.URL)
+	assert.Equal(t, "Completed", message.Status)
+
+	a.Spec.BuildLogsURL = "http://nexus/logs/3.log"
+	o.Status = string(v1.ActivityStatusTypeFailed)
+	message = o.pipelineCompletedMessage(a)
+	assert.Equal(t, "http://nexus/logs/3.log", message.URL)
+	assert.Equal(t, "Failed", message.Status)
+	assert.Equal(t, "danger", message.Color())
+}
@@ -93,7 +93,7 @@ func (o *StepPreCheckComplianceOptions) Init(jxClient jenkinsv1client.Interface,
 	url := fmt.Sprintf("https://github.com/%s/%s.git", org, repo)
 	pipeline := fmt.Sprintf("%s-%s", org, repo)
 	if pipeline != "" && build != "" {
-		name := kube.ToValidName(pipeline + "-" + build)
+		name := o.pipelineActivityName(pipeline, build)
 		log.Infof("Creating compliance check for %s\n", name)
 		_, err := jxClient.JenkinsV1().ComplianceChecks(ns).Create(&jenkinsv1.ComplianceCheck{
 			ObjectMeta: metav1.ObjectMeta{
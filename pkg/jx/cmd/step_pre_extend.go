@@ -118,7 +118,7 @@ func (o *StepPreExtendOptions) Run() error {
 		build := o.getBuildNumber()
 		pipeline, build = o.getPipelineName(gitInfo, pipeline, build, appName)
 		if pipeline != "" && build != "" {
-			name := kube.ToValidName(pipeline + "-" + build)
+			name := o.pipelineActivityName(pipeline, build)
 			key := &kube.PromoteStepActivityKey{
 				PipelineActivityKey: kube.PipelineActivityKey{
 					Name:     name,
@@ -13,7 +13,6 @@ import (
 	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/spf13/cobra"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // StepReleaseOptions contains the CLI arguments
@@ -264,30 +263,6 @@ func (o *StepReleaseOptions) buildSource() error {
 
 }
 
-func (o *StepReleaseOptions) loadDockerRegistry() (string, error) {
-	kubeClient, curNs, err := o.KubeClient()
-	if err != nil {
-		return "", err
-	}
-	ns, _, err := kube.GetDevNamespace(kubeClient, curNs)
-	if err != nil {
-		return "", err
-	}
-
-	configMapName := kube.ConfigMapJenkinsDockerRegistry
-	cm, err := kubeClient.CoreV1().ConfigMaps(ns).Get(configMapName, metav1.GetOptions{})
-	if err != nil {
-		return "", fmt.Errorf("Could not find ConfigMap %s in namespace %s: %s", configMapName, ns, err)
-	}
-	if cm.Data != nil {
-		dockerRegistry := cm.Data["docker.registry"]
-		if dockerRegistry != "" {
-			return dockerRegistry, nil
-		}
-	}
-	return "", fmt.Errorf("Could not find the docker.registry property in the ConfigMap: %s", configMapName)
-}
-
 func (o *StepReleaseOptions) releaseAndPromoteChart(dir string) error {
 	err := os.Chdir(dir)
 	if err != nil {
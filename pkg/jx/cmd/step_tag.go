@@ -51,6 +51,8 @@ var (
 		git tag -fa v$(VERSION) -m "Release version $(VERSION)"
 		git push origin v$(VERSION)
 
+		For an app of a monorepo the tag is prefixed with the app name, such as myapp/v1.0.0, so that each app is versioned independently.
+
 `)
 
 	stepTagExample = templates.Examples(`
@@ -124,7 +126,7 @@ func (o *StepTagOptions) Run() error {
 		}
 	}
 
-	tag := "v" + o.Flags.Version
+	tag := versionTagName(o.getSubProject(), o.Flags.Version)
 
 	err := o.Git().AddCommmit("", fmt.Sprintf("release %s", o.Flags.Version))
 	if err != nil {
@@ -7,9 +7,13 @@ import (
 
 	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
+	typev1 "github.com/jenkins-x/jx/pkg/client/clientset/versioned/typed/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm"
 	"github.com/jenkins-x/jx/pkg/jx/cmd/templates"
 	"github.com/jenkins-x/jx/pkg/kube"
 	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/jenkins-x/jx/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
@@ -22,9 +26,16 @@ const appLabel = "app"
 type StepVerifyOptions struct {
 	StepOptions
 
-	After    int32
-	Pods     int32
-	Restarts int32
+	After             int32
+	Pods              int32
+	Restarts          int32
+	Rollback          bool
+	RollbackAutoMerge bool
+	RollbackTimeout   string
+	RollbackPollTime  string
+
+	// for testing
+	FakePullRequests CreateEnvPullRequestFn
 }
 
 var (
@@ -34,6 +45,12 @@ var (
 
 	StepVerifyExample = templates.Examples(`
 		jx step verify
+
+		# raise a Pull Request to revert the environment to the previous version if the verification fails
+		jx step verify --rollback
+
+		# raise a rollback Pull Request and fail the rollback unless it is merged within 10 minutes
+		jx step verify --rollback --rollback-timeout 10m
 	`)
 )
 
@@ -65,6 +82,10 @@ func NewCmdStepVerify(f Factory, in terminal.FileReader, out terminal.FileWriter
 	cmd.Flags().Int32VarP(&options.After, "after", "", 60, "The time in seconds after which the application should be ready")
 	cmd.Flags().Int32VarP(&options.Pods, "pods", "p", 1, "Number of expected pods to be running")
 	cmd.Flags().Int32VarP(&options.Restarts, "restarts", "r", 0, "Maximum number of restarts which are acceptable within the given time")
+	cmd.Flags().BoolVarP(&options.Rollback, "rollback", "", false, "Raises a Pull Request on the environment to restore the previous version of the application if the verification fails")
+	cmd.Flags().BoolVarP(&options.RollbackAutoMerge, "rollback-auto-merge", "", false, "Merges the rollback Pull Request straight away")
+	cmd.Flags().StringVarP(&options.RollbackTimeout, "rollback-timeout", "", "", "The time to wait for the rollback Pull Request to merge before the rollback is marked as failed. If not specified the step does not wait for the Pull Request to merge")
+	cmd.Flags().StringVarP(&options.RollbackPollTime, "rollback-poll-time", "", "20s", "Poll time when waiting for the rollback Pull Request to merge")
 
 	return cmd
 }
@@ -129,21 +150,13 @@ func (o *StepVerifyOptions) Run() error {
 					if restarts < o.Restarts {
 						continue
 					} else {
-						err = o.updatePipelineActivity(activity, v1.ActivityStatusTypeFailed)
-						if err != nil {
-							return err
-						}
-						return fmt.Errorf("pod '%s' is '%s' and was restarted '%d', which exceeds max number of restarts '%d'",
-							pod.Name, pod.Status.Phase, restarts, o.Restarts)
+						return o.verificationFailed(activity, fmt.Errorf("pod '%s' is '%s' and was restarted '%d', which exceeds max number of restarts '%d'",
+							pod.Name, pod.Status.Phase, restarts, o.Restarts))
 					}
 				} else {
 					if restarts > o.Restarts {
-						err = o.updatePipelineActivity(activity, v1.ActivityStatusTypeFailed)
-						if err != nil {
-							return err
-						}
-						return fmt.Errorf("pod '%s' is running but was restarted '%d', which exceeds max number of restarts '%d'",
-							pod.Name, restarts, o.Restarts)
+						return o.verificationFailed(activity, fmt.Errorf("pod '%s' is running but was restarted '%d', which exceeds max number of restarts '%d'",
+							pod.Name, restarts, o.Restarts))
 					}
 				}
 			}
@@ -151,11 +164,7 @@ func (o *StepVerifyOptions) Run() error {
 	}
 
 	if foundPods != o.Pods {
-		err = o.updatePipelineActivity(activity, v1.ActivityStatusTypeFailed)
-		if err != nil {
-			return err
-		}
-		return fmt.Errorf("found '%d' pods running but expects '%d'", foundPods, o.Pods)
+		return o.verificationFailed(activity, fmt.Errorf("found '%d' pods running but expects '%d'", foundPods, o.Pods))
 	}
 
 	err = o.updatePipelineActivity(activity, v1.ActivityStatusTypeSucceeded)
@@ -171,7 +180,7 @@ func (o *StepVerifyOptions) detectPipelineActivity(jxClient versioned.Interface,
 	if pipeline == "" || build == "" {
 		return nil, errors.New("JOB_NAME or BUILD_NUMBER environment variables not set")
 	}
-	name := kube.ToValidName(pipeline + "-" + build)
+	name := o.pipelineActivityName(pipeline, build)
 	activities := jxClient.JenkinsV1().PipelineActivities(namespace)
 	activity, err := activities.Get(name, metav1.GetOptions{})
 	if err != nil {
@@ -242,3 +251,203 @@ func (o *StepVerifyOptions) updatePipelineActivity(activity *v1.PipelineActivity
 
 	return nil
 }
+
+// verificationFailed marks the activity as failed and rolls back the promotion if rollback is enabled
+func (o *StepVerifyOptions) verificationFailed(activity *v1.PipelineActivity, verifyErr error) error {
+	err := o.updatePipelineActivity(activity, v1.ActivityStatusTypeFailed)
+	if err != nil {
+		return err
+	}
+	if o.Rollback {
+		err = o.RollbackPromotion(activity)
+		if err != nil {
+			log.Warnf("Failed to rollback the promotion of PipelineActivity %s: %s\n", activity.Name, err)
+		}
+	}
+	return verifyErr
+}
+
+// RollbackPromotion raises a Pull Request on the git repository of the environment the activity promoted to which
+// restores the previous version of the application and records it as a Rollback step on the activity. If a rollback
+// timeout is specified the step fails unless the Pull Request is merged before it, otherwise the step is left running
+func (o *StepVerifyOptions) RollbackPromotion(activity *v1.PipelineActivity) error {
+	envName := ""
+	for _, step := range activity.Spec.Steps {
+		if step.Promote != nil {
+			envName = step.Promote.Environment
+			break
+		}
+	}
+	if envName == "" {
+		return fmt.Errorf("no promotion found in pipeline activity '%s' to rollback", activity.Name)
+	}
+
+	jxClient, devNs, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the jx client")
+	}
+	env, err := kube.GetEnvironment(jxClient, devNs, envName)
+	if err != nil {
+		return errors.Wrapf(err, "search environment by name '%s'", envName)
+	}
+	activities := jxClient.JenkinsV1().PipelineActivities(devNs)
+	previousVersion, err := findPreviousPromotedVersion(activities, activity, envName)
+	if err != nil {
+		return err
+	}
+
+	app := activity.RepositoryName()
+	version := activity.Spec.Version
+	if previousVersion == "" {
+		return fmt.Errorf("no earlier version of application '%s' was promoted to environment '%s' to rollback to", app, envName)
+	}
+	timeout := time.Duration(0)
+	if o.RollbackTimeout != "" {
+		timeout, err = time.ParseDuration(o.RollbackTimeout)
+		if err != nil {
+			return fmt.Errorf("Invalid duration format %s for option --rollback-timeout: %s", o.RollbackTimeout, err)
+		}
+	}
+	pollTime, err := time.ParseDuration(o.RollbackPollTime)
+	if err != nil {
+		return fmt.Errorf("Invalid duration format %s for option --rollback-poll-time: %s", o.RollbackPollTime, err)
+	}
+	modifyRequirementsFn := func(requirements *helm.Requirements) error {
+		for _, dep := range requirements.Dependencies {
+			if dep != nil && dep.Name == app {
+				requirements.SetAppVersion(app, previousVersion, dep.Repository, dep.Alias)
+				return nil
+			}
+		}
+		return fmt.Errorf("application '%s' not found in the requirements of environment '%s'", app, envName)
+	}
+
+	branchNameText := "rollback-" + app + "-" + version
+	title := "Rollback " + app + " to " + previousVersion
+	message := fmt.Sprintf("Rollback %s from version %s to %s as the verification failed", app, version, previousVersion)
+
+	var info *ReleasePullRequestInfo
+	if o.FakePullRequests != nil {
+		info, err = o.FakePullRequests(env, modifyRequirementsFn, branchNameText, title, message, nil)
+	} else {
+		info, err = o.createEnvironmentPullRequest(env, modifyRequirementsFn, branchNameText, title, message, nil, nil)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the rollback Pull Request on environment '%s'", envName)
+	}
+
+	merged := false
+	prURL := ""
+	if info != nil && info.PullRequest != nil {
+		pr := info.PullRequest
+		prURL = pr.URL
+		log.Infof("Created rollback Pull Request %s\n", util.ColorInfo(prURL))
+		if o.RollbackAutoMerge && info.GitProvider != nil {
+			err = info.GitProvider.MergePullRequest(pr, message)
+			if err != nil {
+				log.Warnf("Failed to merge the rollback Pull Request %s: %s\n", prURL, err)
+			} else {
+				merged = true
+			}
+		}
+	}
+
+	err = o.updateRollbackStep(activities, activity.Name, envName, func(rollback *v1.RollbackActivityStep) {
+		rollback.Version = version
+		rollback.PreviousVersion = previousVersion
+		rollback.PullRequestURL = prURL
+		kube.StartActivityStep(&rollback.CoreActivityStep)
+		if merged {
+			kube.CompleteActivityStep(&rollback.CoreActivityStep)
+		}
+	})
+	if err != nil || merged {
+		return err
+	}
+	if o.RollbackTimeout == "" {
+		log.Infof("Not waiting for the rollback Pull Request %s to merge as no --rollback-timeout was specified\n", util.ColorInfo(prURL))
+		return nil
+	}
+
+	// the rollback fails unless its Pull Request is merged before the timeout
+	if info != nil && info.PullRequest != nil && info.GitProvider != nil {
+		merged, err = waitForRollbackPullRequest(info.GitProvider, info.PullRequest, timeout, pollTime)
+		if err != nil {
+			log.Warnf("Failed to wait for the rollback Pull Request %s to merge: %s\n", prURL, err)
+		}
+	}
+	if !merged {
+		log.Warnf("The rollback Pull Request %s was not merged within %s\n", prURL, timeout.String())
+	}
+	return o.updateRollbackStep(activities, activity.Name, envName, func(rollback *v1.RollbackActivityStep) {
+		if merged {
+			kube.CompleteActivityStep(&rollback.CoreActivityStep)
+		} else {
+			kube.FailedActivityStep(&rollback.CoreActivityStep)
+		}
+	})
+}
+
+// updateRollbackStep modifies the Rollback step for the environment on the latest version of the activity
+func (o *StepVerifyOptions) updateRollbackStep(activities typev1.PipelineActivityInterface, name string, envName string, fn func(rollback *v1.RollbackActivityStep)) error {
+	latest, err := activities.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the activity with name '%s'", name)
+	}
+	_, rollback, _ := kube.GetOrCreateRollback(latest, envName)
+	fn(rollback)
+	_, err = activities.Update(latest)
+	if err != nil {
+		return errors.Wrap(err, "failed to record the rollback step")
+	}
+	return nil
+}
+
+// waitForRollbackPullRequest polls the Pull Request until it is merged, closed or the timeout expires returning
+// true if it was merged
+func waitForRollbackPullRequest(gitProvider gits.GitProvider, pr *gits.GitPullRequest, timeout time.Duration, pollTime time.Duration) (bool, error) {
+	end := time.Now().Add(timeout)
+	for {
+		err := gitProvider.UpdatePullRequestStatus(pr)
+		if err != nil {
+			return false, err
+		}
+		if pr.Merged != nil && *pr.Merged {
+			return true, nil
+		}
+		if pr.IsClosed() || time.Now().After(end) {
+			return false, nil
+		}
+		time.Sleep(pollTime)
+	}
+}
+
+// findPreviousPromotedVersion returns the version of the newest earlier build of the pipeline which was successfully
+// promoted to the environment or an empty string if there is none
+func findPreviousPromotedVersion(activities typev1.PipelineActivityInterface, activity *v1.PipelineActivity, envName string) (string, error) {
+	list, err := activities.List(metav1.ListOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list the pipeline activities")
+	}
+	answer := ""
+	answerBuild := ""
+	for _, a := range list.Items {
+		if a.Name == activity.Name || a.Spec.Pipeline != activity.Spec.Pipeline || a.Spec.Version == "" || a.Spec.Version == activity.Spec.Version {
+			continue
+		}
+		if !kube.IsResourceVersionNewer(activity.Spec.Build, a.Spec.Build) {
+			continue
+		}
+		promoted := false
+		for _, step := range a.Spec.Steps {
+			if step.Promote != nil && step.Promote.Environment == envName && step.Promote.Status == v1.ActivityStatusTypeSucceeded {
+				promoted = true
+			}
+		}
+		if promoted && (answerBuild == "" || kube.IsResourceVersionNewer(a.Spec.Build, answerBuild)) {
+			answer = a.Spec.Version
+			answerBuild = a.Spec.Build
+		}
+	}
+	return answer, nil
+}
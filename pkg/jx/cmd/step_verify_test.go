@@ -0,0 +1,152 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/gits"
+	"github.com/jenkins-x/jx/pkg/helm"
+	"github.com/jenkins-x/jx/pkg/jx/cmd"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestRollbackPromotion(t *testing.T) {
+	requirements, rollback, repo := runRollbackPromotion(t, false, true, "50ms")
+
+	dep := findDependency(requirements, "myapp")
+	require.NotNil(t, dep)
+	assert.Equal(t, "1.0.1", dep.Version)
+	assert.Equal(t, "http://chartmuseum", dep.Repository)
+
+	assert.Equal(t, "staging", rollback.Environment)
+	assert.Equal(t, "1.0.2", rollback.Version)
+	assert.Equal(t, "1.0.1", rollback.PreviousVersion)
+	assert.Equal(t, string(v1.ActivityStatusTypeFailed), string(rollback.Status), "the rollback should time out")
+	assert.Equal(t, 1, len(repo.PullRequests), "the rollback Pull Request should be open")
+}
+
+func TestRollbackPromotionWithoutTimeout(t *testing.T) {
+	_, rollback, repo := runRollbackPromotion(t, false, true, "")
+
+	assert.Equal(t, "1.0.1", rollback.PreviousVersion)
+	assert.Equal(t, string(v1.ActivityStatusTypeRunning), string(rollback.Status), "the rollback should not wait for the Pull Request")
+	assert.Equal(t, 1, len(repo.PullRequests), "the rollback Pull Request should be open")
+}
+
+func TestRollbackPromotionAutoMerge(t *testing.T) {
+	_, rollback, repo := runRollbackPromotion(t, true, true, "50ms")
+
+	assert.Equal(t, string(v1.ActivityStatusTypeSucceeded), string(rollback.Status))
+	assert.Equal(t, 0, len(repo.PullRequests), "the rollback Pull Request should be merged")
+}
+
+func TestRollbackPromotionWithoutPreviousVersion(t *testing.T) {
+	requirements, rollback, repo := runRollbackPromotion(t, false, false, "50ms")
+
+	dep := findDependency(requirements, "myapp")
+	require.NotNil(t, dep, "the application should not be removed")
+	assert.Equal(t, "1.0.2", dep.Version)
+	assert.Nil(t, rollback)
+	assert.Equal(t, 0, len(repo.PullRequests), "no rollback Pull Request should be created")
+}
+
+func runRollbackPromotion(t *testing.T, autoMerge bool, hasPrevious bool, timeout string) (*helm.Requirements, *v1.RollbackActivityStep, *gits.FakeRepository) {
+	envRepo := gits.NewFakeRepository("myorg", "environment-staging")
+	fakeGitProvider := gits.NewFakeProvider(envRepo)
+
+	requirements := &helm.Requirements{}
+	requirements.SetAppVersion("myapp", "1.0.2", "http://chartmuseum", "")
+	requirements.SetAppVersion("other", "2.0.0", "http://chartmuseum", "")
+
+	o := &cmd.StepVerifyOptions{
+		RollbackAutoMerge: autoMerge,
+		RollbackTimeout:   timeout,
+		RollbackPollTime:  "10ms",
+		FakePullRequests: func(env *v1.Environment, modifyRequirementsFn cmd.ModifyRequirementsFn, branchNameText string, title string, message string, pullRequestInfo *cmd.ReleasePullRequestInfo) (*cmd.ReleasePullRequestInfo, error) {
+			err := modifyRequirementsFn(requirements)
+			if err != nil {
+				return nil, err
+			}
+			pr, err := fakeGitProvider.CreatePullRequest(&gits.GitPullRequestArguments{
+				Title: title,
+				Body:  message,
+				Head:  branchNameText,
+				Base:  "master",
+				GitRepositoryInfo: &gits.GitRepositoryInfo{
+					Organisation: "myorg",
+					Name:         "environment-staging",
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &cmd.ReleasePullRequestInfo{
+				GitProvider: fakeGitProvider,
+				PullRequest: pr,
+			}, nil
+		},
+	}
+
+	staging := kube.NewPermanentEnvironmentWithGit("staging", "https://github.com/myorg/environment-staging.git")
+	previous := createPromotedActivity("1", "1.0.1", v1.ActivityStatusTypeSucceeded)
+	current := createPromotedActivity("2", "1.0.2", v1.ActivityStatusTypeSucceeded)
+	jxObjects := []runtime.Object{staging, current}
+	if hasPrevious {
+		jxObjects = append(jxObjects, previous)
+	}
+	cmd.ConfigureTestOptionsWithResources(&o.CommonOptions, nil, jxObjects, gits.NewGitCLI(), helm.NewHelmCLI("helm", helm.V2, "", true))
+
+	err := o.RollbackPromotion(current)
+	if !hasPrevious {
+		require.Error(t, err)
+		return requirements, nil, envRepo
+	}
+	require.NoError(t, err)
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	require.NoError(t, err)
+	activity, err := jxClient.JenkinsV1().PipelineActivities(ns).Get(current.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	_, rollback, created := kube.GetOrCreateRollback(activity, "staging")
+	require.False(t, created, "the Rollback step should be recorded on the activity")
+	return requirements, rollback, envRepo
+}
+
+func createPromotedActivity(build string, version string, status v1.ActivityStatusType) *v1.PipelineActivity {
+	return &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myorg-myapp-master-" + build,
+			Namespace: "jx",
+		},
+		Spec: v1.PipelineActivitySpec{
+			Pipeline:      "myorg/myapp/master",
+			Build:         build,
+			Version:       version,
+			GitRepository: "myapp",
+			Steps: []v1.PipelineActivityStep{
+				{
+					Kind: v1.ActivityStepKindTypePromote,
+					Promote: &v1.PromoteActivityStep{
+						CoreActivityStep: v1.CoreActivityStep{
+							Status: status,
+						},
+						Environment: "staging",
+					},
+				},
+			},
+		},
+	}
+}
+
+func findDependency(requirements *helm.Requirements, name string) *helm.Dependency {
+	for _, dep := range requirements.Dependencies {
+		if dep != nil && dep.Name == name {
+			return dep
+		}
+	}
+	return nil
+}
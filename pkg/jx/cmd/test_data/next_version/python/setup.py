@@ -0,0 +1,8 @@
+from setuptools import setup, find_packages
+
+setup(
+    name='semver-release-version',
+    version='0.3.1',
+    packages=find_packages(),
+    install_requires=['requests>=2.20.0'],
+)
@@ -40,6 +40,7 @@ type PromoteStepActivityKey struct {
 	ApplicationURL string
 }
 
+type PromoteFn func(*v1.PipelineActivity, *v1.PipelineActivityStep, *v1.PromoteActivityStep) error
 type PromotePullRequestFn func(*v1.PipelineActivity, *v1.PipelineActivityStep, *v1.PromoteActivityStep, *v1.PromotePullRequestStep) error
 type PromoteUpdateFn func(*v1.PipelineActivity, *v1.PipelineActivityStep, *v1.PromoteActivityStep, *v1.PromoteUpdateStep) error
 
@@ -86,10 +87,40 @@ func CreatePipelineDetails(activity *v1.PipelineActivity) *PipelineDetails {
 	}
 }
 
+// PipelineActivityName returns the name of the PipelineActivity of the build of the pipeline which includes the app
+// when building an app of a monorepo
+func PipelineActivityName(pipeline string, app string, build string) string {
+	name := pipeline
+	if app != "" {
+		name += "-" + app
+	}
+	if build != "" {
+		name += "-" + build
+	}
+	return ToValidName(name)
+}
+
+// PipelineActivityApp returns the app of a monorepo included in the name of the PipelineActivity or an empty string
+// if the activity is not for an app of a monorepo
+func PipelineActivityApp(activity *v1.PipelineActivity) string {
+	prefix := PipelineActivityName(activity.Spec.Pipeline, "", "") + "-"
+	suffix := "-" + activity.Spec.Build
+	name := activity.Name
+	if activity.Spec.Pipeline == "" || activity.Spec.Build == "" || len(name) <= len(prefix)+len(suffix) ||
+		!strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix)
+}
+
 // GenerateBuildNumber generates a new build number for the given pipeline
 func GenerateBuildNumber(activities typev1.PipelineActivityInterface, owner string, repository string, branch string) (string, *v1.PipelineActivity, error) {
+	return GenerateAppBuildNumber(activities, owner, repository, branch, "")
+}
+
+// GenerateAppBuildNumber generates a new build number for the given pipeline and app of a monorepo
+func GenerateAppBuildNumber(activities typev1.PipelineActivityInterface, owner string, repository string, branch string, app string) (string, *v1.PipelineActivity, error) {
 	pipelineName := owner + "/" + repository + "/" + branch
-	namePrefix := owner + "-" + repository + "-" + branch
 
 	attempts := 100
 	for i := 0; i < attempts; i++ {
@@ -115,7 +146,7 @@ func GenerateBuildNumber(activities typev1.PipelineActivityInterface, owner stri
 		}
 		buildCounter++
 		build := strconv.Itoa(buildCounter)
-		name := namePrefix + "-" + build
+		name := PipelineActivityName(pipelineName, app, build)
 
 		k := &PipelineActivityKey{
 			Name:     name,
@@ -289,6 +320,95 @@ func GetOrCreateStage(a *v1.PipelineActivity, stageName string) (*v1.PipelineAct
 	return &spec.Steps[len(spec.Steps)-1], stage, true
 }
 
+// GetOrCreateApproval gets or creates the Approval step with the given name
+func GetOrCreateApproval(a *v1.PipelineActivity, name string) (*v1.PipelineActivityStep, *v1.ApprovalActivityStep, bool) {
+	spec := &a.Spec
+	for i := range spec.Steps {
+		approval := spec.Steps[i].Approval
+		if approval != nil && approval.Name == name {
+			return &spec.Steps[i], approval, false
+		}
+	}
+
+	approval := &v1.ApprovalActivityStep{
+		CoreActivityStep: v1.CoreActivityStep{
+			Name: name,
+		},
+	}
+	spec.Steps = append(spec.Steps, v1.PipelineActivityStep{
+		Kind:     v1.ActivityStepKindTypeApproval,
+		Approval: approval,
+	})
+	return &spec.Steps[len(spec.Steps)-1], approval, true
+}
+
+// GetOrCreateWait gets or creates the Wait step with the given name
+func GetOrCreateWait(a *v1.PipelineActivity, name string) (*v1.PipelineActivityStep, *v1.WaitActivityStep, bool) {
+	spec := &a.Spec
+	for i := range spec.Steps {
+		wait := spec.Steps[i].Wait
+		if wait != nil && wait.Name == name {
+			return &spec.Steps[i], wait, false
+		}
+	}
+
+	wait := &v1.WaitActivityStep{
+		CoreActivityStep: v1.CoreActivityStep{
+			Name: name,
+		},
+	}
+	spec.Steps = append(spec.Steps, v1.PipelineActivityStep{
+		Kind: v1.ActivityStepKindTypeWait,
+		Wait: wait,
+	})
+	return &spec.Steps[len(spec.Steps)-1], wait, true
+}
+
+// GetOrCreateJob gets or creates the Job step with the given name
+func GetOrCreateJob(a *v1.PipelineActivity, name string) (*v1.PipelineActivityStep, *v1.JobActivityStep, bool) {
+	spec := &a.Spec
+	for i := range spec.Steps {
+		job := spec.Steps[i].Job
+		if job != nil && job.Name == name {
+			return &spec.Steps[i], job, false
+		}
+	}
+
+	job := &v1.JobActivityStep{
+		CoreActivityStep: v1.CoreActivityStep{
+			Name: name,
+		},
+	}
+	spec.Steps = append(spec.Steps, v1.PipelineActivityStep{
+		Kind: v1.ActivityStepKindTypeJob,
+		Job:  job,
+	})
+	return &spec.Steps[len(spec.Steps)-1], job, true
+}
+
+// GetOrCreateRollback gets or creates the Rollback step for the given environment
+func GetOrCreateRollback(a *v1.PipelineActivity, envName string) (*v1.PipelineActivityStep, *v1.RollbackActivityStep, bool) {
+	spec := &a.Spec
+	for i := range spec.Steps {
+		rollback := spec.Steps[i].Rollback
+		if rollback != nil && rollback.Environment == envName {
+			return &spec.Steps[i], rollback, false
+		}
+	}
+
+	rollback := &v1.RollbackActivityStep{
+		CoreActivityStep: v1.CoreActivityStep{
+			Name: envName,
+		},
+		Environment: envName,
+	}
+	spec.Steps = append(spec.Steps, v1.PipelineActivityStep{
+		Kind:     v1.ActivityStepKindTypeRollback,
+		Rollback: rollback,
+	})
+	return &spec.Steps[len(spec.Steps)-1], rollback, true
+}
+
 // GetOrCreatePromote gets or creates the Promote step for the key
 func (k *PromoteStepActivityKey) GetOrCreatePromote(activities typev1.PipelineActivityInterface) (*v1.PipelineActivity, *v1.PipelineActivityStep, *v1.PromoteActivityStep, bool, error) {
 	a, _, err := k.GetOrCreate(activities)
@@ -384,6 +504,32 @@ func (k *PromoteStepActivityKey) GetOrCreatePromoteUpdate(activities typev1.Pipe
 	return a, s, p, p.Update, created, err
 }
 
+// OnPromote applies the function to the Promote step for the key and saves any changes
+func (k *PromoteStepActivityKey) OnPromote(activities typev1.PipelineActivityInterface, fn PromoteFn) error {
+	if !k.IsValid() {
+		return nil
+	}
+	if activities == nil {
+		log.Warn("Warning: no PipelineActivities client available!")
+		return nil
+	}
+	a, s, ps, added, err := k.GetOrCreatePromote(activities)
+	if err != nil {
+		return err
+	}
+	p1 := asYaml(a)
+	err = fn(a, s, ps)
+	if err != nil {
+		return err
+	}
+	p2 := asYaml(a)
+
+	if added || p1 == "" || p1 != p2 {
+		_, err = activities.Update(a)
+	}
+	return err
+}
+
 func (k *PromoteStepActivityKey) OnPromotePullRequest(activities typev1.PipelineActivityInterface, fn PromotePullRequestFn) error {
 	if !k.IsValid() {
 		return nil
@@ -16,6 +16,11 @@ func StartPromote(p *v1.PromoteActivityStep) error {
 	if p.Status == v1.ActivityStatusTypeNone {
 		p.Status = v1.ActivityStatusTypeRunning
 	}
+	if p.Status == v1.ActivityStatusTypePending {
+		// the promotion was queued waiting for a deployment window
+		p.Status = v1.ActivityStatusTypeRunning
+		p.Description = ""
+	}
 	return nil
 }
 
@@ -152,3 +157,42 @@ func FailedPromotionUpdate(a *v1.PipelineActivity, s *v1.PipelineActivityStep, p
 	p.Status = v1.ActivityStatusTypeFailed
 	return nil
 }
+
+// StartActivityStep marks the step as running if it has not started yet
+func StartActivityStep(s *v1.CoreActivityStep) error {
+	if s.StartedTimestamp == nil {
+		s.StartedTimestamp = &metav1.Time{
+			Time: time.Now(),
+		}
+	}
+	if s.Status == v1.ActivityStatusTypeNone || s.Status == v1.ActivityStatusTypePending {
+		s.Status = v1.ActivityStatusTypeRunning
+	}
+	return nil
+}
+
+// CompleteActivityStep marks the step as succeeded
+func CompleteActivityStep(s *v1.CoreActivityStep) error {
+	return completeActivityStep(s, v1.ActivityStatusTypeSucceeded)
+}
+
+// FailedActivityStep marks the step as failed
+func FailedActivityStep(s *v1.CoreActivityStep) error {
+	return completeActivityStep(s, v1.ActivityStatusTypeFailed)
+}
+
+func completeActivityStep(s *v1.CoreActivityStep, status v1.ActivityStatusType) error {
+	StartActivityStep(s)
+	if s.CompletedTimestamp == nil {
+		s.CompletedTimestamp = &metav1.Time{
+			Time: time.Now(),
+		}
+	}
+	s.Status = status
+	return nil
+}
+
+// AbortActivityStep marks the step as aborted
+func AbortActivityStep(s *v1.CoreActivityStep) error {
+	return completeActivityStep(s, v1.ActivityStatusTypeAborted)
+}
@@ -95,6 +95,31 @@ func TestGenerateBuildNumber(t *testing.T) {
 	assert.Equal(t, expected, results, "generated build numbers")
 }
 
+func TestPipelineActivityName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "jstrachan-cheese-master-3", kube.PipelineActivityName("jstrachan/cheese/master", "", "3"))
+	assert.Equal(t, "jstrachan-cheese-master-frontend-3", kube.PipelineActivityName("jstrachan/cheese/master", "frontend", "3"))
+	assert.Equal(t, "jstrachan-cheese-master", kube.PipelineActivityName("JStrachan/cheese/master", "", ""))
+}
+
+func TestPipelineActivityApp(t *testing.T) {
+	t.Parallel()
+	for name, expected := range map[string]string{
+		"jstrachan-cheese-master-frontend-3": "frontend",
+		"jstrachan-cheese-master-3":          "",
+		"jstrachan-cheese-master-33":         "",
+	} {
+		activity := &v1.PipelineActivity{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.PipelineActivitySpec{
+				Pipeline: "jstrachan/cheese/master",
+				Build:    "3",
+			},
+		}
+		assert.Equal(t, expected, kube.PipelineActivityApp(activity), "activity %s", name)
+	}
+}
+
 func TestCreateOrUpdateActivities(t *testing.T) {
 	t.Parallel()
 	activities := &MockPipelineActivityInterface{
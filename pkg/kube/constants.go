@@ -194,6 +194,9 @@ const (
 	// ValueJobKindPostPreview
 	ValueJobKindPostPreview = "post-preview-step"
 
+	// ValueJobKindPreviewSeed for Jobs which seed the dependencies of a preview environment
+	ValueJobKindPreviewSeed = "preview-seed"
+
 	// AnnotationURL indicates a service/server's URL
 	AnnotationURL = "jenkins.io/url"
 
@@ -214,6 +217,35 @@ const (
 	// AnnotationLocalDir the local directory that is sync'd to the DevPod
 	AnnotationLocalDir = "jenkins.io/local-dir"
 
+	// AnnotationBrokenBuildNotifications set to false on a User to opt out of direct messages about broken release builds
+	AnnotationBrokenBuildNotifications = "jenkins.io/broken-build-notifications"
+	// AnnotationNotifiedUsers the comma separated users which have been sent a direct message about a failed PipelineActivity.
+	// It is added, possibly empty, once the failed PipelineActivity has been processed
+	AnnotationNotifiedUsers = "jenkins.io/notified-users"
+
+	// AnnotationChatThread the ID of the chat thread of the notifications about a PipelineActivity or preview Environment
+	AnnotationChatThread = "jenkins.io/chat-thread"
+
+	// AnnotationPreviewLastDeployed the RFC3339 time a preview Environment was last deployed
+	AnnotationPreviewLastDeployed = "jenkins.io/preview-last-deployed"
+	// AnnotationPreviewLastWoken the RFC3339 time a hibernated preview Environment was last woken up
+	AnnotationPreviewLastWoken = "jenkins.io/preview-last-woken"
+	// AnnotationPreviewLastTraffic the RFC3339 time new ingress requests to a preview Environment were last seen
+	AnnotationPreviewLastTraffic = "jenkins.io/preview-last-traffic"
+	// AnnotationPreviewIngressRequests the number of ingress requests to the namespace of a preview Environment counted by
+	// the ingress controller when its traffic was last checked
+	AnnotationPreviewIngressRequests = "jenkins.io/preview-ingress-requests"
+	// AnnotationPreviewTimeToLive the duration after its last deployment when a preview Environment expires
+	AnnotationPreviewTimeToLive = "jenkins.io/preview-ttl"
+	// AnnotationPreviewIdleTimeout the duration without deployments, ingress requests or wake ups after which a preview Environment expires
+	AnnotationPreviewIdleTimeout = "jenkins.io/preview-idle-timeout"
+	// AnnotationPreviewHibernateAfter the duration without deployments, ingress requests or wake ups after which a preview Environment is scaled to zero
+	AnnotationPreviewHibernateAfter = "jenkins.io/preview-hibernate-after"
+	// AnnotationHibernatedReplicas the number of replicas of a Deployment before its preview Environment was scaled to zero
+	AnnotationHibernatedReplicas = "jenkins.io/hibernated-replicas"
+	// AnnotationPreviewDependencies the comma separated helm releases of the dependencies installed alongside a preview Environment
+	AnnotationPreviewDependencies = "jenkins.io/preview-dependencies"
+
 	// AnnotationIsDefaultStorageClass used to indicate a storageclass is default
 	AnnotationIsDefaultStorageClass = "storageclass.kubernetes.io/is-default-class"
 
@@ -0,0 +1,115 @@
+package kube
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+	"gopkg.in/robfig/cron.v2"
+)
+
+// maxDeploymentWindowSearches bounds how many windows and freezes are skipped when looking for the next window
+const maxDeploymentWindowSearches = 1000
+
+// HasDeploymentWindows returns true if promotions into the environment are restricted by deployment windows or freezes
+func HasDeploymentWindows(env *v1.Environment) bool {
+	return env != nil && (len(env.Spec.DeploymentWindows) > 0 || len(env.Spec.Freezes) > 0)
+}
+
+// IsInDeploymentWindow returns true if promotions into the environment are allowed at the given time
+func IsInDeploymentWindow(env *v1.Environment, t time.Time) (bool, error) {
+	next, err := NextDeploymentWindow(env, t)
+	if err != nil {
+		return false, err
+	}
+	return !next.After(t), nil
+}
+
+// NextDeploymentWindow returns the earliest time at or after the given time when one of the deployment windows
+// of the environment is open and none of its freezes are in effect
+func NextDeploymentWindow(env *v1.Environment, t time.Time) (time.Time, error) {
+	if !HasDeploymentWindows(env) {
+		return t, nil
+	}
+	windows := env.Spec.DeploymentWindows
+	freezes := env.Spec.Freezes
+
+	candidate := t
+	for i := 0; i < maxDeploymentWindowSearches; i++ {
+		frozenUntil := time.Time{}
+		for _, freeze := range freezes {
+			end, _, err := deploymentWindowEnd(&freeze, candidate)
+			if err != nil {
+				return t, errors.Wrapf(err, "invalid freeze %s on Environment %s", freeze.Name, env.Name)
+			}
+			if end.After(frozenUntil) {
+				frozenUntil = end
+			}
+		}
+		if !frozenUntil.IsZero() {
+			candidate = frozenUntil
+			continue
+		}
+		if len(windows) == 0 {
+			return candidate, nil
+		}
+
+		nextStart := time.Time{}
+		for _, window := range windows {
+			end, start, err := deploymentWindowEnd(&window, candidate)
+			if err != nil {
+				return t, errors.Wrapf(err, "invalid deployment window %s on Environment %s", window.Name, env.Name)
+			}
+			if !end.IsZero() {
+				return candidate, nil
+			}
+			if !start.IsZero() && (nextStart.IsZero() || start.Before(nextStart)) {
+				nextStart = start
+			}
+		}
+		if nextStart.IsZero() {
+			return t, fmt.Errorf("the deployment windows of Environment %s never open", env.Name)
+		}
+		candidate = nextStart
+	}
+	return t, fmt.Errorf("could not find a deployment window of Environment %s which is not frozen", env.Name)
+}
+
+// deploymentWindowEnd returns when the window ends if it is open at the given time or the zero time if it is not.
+// The start of the next window after the given time is also returned
+func deploymentWindowEnd(window *v1.DeploymentWindow, t time.Time) (time.Time, time.Time, error) {
+	schedule, duration, err := parseDeploymentWindow(window)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	// the first start after t - duration is the only window which could still be open at t
+	start := schedule.Next(t.Add(-duration))
+	if !start.IsZero() && !start.After(t) {
+		return start.Add(duration), schedule.Next(t), nil
+	}
+	return time.Time{}, start, nil
+}
+
+func parseDeploymentWindow(window *v1.DeploymentWindow) (cron.Schedule, time.Duration, error) {
+	timeZone := window.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	_, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "invalid time zone %s", timeZone)
+	}
+	schedule, err := cron.Parse("TZ=" + timeZone + " " + window.Schedule)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "invalid schedule %s", window.Schedule)
+	}
+	duration, err := time.ParseDuration(window.Duration)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "invalid duration %s", window.Duration)
+	}
+	if duration <= 0 {
+		return nil, 0, fmt.Errorf("the duration %s must be positive", window.Duration)
+	}
+	return schedule, duration, nil
+}
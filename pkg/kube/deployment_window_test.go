@@ -0,0 +1,118 @@
+package kube_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextDeploymentWindow(t *testing.T) {
+	t.Parallel()
+	env := kube.NewPermanentEnvironment("production")
+	env.Spec.DeploymentWindows = []v1.DeploymentWindow{
+		{
+			Name:     "working-hours",
+			Schedule: "0 9 * * 1-4",
+			Duration: "8h",
+			TimeZone: "Europe/London",
+		},
+	}
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	// Wednesday lunchtime is inside the window
+	wednesday := time.Date(2018, time.November, 14, 12, 30, 0, 0, london)
+	assertDeploymentWindow(t, env, wednesday, wednesday)
+
+	// Wednesday evening waits for Thursday morning
+	assertDeploymentWindow(t, env, time.Date(2018, time.November, 14, 18, 0, 0, 0, london), time.Date(2018, time.November, 15, 9, 0, 0, 0, london))
+
+	// Friday waits for Monday morning
+	assertDeploymentWindow(t, env, time.Date(2018, time.November, 16, 10, 0, 0, 0, london), time.Date(2018, time.November, 19, 9, 0, 0, 0, london))
+
+	// the window is evaluated in its own time zone
+	assertDeploymentWindow(t, env, time.Date(2018, time.November, 14, 8, 30, 0, 0, time.UTC), time.Date(2018, time.November, 14, 9, 0, 0, 0, london))
+}
+
+func TestNextDeploymentWindowWithFreeze(t *testing.T) {
+	t.Parallel()
+	env := kube.NewPermanentEnvironment("production")
+	env.Spec.DeploymentWindows = []v1.DeploymentWindow{
+		{
+			Name:     "working-hours",
+			Schedule: "0 9 * * 1-5",
+			Duration: "8h",
+		},
+	}
+	env.Spec.Freezes = []v1.DeploymentWindow{
+		{
+			Name:     "christmas",
+			Schedule: "0 0 20 12 *",
+			Duration: "336h",
+		},
+	}
+
+	// the freeze ends on the 3rd of January which is a Thursday
+	assertDeploymentWindow(t, env, time.Date(2018, time.December, 21, 10, 0, 0, 0, time.UTC), time.Date(2019, time.January, 3, 9, 0, 0, 0, time.UTC))
+
+	// outside of the freeze the windows apply as usual
+	monday := time.Date(2018, time.December, 17, 10, 0, 0, 0, time.UTC)
+	assertDeploymentWindow(t, env, monday, monday)
+}
+
+func TestNextDeploymentWindowFreezeOnly(t *testing.T) {
+	t.Parallel()
+	env := kube.NewPermanentEnvironment("production")
+	env.Spec.Freezes = []v1.DeploymentWindow{
+		{
+			Name:     "weekend",
+			Schedule: "0 17 * * 5",
+			Duration: "64h",
+		},
+	}
+
+	wednesday := time.Date(2018, time.November, 14, 12, 30, 0, 0, time.UTC)
+	assertDeploymentWindow(t, env, wednesday, wednesday)
+	assertDeploymentWindow(t, env, time.Date(2018, time.November, 17, 12, 0, 0, 0, time.UTC), time.Date(2018, time.November, 19, 9, 0, 0, 0, time.UTC))
+}
+
+func TestNextDeploymentWindowInvalid(t *testing.T) {
+	t.Parallel()
+	env := kube.NewPermanentEnvironment("production")
+	env.Spec.DeploymentWindows = []v1.DeploymentWindow{
+		{
+			Name:     "broken",
+			Schedule: "0 9 * *",
+			Duration: "8h",
+		},
+	}
+	_, err := kube.NextDeploymentWindow(env, time.Now())
+	assert.Error(t, err)
+
+	env.Spec.DeploymentWindows[0].Schedule = "0 9 * * *"
+	env.Spec.DeploymentWindows[0].TimeZone = "Nowhere/Special"
+	_, err = kube.NextDeploymentWindow(env, time.Now())
+	assert.Error(t, err)
+}
+
+func TestNextDeploymentWindowWithoutWindows(t *testing.T) {
+	t.Parallel()
+	env := kube.NewPermanentEnvironment("staging")
+	now := time.Now()
+	assertDeploymentWindow(t, env, now, now)
+	assert.False(t, kube.HasDeploymentWindows(env))
+}
+
+func assertDeploymentWindow(t *testing.T, env *v1.Environment, now time.Time, expected time.Time) {
+	next, err := kube.NextDeploymentWindow(env, now)
+	require.NoError(t, err)
+	assert.True(t, expected.Equal(next), "expected next deployment window at %s but was %s for %s", expected, next, now)
+
+	open, err := kube.IsInDeploymentWindow(env, now)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Equal(now), open, "deployment window open at %s", now)
+}
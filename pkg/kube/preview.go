@@ -0,0 +1,302 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ExpiredPreview is a preview Environment which should be deleted along with the reason why
+type ExpiredPreview struct {
+	Environment *v1.Environment
+	Reason      string
+}
+
+// GetPreviewLastDeployed returns the time the preview Environment was last deployed or when it was created
+func GetPreviewLastDeployed(env *v1.Environment) time.Time {
+	answer := env.CreationTimestamp.Time
+	t := getAnnotationTime(env, AnnotationPreviewLastDeployed)
+	if t.After(answer) {
+		answer = t
+	}
+	return answer
+}
+
+// GetPreviewLastActive returns the time the preview Environment was last deployed, received ingress requests or was
+// woken up
+func GetPreviewLastActive(env *v1.Environment) time.Time {
+	answer := GetPreviewLastDeployed(env)
+	for _, name := range []string{AnnotationPreviewLastTraffic, AnnotationPreviewLastWoken} {
+		t := getAnnotationTime(env, name)
+		if t.After(answer) {
+			answer = t
+		}
+	}
+	return answer
+}
+
+// SetPreviewLastDeployed records the time the preview Environment was last deployed
+func SetPreviewLastDeployed(env *v1.Environment, t time.Time) {
+	setAnnotation(env, AnnotationPreviewLastDeployed, t.UTC().Format(time.RFC3339))
+}
+
+// SetPreviewLastWoken records the time the preview Environment was woken up so that it is not hibernated again straight away
+func SetPreviewLastWoken(env *v1.Environment, t time.Time) {
+	setAnnotation(env, AnnotationPreviewLastWoken, t.UTC().Format(time.RFC3339))
+}
+
+// SetPreviewExpiry records the time to live and idle timeout of the preview Environment so that they can be
+// enforced when garbage collecting previews. Empty values remove any previous expiry
+func SetPreviewExpiry(env *v1.Environment, ttl string, idleTimeout string) {
+	setAnnotation(env, AnnotationPreviewTimeToLive, ttl)
+	setAnnotation(env, AnnotationPreviewIdleTimeout, idleTimeout)
+}
+
+// GetPreviewExpiry returns the time to live and idle timeout of the preview Environment defaulting to the
+// given durations if the preview does not specify them
+func GetPreviewExpiry(env *v1.Environment, defaultTTL time.Duration, defaultIdleTimeout time.Duration) (time.Duration, time.Duration, error) {
+	ttl, err := getAnnotationDuration(env, AnnotationPreviewTimeToLive, defaultTTL)
+	if err != nil {
+		return ttl, defaultIdleTimeout, err
+	}
+	idleTimeout, err := getAnnotationDuration(env, AnnotationPreviewIdleTimeout, defaultIdleTimeout)
+	return ttl, idleTimeout, err
+}
+
+// SetPreviewHibernateAfter records the idle duration after which the preview Environment is scaled to zero.
+// An empty value disables hibernation of the preview
+func SetPreviewHibernateAfter(env *v1.Environment, hibernateAfter string) {
+	setAnnotation(env, AnnotationPreviewHibernateAfter, hibernateAfter)
+}
+
+// FindPreviewsToHibernate returns the previews which are not hibernated and have had no deployments, ingress requests
+// or wake ups for longer than their hibernate after duration at the given time
+func FindPreviewsToHibernate(previews []v1.Environment, defaultHibernateAfter time.Duration, now time.Time) ([]v1.Environment, error) {
+	answer := []v1.Environment{}
+	for i := range previews {
+		env := &previews[i]
+		if env.Spec.PreviewGitSpec.Hibernated {
+			continue
+		}
+		hibernateAfter, err := getAnnotationDuration(env, AnnotationPreviewHibernateAfter, defaultHibernateAfter)
+		if err != nil {
+			return answer, err
+		}
+		if hibernateAfter > 0 && now.Sub(GetPreviewLastActive(env)) > hibernateAfter {
+			answer = append(answer, *env)
+		}
+	}
+	return answer, nil
+}
+
+// HibernatePreview scales all the deployments in the namespace of the preview Environment to zero remembering their
+// replicas so that they can be restored by WakePreview. The caller must update the Environment
+func HibernatePreview(kubeClient kubernetes.Interface, env *v1.Environment) error {
+	ns := env.Spec.Namespace
+	deployments := kubeClient.AppsV1beta1().Deployments(ns)
+	list, err := deployments.List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the deployments in namespace %s", ns)
+	}
+	for _, d := range list.Items {
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		if replicas == 0 {
+			continue
+		}
+		if d.Annotations == nil {
+			d.Annotations = map[string]string{}
+		}
+		d.Annotations[AnnotationHibernatedReplicas] = strconv.Itoa(int(replicas))
+		zero := int32(0)
+		d.Spec.Replicas = &zero
+		_, err = deployments.Update(&d)
+		if err != nil {
+			return errors.Wrapf(err, "failed to scale deployment %s in namespace %s to zero", d.Name, ns)
+		}
+	}
+	env.Spec.PreviewGitSpec.Hibernated = true
+	return nil
+}
+
+// WakePreview restores the replicas of the deployments in the namespace of the preview Environment which were scaled
+// to zero by HibernatePreview. Waking a preview counts as activity so it resets its idle time. The caller must update
+// the Environment
+func WakePreview(kubeClient kubernetes.Interface, env *v1.Environment, now time.Time) error {
+	ns := env.Spec.Namespace
+	deployments := kubeClient.AppsV1beta1().Deployments(ns)
+	list, err := deployments.List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the deployments in namespace %s", ns)
+	}
+	for _, d := range list.Items {
+		value := d.Annotations[AnnotationHibernatedReplicas]
+		if value == "" {
+			continue
+		}
+		replicas, err := strconv.Atoi(value)
+		if err != nil || replicas <= 0 {
+			replicas = 1
+		}
+		r := int32(replicas)
+		d.Spec.Replicas = &r
+		delete(d.Annotations, AnnotationHibernatedReplicas)
+		_, err = deployments.Update(&d)
+		if err != nil {
+			return errors.Wrapf(err, "failed to scale deployment %s in namespace %s to %d", d.Name, ns, replicas)
+		}
+	}
+	env.Spec.PreviewGitSpec.Hibernated = false
+	SetPreviewLastWoken(env, now)
+	return nil
+}
+
+// PreviewApplicationURLStatus returns the application URL of the preview Environment along with whether it is
+// hibernated as the URL does not respond until the preview is woken up
+func PreviewApplicationURLStatus(env *v1.Environment) string {
+	answer := env.Spec.PreviewGitSpec.ApplicationURL
+	if env.Spec.PreviewGitSpec.Hibernated {
+		if answer == "" {
+			return "hibernated"
+		}
+		answer += " (hibernated)"
+	}
+	return answer
+}
+
+// SetPreviewDependencies records the helm releases of the dependencies installed alongside the preview Environment
+// so that they can be deleted with the preview
+func SetPreviewDependencies(env *v1.Environment, releases []string) {
+	setAnnotation(env, AnnotationPreviewDependencies, strings.Join(releases, ","))
+}
+
+// GetPreviewDependencies returns the helm releases of the dependencies installed alongside the preview Environment
+func GetPreviewDependencies(env *v1.Environment) []string {
+	answer := []string{}
+	if env.Annotations == nil {
+		return answer
+	}
+	for _, release := range strings.Split(env.Annotations[AnnotationPreviewDependencies], ",") {
+		release = strings.TrimSpace(release)
+		if release != "" {
+			answer = append(answer, release)
+		}
+	}
+	return answer
+}
+
+// GetRepositoryPreviews returns the preview environments created from the given git source URL
+func GetRepositoryPreviews(envs []v1.Environment, sourceURL string) []v1.Environment {
+	answer := []v1.Environment{}
+	for _, env := range envs {
+		if env.Spec.Kind == v1.EnvironmentKindTypePreview && env.Spec.Source.URL == sourceURL {
+			answer = append(answer, env)
+		}
+	}
+	return answer
+}
+
+// SortPreviewsByLastActive sorts the preview environments so that the least recently active come first
+func SortPreviewsByLastActive(previews []v1.Environment) {
+	sort.SliceStable(previews, func(i, j int) bool {
+		return GetPreviewLastActive(&previews[i]).Before(GetPreviewLastActive(&previews[j]))
+	})
+}
+
+// PreviewsToEvict returns the least recently active previews which need to be deleted so that another preview
+// can be created without exceeding the maximum number of instances. The preview with the name to keep is never evicted
+func PreviewsToEvict(previews []v1.Environment, maximumInstances int, keep string) []v1.Environment {
+	answer := []v1.Environment{}
+	if maximumInstances <= 0 {
+		return answer
+	}
+	others := []v1.Environment{}
+	for _, env := range previews {
+		if env.Name != keep {
+			others = append(others, env)
+		}
+	}
+	count := len(others) + 1 - maximumInstances
+	if count <= 0 {
+		return answer
+	}
+	SortPreviewsByLastActive(others)
+	return append(answer, others[0:count]...)
+}
+
+// FindExpiredPreviews returns the previews which have exceeded their time to live since their last deployment or
+// have been idle for longer than their idle timeout at the given time
+func FindExpiredPreviews(previews []v1.Environment, defaultTTL time.Duration, defaultIdleTimeout time.Duration, now time.Time) ([]ExpiredPreview, error) {
+	answer := []ExpiredPreview{}
+	for i := range previews {
+		env := &previews[i]
+		ttl, idleTimeout, err := GetPreviewExpiry(env, defaultTTL, defaultIdleTimeout)
+		if err != nil {
+			return answer, err
+		}
+		lastDeployed := GetPreviewLastDeployed(env)
+		if ttl > 0 && now.Sub(lastDeployed) > ttl {
+			answer = append(answer, ExpiredPreview{
+				Environment: env,
+				Reason:      fmt.Sprintf("last deployed %s ago which exceeds the time to live of %s", roundDuration(now.Sub(lastDeployed)), ttl),
+			})
+			continue
+		}
+		lastActive := GetPreviewLastActive(env)
+		if idleTimeout > 0 && now.Sub(lastActive) > idleTimeout {
+			answer = append(answer, ExpiredPreview{
+				Environment: env,
+				Reason:      fmt.Sprintf("idle for %s which exceeds the idle timeout of %s", roundDuration(now.Sub(lastActive)), idleTimeout),
+			})
+		}
+	}
+	return answer, nil
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	return d - d%time.Minute
+}
+
+func getAnnotationTime(env *v1.Environment, name string) time.Time {
+	if env.Annotations == nil || env.Annotations[name] == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, env.Annotations[name])
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func getAnnotationDuration(env *v1.Environment, name string, defaultValue time.Duration) (time.Duration, error) {
+	if env.Annotations == nil || env.Annotations[name] == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(env.Annotations[name])
+	if err != nil {
+		return defaultValue, errors.Wrapf(err, "invalid annotation %s on Environment %s", name, env.Name)
+	}
+	return d, nil
+}
+
+func setAnnotation(env *v1.Environment, name string, value string) {
+	if value == "" {
+		if env.Annotations != nil {
+			delete(env.Annotations, name)
+		}
+		return
+	}
+	if env.Annotations == nil {
+		env.Annotations = map[string]string{}
+	}
+	env.Annotations[name] = value
+}
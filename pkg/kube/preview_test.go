@@ -0,0 +1,158 @@
+package kube_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/apps/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestPreview(name string, sourceURL string, created time.Time) v1.Environment {
+	env := kube.NewPreviewEnvironment(name)
+	env.Spec.Source.URL = sourceURL
+	env.CreationTimestamp = metav1.NewTime(created)
+	return *env
+}
+
+func TestPreviewsToEvict(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2018, time.November, 14, 12, 0, 0, 0, time.UTC)
+	repo := "https://github.com/jenkins-x/cheese.git"
+
+	pr1 := newTestPreview("pr-1", repo, now.Add(-72*time.Hour))
+	pr2 := newTestPreview("pr-2", repo, now.Add(-48*time.Hour))
+	pr3 := newTestPreview("pr-3", repo, now.Add(-96*time.Hour))
+	other := newTestPreview("other-pr-1", "https://github.com/jenkins-x/wine.git", now.Add(-200*time.Hour))
+
+	// pr-1 was redeployed recently and pr-3 was woken up recently
+	kube.SetPreviewLastDeployed(&pr1, now.Add(-1*time.Hour))
+	kube.SetPreviewLastWoken(&pr3, now.Add(-2*time.Hour))
+
+	previews := kube.GetRepositoryPreviews([]v1.Environment{pr1, pr2, pr3, other}, repo)
+	assertPreviewNames(t, previews, "pr-1", "pr-2", "pr-3")
+
+	assertPreviewNames(t, kube.PreviewsToEvict(previews, 0, "pr-4"))
+	assertPreviewNames(t, kube.PreviewsToEvict(previews, 4, "pr-4"))
+	assertPreviewNames(t, kube.PreviewsToEvict(previews, 3, "pr-4"), "pr-2")
+	assertPreviewNames(t, kube.PreviewsToEvict(previews, 2, "pr-4"), "pr-2", "pr-3")
+	assertPreviewNames(t, kube.PreviewsToEvict(previews, 1, "pr-2"), "pr-3", "pr-1")
+}
+
+func TestFindExpiredPreviews(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2018, time.November, 14, 12, 0, 0, 0, time.UTC)
+	repo := "https://github.com/jenkins-x/cheese.git"
+
+	old := newTestPreview("old", repo, now.Add(-100*time.Hour))
+	idle := newTestPreview("idle", repo, now.Add(-30*time.Hour))
+	busy := newTestPreview("busy", repo, now.Add(-30*time.Hour))
+	kube.SetPreviewLastWoken(&busy, now.Add(-10*time.Minute))
+	custom := newTestPreview("custom", repo, now.Add(-30*time.Hour))
+	kube.SetPreviewExpiry(&custom, "", "48h")
+
+	previews := []v1.Environment{old, idle, busy, custom}
+	expired, err := kube.FindExpiredPreviews(previews, 72*time.Hour, 24*time.Hour, now)
+	require.NoError(t, err)
+	names := []string{}
+	for _, e := range expired {
+		names = append(names, e.Environment.Name)
+	}
+	assert.Equal(t, []string{"old", "idle"}, names)
+	assert.Equal(t, "last deployed 100h0m0s ago which exceeds the time to live of 72h0m0s", expired[0].Reason)
+	assert.Equal(t, "idle for 30h0m0s which exceeds the idle timeout of 24h0m0s", expired[1].Reason)
+
+	expired, err = kube.FindExpiredPreviews(previews, 0, 0, now)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+
+	kube.SetPreviewExpiry(&previews[0], "a week", "")
+	_, err = kube.FindExpiredPreviews(previews, 0, 0, now)
+	assert.Error(t, err)
+}
+
+func TestHibernateAndWakePreview(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2018, time.November, 14, 12, 0, 0, 0, time.UTC)
+	repo := "https://github.com/jenkins-x/cheese.git"
+
+	idle := newTestPreview("idle", repo, now.Add(-3*time.Hour))
+	busy := newTestPreview("busy", repo, now.Add(-3*time.Hour))
+	kube.SetPreviewLastWoken(&busy, now.Add(-10*time.Minute))
+	disabled := newTestPreview("disabled", repo, now.Add(-3*time.Hour))
+	kube.SetPreviewHibernateAfter(&disabled, "0s")
+	hibernated := newTestPreview("hibernated", repo, now.Add(-3*time.Hour))
+	hibernated.Spec.PreviewGitSpec.Hibernated = true
+
+	previews, err := kube.FindPreviewsToHibernate([]v1.Environment{idle, busy, disabled, hibernated}, 2*time.Hour, now)
+	require.NoError(t, err)
+	assertPreviewNames(t, previews, "idle")
+
+	ns := idle.Spec.Namespace
+	three := int32(3)
+	kubeClient := fake.NewSimpleClientset(
+		&v1beta1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cheese", Namespace: ns},
+			Spec:       v1beta1.DeploymentSpec{Replicas: &three},
+		},
+		&v1beta1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: ns},
+		},
+	)
+
+	idle.Spec.PreviewGitSpec.ApplicationURL = "http://cheese.jx-pr-1.example.com"
+	err = kube.HibernatePreview(kubeClient, &idle)
+	require.NoError(t, err)
+	assert.True(t, idle.Spec.PreviewGitSpec.Hibernated)
+	assert.Equal(t, "http://cheese.jx-pr-1.example.com (hibernated)", kube.PreviewApplicationURLStatus(&idle))
+	assertDeploymentReplicas(t, kubeClient, ns, "cheese", 0)
+	assertDeploymentReplicas(t, kubeClient, ns, "db", 0)
+
+	err = kube.WakePreview(kubeClient, &idle, now)
+	require.NoError(t, err)
+	assert.False(t, idle.Spec.PreviewGitSpec.Hibernated)
+	assert.Equal(t, now, kube.GetPreviewLastActive(&idle))
+	assert.Equal(t, "http://cheese.jx-pr-1.example.com", kube.PreviewApplicationURLStatus(&idle))
+	assertDeploymentReplicas(t, kubeClient, ns, "cheese", 3)
+	assertDeploymentReplicas(t, kubeClient, ns, "db", 1)
+}
+
+func assertDeploymentReplicas(t *testing.T, kubeClient *fake.Clientset, ns string, name string, expected int32) {
+	d, err := kubeClient.AppsV1beta1().Deployments(ns).Get(name, metav1.GetOptions{})
+	require.NoError(t, err)
+	if assert.NotNil(t, d.Spec.Replicas, "replicas of deployment %s", name) {
+		assert.Equal(t, expected, *d.Spec.Replicas, "replicas of deployment %s", name)
+	}
+	_, found := d.Annotations[kube.AnnotationHibernatedReplicas]
+	assert.Equal(t, expected == 0, found, "hibernated replicas annotation of deployment %s", name)
+}
+
+func TestPreviewDependencies(t *testing.T) {
+	t.Parallel()
+	env := kube.NewPreviewEnvironment("pr-1")
+	assert.Empty(t, kube.GetPreviewDependencies(env))
+
+	kube.SetPreviewDependencies(env, []string{"pr-1-postgres", "pr-1-redis"})
+	assert.Equal(t, "pr-1-postgres,pr-1-redis", env.Annotations[kube.AnnotationPreviewDependencies])
+	assert.Equal(t, []string{"pr-1-postgres", "pr-1-redis"}, kube.GetPreviewDependencies(env))
+
+	kube.SetPreviewDependencies(env, nil)
+	_, found := env.Annotations[kube.AnnotationPreviewDependencies]
+	assert.False(t, found)
+}
+
+func assertPreviewNames(t *testing.T, previews []v1.Environment, expected ...string) {
+	names := []string{}
+	for _, env := range previews {
+		names = append(names, env.Name)
+	}
+	if expected == nil {
+		expected = []string{}
+	}
+	assert.Equal(t, expected, names)
+}
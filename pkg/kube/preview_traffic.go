@@ -0,0 +1,104 @@
+package kube
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/expfmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultIngressControllerSelector the label selector of the pods of the nginx ingress controller installed by jx
+	DefaultIngressControllerSelector = "app=nginx-ingress,component=controller"
+
+	// ingressControllerMetricsPort the port of the prometheus metrics of the nginx ingress controller
+	ingressControllerMetricsPort = "10254"
+
+	// ingressRequestsMetric the prometheus counter of the requests handled by the nginx ingress controller
+	ingressRequestsMetric = "nginx_ingress_controller_requests"
+)
+
+// GetIngressRequestCounts returns the number of requests handled by the nginx ingress controller pods matching the
+// selector in the namespace for each namespace of the ingresses, using the prometheus metrics of the controllers
+func GetIngressRequestCounts(kubeClient kubernetes.Interface, ns string, selector string) (map[string]float64, error) {
+	answer := map[string]float64{}
+	pods, err := kubeClient.CoreV1().Pods(ns).List(metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return answer, errors.Wrapf(err, "failed to list the ingress controller pods in namespace %s", ns)
+	}
+	found := false
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		data, err := kubeClient.CoreV1().RESTClient().Get().Namespace(ns).Resource("pods").
+			Name(pod.Name + ":" + ingressControllerMetricsPort).SubResource("proxy").Suffix("metrics").DoRaw()
+		if err != nil {
+			log.Warnf("Failed to get the metrics of ingress controller pod %s: %s\n", pod.Name, err)
+			continue
+		}
+		err = ParseIngressRequestCounts(bytes.NewReader(data), answer)
+		if err != nil {
+			return answer, errors.Wrapf(err, "failed to parse the metrics of ingress controller pod %s", pod.Name)
+		}
+		found = true
+	}
+	if !found {
+		return answer, errors.Errorf("no running ingress controller pods matching %s found in namespace %s", selector, ns)
+	}
+	return answer, nil
+}
+
+// ParseIngressRequestCounts adds the requests of each namespace from the prometheus text metrics of an nginx ingress
+// controller to the counts
+func ParseIngressRequestCounts(r io.Reader, counts map[string]float64) error {
+	parser := expfmt.TextParser{}
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return err
+	}
+	family := families[ingressRequestsMetric]
+	if family == nil {
+		return nil
+	}
+	for _, metric := range family.GetMetric() {
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == "namespace" {
+				counts[label.GetValue()] += metric.GetCounter().GetValue()
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// RecordPreviewTraffic records the number of ingress requests to the namespace of the preview Environment. If the
+// number changed since it was last recorded the preview received traffic so its last traffic time is set to now.
+// Returns true if the Environment was modified and needs to be updated
+func RecordPreviewTraffic(env *v1.Environment, requests float64, now time.Time) bool {
+	value := strconv.FormatFloat(requests, 'f', -1, 64)
+	previous := ""
+	if env.Annotations != nil {
+		previous = env.Annotations[AnnotationPreviewIngressRequests]
+	}
+	if previous == value {
+		return false
+	}
+	// the first count is only a baseline and a lower count means the ingress controller was restarted
+	previousRequests, err := strconv.ParseFloat(previous, 64)
+	if err == nil && requests > previousRequests {
+		setAnnotation(env, AnnotationPreviewLastTraffic, now.UTC().Format(time.RFC3339))
+	}
+	setAnnotation(env, AnnotationPreviewIngressRequests, value)
+	return true
+}
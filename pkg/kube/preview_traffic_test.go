@@ -0,0 +1,54 @@
+package kube_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ingressControllerMetrics = `# HELP nginx_ingress_controller_requests The total number of client requests.
+# TYPE nginx_ingress_controller_requests counter
+nginx_ingress_controller_requests{controller_class="nginx",ingress="cheese",namespace="jx-jstrachan-cheese-pr-1",status="200"} 12
+nginx_ingress_controller_requests{controller_class="nginx",ingress="cheese",namespace="jx-jstrachan-cheese-pr-1",status="404"} 3
+nginx_ingress_controller_requests{controller_class="nginx",ingress="jenkins",namespace="jx",status="200"} 42
+# HELP nginx_ingress_controller_nginx_process_requests_total total number of client requests
+# TYPE nginx_ingress_controller_nginx_process_requests_total counter
+nginx_ingress_controller_nginx_process_requests_total{controller_class="nginx"} 1000
+`
+
+func TestParseIngressRequestCounts(t *testing.T) {
+	t.Parallel()
+	counts := map[string]float64{}
+	require.NoError(t, kube.ParseIngressRequestCounts(strings.NewReader(ingressControllerMetrics), counts))
+	// another ingress controller replica adds to the counts
+	require.NoError(t, kube.ParseIngressRequestCounts(strings.NewReader(ingressControllerMetrics), counts))
+	assert.Equal(t, map[string]float64{
+		"jx-jstrachan-cheese-pr-1": 30,
+		"jx":                       84,
+	}, counts)
+}
+
+func TestRecordPreviewTraffic(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2018, time.November, 14, 12, 0, 0, 0, time.UTC)
+	env := newTestPreview("pr-1", "https://github.com/jenkins-x/cheese.git", now.Add(-72*time.Hour))
+
+	// the first count is a baseline
+	assert.True(t, kube.RecordPreviewTraffic(&env, 15, now.Add(-2*time.Hour)))
+	assert.Equal(t, now.Add(-72*time.Hour), kube.GetPreviewLastActive(&env))
+
+	assert.False(t, kube.RecordPreviewTraffic(&env, 15, now.Add(-1*time.Hour)))
+	assert.Equal(t, now.Add(-72*time.Hour), kube.GetPreviewLastActive(&env))
+
+	assert.True(t, kube.RecordPreviewTraffic(&env, 20, now))
+	assert.Equal(t, now, kube.GetPreviewLastActive(&env))
+
+	// a restarted ingress controller starts counting again
+	assert.True(t, kube.RecordPreviewTraffic(&env, 2, now.Add(time.Hour)))
+	assert.Equal(t, now, kube.GetPreviewLastActive(&env))
+	assert.Equal(t, "2", env.Annotations[kube.AnnotationPreviewIngressRequests])
+}
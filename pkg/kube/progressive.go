@@ -0,0 +1,434 @@
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// CanarySubset the Istio DestinationRule subset which selects the pods of the canary release
+	CanarySubset = "canary"
+	// StableSubset the Istio DestinationRule subset which selects the pods of the current release
+	StableSubset = "stable"
+
+	// LabelRelease the label helm charts use to select the pods of a release
+	LabelRelease = "release"
+
+	// ServiceNameValue the helm value which names the Service of a chart. Releases deployed alongside the stable
+	// release set it to their own name so that they do not take over the Service receiving the traffic
+	ServiceNameValue = "service.name"
+
+	// DefaultPrometheusService the name of the Prometheus service installed by the prometheus addon
+	DefaultPrometheusService = "prometheus-server"
+)
+
+// DefaultCanarySteps the percentages of traffic sent to a canary if none are configured on the Environment
+var DefaultCanarySteps = []int32{10, 50}
+
+// VirtualServiceResource the Istio VirtualService resource
+var VirtualServiceResource = schema.GroupVersionResource{
+	Group:    "networking.istio.io",
+	Version:  "v1alpha3",
+	Resource: "virtualservices",
+}
+
+// DestinationRuleResource the Istio DestinationRule resource
+var DestinationRuleResource = schema.GroupVersionResource{
+	Group:    "networking.istio.io",
+	Version:  "v1alpha3",
+	Resource: "destinationrules",
+}
+
+// EnsureCanaryRouting creates or updates the Istio resources which split the traffic of the service between the
+// pods of the stable release and the pods of the canary release behind the canary service. The DestinationRules
+// define the stable and canary subsets using the release label of the pods and the VirtualService of the service
+// routes to both subsets, sending all of the traffic to the stable subset until the weights are changed
+func EnsureCanaryRouting(client dynamic.Interface, ns string, service string, stableRelease string, canaryService string, canaryRelease string) error {
+	err := ensureDestinationRuleSubset(client, ns, service, StableSubset, stableRelease)
+	if err != nil {
+		return err
+	}
+	err = ensureDestinationRuleSubset(client, ns, canaryService, CanarySubset, canaryRelease)
+	if err != nil {
+		return err
+	}
+	virtualServices := client.Resource(VirtualServiceResource).Namespace(ns)
+	vs, err := virtualServices.Get(service, meta_v1.GetOptions{})
+	create := false
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to find Istio VirtualService %s in namespace %s", service, ns)
+		}
+		create = true
+		vs = newIstioResource("VirtualService", service)
+		vs.Object["spec"] = map[string]interface{}{
+			"hosts": []interface{}{service},
+		}
+	}
+	err = SetCanaryRoutes(vs, service, canaryService)
+	if err != nil {
+		return errors.Wrapf(err, "failed to route Istio VirtualService %s to the canary", service)
+	}
+	if create {
+		_, err = virtualServices.Create(vs)
+	} else {
+		_, err = virtualServices.Update(vs)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to save Istio VirtualService %s in namespace %s", service, ns)
+	}
+	return nil
+}
+
+// SetCanaryRoutes replaces the destinations of every HTTP route of the VirtualService with the stable subset of the
+// stable host and the canary subset of the canary host, sending all of the traffic to the stable subset
+func SetCanaryRoutes(vs *unstructured.Unstructured, stableHost string, canaryHost string) error {
+	httpRoutes, _, err := unstructured.NestedSlice(vs.Object, "spec", "http")
+	if err != nil {
+		return err
+	}
+	if len(httpRoutes) == 0 {
+		httpRoutes = []interface{}{map[string]interface{}{}}
+	}
+	for _, httpRoute := range httpRoutes {
+		httpMap, ok := httpRoute.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		httpMap["route"] = []interface{}{
+			map[string]interface{}{
+				"destination": map[string]interface{}{"host": stableHost, "subset": StableSubset},
+				"weight":      int64(100),
+			},
+			map[string]interface{}{
+				"destination": map[string]interface{}{"host": canaryHost, "subset": CanarySubset},
+				"weight":      int64(0),
+			},
+		}
+	}
+	return unstructured.SetNestedSlice(vs.Object, httpRoutes, "spec", "http")
+}
+
+// SetDestinationRuleSubset adds or replaces the subset of the DestinationRule which selects the pods of the release
+func SetDestinationRuleSubset(dr *unstructured.Unstructured, host string, subset string, release string) error {
+	err := unstructured.SetNestedField(dr.Object, host, "spec", "host")
+	if err != nil {
+		return err
+	}
+	subsets, _, err := unstructured.NestedSlice(dr.Object, "spec", "subsets")
+	if err != nil {
+		return err
+	}
+	answer := []interface{}{}
+	for _, s := range subsets {
+		if m, ok := s.(map[string]interface{}); ok && m["name"] == subset {
+			continue
+		}
+		answer = append(answer, s)
+	}
+	answer = append(answer, map[string]interface{}{
+		"name":   subset,
+		"labels": map[string]interface{}{LabelRelease: release},
+	})
+	return unstructured.SetNestedSlice(dr.Object, answer, "spec", "subsets")
+}
+
+func ensureDestinationRuleSubset(client dynamic.Interface, ns string, host string, subset string, release string) error {
+	destinationRules := client.Resource(DestinationRuleResource).Namespace(ns)
+	dr, err := destinationRules.Get(host, meta_v1.GetOptions{})
+	create := false
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to find Istio DestinationRule %s in namespace %s", host, ns)
+		}
+		create = true
+		dr = newIstioResource("DestinationRule", host)
+	}
+	err = SetDestinationRuleSubset(dr, host, subset, release)
+	if err != nil {
+		return errors.Wrapf(err, "failed to add subset %s to Istio DestinationRule %s", subset, host)
+	}
+	if create {
+		_, err = destinationRules.Create(dr)
+	} else {
+		_, err = destinationRules.Update(dr)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to save Istio DestinationRule %s in namespace %s", host, ns)
+	}
+	return nil
+}
+
+func newIstioResource(kind string, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": VirtualServiceResource.GroupVersion().String(),
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		},
+	}
+}
+
+// SetCanaryWeight sends the given percentage of the traffic of the Istio VirtualService to the canary subset and the rest to the stable subset
+func SetCanaryWeight(client dynamic.Interface, ns string, name string, weight int32) error {
+	virtualServices := client.Resource(VirtualServiceResource).Namespace(ns)
+	vs, err := virtualServices.Get(name, meta_v1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to find Istio VirtualService %s in namespace %s", name, ns)
+	}
+	err = SetVirtualServiceWeights(vs, map[string]int64{
+		StableSubset: int64(100 - weight),
+		CanarySubset: int64(weight),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the weights of Istio VirtualService %s in namespace %s", name, ns)
+	}
+	_, err = virtualServices.Update(vs)
+	return err
+}
+
+// SetVirtualServiceWeights updates the weight of every HTTP route destination of the VirtualService using the weight of its subset
+func SetVirtualServiceWeights(vs *unstructured.Unstructured, weights map[string]int64) error {
+	httpRoutes, found, err := unstructured.NestedSlice(vs.Object, "spec", "http")
+	if err != nil {
+		return err
+	}
+	if !found || len(httpRoutes) == 0 {
+		return fmt.Errorf("VirtualService %s has no http routes", vs.GetName())
+	}
+	matched := false
+	for _, httpRoute := range httpRoutes {
+		httpMap, ok := httpRoute.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		routes, _, err := unstructured.NestedSlice(httpMap, "route")
+		if err != nil {
+			return err
+		}
+		for _, route := range routes {
+			routeMap, ok := route.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			subset, _, err := unstructured.NestedString(routeMap, "destination", "subset")
+			if err != nil {
+				return err
+			}
+			weight, ok := weights[subset]
+			if !ok {
+				continue
+			}
+			routeMap["weight"] = weight
+			matched = true
+		}
+		err = unstructured.SetNestedSlice(httpMap, routes, "route")
+		if err != nil {
+			return err
+		}
+	}
+	if !matched {
+		return fmt.Errorf("VirtualService %s has no route destinations for the subsets %s and %s", vs.GetName(), StableSubset, CanarySubset)
+	}
+	return unstructured.SetNestedSlice(vs.Object, httpRoutes, "spec", "http")
+}
+
+// GetActiveRelease returns the helm release the Service currently sends traffic to for blue/green deployments or
+// an empty string if the Service does not exist yet
+func GetActiveRelease(kubeClient kubernetes.Interface, ns string, name string) (string, error) {
+	svc, err := kubeClient.CoreV1().Services(ns).Get(name, meta_v1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "failed to find Service %s in namespace %s", name, ns)
+	}
+	return svc.Spec.Selector[LabelRelease], nil
+}
+
+// FindReleaseService returns the Service named after the helm release or the first Service with the release label
+func FindReleaseService(kubeClient kubernetes.Interface, ns string, release string) (*corev1.Service, error) {
+	services := kubeClient.CoreV1().Services(ns)
+	svc, err := services.Get(release, meta_v1.GetOptions{})
+	if err == nil {
+		return svc, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	list, err := services.List(meta_v1.ListOptions{
+		LabelSelector: LabelRelease + "=" + release,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("no Service found for release %s in namespace %s", release, ns)
+	}
+	return &list.Items[0], nil
+}
+
+// SwitchServiceRelease changes the selector of the Service so that it sends traffic to the pods of the given helm
+// release. The selector of the Service of the release is used so that labels such as the app name of the release
+// also match. The Service is created from the Service of the release if it does not exist. It is not owned by any
+// release so that removing the previous release does not remove the Service
+func SwitchServiceRelease(kubeClient kubernetes.Interface, ns string, name string, release string) error {
+	services := kubeClient.CoreV1().Services(ns)
+	releaseSvc, releaseErr := FindReleaseService(kubeClient, ns, release)
+	svc, err := services.Get(name, meta_v1.GetOptions{})
+	create := false
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to find Service %s in namespace %s", name, ns)
+		}
+		if releaseErr != nil {
+			return errors.Wrapf(releaseErr, "failed to create Service %s in namespace %s", name, ns)
+		}
+		create = true
+		svc = &corev1.Service{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+			},
+			Spec: corev1.ServiceSpec{
+				Type: releaseSvc.Spec.Type,
+			},
+		}
+		for _, port := range releaseSvc.Spec.Ports {
+			port.NodePort = 0
+			svc.Spec.Ports = append(svc.Spec.Ports, port)
+		}
+	}
+	selector := map[string]string{}
+	if releaseErr == nil && releaseSvc.Name != name {
+		for k, v := range releaseSvc.Spec.Selector {
+			selector[k] = v
+		}
+	} else {
+		for k, v := range svc.Spec.Selector {
+			selector[k] = v
+		}
+	}
+	selector[LabelRelease] = release
+	svc.Spec.Selector = selector
+	if create {
+		_, err = services.Create(svc)
+	} else {
+		_, err = services.Update(svc)
+	}
+	return err
+}
+
+// DeleteReleaseWorkloads deletes the Deployments of the helm release without removing the release itself so that
+// Services it still shares with other releases keep serving traffic
+func DeleteReleaseWorkloads(kubeClient kubernetes.Interface, ns string, release string) error {
+	deployments := kubeClient.AppsV1beta1().Deployments(ns)
+	list, err := deployments.List(meta_v1.ListOptions{
+		LabelSelector: LabelRelease + "=" + release,
+	})
+	if err != nil {
+		return err
+	}
+	for _, d := range list.Items {
+		err = deployments.Delete(d.Name, &meta_v1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete Deployment %s in namespace %s", d.Name, ns)
+		}
+	}
+	return nil
+}
+
+// BlueGreenReleaseName returns the name of the release to deploy the next version into given the currently active release
+func BlueGreenReleaseName(releaseName string, activeRelease string) string {
+	if activeRelease == releaseName+"-blue" {
+		return releaseName + "-green"
+	}
+	return releaseName + "-blue"
+}
+
+type prometheusQueryResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Value []interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// QueryPrometheus evaluates the PromQL query using the HTTP API of the Prometheus server and returns the values of the resulting vector
+func QueryPrometheus(prometheusURL string, query string) ([]float64, error) {
+	u := strings.TrimSuffix(prometheusURL, "/") + "/api/v1/query?query=" + url.QueryEscape(query)
+	resp, err := http.Get(u)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query Prometheus at %s", prometheusURL)
+	}
+	defer resp.Body.Close()
+
+	result := prometheusQueryResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the response of Prometheus at %s with status %s", prometheusURL, resp.Status)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("Prometheus query %s failed with %s: %s", query, result.ErrorType, result.Error)
+	}
+	if result.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("Prometheus query %s returned a %s rather than a vector", query, result.Data.ResultType)
+	}
+	answer := []float64{}
+	for _, r := range result.Data.Result {
+		if len(r.Value) != 2 {
+			continue
+		}
+		text, ok := r.Value[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return answer, errors.Wrapf(err, "failed to parse Prometheus value %s", text)
+		}
+		answer = append(answer, value)
+	}
+	return answer, nil
+}
+
+// AnalysePromotion returns an error if any value of the analysis query is greater than its maximum value
+func AnalysePromotion(analysis *v1.PromotionAnalysis, prometheusURL string) error {
+	if analysis == nil || analysis.Query == "" {
+		return nil
+	}
+	maxValue, err := strconv.ParseFloat(analysis.MaxValue, 64)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the maxValue %s of the promotion analysis", analysis.MaxValue)
+	}
+	values, err := QueryPrometheus(prometheusURL, analysis.Query)
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		if value > maxValue {
+			return fmt.Errorf("the value %v of the query %s is greater than the maximum %v", value, analysis.Query, maxValue)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package kube_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSetVirtualServiceWeights(t *testing.T) {
+	t.Parallel()
+	vs := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "myapp",
+			},
+			"spec": map[string]interface{}{
+				"http": []interface{}{
+					map[string]interface{}{
+						"route": []interface{}{
+							map[string]interface{}{
+								"destination": map[string]interface{}{"host": "myapp", "subset": kube.StableSubset},
+								"weight":      int64(100),
+							},
+							map[string]interface{}{
+								"destination": map[string]interface{}{"host": "myapp", "subset": kube.CanarySubset},
+								"weight":      int64(0),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := kube.SetVirtualServiceWeights(vs, map[string]int64{kube.StableSubset: 75, kube.CanarySubset: 25})
+	require.NoError(t, err)
+
+	httpRoutes, _, err := unstructured.NestedSlice(vs.Object, "spec", "http")
+	require.NoError(t, err)
+	routes, _, err := unstructured.NestedSlice(httpRoutes[0].(map[string]interface{}), "route")
+	require.NoError(t, err)
+	require.Len(t, routes, 2)
+	assert.Equal(t, int64(75), routes[0].(map[string]interface{})["weight"])
+	assert.Equal(t, int64(25), routes[1].(map[string]interface{})["weight"])
+}
+
+func TestSetVirtualServiceWeightsWithoutSubsets(t *testing.T) {
+	t.Parallel()
+	vs := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"http": []interface{}{
+					map[string]interface{}{
+						"route": []interface{}{
+							map[string]interface{}{
+								"destination": map[string]interface{}{"host": "myapp"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := kube.SetVirtualServiceWeights(vs, map[string]int64{kube.StableSubset: 50, kube.CanarySubset: 50})
+	assert.Error(t, err)
+}
+
+func TestSetCanaryRoutes(t *testing.T) {
+	t.Parallel()
+	vs := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "myapp",
+			},
+			"spec": map[string]interface{}{
+				"hosts": []interface{}{"myapp"},
+			},
+		},
+	}
+
+	err := kube.SetCanaryRoutes(vs, "myapp", "jx-production-myapp-canary")
+	require.NoError(t, err)
+	err = kube.SetVirtualServiceWeights(vs, map[string]int64{kube.StableSubset: 90, kube.CanarySubset: 10})
+	require.NoError(t, err)
+
+	httpRoutes, _, err := unstructured.NestedSlice(vs.Object, "spec", "http")
+	require.NoError(t, err)
+	require.Len(t, httpRoutes, 1)
+	routes, _, err := unstructured.NestedSlice(httpRoutes[0].(map[string]interface{}), "route")
+	require.NoError(t, err)
+	require.Len(t, routes, 2)
+	assert.Equal(t, map[string]interface{}{
+		"destination": map[string]interface{}{"host": "myapp", "subset": kube.StableSubset},
+		"weight":      int64(90),
+	}, routes[0])
+	assert.Equal(t, map[string]interface{}{
+		"destination": map[string]interface{}{"host": "jx-production-myapp-canary", "subset": kube.CanarySubset},
+		"weight":      int64(10),
+	}, routes[1])
+}
+
+func TestSetDestinationRuleSubset(t *testing.T) {
+	t.Parallel()
+	dr := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "myapp",
+			},
+			"spec": map[string]interface{}{
+				"trafficPolicy": map[string]interface{}{"tls": map[string]interface{}{"mode": "ISTIO_MUTUAL"}},
+				"subsets": []interface{}{
+					map[string]interface{}{"name": kube.StableSubset, "labels": map[string]interface{}{"version": "v1"}},
+					map[string]interface{}{"name": "other", "labels": map[string]interface{}{"version": "v2"}},
+				},
+			},
+		},
+	}
+
+	err := kube.SetDestinationRuleSubset(dr, "myapp", kube.StableSubset, "jx-production-myapp")
+	require.NoError(t, err)
+
+	host, _, err := unstructured.NestedString(dr.Object, "spec", "host")
+	require.NoError(t, err)
+	assert.Equal(t, "myapp", host)
+	_, found, err := unstructured.NestedMap(dr.Object, "spec", "trafficPolicy")
+	require.NoError(t, err)
+	assert.True(t, found, "the traffic policy should be kept")
+	subsets, _, err := unstructured.NestedSlice(dr.Object, "spec", "subsets")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "other", "labels": map[string]interface{}{"version": "v2"}},
+		map[string]interface{}{"name": kube.StableSubset, "labels": map[string]interface{}{kube.LabelRelease: "jx-production-myapp"}},
+	}, subsets)
+}
+
+func TestSwitchServiceRelease(t *testing.T) {
+	t.Parallel()
+	kubeClient := fake.NewSimpleClientset(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myapp",
+			Namespace: "jx-production",
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				"app":             "myapp",
+				kube.LabelRelease: "jx-production-myapp-blue",
+			},
+		},
+	})
+
+	active, err := kube.GetActiveRelease(kubeClient, "jx-production", "myapp")
+	require.NoError(t, err)
+	assert.Equal(t, "jx-production-myapp-blue", active)
+
+	next := kube.BlueGreenReleaseName("jx-production-myapp", active)
+	assert.Equal(t, "jx-production-myapp-green", next)
+
+	err = kube.SwitchServiceRelease(kubeClient, "jx-production", "myapp", next)
+	require.NoError(t, err)
+
+	svc, err := kubeClient.CoreV1().Services("jx-production").Get("myapp", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "jx-production-myapp-green", svc.Spec.Selector[kube.LabelRelease])
+	assert.Equal(t, "myapp", svc.Spec.Selector["app"])
+
+	assert.Equal(t, "jx-production-myapp-blue", kube.BlueGreenReleaseName("jx-production-myapp", next))
+	assert.Equal(t, "jx-production-myapp-blue", kube.BlueGreenReleaseName("jx-production-myapp", ""))
+}
+
+func TestAnalysePromotion(t *testing.T) {
+	t.Parallel()
+	errorRate := "0.01"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/query", r.URL.Path)
+		assert.Equal(t, "myapp_error_rate", r.URL.Query().Get("query"))
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1540000000.0,"%s"]}]}}`, errorRate)
+	}))
+	defer server.Close()
+
+	analysis := &v1.PromotionAnalysis{
+		Query:    "myapp_error_rate",
+		MaxValue: "0.05",
+	}
+	err := kube.AnalysePromotion(analysis, server.URL)
+	assert.NoError(t, err)
+
+	errorRate = "0.2"
+	err = kube.AnalysePromotion(analysis, server.URL)
+	assert.Error(t, err)
+
+	err = kube.AnalysePromotion(nil, server.URL)
+	assert.NoError(t, err)
+}
@@ -31,6 +31,37 @@ func GetOrCreateRelease(jxClient versioned.Interface, ns string, release *v1.Rel
 	return answer, nil
 }
 
+// FindReleaseCommits returns the commits of the Release built by the PipelineActivity which is either the Release
+// of the same repository and version or the Release containing the last commit of the activity
+func FindReleaseCommits(jxClient versioned.Interface, ns string, activity *v1.PipelineActivity) ([]v1.CommitSummary, error) {
+	releaseList, err := jxClient.JenkinsV1().Releases(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list Releases in namespace %s", ns)
+	}
+	spec := &activity.Spec
+	repoName := activity.RepositoryName()
+	for _, release := range releaseList.Items {
+		if spec.Version == "" || release.Spec.Version != spec.Version {
+			continue
+		}
+		if release.Spec.GitRepository == repoName || release.Spec.Name == repoName {
+			if spec.GitOwner == "" || release.Spec.GitOwner == "" || release.Spec.GitOwner == spec.GitOwner {
+				return release.Spec.Commits, nil
+			}
+		}
+	}
+	if spec.LastCommitSHA != "" {
+		for _, release := range releaseList.Items {
+			for _, commit := range release.Spec.Commits {
+				if commit.SHA == spec.LastCommitSHA {
+					return release.Spec.Commits, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
 type ReleaseOrder []v1.Release
 
 func (a ReleaseOrder) Len() int      { return len(a) }
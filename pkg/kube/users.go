@@ -2,6 +2,7 @@ package kube
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
@@ -54,3 +55,41 @@ func DeleteUser(jxClient versioned.Interface, ns string, userName string) error
 	}
 	return err
 }
+
+// GetUserDetails returns the details of the user falling back to the deprecated User field for older resources
+func GetUserDetails(user *v1.User) *v1.UserDetails {
+	if user.Spec.Login == "" && user.Spec.Email == "" {
+		return &user.User
+	}
+	return &user.Spec
+}
+
+// FindUsersForCommits returns the Users who authored or committed the commits matching them on email or login.
+// Each User is only returned once regardless of how many commits they made
+func FindUsersForCommits(users []v1.User, commits []v1.CommitSummary) []*v1.User {
+	answer := []*v1.User{}
+	found := map[string]bool{}
+	for _, commit := range commits {
+		for _, details := range []*v1.UserDetails{commit.Author, commit.Committer} {
+			if details == nil {
+				continue
+			}
+			for i := range users {
+				user := &users[i]
+				if found[user.Name] || !matchesUserDetails(GetUserDetails(user), details) {
+					continue
+				}
+				found[user.Name] = true
+				answer = append(answer, user)
+			}
+		}
+	}
+	return answer
+}
+
+func matchesUserDetails(user *v1.UserDetails, details *v1.UserDetails) bool {
+	if user.Email != "" && strings.EqualFold(user.Email, details.Email) {
+		return true
+	}
+	return user.Login != "" && user.Login == details.Login
+}
@@ -1,9 +1,13 @@
 package workflow
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/jenkins-x/jx/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx/pkg/kube"
+	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -73,6 +77,74 @@ func CreateWorkflowPromoteStep(envName string, preconditionSteps ...v1.WorkflowS
 			Environment: envName,
 		},
 	}
+	addPreconditions(&answer, preconditionSteps)
+	return answer
+}
+
+// CreateWorkflowParallelPromoteStep creates a Workflow step which promotes to all of the given environments at once
+func CreateWorkflowParallelPromoteStep(name string, envNames []string, preconditionSteps ...v1.WorkflowStep) v1.WorkflowStep {
+	answer := v1.WorkflowStep{
+		Kind: v1.WorkflowStepKindTypeParallel,
+		Name: name,
+		Parallel: &v1.ParallelWorkflowStep{
+			Environments: envNames,
+		},
+	}
+	addPreconditions(&answer, preconditionSteps)
+	return answer
+}
+
+// CreateWorkflowApprovalStep creates a Workflow step which waits for a manual approval before promoting to the environment
+func CreateWorkflowApprovalStep(name string, envName string, preconditionSteps ...v1.WorkflowStep) v1.WorkflowStep {
+	answer := v1.WorkflowStep{
+		Kind: v1.WorkflowStepKindTypeApproval,
+		Name: name,
+		Approval: &v1.ApprovalWorkflowStep{
+			Environment: envName,
+		},
+	}
+	addPreconditions(&answer, preconditionSteps)
+	return answer
+}
+
+// CreateWorkflowWaitStep creates a Workflow step which waits for the given duration such as `30m`
+func CreateWorkflowWaitStep(name string, duration string, preconditionSteps ...v1.WorkflowStep) v1.WorkflowStep {
+	answer := v1.WorkflowStep{
+		Kind: v1.WorkflowStepKindTypeWait,
+		Name: name,
+		Wait: &v1.WaitWorkflowStep{
+			Duration: duration,
+		},
+	}
+	addPreconditions(&answer, preconditionSteps)
+	return answer
+}
+
+// CreateWorkflowJobStep creates a Workflow step which runs a verification Job in the namespace of the environment
+func CreateWorkflowJobStep(name string, envName string, spec batchv1.JobSpec, preconditionSteps ...v1.WorkflowStep) v1.WorkflowStep {
+	answer := v1.WorkflowStep{
+		Kind: v1.WorkflowStepKindTypeJob,
+		Name: name,
+		Job: &v1.JobWorkflowStep{
+			Environment: envName,
+			Spec:        spec,
+		},
+	}
+	addPreconditions(&answer, preconditionSteps)
+	return answer
+}
+
+// StepName returns the name of the step or a default name based on its kind and index in the workflow
+func StepName(step *v1.WorkflowStep, index int) string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return fmt.Sprintf("%s-%d", strings.ToLower(string(step.Kind)), index+1)
+}
+
+// addPreconditions makes the step wait for the environments promoted by the promote steps and for
+// the other kinds of step to succeed
+func addPreconditions(answer *v1.WorkflowStep, preconditionSteps []v1.WorkflowStep) {
 	for _, preconditionStep := range preconditionSteps {
 		promote := preconditionStep.Promote
 		if promote != nil {
@@ -80,7 +152,8 @@ func CreateWorkflowPromoteStep(envName string, preconditionSteps ...v1.WorkflowS
 			if envName != "" {
 				answer.Preconditions.Environments = append(answer.Preconditions.Environments, envName)
 			}
+		} else if preconditionStep.Name != "" {
+			answer.Preconditions.Steps = append(answer.Preconditions.Steps, preconditionStep.Name)
 		}
 	}
-	return answer
 }